@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"w2w/internal/database"
+	"w2w/internal/embeddings"
+	"w2w/internal/eval"
+	"w2w/internal/llm"
+	"w2w/internal/services"
+)
+
+func main() {
+	godotenv.Load()
+
+	openaiKey := os.Getenv("OPENAI_API_KEY")
+	dbPath := os.Getenv("DATABASE_PATH")
+	if dbPath == "" {
+		dbPath = "./vibe.db"
+	}
+
+	goldenPath := ""
+	configsPath := ""
+	baselinePath := ""
+	outPath := ""
+	k := 0
+	offline := openaiKey == ""
+	similar := false
+
+	for _, arg := range os.Args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "--golden="):
+			goldenPath = strings.TrimPrefix(arg, "--golden=")
+		case strings.HasPrefix(arg, "--configs="):
+			configsPath = strings.TrimPrefix(arg, "--configs=")
+		case strings.HasPrefix(arg, "--baseline="):
+			baselinePath = strings.TrimPrefix(arg, "--baseline=")
+		case strings.HasPrefix(arg, "--out="):
+			outPath = strings.TrimPrefix(arg, "--out=")
+		case strings.HasPrefix(arg, "--k="):
+			k, _ = strconv.Atoi(strings.TrimPrefix(arg, "--k="))
+		case arg == "--offline":
+			offline = true
+		case arg == "--similar":
+			similar = true
+		case arg == "--help":
+			fmt.Println("Usage: eval --golden=FILE [flags]")
+			fmt.Println("  --golden=FILE    Golden queries with relevant media IDs (YAML or JSON)")
+			fmt.Println("  --configs=FILE   Pipeline variants to compare (default: vector, rerank, hybrid, mmr)")
+			fmt.Println("  --baseline=FILE  Saved run to diff against")
+			fmt.Println("  --out=FILE       Save this run as JSON")
+			fmt.Println("  --k=N            Cutoff for recall/nDCG/MRR (default 10)")
+			fmt.Println("  --offline        Use the hash embedder and overlap reranker (no network)")
+			fmt.Println("  --similar        Also score /similar against mined Reddit similar_to judgments")
+			fmt.Println()
+			fmt.Println("Runs offline automatically when OPENAI_API_KEY is not set. Offline runs")
+			fmt.Println("re-embed every vibe profile in memory; the database is not modified.")
+			os.Exit(0)
+		}
+	}
+	if goldenPath == "" && !similar {
+		log.Fatal("--golden or --similar is required (see --help)")
+	}
+
+	set := &eval.GoldenSet{}
+	if goldenPath != "" {
+		var err error
+		set, err = eval.LoadGoldenSet(goldenPath)
+		if err != nil {
+			log.Fatalf("Golden set error: %v", err)
+		}
+	}
+	plan := eval.DefaultPlan()
+	if configsPath != "" {
+		p, err := eval.LoadPlan(configsPath)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		plan = *p
+	}
+	if k > 0 {
+		plan.K = k
+	}
+
+	var embedder embeddings.Provider
+	var llmClient *llm.Client
+	if offline {
+		embedder = eval.HashEmbedder{}
+	} else {
+		embedder = embeddings.NewOpenAIProvider(openaiKey)
+		llmClient = llm.NewClient(openaiKey)
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("  Vibe Search Evaluation")
+	fmt.Println("========================================")
+	fmt.Printf("  Database:  %s\n", dbPath)
+	if goldenPath != "" {
+		fmt.Printf("  Golden:    %s (%d queries)\n", set.Name, len(set.Queries))
+	}
+	fmt.Printf("  Similar:   %v\n", similar)
+	fmt.Printf("  Embedder:  %s\n", embedder.ModelName())
+	fmt.Printf("  K:         %d\n", plan.K)
+	fmt.Printf("  Configs:   %d\n", len(plan.Configs))
+	fmt.Println("========================================")
+	fmt.Println()
+
+	// Open without migrating: New would migrate and back up first, and an
+	// evaluation must not touch the database it scores
+	db, err := database.Open(dbPath)
+	if err != nil {
+		log.Fatalf("Database error: %v", err)
+	}
+	defer db.Close()
+	if v, err := db.SchemaVersion(); err != nil {
+		log.Fatalf("Database error: %v", err)
+	} else if v != database.LatestVersion() {
+		log.Fatalf("Database is at schema version %d, this build expects %d; run cmd/migrate first", v, database.LatestVersion())
+	}
+
+	// Read-only so an offline run cannot re-embed the axes with the hash
+	// embedder or seed anything into a live database
+	svc, err := services.NewReadOnlyVibeSearchService(db, embedder, llmClient)
+	if err != nil {
+		log.Fatalf("Vibe search error: %v", err)
+	}
+	if offline {
+		svc.SetReranker(eval.OverlapReranker{})
+		n, err := svc.Reindex()
+		if err != nil {
+			log.Fatalf("Reindex failed: %v", err)
+		}
+		fmt.Printf("  Re-embedded %d vibe profiles offline\n\n", n)
+	}
+
+	startTime := time.Now()
+	if goldenPath != "" {
+		report, err := eval.NewRunner(svc, embedder.ModelName()).Run(set, plan)
+		if err != nil {
+			log.Fatalf("Evaluation failed: %v", err)
+		}
+
+		fmt.Printf("  %-12s %8s %8s %8s %8s %8s\n", "config", "recall", "ndcg", "mrr", "coverage", "ild")
+		for _, c := range report.Configs {
+			m := c.Metrics
+			fmt.Printf("  %-12s %8.3f %8.3f %8.3f %8.3f %8.3f\n", c.Config.Name, m.Recall, m.NDCG, m.MRR, m.Coverage, m.Diversity)
+		}
+
+		if baselinePath != "" {
+			baseline, err := eval.LoadReport(baselinePath)
+			if err != nil {
+				log.Fatalf("Baseline error: %v", err)
+			}
+			if baseline.Embedder != report.Embedder {
+				fmt.Printf("\n  WARNING: baseline used %s, this run used %s\n", baseline.Embedder, report.Embedder)
+			}
+			if baseline.K != report.K {
+				fmt.Printf("\n  WARNING: baseline scored at k=%d, this run at k=%d\n", baseline.K, report.K)
+			}
+
+			deltas, regressions := eval.Diff(baseline, report)
+			fmt.Printf("\n  vs baseline (%s)\n", baseline.CreatedAt.Format(time.RFC3339))
+			fmt.Printf("  %-12s %8s %8s %8s %8s %8s\n", "config", "recall", "ndcg", "mrr", "coverage", "ild")
+			for _, d := range deltas {
+				m := d.Delta()
+				fmt.Printf("  %-12s %+8.3f %+8.3f %+8.3f %+8.3f %+8.3f\n", d.Config, m.Recall, m.NDCG, m.MRR, m.Coverage, m.Diversity)
+			}
+			if len(regressions) > 0 {
+				fmt.Println("\n  Queries with lower nDCG:")
+				for _, r := range regressions {
+					fmt.Printf("    [%s] %s %q: %.3f -> %.3f\n", r.Config, r.ID, r.Query, r.Baseline, r.Current)
+				}
+			}
+		}
+
+		if outPath != "" {
+			if err := report.Save(outPath); err != nil {
+				log.Fatalf("Failed to save run: %v", err)
+			}
+			fmt.Printf("\n  Saved run to %s\n", outPath)
+		}
+	}
+
+	if similar {
+		// Judgments are read as last mined by the server's job; this never
+		// re-mines, so the database stays untouched
+		run, err := services.NewJudgmentJob(db, svc).Score(plan.K)
+		if err != nil {
+			log.Fatalf("Similar scoring failed: %v", err)
+		}
+		fmt.Println()
+		if run == nil {
+			fmt.Println("  /similar: no reference has enough similar_to judgments yet")
+		} else {
+			fmt.Printf("  /similar vs Reddit judgments (%d references, %d judgments)\n", run.ReferenceCount, run.JudgmentCount)
+			fmt.Printf("  ndcg@%d %.3f   recall %.3f   mrr %.3f\n", run.K, run.NDCG, run.Recall, run.MRR)
+		}
+	}
+
+	fmt.Println("\n========================================")
+	fmt.Printf("  Duration: %s\n", time.Since(startTime).Round(time.Millisecond))
+	fmt.Println("========================================")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"w2w/internal/database"
+	"w2w/internal/llm"
+	"w2w/internal/services"
+)
+
+func main() {
+	godotenv.Load()
+
+	openaiKey := os.Getenv("OPENAI_API_KEY")
+	dbPath := os.Getenv("DATABASE_PATH")
+	if dbPath == "" {
+		dbPath = "./vibe.db"
+	}
+
+	force := false
+	for _, arg := range os.Args[1:] {
+		switch arg {
+		case "--force":
+			force = true
+		case "--help":
+			fmt.Println("Usage: facet-backfill [flags]")
+			fmt.Println("  --force  Re-extract facets for every media entry, not just untagged ones")
+			fmt.Println()
+			fmt.Println("Uses the LLM when OPENAI_API_KEY is set, keyword matching otherwise.")
+			os.Exit(0)
+		}
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("  Vibe Facet Backfill")
+	fmt.Println("========================================")
+	fmt.Printf("  Database:  %s\n", dbPath)
+	fmt.Printf("  Extractor: %s\n", map[bool]string{true: "llm", false: "keyword"}[openaiKey != ""])
+	fmt.Printf("  Force:     %v\n", force)
+	fmt.Println("========================================")
+	fmt.Println()
+
+	db, err := database.New(dbPath)
+	if err != nil {
+		log.Fatalf("Database error: %v", err)
+	}
+	defer db.Close()
+
+	var llmClient *llm.Client
+	if openaiKey != "" {
+		llmClient = llm.NewClient(openaiKey)
+	}
+
+	facets, err := services.NewFacetService(db, llmClient)
+	if err != nil {
+		log.Fatalf("Facet service error: %v", err)
+	}
+
+	startTime := time.Now()
+	errors := 0
+	tagged, err := facets.Backfill(force, func(done, total int, mediaID string, err error) {
+		if err != nil {
+			errors++
+			fmt.Printf("  [%d/%d] %s — error: %v\n", done, total, mediaID, err)
+		}
+		if done%50 == 0 || done == total {
+			fmt.Printf("  ... %d/%d processed\n", done, total)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	fmt.Println("\n========================================")
+	fmt.Println("  Backfill Complete!")
+	fmt.Println("========================================")
+	fmt.Printf("  Tagged:   %d\n", tagged)
+	fmt.Printf("  Errors:   %d\n", errors)
+	fmt.Printf("  Duration: %s\n", time.Since(startTime).Round(time.Second))
+	fmt.Println("========================================")
+}
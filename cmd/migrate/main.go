@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"w2w/internal/database"
+)
+
+func usage() {
+	fmt.Println("Usage: migrate [command]")
+	fmt.Println("  status        Show which migrations are applied (default)")
+	fmt.Println("  up            Apply every pending migration")
+	fmt.Println("  to VERSION    Migrate up or down to VERSION")
+	fmt.Println()
+	fmt.Println("Only migrations with a rollback can be undone. The baseline (version 1)")
+	fmt.Println("has none, so no database can go below version 1.")
+	fmt.Println()
+	fmt.Println("The database is backed up next to itself before anything changes.")
+}
+
+func main() {
+	godotenv.Load()
+
+	dbPath := os.Getenv("DATABASE_PATH")
+	if dbPath == "" {
+		dbPath = "./vibe.db"
+	}
+
+	command := "status"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	target := -1
+	switch command {
+	case "status":
+	case "up":
+		target = database.LatestVersion()
+	case "to":
+		if len(os.Args) < 3 {
+			usage()
+			os.Exit(1)
+		}
+		v, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			log.Fatalf("Invalid version %q", os.Args[2])
+		}
+		target = v
+	case "--help", "help":
+		usage()
+		os.Exit(0)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("  Schema Migrations")
+	fmt.Println("========================================")
+	fmt.Printf("  Database: %s\n", dbPath)
+	fmt.Printf("  Latest:   %d\n", database.LatestVersion())
+	fmt.Println("========================================")
+	fmt.Println()
+
+	db, err := database.Open(dbPath)
+	if err != nil {
+		log.Fatalf("Database error: %v", err)
+	}
+	defer db.Close()
+
+	if target >= 0 {
+		backup, err := db.MigrateTo(target)
+		if backup != "" {
+			fmt.Printf("Backed up to %s\n", backup)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		fmt.Println()
+	}
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		log.Fatalf("Failed to read migrations: %v", err)
+	}
+	version, err := db.SchemaVersion()
+	if err != nil {
+		log.Fatalf("Failed to read schema version: %v", err)
+	}
+
+	fmt.Printf("  %-8s %-28s %s\n", "Version", "Name", "Applied")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.Applied {
+			applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case s.Unknown:
+			applied += "  (unknown to this build)"
+		case s.Modified:
+			applied += "  (changed since applied!)"
+		case !s.Reversible:
+			applied += "  (irreversible)"
+		}
+		fmt.Printf("  %-8d %-28s %s\n", s.Version, s.Name, applied)
+	}
+	fmt.Println()
+	fmt.Printf("Schema version: %d of %d\n", version, database.LatestVersion())
+}
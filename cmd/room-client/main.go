@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+)
+
+// room-client is a terminal client for a watch-party voting room, for
+// trying the WebSocket protocol locally without the frontend.
+func main() {
+	server := "http://localhost:8080"
+	invite := ""
+	groupID := ""
+	nickname := ""
+	sessionFile := ""
+
+	for _, arg := range os.Args[1:] {
+		switch {
+		case strings.HasPrefix(arg, "--server="):
+			server = strings.TrimSuffix(strings.TrimPrefix(arg, "--server="), "/")
+		case strings.HasPrefix(arg, "--invite="):
+			invite = strings.TrimPrefix(arg, "--invite=")
+		case strings.HasPrefix(arg, "--group="):
+			groupID = strings.TrimPrefix(arg, "--group=")
+		case strings.HasPrefix(arg, "--nickname="):
+			nickname = strings.TrimPrefix(arg, "--nickname=")
+		case strings.HasPrefix(arg, "--session-file="):
+			sessionFile = strings.TrimPrefix(arg, "--session-file=")
+		case arg == "--help":
+			fmt.Println("Usage: room-client (--invite=CODE | --group=ID) [flags]")
+			fmt.Println("  --server=URL         API server (default http://localhost:8080)")
+			fmt.Println("  --invite=CODE        Join the group behind an invite code, then enter its room")
+			fmt.Println("  --group=ID           Enter the room of a group you already belong to")
+			fmt.Println("  --nickname=NAME      Nickname to join with")
+			fmt.Println("  --session-file=FILE  Keep the session cookie here, so you can reconnect as the same member")
+			fmt.Println()
+			fmt.Println("Commands once connected:")
+			fmt.Println("  start <vibe>       Build a shortlist and start the countdown")
+			fmt.Println("                     (prefix with size=N countdown=SECS seen to tune it)")
+			fmt.Println("  up N | down N      Vote on shortlist entry N; clear N removes your vote")
+			fmt.Println("  veto N             Use your one veto this round")
+			fmt.Println("  suggest MEDIA_ID   Add a title to the shortlist")
+			fmt.Println("  finish             End the countdown now (owner only)")
+			fmt.Println("  state | quit")
+			os.Exit(0)
+		}
+	}
+	if invite == "" && groupID == "" {
+		log.Fatal("--invite or --group is required (see --help)")
+	}
+
+	base, err := url.Parse(server)
+	if err != nil {
+		log.Fatalf("Invalid server URL: %v", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	if sessionFile != "" {
+		if raw, err := os.ReadFile(sessionFile); err == nil {
+			jar.SetCookies(base, []*http.Cookie{{Name: middleware.SessionCookieName, Value: strings.TrimSpace(string(raw))}})
+		}
+	}
+	client := &http.Client{Jar: jar}
+
+	if invite != "" {
+		body, _ := json.Marshal(models.GroupJoinRequest{InviteCode: invite, Nickname: nickname})
+		resp, err := client.Post(server+"/api/groups/join", "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Fatalf("Join failed: %v", err)
+		}
+		var group models.Group
+		err = json.NewDecoder(resp.Body).Decode(&group)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			log.Fatalf("Join failed: HTTP %d", resp.StatusCode)
+		}
+		groupID = group.ID
+		fmt.Printf("Joined %q (group %s)\n", group.Name, group.ID)
+	} else {
+		// Any request gets us a session cookie if we do not have one yet
+		resp, err := client.Get(server + "/health")
+		if err != nil {
+			log.Fatalf("Server unreachable: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	var session string
+	for _, c := range jar.Cookies(base) {
+		if c.Name == middleware.SessionCookieName {
+			session = c.Value
+		}
+	}
+	if sessionFile != "" && session != "" {
+		if err := os.WriteFile(sessionFile, []byte(session), 0600); err != nil {
+			log.Printf("Could not save session: %v", err)
+		}
+	}
+
+	wsURL := *base
+	wsURL.Scheme = "ws"
+	if base.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	}
+	wsURL.Path = "/api/groups/" + groupID + "/room"
+
+	config, err := websocket.NewConfig(wsURL.String(), server)
+	if err != nil {
+		log.Fatalf("Invalid room URL: %v", err)
+	}
+	config.Header.Set("Cookie", middleware.SessionCookieName+"="+session)
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		log.Fatalf("Could not enter room: %v", err)
+	}
+	defer ws.Close()
+
+	var mu sync.Mutex
+	var shortlist []models.RoomCandidate
+
+	go func() {
+		for {
+			var event models.RoomEvent
+			if err := websocket.JSON.Receive(ws, &event); err != nil {
+				fmt.Println("Disconnected:", err)
+				os.Exit(0)
+			}
+			if event.Type == "error" {
+				fmt.Println("!", event.Error)
+				continue
+			}
+			if event.State != nil {
+				mu.Lock()
+				shortlist = event.State.Shortlist
+				mu.Unlock()
+				printState(event.State)
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		mu.Lock()
+		pick := func() (string, bool) {
+			if len(fields) < 2 {
+				return "", false
+			}
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 1 || n > len(shortlist) {
+				return "", false
+			}
+			return shortlist[n-1].MediaID, true
+		}
+		var msg *models.RoomMessage
+		switch fields[0] {
+		case "start":
+			msg = &models.RoomMessage{Type: "start"}
+			var words []string
+			for _, f := range fields[1:] {
+				switch {
+				case strings.HasPrefix(f, "size="):
+					msg.Size, _ = strconv.Atoi(strings.TrimPrefix(f, "size="))
+				case strings.HasPrefix(f, "countdown="):
+					msg.Countdown, _ = strconv.Atoi(strings.TrimPrefix(f, "countdown="))
+				case f == "seen":
+					msg.MarkSeen = true
+				default:
+					words = append(words, f)
+				}
+			}
+			msg.Query = strings.Join(words, " ")
+		case "up", "down", "clear", "veto":
+			id, ok := pick()
+			if !ok {
+				fmt.Println("! which one? use the number from the shortlist")
+				break
+			}
+			msg = &models.RoomMessage{Type: "vote", MediaID: id}
+			switch fields[0] {
+			case "up":
+				msg.Value = 1
+			case "down":
+				msg.Value = -1
+			case "veto":
+				msg.Type = "veto"
+			}
+		case "suggest":
+			if len(fields) < 2 {
+				fmt.Println("! suggest MEDIA_ID")
+				break
+			}
+			msg = &models.RoomMessage{Type: "suggest", MediaID: fields[1]}
+		case "finish", "state":
+			msg = &models.RoomMessage{Type: fields[0]}
+		case "quit", "exit":
+			ws.Close()
+			os.Exit(0)
+		default:
+			fmt.Println("! unknown command (see --help)")
+		}
+		mu.Unlock()
+
+		if msg != nil {
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				log.Fatalf("Send failed: %v", err)
+			}
+		}
+	}
+}
+
+func printState(s *models.RoomState) {
+	fmt.Println("----------------------------------------")
+	fmt.Printf("Round %d: %s", s.Round, s.Phase)
+	if s.Query != "" {
+		fmt.Printf(" — %q", s.Query)
+	}
+	if s.SecondsLeft > 0 {
+		fmt.Printf(" (%ds left)", s.SecondsLeft)
+	}
+	fmt.Println()
+
+	for i, c := range s.Shortlist {
+		mark := " "
+		switch {
+		case s.Winner != nil && s.Winner.MediaID == c.MediaID:
+			mark = "*"
+		case c.Vetoed:
+			mark = "x"
+		}
+		line := fmt.Sprintf(" %s %2d. %-32s +%d -%d", mark, i+1, c.Title, c.Up, c.Down)
+		if c.YourVote != 0 {
+			line += fmt.Sprintf("  (you %+d)", c.YourVote)
+		}
+		if c.Vetoed {
+			line += "  vetoed by " + c.VetoedBy
+		}
+		if c.SuggestedBy != "" {
+			line += "  suggested by " + c.SuggestedBy
+		}
+		fmt.Println(line)
+	}
+
+	var people []string
+	for _, p := range s.Participants {
+		name := p.Nickname
+		if p.IsYou {
+			name += " (you)"
+		}
+		if !p.Online {
+			name += " [offline]"
+		} else if p.Voted {
+			name += " ✓"
+		}
+		people = append(people, name)
+	}
+	fmt.Println("Here:", strings.Join(people, ", "))
+	if s.Winner != nil {
+		fmt.Printf("Winner: %s", s.Winner.Title)
+		if s.MarkSeen {
+			fmt.Print(" (marked as seen)")
+		}
+		fmt.Println()
+	}
+}
@@ -20,6 +20,14 @@ type SeedEntry struct {
 	Synopsis  string
 	// Pre-written vibe profiles for when no API key is available
 	FallbackVibe string
+	// Durations in minutes: Runtime for films, EpisodeRuntime and counts for series
+	Runtime        int
+	EpisodeRuntime int
+	Episodes       int
+	Seasons        int
+	// TMDB vote average (0-10) and vote count, used for hidden-gem scoring
+	VoteAverage float64
+	VoteCount   int
 }
 
 // Sample hidden gems and quality titles
@@ -30,6 +38,11 @@ var seedData = []SeedEntry{
 		Year:      2022,
 		Synopsis:  "A bullied teen receives mysterious help from an entity claiming to be her dead father's uploaded consciousness.",
 		FallbackVibe: "Cerebral and haunting digital noir. The pacing is deliberate and tense, with moments of existential dread punctuated by raw emotional vulnerability. Aesthetic of cold server rooms and warm human memories colliding.",
+		EpisodeRuntime: 45,
+		Episodes:       16,
+		Seasons:        2,
+		VoteAverage:    8.4,
+		VoteCount:      300,
 	},
 	{
 		Title:     "Id:Invaded",
@@ -37,6 +50,11 @@ var seedData = []SeedEntry{
 		Year:      2020,
 		Synopsis:  "A detective enters the subconscious minds of serial killers using a device that creates dream worlds from their cognition particles.",
 		FallbackVibe: "Mind-bending neo-noir with a kaleidoscopic dreamscape aesthetic. Frenetic psychological energy wrapped in contemplative detective procedural pacing. Feels like falling through fractured mirrors of guilt and identity.",
+		EpisodeRuntime: 24,
+		Episodes:       13,
+		Seasons:        1,
+		VoteAverage:    7.9,
+		VoteCount:      150,
 	},
 	{
 		Title:     "Odd Taxi",
@@ -44,6 +62,11 @@ var seedData = []SeedEntry{
 		Year:      2021,
 		Synopsis:  "A 41-year-old walrus taxi driver becomes entangled in a missing girl case through his passengers' interconnected stories.",
 		FallbackVibe: "Rain-soaked urban melancholy with sharp wit. Deceptively cozy surface hiding razor-sharp tension. Lo-fi jazz atmosphere, the quiet dread of ordinary people carrying extraordinary secrets.",
+		EpisodeRuntime: 24,
+		Episodes:       13,
+		Seasons:        1,
+		VoteAverage:    8.2,
+		VoteCount:      120,
 	},
 	{
 		Title:     "Akira",
@@ -51,6 +74,9 @@ var seedData = []SeedEntry{
 		Year:      1988,
 		Synopsis:  "A biker gang member gains telekinetic powers after a military experiment goes wrong in post-apocalyptic Neo-Tokyo.",
 		FallbackVibe: "Hyperkinetic cyberpunk explosion of neon and chrome. Pulsing with restless adolescent energy and apocalyptic dread. Every frame vibrates with mechanical detail and psychic overflow.",
+		Runtime:     124,
+		VoteAverage: 7.9,
+		VoteCount:   4300,
 	},
 	{
 		Title:     "Perfect Blue",
@@ -58,6 +84,9 @@ var seedData = []SeedEntry{
 		Year:      1997,
 		Synopsis:  "A pop idol turned actress's sense of reality becomes distorted as she's stalked and her virtual image takes on a life of its own.",
 		FallbackVibe: "Suffocating psychological thriller wrapped in glossy pop aesthetics. Reality fractures like a broken mirror. Paranoid, claustrophobic, deeply unsettling descent into identity dissolution.",
+		Runtime:     81,
+		VoteAverage: 8.1,
+		VoteCount:   2200,
 	},
 	{
 		Title:     "Paprika",
@@ -65,6 +94,9 @@ var seedData = []SeedEntry{
 		Year:      2006,
 		Synopsis:  "A research psychologist uses a device that allows therapists to enter patients' dreams, but the device is stolen.",
 		FallbackVibe: "Kaleidoscopic dream logic explosion. Joyful and terrifying in equal measure. Saturated colors bleeding into impossible geometries. The aesthetic of pure imagination unshackled.",
+		Runtime:     90,
+		VoteAverage: 7.8,
+		VoteCount:   2300,
 	},
 	{
 		Title:     "Serial Experiments Lain",
@@ -72,6 +104,11 @@ var seedData = []SeedEntry{
 		Year:      1998,
 		Synopsis:  "A shy girl becomes obsessed with the Wired, a global communications network, and discovers unsettling truths about reality and identity.",
 		FallbackVibe: "Eerie digital liminal spaces and humming power lines. Slow, hypnotic dread. The loneliness of dial-up connections and empty suburban streets. God is in the machine.",
+		EpisodeRuntime: 24,
+		Episodes:       13,
+		Seasons:        1,
+		VoteAverage:    7.9,
+		VoteCount:      600,
 	},
 	{
 		Title:     "Blade Runner 2049",
@@ -79,6 +116,9 @@ var seedData = []SeedEntry{
 		Year:      2017,
 		Synopsis:  "A blade runner discovers a secret that could destabilize society and his quest leads him to find a former blade runner.",
 		FallbackVibe: "Glacial neon-noir meditation. Vast empty spaces that swallow the soul. Every shot a melancholic painting of rain and holographic ghosts. Aching loneliness rendered in brutalist architecture.",
+		Runtime:     164,
+		VoteAverage: 7.6,
+		VoteCount:   13500,
 	},
 	{
 		Title:     "Arrival",
@@ -86,6 +126,9 @@ var seedData = []SeedEntry{
 		Year:      2016,
 		Synopsis:  "A linguist is recruited to help communicate with alien visitors before global tensions escalate.",
 		FallbackVibe: "Contemplative sci-fi wrapped in fog and grief. Time bends like light through water. Quiet, mournful, intellectually stimulating. The weight of knowing the future and choosing it anyway.",
+		Runtime:     116,
+		VoteAverage: 7.6,
+		VoteCount:   18000,
 	},
 	{
 		Title:     "The Lighthouse",
@@ -93,6 +136,9 @@ var seedData = []SeedEntry{
 		Year:      2019,
 		Synopsis:  "Two lighthouse keepers try to maintain their sanity while living on a remote island.",
 		FallbackVibe: "Claustrophobic maritime madness in crushing black and white. Salt-crusted fever dream. Fog horns and crashing waves as the soundtrack to psychological disintegration. Mythic dread.",
+		Runtime:     109,
+		VoteAverage: 7.3,
+		VoteCount:   5000,
 	},
 	{
 		Title:     "Severance",
@@ -100,6 +146,11 @@ var seedData = []SeedEntry{
 		Year:      2022,
 		Synopsis:  "Employees of a corporation undergo a procedure that divides their work and personal memories.",
 		FallbackVibe: "Corporate uncanny valley nightmare in mint green and fluorescent dread. Retrofuturistic unease. The horror of bureaucratic existence rendered in painfully sterile corridors. Kafkaesque with a beating heart.",
+		EpisodeRuntime: 55,
+		Episodes:       19,
+		Seasons:        2,
+		VoteAverage:    8.4,
+		VoteCount:      1900,
 	},
 	{
 		Title:     "Mr. Robot",
@@ -107,6 +158,11 @@ var seedData = []SeedEntry{
 		Year:      2015,
 		Synopsis:  "A cybersecurity engineer with social anxiety and clinical depression becomes involved with an underground hacker group.",
 		FallbackVibe: "Paranoid digital-age thriller shot through fractured perspectives. Off-center framing that mirrors psychological instability. Loneliness in the age of connection. The revolution will be debugged.",
+		EpisodeRuntime: 50,
+		Episodes:       45,
+		Seasons:        4,
+		VoteAverage:    8.2,
+		VoteCount:      4700,
 	},
 	{
 		Title:     "Cowboy Bebop",
@@ -114,6 +170,11 @@ var seedData = []SeedEntry{
 		Year:      1998,
 		Synopsis:  "A ragtag crew of bounty hunters aboard a spaceship pursue criminals across the solar system.",
 		FallbackVibe: "Cool-jazz-in-space melancholy. Effortlessly stylish ennui. Everyone running from their past through neon-lit corridors and dusty frontier towns. You're gonna carry that weight.",
+		EpisodeRuntime: 24,
+		Episodes:       26,
+		Seasons:        1,
+		VoteAverage:    8.5,
+		VoteCount:      2000,
 	},
 	{
 		Title:     "Ghost in the Shell",
@@ -121,6 +182,9 @@ var seedData = []SeedEntry{
 		Year:      1995,
 		Synopsis:  "A cyborg policewoman hunts a mysterious hacker while questioning the nature of consciousness.",
 		FallbackVibe: "Philosophical cyberpunk meditation dripping with rain and neon. Where does the machine end and the soul begin? Lush urban decay and digital transcendence. Haunted by questions of identity.",
+		Runtime:     83,
+		VoteAverage: 7.8,
+		VoteCount:   3300,
 	},
 	{
 		Title:     "Annihilation",
@@ -128,6 +192,9 @@ var seedData = []SeedEntry{
 		Year:      2018,
 		Synopsis:  "A biologist joins an expedition into an environmental disaster zone where the laws of nature don't apply.",
 		FallbackVibe: "Iridescent body-horror beauty. Nature reclaiming and remixing human form. Dream-logic dread in prismatic colors. Self-destruction as transformation. Hypnotically unsettling.",
+		Runtime:     115,
+		VoteAverage: 6.4,
+		VoteCount:   9000,
 	},
 }
 
@@ -179,6 +246,19 @@ func main() {
 		// Check if already exists
 		existing, _ := db.GetMediaByTitle(entry.Title)
 		if existing != nil {
+			// Fill in durations for databases seeded before they were tracked
+			if existing.RuntimeMinutes == 0 && existing.EpisodeRuntime == 0 && existing.EpisodeCount == 0 {
+				existing.RuntimeMinutes = entry.Runtime
+				existing.EpisodeRuntime = entry.EpisodeRuntime
+				existing.EpisodeCount = entry.Episodes
+				existing.SeasonCount = entry.Seasons
+				if err := db.SetMediaRuntime(existing); err != nil {
+					fmt.Printf(" runtime error: %v...", err)
+				}
+			}
+			if err := db.SetMediaVotes(existing.ID, entry.VoteAverage, entry.VoteCount); err != nil {
+				fmt.Printf(" votes error: %v...", err)
+			}
 			// Check if embedding is missing and backfill if needed
 			emb, _ := db.GetEmbedding(existing.ID)
 			if emb != nil {
@@ -227,12 +307,19 @@ func main() {
 			PlotSummary: entry.Synopsis,
 			VibeProfile: vibeProfile,
 			QualityScore: 0.8, // Start with decent quality score for known good titles
+			RuntimeMinutes: entry.Runtime,
+			EpisodeRuntime: entry.EpisodeRuntime,
+			EpisodeCount:   entry.Episodes,
+			SeasonCount:    entry.Seasons,
 		}
 
 		if err := db.CreateMedia(media); err != nil {
 			fmt.Printf(" DB error: %v\n", err)
 			continue
 		}
+		if err := db.SetMediaVotes(media.ID, entry.VoteAverage, entry.VoteCount); err != nil {
+			fmt.Printf(" votes error: %v...", err)
+		}
 
 		// Generate and store embedding
 		embedding, err := embedProvider.Embed(vibeProfile)
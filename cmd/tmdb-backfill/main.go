@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"w2w/internal/database"
+	"w2w/internal/models"
+	"w2w/internal/tmdb"
+)
+
+func main() {
+	godotenv.Load()
+
+	tmdbKey := os.Getenv("TMDB_API_KEY")
+	dbPath := os.Getenv("DATABASE_PATH")
+	if dbPath == "" {
+		dbPath = "./vibe.db"
+	}
+
+	franchisesOnly := false
+	for _, arg := range os.Args[1:] {
+		switch arg {
+		case "--franchises":
+			franchisesOnly = true
+		case "--help":
+			fmt.Println("Usage: tmdb-backfill [--franchises]")
+			fmt.Println()
+			fmt.Println("Fetches runtime, episode runtime, episode count, season count and vote")
+			fmt.Println("average/count from TMDB for imported media missing any of them.")
+			fmt.Println()
+			fmt.Println("  --franchises  Instead, link imported movies to the franchise of their")
+			fmt.Println("                TMDB collection, in release order")
+			os.Exit(0)
+		}
+	}
+
+	if tmdbKey == "" {
+		log.Fatal("TMDB_API_KEY is required. Get one free at https://www.themoviedb.org/settings/api and add it to .env")
+	}
+
+	fmt.Println("========================================")
+	fmt.Println("  TMDB Backfill")
+	fmt.Println("========================================")
+	fmt.Printf("  Database: %s\n", dbPath)
+	fmt.Println("========================================")
+	fmt.Println()
+
+	db, err := database.New(dbPath)
+	if err != nil {
+		log.Fatalf("Database error: %v", err)
+	}
+	defer db.Close()
+
+	client := tmdb.NewClient(tmdbKey)
+	if franchisesOnly {
+		backfillFranchises(client, db)
+		return
+	}
+
+	missing, err := db.GetMediaMissingDetails()
+	if err != nil {
+		log.Fatalf("Failed to list media: %v", err)
+	}
+	fmt.Printf("  %d media entries missing durations or votes\n\n", len(missing))
+
+	startTime := time.Now()
+	updated, skipped, errors := 0, 0, 0
+	for i := range missing {
+		m := &missing[i]
+		id, err := strconv.Atoi(strings.TrimPrefix(m.ExternalID, "tmdb:"))
+		if err != nil || !strings.HasPrefix(m.ExternalID, "tmdb:") {
+			skipped++
+			continue
+		}
+
+		var voteAverage float64
+		var voteCount int
+		switch {
+		case strings.HasPrefix(m.ID, "tmdb-movie-"):
+			details, err := client.GetMovieDetails(id)
+			if err != nil {
+				errors++
+				fmt.Printf("  [%d/%d] %s — error: %v\n", i+1, len(missing), m.Title, err)
+				continue
+			}
+			m.RuntimeMinutes = details.Runtime
+			voteAverage, voteCount = details.VoteAverage, details.VoteCount
+		case strings.HasPrefix(m.ID, "tmdb-tv-"):
+			details, err := client.GetTVDetails(id)
+			if err != nil {
+				errors++
+				fmt.Printf("  [%d/%d] %s — error: %v\n", i+1, len(missing), m.Title, err)
+				continue
+			}
+			m.EpisodeRuntime = details.EpisodeMinutes()
+			m.EpisodeCount = details.NumberOfEpisodes
+			m.SeasonCount = details.NumberOfSeasons
+			voteAverage, voteCount = details.VoteAverage, details.VoteCount
+		default:
+			skipped++
+			continue
+		}
+
+		if !hasDuration(m) && voteCount == 0 {
+			skipped++
+			continue
+		}
+		if err := db.SetMediaRuntime(m); err != nil {
+			errors++
+			fmt.Printf("  [%d/%d] %s — error: %v\n", i+1, len(missing), m.Title, err)
+			continue
+		}
+		if err := db.SetMediaVotes(m.ID, voteAverage, voteCount); err != nil {
+			errors++
+			fmt.Printf("  [%d/%d] %s — error: %v\n", i+1, len(missing), m.Title, err)
+			continue
+		}
+		updated++
+		if (i+1)%50 == 0 || i+1 == len(missing) {
+			fmt.Printf("  ... %d/%d processed\n", i+1, len(missing))
+		}
+	}
+
+	fmt.Println("\n========================================")
+	fmt.Println("  Backfill Complete!")
+	fmt.Println("========================================")
+	fmt.Printf("  Updated:  %d\n", updated)
+	fmt.Printf("  Skipped:  %d (no TMDB ID or no data)\n", skipped)
+	fmt.Printf("  Errors:   %d\n", errors)
+	fmt.Printf("  Duration: %s\n", time.Since(startTime).Round(time.Second))
+	fmt.Println("========================================")
+}
+
+// hasDuration reports whether TMDB returned anything worth storing
+func hasDuration(m *models.Media) bool {
+	return m.RuntimeMinutes > 0 || m.EpisodeRuntime > 0 || m.EpisodeCount > 0
+}
+
+// backfillFranchises links every imported movie not yet in a franchise to
+// the franchise of its TMDB collection
+func backfillFranchises(client *tmdb.Client, db *database.DB) {
+	movies, err := db.GetTMDBMoviesWithoutFranchise()
+	if err != nil {
+		log.Fatalf("Failed to list media: %v", err)
+	}
+	fmt.Printf("  %d movies not in a franchise\n\n", len(movies))
+
+	startTime := time.Now()
+	collections := make(map[int]*tmdb.CollectionDetails)
+	linked, standalone, errors := 0, 0, 0
+	for i, m := range movies {
+		id, err := strconv.Atoi(strings.TrimPrefix(m.ExternalID, "tmdb:"))
+		if err != nil {
+			standalone++
+			continue
+		}
+		details, err := client.GetMovieDetails(id)
+		if err != nil {
+			errors++
+			fmt.Printf("  [%d/%d] %s — error: %v\n", i+1, len(movies), m.Title, err)
+			continue
+		}
+		ref := details.BelongsToCollection
+		if ref == nil {
+			standalone++
+			continue
+		}
+		collection, ok := collections[ref.ID]
+		if !ok {
+			if collection, err = client.GetCollection(ref.ID); err != nil {
+				errors++
+				fmt.Printf("  [%d/%d] %s — collection error: %v\n", i+1, len(movies), m.Title, err)
+				continue
+			}
+			collections[ref.ID] = collection
+		}
+
+		order := collection.WatchOrder(id)
+		if order == 0 {
+			order = len(collection.Parts) + 1
+		}
+		franchise := &models.Franchise{
+			ID:         fmt.Sprintf("tmdb-collection-%d", ref.ID),
+			Name:       ref.Name,
+			Source:     models.FranchiseSourceTMDB,
+			ExternalID: fmt.Sprintf("tmdb:%d", ref.ID),
+		}
+		entry := models.FranchiseEntry{MediaID: m.ID, WatchOrder: order, Role: models.FranchiseRoleMain}
+		if err := db.LinkFranchiseEntry(franchise, entry); err != nil {
+			errors++
+			fmt.Printf("  [%d/%d] %s — error: %v\n", i+1, len(movies), m.Title, err)
+			continue
+		}
+		linked++
+		fmt.Printf("  [%d/%d] %s → %s (#%d)\n", i+1, len(movies), m.Title, ref.Name, order)
+	}
+
+	fmt.Println("\n========================================")
+	fmt.Println("  Franchise Backfill Complete!")
+	fmt.Println("========================================")
+	fmt.Printf("  Linked:     %d\n", linked)
+	fmt.Printf("  Standalone: %d\n", standalone)
+	fmt.Printf("  Errors:     %d\n", errors)
+	fmt.Printf("  Duration:   %s\n", time.Since(startTime).Round(time.Second))
+	fmt.Println("========================================")
+}
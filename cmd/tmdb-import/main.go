@@ -135,23 +135,24 @@ func main() {
 	fmt.Printf("  New media added:    %d\n", stats.added)
 	fmt.Printf("  Already existed:    %d\n", stats.skipped)
 	fmt.Printf("  Embeddings created: %d\n", stats.embedded)
+	fmt.Printf("  Franchise links:    %d\n", stats.franchised)
 	fmt.Printf("  Errors:             %d\n", stats.errors)
 	fmt.Printf("  Duration:           %s\n", elapsed.Round(time.Second))
 	fmt.Println("========================================")
 
 	// Print total counts
-	var totalMedia, totalEmbed int
-	db.QueryRow("SELECT COUNT(*) FROM media").Scan(&totalMedia)
-	db.QueryRow("SELECT COUNT(*) FROM vibe_embeddings").Scan(&totalEmbed)
+	totalMedia, _ := db.CountMedia()
+	totalEmbed, _ := db.CountEmbeddings()
 	fmt.Printf("\n  Total media in DB:     %d\n", totalMedia)
 	fmt.Printf("  Total with embeddings: %d\n", totalEmbed)
 }
 
 type importStats struct {
-	added    int
-	skipped  int
-	embedded int
-	errors   int
+	added      int
+	skipped    int
+	embedded   int
+	franchised int
+	errors     int
 }
 
 func importMovies(client *tmdb.Client, db *database.DB, embedder embeddings.Provider, pages int, stats *importStats) {
@@ -214,6 +215,7 @@ func importMovies(client *tmdb.Client, db *database.DB, embedder embeddings.Prov
 				QualityScore:    qualityScore,
 				PopularityScore: popularityScore,
 				ExternalID:      fmt.Sprintf("tmdb:%d", details.ID),
+				RuntimeMinutes:  details.Runtime,
 			}
 
 			if err := db.CreateMedia(media); err != nil {
@@ -221,6 +223,12 @@ func importMovies(client *tmdb.Client, db *database.DB, embedder embeddings.Prov
 				continue
 			}
 			stats.added++
+			db.SetMediaVotes(media.ID, details.VoteAverage, details.VoteCount)
+			if linked, err := linkCollection(client, db, mediaID, details); err != nil {
+				fmt.Printf("  [p%d] %s — collection error: %v\n", page, details.Title, err)
+			} else if linked {
+				stats.franchised++
+			}
 
 			// Generate embedding
 			if embedder != nil {
@@ -310,6 +318,9 @@ func importTV(client *tmdb.Client, db *database.DB, embedder embeddings.Provider
 				QualityScore:    qualityScore,
 				PopularityScore: popularityScore,
 				ExternalID:      fmt.Sprintf("tmdb:%d", details.ID),
+				EpisodeRuntime:  details.EpisodeMinutes(),
+				EpisodeCount:    details.NumberOfEpisodes,
+				SeasonCount:     details.NumberOfSeasons,
 			}
 
 			if err := db.CreateMedia(media); err != nil {
@@ -317,6 +328,7 @@ func importTV(client *tmdb.Client, db *database.DB, embedder embeddings.Provider
 				continue
 			}
 			stats.added++
+			db.SetMediaVotes(media.ID, details.VoteAverage, details.VoteCount)
 
 			if embedder != nil {
 				embedding, err := embedder.Embed(vibeText)
@@ -388,6 +400,9 @@ func importAnimeTV(client *tmdb.Client, db *database.DB, embedder embeddings.Pro
 				QualityScore:    qualityScore,
 				PopularityScore: popularityScore,
 				ExternalID:      fmt.Sprintf("tmdb:%d", details.ID),
+				EpisodeRuntime:  details.EpisodeMinutes(),
+				EpisodeCount:    details.NumberOfEpisodes,
+				SeasonCount:     details.NumberOfSeasons,
 			}
 
 			if err := db.CreateMedia(media); err != nil {
@@ -395,6 +410,7 @@ func importAnimeTV(client *tmdb.Client, db *database.DB, embedder embeddings.Pro
 				continue
 			}
 			stats.added++
+			db.SetMediaVotes(media.ID, details.VoteAverage, details.VoteCount)
 
 			if embedder != nil {
 				embedding, err := embedder.Embed(vibeText)
@@ -466,6 +482,7 @@ func importAnimeMovies(client *tmdb.Client, db *database.DB, embedder embeddings
 				QualityScore:    qualityScore,
 				PopularityScore: popularityScore,
 				ExternalID:      fmt.Sprintf("tmdb:%d", details.ID),
+				RuntimeMinutes:  details.Runtime,
 			}
 
 			if err := db.CreateMedia(media); err != nil {
@@ -473,6 +490,12 @@ func importAnimeMovies(client *tmdb.Client, db *database.DB, embedder embeddings
 				continue
 			}
 			stats.added++
+			db.SetMediaVotes(media.ID, details.VoteAverage, details.VoteCount)
+			if linked, err := linkCollection(client, db, mediaID, details); err != nil {
+				fmt.Printf("  [p%d] %s — collection error: %v\n", page, details.Title, err)
+			} else if linked {
+				stats.franchised++
+			}
 
 			if embedder != nil {
 				embedding, err := embedder.Embed(vibeText)
@@ -498,29 +521,19 @@ func importAnimeMovies(client *tmdb.Client, db *database.DB, embedder embeddings
 
 func backfillEmbeddings(db *database.DB, embedder embeddings.Provider, stats *importStats) {
 	// Find media entries without embeddings
-	rows, err := db.Query(`
-		SELECT m.id, m.vibe_profile
-		FROM media m
-		LEFT JOIN vibe_embeddings ve ON m.id = ve.media_id
-		WHERE ve.media_id IS NULL AND m.vibe_profile != ''
-	`)
+	profiles, err := db.GetVibeProfilesWithoutEmbedding()
 	if err != nil {
 		fmt.Printf("  Backfill query error: %v\n", err)
 		return
 	}
-	defer rows.Close()
 
 	type entry struct {
 		id          string
 		vibeProfile string
 	}
 	var missing []entry
-	for rows.Next() {
-		var e entry
-		if err := rows.Scan(&e.id, &e.vibeProfile); err != nil {
-			continue
-		}
-		missing = append(missing, e)
+	for id, profile := range profiles {
+		missing = append(missing, entry{id, profile})
 	}
 
 	if len(missing) == 0 {
@@ -549,6 +562,39 @@ func backfillEmbeddings(db *database.DB, embedder embeddings.Provider, stats *im
 	}
 }
 
+// collections caches the TMDB collections fetched during the run
+var collections = make(map[int]*tmdb.CollectionDetails)
+
+// linkCollection adds a movie to the franchise of its TMDB collection, in
+// release order. Returns false if the movie belongs to no collection.
+func linkCollection(client *tmdb.Client, db *database.DB, mediaID string, details *tmdb.MovieDetails) (bool, error) {
+	ref := details.BelongsToCollection
+	if ref == nil {
+		return false, nil
+	}
+	collection, ok := collections[ref.ID]
+	if !ok {
+		var err error
+		if collection, err = client.GetCollection(ref.ID); err != nil {
+			return false, err
+		}
+		collections[ref.ID] = collection
+	}
+
+	order := collection.WatchOrder(details.ID)
+	if order == 0 {
+		order = len(collection.Parts) + 1
+	}
+	franchise := &models.Franchise{
+		ID:         fmt.Sprintf("tmdb-collection-%d", ref.ID),
+		Name:       ref.Name,
+		Source:     models.FranchiseSourceTMDB,
+		ExternalID: fmt.Sprintf("tmdb:%d", ref.ID),
+	}
+	entry := models.FranchiseEntry{MediaID: mediaID, WatchOrder: order, Role: models.FranchiseRoleMain}
+	return true, db.LinkFranchiseEntry(franchise, entry)
+}
+
 func extractYear(dateStr string) int {
 	if len(dateStr) >= 4 {
 		y, _ := strconv.Atoi(dateStr[:4])
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Vibe Axis Operations
+// ============================================================================
+
+// InsertAxisIfMissing stores an axis definition unless the slug exists, so
+// built-in axes never overwrite an admin's redefinition
+func (db *DB) InsertAxisIfMissing(axis *models.VibeAxis) error {
+	low, high, err := marshalAnchors(axis)
+	if err != nil {
+		return err
+	}
+	_, err = db.conn.Exec(
+		`INSERT OR IGNORE INTO vibe_axes (slug, low_label, high_label, low_anchors, high_anchors, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		axis.Slug, axis.LowLabel, axis.HighLabel, low, high, time.Now(),
+	)
+	return err
+}
+
+// SaveAxis inserts or replaces an axis, centroids included
+func (db *DB) SaveAxis(axis *models.VibeAxis) error {
+	low, high, err := marshalAnchors(axis)
+	if err != nil {
+		return err
+	}
+	lowCentroid, err := json.Marshal(axis.LowCentroid)
+	if err != nil {
+		return fmt.Errorf("failed to serialize centroid: %w", err)
+	}
+	highCentroid, err := json.Marshal(axis.HighCentroid)
+	if err != nil {
+		return fmt.Errorf("failed to serialize centroid: %w", err)
+	}
+
+	axis.UpdatedAt = time.Now()
+	_, err = db.conn.Exec(
+		`INSERT INTO vibe_axes (slug, low_label, high_label, low_anchors, high_anchors,
+			low_centroid, high_centroid, model, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			low_label = excluded.low_label, high_label = excluded.high_label,
+			low_anchors = excluded.low_anchors, high_anchors = excluded.high_anchors,
+			low_centroid = excluded.low_centroid, high_centroid = excluded.high_centroid,
+			model = excluded.model, updated_at = excluded.updated_at`,
+		axis.Slug, axis.LowLabel, axis.HighLabel, low, high,
+		lowCentroid, highCentroid, axis.Model, axis.UpdatedAt,
+	)
+	return err
+}
+
+func marshalAnchors(axis *models.VibeAxis) (string, string, error) {
+	low, err := json.Marshal(axis.LowAnchors)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to serialize anchors: %w", err)
+	}
+	high, err := json.Marshal(axis.HighAnchors)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to serialize anchors: %w", err)
+	}
+	return string(low), string(high), nil
+}
+
+// GetAxes returns every axis with its centroids and how many titles have a
+// position on it, ordered by slug
+func (db *DB) GetAxes() ([]models.VibeAxis, error) {
+	rows, err := db.conn.Query(
+		`SELECT a.slug, a.low_label, a.high_label, a.low_anchors, a.high_anchors,
+		       a.low_centroid, a.high_centroid, a.model, a.updated_at,
+		       (SELECT COUNT(*) FROM media_axes ma WHERE ma.axis_slug = a.slug)
+		FROM vibe_axes a
+		ORDER BY a.slug`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var axes []models.VibeAxis
+	for rows.Next() {
+		var a models.VibeAxis
+		var low, high string
+		var lowCentroid, highCentroid []byte
+		if err := rows.Scan(&a.Slug, &a.LowLabel, &a.HighLabel, &low, &high,
+			&lowCentroid, &highCentroid, &a.Model, &a.UpdatedAt, &a.MediaCount); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(low), &a.LowAnchors); err != nil {
+			return nil, fmt.Errorf("failed to deserialize anchors for %s: %w", a.Slug, err)
+		}
+		if err := json.Unmarshal([]byte(high), &a.HighAnchors); err != nil {
+			return nil, fmt.Errorf("failed to deserialize anchors for %s: %w", a.Slug, err)
+		}
+		if len(lowCentroid) > 0 && len(highCentroid) > 0 {
+			if err := json.Unmarshal(lowCentroid, &a.LowCentroid); err != nil {
+				return nil, fmt.Errorf("failed to deserialize centroid for %s: %w", a.Slug, err)
+			}
+			if err := json.Unmarshal(highCentroid, &a.HighCentroid); err != nil {
+				return nil, fmt.Errorf("failed to deserialize centroid for %s: %w", a.Slug, err)
+			}
+		}
+		axes = append(axes, a)
+	}
+	return axes, rows.Err()
+}
+
+// DeleteAxis removes an axis and every position on it. Returns false if
+// there was no such axis.
+func (db *DB) DeleteAxis(slug string) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM vibe_axes WHERE slug = ?`, slug)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetAxisProjectionTimes returns when each title was last projected onto
+// each axis, keyed by axis slug then media ID
+func (db *DB) GetAxisProjectionTimes() (map[string]map[string]time.Time, error) {
+	rows, err := db.conn.Query(`SELECT axis_slug, media_id, updated_at FROM media_axes`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[string]map[string]time.Time)
+	for rows.Next() {
+		var slug, mediaID string
+		var updated time.Time
+		if err := rows.Scan(&slug, &mediaID, &updated); err != nil {
+			return nil, err
+		}
+		if times[slug] == nil {
+			times[slug] = make(map[string]time.Time)
+		}
+		times[slug][mediaID] = updated
+	}
+	return times, rows.Err()
+}
+
+// GetEmbeddingTimes returns when each title's embedding was stored
+func (db *DB) GetEmbeddingTimes() (map[string]time.Time, error) {
+	rows, err := db.conn.Query(`SELECT media_id, created_at FROM vibe_embeddings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[string]time.Time)
+	for rows.Next() {
+		var mediaID string
+		var created sql.NullTime
+		if err := rows.Scan(&mediaID, &created); err != nil {
+			return nil, err
+		}
+		times[mediaID] = created.Time
+	}
+	return times, rows.Err()
+}
+
+// SaveAxisProjections stores raw projections onto an axis and rescales every
+// position on it to 0-1 across the catalog
+func (db *DB) SaveAxisProjections(slug string, raw map[string]float64) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO media_axes (media_id, axis_slug, raw, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(media_id, axis_slug) DO UPDATE SET raw = excluded.raw, updated_at = excluded.updated_at`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for mediaID, r := range raw {
+		if _, err := stmt.Exec(mediaID, slug, r, now); err != nil {
+			return err
+		}
+	}
+
+	var lo, hi sql.NullFloat64
+	if err := tx.QueryRow(
+		`SELECT MIN(raw), MAX(raw) FROM media_axes WHERE axis_slug = ?`, slug,
+	).Scan(&lo, &hi); err != nil {
+		return err
+	}
+	if hi.Float64 > lo.Float64 {
+		_, err = tx.Exec(`UPDATE media_axes SET position = (raw - ?) / ? WHERE axis_slug = ?`,
+			lo.Float64, hi.Float64-lo.Float64, slug)
+	} else {
+		_, err = tx.Exec(`UPDATE media_axes SET position = 0.5 WHERE axis_slug = ?`, slug)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAxisPositions returns the positions of the given titles on the given
+// axes, keyed by media ID then axis slug. Titles without a position on an
+// axis are left out of that axis.
+func (db *DB) GetAxisPositions(slugs, mediaIDs []string) (map[string]map[string]float64, error) {
+	positions := make(map[string]map[string]float64)
+	if len(slugs) == 0 || len(mediaIDs) == 0 {
+		return positions, nil
+	}
+
+	args := make([]interface{}, 0, len(slugs)+len(mediaIDs))
+	for _, s := range slugs {
+		args = append(args, s)
+	}
+	for _, id := range mediaIDs {
+		args = append(args, id)
+	}
+	rows, err := db.conn.Query(
+		`SELECT media_id, axis_slug, position FROM media_axes
+		WHERE axis_slug IN (?`+strings.Repeat(`, ?`, len(slugs)-1)+`)
+		AND media_id IN (?`+strings.Repeat(`, ?`, len(mediaIDs)-1)+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mediaID, slug string
+		var position float64
+		if err := rows.Scan(&mediaID, &slug, &position); err != nil {
+			return nil, err
+		}
+		if positions[mediaID] == nil {
+			positions[mediaID] = make(map[string]float64)
+		}
+		positions[mediaID][slug] = position
+	}
+	return positions, rows.Err()
+}
+
+// GetMediaIDsOnAxis returns the titles positioned between lo and hi
+// (inclusive) on an axis
+func (db *DB) GetMediaIDsOnAxis(slug string, lo, hi float64) (map[string]bool, error) {
+	rows, err := db.conn.Query(
+		`SELECT media_id FROM media_axes WHERE axis_slug = ? AND position >= ? AND position <= ?`,
+		slug, lo, hi,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Collaborative Filtering Operations
+// ============================================================================
+
+// GetAllSeenRatings returns every (user, media, rating, status) for building
+// the co-watch matrix
+func (db *DB) GetAllSeenRatings() ([]models.SeenMedia, error) {
+	rows, err := db.conn.Query(`SELECT user_id, media_id, rating, status FROM seen_media`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seen []models.SeenMedia
+	for rows.Next() {
+		var s models.SeenMedia
+		if err := rows.Scan(&s.UserID, &s.MediaID, &s.Rating, &s.Status); err != nil {
+			return nil, err
+		}
+		seen = append(seen, s)
+	}
+	return seen, rows.Err()
+}
+
+// GetPendingSeenChanges returns the users and media touched since the last
+// ClearSeenChanges, plus the high-water mark to clear up to afterwards
+func (db *DB) GetPendingSeenChanges() (int64, []string, []string, error) {
+	rows, err := db.conn.Query(`SELECT id, user_id, media_id FROM seen_changes ORDER BY id`)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer rows.Close()
+
+	var maxID int64
+	userSet := make(map[string]bool)
+	mediaSet := make(map[string]bool)
+	for rows.Next() {
+		var id int64
+		var userID, mediaID string
+		if err := rows.Scan(&id, &userID, &mediaID); err != nil {
+			return 0, nil, nil, err
+		}
+		maxID = id
+		userSet[userID] = true
+		mediaSet[mediaID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, nil, err
+	}
+
+	users := make([]string, 0, len(userSet))
+	for u := range userSet {
+		users = append(users, u)
+	}
+	media := make([]string, 0, len(mediaSet))
+	for m := range mediaSet {
+		media = append(media, m)
+	}
+	return maxID, users, media, nil
+}
+
+// ClearSeenChanges drops change-log rows up to and including upToID
+func (db *DB) ClearSeenChanges(upToID int64) error {
+	_, err := db.conn.Exec(`DELETE FROM seen_changes WHERE id <= ?`, upToID)
+	return err
+}
+
+// CountItemNeighbors returns how many neighbor rows are stored
+func (db *DB) CountItemNeighbors() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM item_neighbors`).Scan(&count)
+	return count, err
+}
+
+// ReplaceItemNeighbors swaps the neighbor lists of the given media in one
+// transaction. Media mapped to an empty list lose all their neighbors; with
+// all set, media missing from the map lose theirs too.
+func (db *DB) ReplaceItemNeighbors(neighbors map[string][]models.ItemNeighbor, all bool) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if all {
+		if _, err := tx.Exec(`DELETE FROM item_neighbors`); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for mediaID, list := range neighbors {
+		if _, err := tx.Exec(`DELETE FROM item_neighbors WHERE media_id = ?`, mediaID); err != nil {
+			return err
+		}
+		for _, n := range list {
+			if _, err := tx.Exec(
+				`INSERT INTO item_neighbors (media_id, neighbor_id, score, co_count, updated_at)
+				VALUES (?, ?, ?, ?, ?)`,
+				mediaID, n.NeighborID, n.Score, n.CoCount, now,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetItemNeighbors returns a media's strongest co-watch neighbors
+func (db *DB) GetItemNeighbors(mediaID string, limit int) ([]models.ItemNeighbor, error) {
+	rows, err := db.conn.Query(
+		`SELECT media_id, neighbor_id, score, co_count, updated_at
+		FROM item_neighbors WHERE media_id = ?
+		ORDER BY score DESC LIMIT ?`,
+		mediaID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var neighbors []models.ItemNeighbor
+	for rows.Next() {
+		var n models.ItemNeighbor
+		if err := rows.Scan(&n.MediaID, &n.NeighborID, &n.Score, &n.CoCount, &n.UpdatedAt); err != nil {
+			return nil, err
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, rows.Err()
+}
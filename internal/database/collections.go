@@ -0,0 +1,297 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Collection Operations
+// ============================================================================
+
+const collectionColumns = `c.id, c.user_id, c.title, c.description, c.is_public, c.slug,
+	c.vibe_summary, c.created_at, c.updated_at,
+	(SELECT COUNT(*) FROM collection_items ci WHERE ci.collection_id = c.id)`
+
+func scanCollection(row interface{ Scan(...interface{}) error }) (*models.Collection, error) {
+	var c models.Collection
+	if err := row.Scan(&c.ID, &c.UserID, &c.Title, &c.Description, &c.Public, &c.Slug,
+		&c.VibeSummary, &c.CreatedAt, &c.UpdatedAt, &c.ItemCount); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateCollection inserts a new collection
+func (db *DB) CreateCollection(c *models.Collection) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO collections (id, user_id, title, description, is_public, slug, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.UserID, c.Title, c.Description, c.Public, c.Slug, c.CreatedAt, c.UpdatedAt,
+	)
+	return err
+}
+
+// GetCollection retrieves a collection (without items) by ID
+func (db *DB) GetCollection(id string) (*models.Collection, error) {
+	c, err := scanCollection(db.conn.QueryRow(
+		`SELECT `+collectionColumns+` FROM collections c WHERE c.id = ?`, id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// GetCollectionBySlug retrieves a collection (without items) by its slug
+func (db *DB) GetCollectionBySlug(slug string) (*models.Collection, error) {
+	c, err := scanCollection(db.conn.QueryRow(
+		`SELECT `+collectionColumns+` FROM collections c WHERE c.slug = ?`, slug,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+// GetCollectionsByIDs retrieves several collections (without items)
+func (db *DB) GetCollectionsByIDs(ids []string) (map[string]*models.Collection, error) {
+	result := make(map[string]*models.Collection, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := db.conn.Query(
+		`SELECT `+collectionColumns+` FROM collections c WHERE c.id IN (`+placeholders(len(ids))+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[c.ID] = c
+	}
+	return result, rows.Err()
+}
+
+// ListCollections returns a user's collections, most recently updated first
+func (db *DB) ListCollections(userID string) ([]models.Collection, error) {
+	rows, err := db.conn.Query(
+		`SELECT `+collectionColumns+` FROM collections c
+		WHERE c.user_id = ? ORDER BY c.updated_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []models.Collection
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, *c)
+	}
+	return collections, rows.Err()
+}
+
+// UpdateCollection saves a collection's title, description and visibility
+func (db *DB) UpdateCollection(c *models.Collection) error {
+	_, err := db.conn.Exec(
+		`UPDATE collections SET title = ?, description = ?, is_public = ?, updated_at = ?
+		WHERE id = ?`,
+		c.Title, c.Description, c.Public, time.Now(), c.ID,
+	)
+	return err
+}
+
+// DeleteCollection removes a collection and its items
+func (db *DB) DeleteCollection(id string) error {
+	_, err := db.conn.Exec(`DELETE FROM collections WHERE id = ?`, id)
+	return err
+}
+
+// GetCollectionItems returns a collection's items with media details, in order
+func (db *DB) GetCollectionItems(collectionID string) ([]models.CollectionItem, error) {
+	rows, err := db.conn.Query(
+		`SELECT ci.media_id, ci.position, ci.note, ci.added_at,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM collection_items ci
+		JOIN media m ON ci.media_id = m.id
+		WHERE ci.collection_id = ?
+		ORDER BY ci.position`,
+		collectionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.CollectionItem
+	for rows.Next() {
+		var item models.CollectionItem
+		var m models.Media
+		if err := rows.Scan(
+			&item.MediaID, &item.Position, &item.Note, &item.AddedAt,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		item.Media = &m
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// AddCollectionItem appends a title to a collection. Adding a title that is
+// already there updates its note in place.
+func (db *DB) AddCollectionItem(collectionID, mediaID, note string) error {
+	now := time.Now()
+	_, err := db.conn.Exec(
+		`INSERT INTO collection_items (collection_id, media_id, position, note, added_at)
+		VALUES (?, ?, (SELECT COALESCE(MAX(position) + 1, 0) FROM collection_items WHERE collection_id = ?), ?, ?)
+		ON CONFLICT(collection_id, media_id) DO UPDATE SET note = excluded.note`,
+		collectionID, mediaID, collectionID, note, now,
+	)
+	if err != nil {
+		return err
+	}
+	return db.touchCollection(collectionID, now)
+}
+
+// UpdateCollectionItemNote changes an item's note. Returns false if the
+// title is not in the collection.
+func (db *DB) UpdateCollectionItemNote(collectionID, mediaID, note string) (bool, error) {
+	res, err := db.conn.Exec(
+		`UPDATE collection_items SET note = ? WHERE collection_id = ? AND media_id = ?`,
+		note, collectionID, mediaID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	return true, db.touchCollection(collectionID, time.Now())
+}
+
+// RemoveCollectionItem deletes a title from a collection. Returns false if
+// it was not there.
+func (db *DB) RemoveCollectionItem(collectionID, mediaID string) (bool, error) {
+	res, err := db.conn.Exec(
+		`DELETE FROM collection_items WHERE collection_id = ? AND media_id = ?`,
+		collectionID, mediaID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	return true, db.touchCollection(collectionID, time.Now())
+}
+
+// ReorderCollection moves the given media to the top in the given order,
+// keeping the rest in their relative order below
+func (db *DB) ReorderCollection(collectionID string, mediaIDs []string) error {
+	if err := db.reorderPositions("collection_items", "collection_id", collectionID, mediaIDs); err != nil {
+		return err
+	}
+	return db.touchCollection(collectionID, time.Now())
+}
+
+func (db *DB) touchCollection(id string, at time.Time) error {
+	_, err := db.conn.Exec(`UPDATE collections SET updated_at = ? WHERE id = ?`, at, id)
+	return err
+}
+
+// SetCollectionVibe stores a collection's generated vibe summary and its
+// embedding
+func (db *DB) SetCollectionVibe(id, summary string, embedding []float32, model string) error {
+	var data interface{}
+	if embedding != nil {
+		b, err := json.Marshal(embedding)
+		if err != nil {
+			return fmt.Errorf("failed to serialize embedding: %w", err)
+		}
+		data = b
+	}
+	_, err := db.conn.Exec(
+		`UPDATE collections SET vibe_summary = ?, embedding = ?, embedding_model = ? WHERE id = ?`,
+		summary, data, model, id,
+	)
+	return err
+}
+
+// GetAllCollectionEmbeddings loads every collection embedding for the
+// in-memory collection index
+func (db *DB) GetAllCollectionEmbeddings() (map[string][]float32, error) {
+	rows, err := db.conn.Query(`SELECT id, embedding FROM collections WHERE embedding IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]float32)
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, err
+		}
+		var vec []float32
+		if err := json.Unmarshal(data, &vec); err != nil {
+			continue
+		}
+		result[id] = vec
+	}
+	return result, rows.Err()
+}
+
+// ImportCollectionToWatchlist appends a collection's titles, in collection
+// order, to the end of the user's watchlist. Titles already seen or already
+// on the watchlist are skipped; curator notes carry over. Returns how many
+// were added.
+func (db *DB) ImportCollectionToWatchlist(userID, collectionID string) (int, error) {
+	res, err := db.conn.Exec(
+		`INSERT INTO watchlist (user_id, media_id, priority, position, note, added_at)
+		SELECT ?, ci.media_id, ?,
+		       (SELECT COALESCE(MAX(position) + 1, 0) FROM watchlist WHERE user_id = ?)
+		         + ROW_NUMBER() OVER (ORDER BY ci.position) - 1,
+		       ci.note, ?
+		FROM collection_items ci
+		WHERE ci.collection_id = ?
+		AND ci.media_id NOT IN (SELECT media_id FROM seen_media WHERE user_id = ?)
+		AND ci.media_id NOT IN (SELECT media_id FROM watchlist WHERE user_id = ?)`,
+		userID, models.PriorityNormal, userID, time.Now(), collectionID, userID, userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
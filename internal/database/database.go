@@ -10,123 +10,48 @@ import (
 	"w2w/internal/models"
 )
 
-// DB wraps the SQL database connection
+// DB is the SQLite implementation of every repository. The connection is
+// unexported so SQL stays in this package.
 type DB struct {
-	*sql.DB
+	conn *sql.DB
+	path string
 }
 
-// New creates a new database connection and runs migrations
+// New opens the database and migrates it to the latest schema version
 func New(dbPath string) (*DB, error) {
-	sqlDB, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	db, err := Open(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
-	}
-
-	// Test connection
-	if err := sqlDB.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+		return nil, err
 	}
 
-	db := &DB{sqlDB}
-
 	// Run migrations
-	if err := db.migrate(); err != nil {
+	if _, err := db.MigrateTo(LatestVersion()); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return db, nil
 }
 
-// migrate creates all necessary tables
-func (db *DB) migrate() error {
-	migrations := []string{
-		// Users table
-		`CREATE TABLE IF NOT EXISTS users (
-			id TEXT PRIMARY KEY,
-			username TEXT NOT NULL UNIQUE,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-
-		// Media table - stores movies, TV shows, anime with vibe profiles
-		`CREATE TABLE IF NOT EXISTS media (
-			id TEXT PRIMARY KEY,
-			title TEXT NOT NULL,
-			media_type TEXT NOT NULL CHECK(media_type IN ('movie', 'tv', 'anime')),
-			year INTEGER,
-			plot_summary TEXT,
-			vibe_profile TEXT NOT NULL,
-			quality_score REAL DEFAULT 0.0,
-			popularity_score REAL DEFAULT 0.0,
-			source_subreddit TEXT,
-			external_id TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-
-		// Index for media lookup
-		`CREATE INDEX IF NOT EXISTS idx_media_title ON media(title)`,
-		`CREATE INDEX IF NOT EXISTS idx_media_type ON media(media_type)`,
-		`CREATE INDEX IF NOT EXISTS idx_media_external_id ON media(external_id)`,
-
-		// Seen media table - tracks what users have watched
-		`CREATE TABLE IF NOT EXISTS seen_media (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
-			rating REAL CHECK(rating IS NULL OR (rating >= 1 AND rating <= 10)),
-			watched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			UNIQUE(user_id, media_id)
-		)`,
-
-		// Index for efficient anti-join queries
-		`CREATE INDEX IF NOT EXISTS idx_seen_user_id ON seen_media(user_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_seen_media_id ON seen_media(media_id)`,
-
-		// Vibe embeddings table - stores vector representations
-		`CREATE TABLE IF NOT EXISTS vibe_embeddings (
-			media_id TEXT PRIMARY KEY REFERENCES media(id) ON DELETE CASCADE,
-			embedding BLOB NOT NULL,
-			model TEXT NOT NULL,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-
-		// Reddit threads table
-		`CREATE TABLE IF NOT EXISTS reddit_threads (
-			id TEXT PRIMARY KEY,
-			subreddit TEXT NOT NULL,
-			title TEXT NOT NULL,
-			body TEXT,
-			thread_type TEXT CHECK(thread_type IN ('similar_to', 'hidden_gem', 'quality_discussion', 'other')),
-			reference_show TEXT,
-			score INTEGER DEFAULT 0,
-			num_comments INTEGER DEFAULT 0,
-			scraped_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-
-		`CREATE INDEX IF NOT EXISTS idx_threads_subreddit ON reddit_threads(subreddit)`,
-		`CREATE INDEX IF NOT EXISTS idx_threads_type ON reddit_threads(thread_type)`,
-
-		// Reddit mentions table - tracks show mentions in threads
-		`CREATE TABLE IF NOT EXISTS reddit_mentions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			thread_id TEXT NOT NULL REFERENCES reddit_threads(id) ON DELETE CASCADE,
-			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
-			mention_context TEXT,
-			quality_boost REAL DEFAULT 0.0,
-			UNIQUE(thread_id, media_id)
-		)`,
-
-		`CREATE INDEX IF NOT EXISTS idx_mentions_media ON reddit_mentions(media_id)`,
+// Open opens the database without migrating it
+func Open(dbPath string) (*DB, error) {
+	sqlDB, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	for _, m := range migrations {
-		if _, err := db.Exec(m); err != nil {
-			return fmt.Errorf("migration failed: %w\nSQL: %s", err, m)
-		}
+	// Test connection
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return nil
+	return &DB{conn: sqlDB, path: dbPath}, nil
+}
+
+// Close closes the database connection
+func (db *DB) Close() error {
+	return db.conn.Close()
 }
 
 // ============================================================================
@@ -135,7 +60,7 @@ func (db *DB) migrate() error {
 
 // CreateUser creates a new user
 func (db *DB) CreateUser(user *models.User) error {
-	_, err := db.Exec(
+	_, err := db.conn.Exec(
 		`INSERT INTO users (id, username, created_at) VALUES (?, ?, ?)`,
 		user.ID, user.Username, time.Now(),
 	)
@@ -145,7 +70,7 @@ func (db *DB) CreateUser(user *models.User) error {
 // GetUser retrieves a user by ID
 func (db *DB) GetUser(id string) (*models.User, error) {
 	user := &models.User{}
-	err := db.QueryRow(
+	err := db.conn.QueryRow(
 		`SELECT id, username, created_at FROM users WHERE id = ?`,
 		id,
 	).Scan(&user.ID, &user.Username, &user.CreatedAt)
@@ -162,13 +87,15 @@ func (db *DB) GetUser(id string) (*models.User, error) {
 // CreateMedia inserts a new media entry
 func (db *DB) CreateMedia(media *models.Media) error {
 	now := time.Now()
-	_, err := db.Exec(
+	_, err := db.conn.Exec(
 		`INSERT INTO media (id, title, media_type, year, plot_summary, vibe_profile,
-		quality_score, popularity_score, source_subreddit, external_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		quality_score, popularity_score, source_subreddit, external_id,
+		runtime_minutes, episode_runtime, episode_count, season_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		media.ID, media.Title, media.MediaType, media.Year, media.PlotSummary,
 		media.VibeProfile, media.QualityScore, media.PopularityScore,
-		media.SourceSubreddit, media.ExternalID, now, now,
+		media.SourceSubreddit, media.ExternalID,
+		media.RuntimeMinutes, media.EpisodeRuntime, media.EpisodeCount, media.SeasonCount, now, now,
 	)
 	return err
 }
@@ -176,14 +103,18 @@ func (db *DB) CreateMedia(media *models.Media) error {
 // GetMedia retrieves a media entry by ID
 func (db *DB) GetMedia(id string) (*models.Media, error) {
 	media := &models.Media{}
-	err := db.QueryRow(
+	err := db.conn.QueryRow(
 		`SELECT id, title, media_type, year, plot_summary, vibe_profile,
-		quality_score, popularity_score, source_subreddit, external_id, created_at, updated_at
+		quality_score, popularity_score, source_subreddit, external_id,
+		runtime_minutes, episode_runtime, episode_count, season_count,
+		created_at, updated_at
 		FROM media WHERE id = ?`,
 		id,
 	).Scan(&media.ID, &media.Title, &media.MediaType, &media.Year, &media.PlotSummary,
 		&media.VibeProfile, &media.QualityScore, &media.PopularityScore,
-		&media.SourceSubreddit, &media.ExternalID, &media.CreatedAt, &media.UpdatedAt)
+		&media.SourceSubreddit, &media.ExternalID,
+		&media.RuntimeMinutes, &media.EpisodeRuntime, &media.EpisodeCount, &media.SeasonCount,
+		&media.CreatedAt, &media.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -193,14 +124,18 @@ func (db *DB) GetMedia(id string) (*models.Media, error) {
 // GetMediaByTitle finds media by exact title match
 func (db *DB) GetMediaByTitle(title string) (*models.Media, error) {
 	media := &models.Media{}
-	err := db.QueryRow(
+	err := db.conn.QueryRow(
 		`SELECT id, title, media_type, year, plot_summary, vibe_profile,
-		quality_score, popularity_score, source_subreddit, external_id, created_at, updated_at
+		quality_score, popularity_score, source_subreddit, external_id,
+		runtime_minutes, episode_runtime, episode_count, season_count,
+		created_at, updated_at
 		FROM media WHERE title = ? COLLATE NOCASE`,
 		title,
 	).Scan(&media.ID, &media.Title, &media.MediaType, &media.Year, &media.PlotSummary,
 		&media.VibeProfile, &media.QualityScore, &media.PopularityScore,
-		&media.SourceSubreddit, &media.ExternalID, &media.CreatedAt, &media.UpdatedAt)
+		&media.SourceSubreddit, &media.ExternalID,
+		&media.RuntimeMinutes, &media.EpisodeRuntime, &media.EpisodeCount, &media.SeasonCount,
+		&media.CreatedAt, &media.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -209,32 +144,53 @@ func (db *DB) GetMediaByTitle(title string) (*models.Media, error) {
 
 // UpdateQualityScore updates the quality score for a media entry
 func (db *DB) UpdateQualityScore(mediaID string, boost float64) error {
-	_, err := db.Exec(
+	_, err := db.conn.Exec(
 		`UPDATE media SET quality_score = quality_score + ?, updated_at = ? WHERE id = ?`,
 		boost, time.Now(), mediaID,
 	)
 	return err
 }
 
+// UpdateVibeProfile replaces the vibe profile of a media entry
+func (db *DB) UpdateVibeProfile(mediaID, vibeProfile string) error {
+	_, err := db.conn.Exec(
+		`UPDATE media SET vibe_profile = ?, updated_at = ? WHERE id = ?`,
+		vibeProfile, time.Now(), mediaID,
+	)
+	return err
+}
+
+// CountMedia returns how many media entries there are
+func (db *DB) CountMedia() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM media`).Scan(&count)
+	return count, err
+}
+
 // ============================================================================
 // Seen Media Operations (with Anti-Join support)
 // ============================================================================
 
-// MarkAsSeen adds a media to user's seen list
+// MarkAsSeen adds a media to user's seen list as completed. A title already
+// tracked keeps its progress and rewatch count.
 func (db *DB) MarkAsSeen(seen *models.SeenMedia) error {
 	now := time.Now()
-	_, err := db.Exec(
-		`INSERT OR REPLACE INTO seen_media (user_id, media_id, rating, watched_at, created_at)
-		VALUES (?, ?, ?, ?, ?)`,
-		seen.UserID, seen.MediaID, seen.Rating, now, now,
+	_, err := db.conn.Exec(
+		`INSERT INTO seen_media (user_id, media_id, rating, watched_at, created_at, status, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, media_id) DO UPDATE SET
+			rating = excluded.rating, watched_at = excluded.watched_at,
+			status = excluded.status, updated_at = excluded.updated_at`,
+		seen.UserID, seen.MediaID, seen.Rating, now, now, models.WatchCompleted, now,
 	)
 	return err
 }
 
 // GetSeenMedia retrieves all seen media for a user
 func (db *DB) GetSeenMedia(userID string) ([]models.SeenMedia, error) {
-	rows, err := db.Query(
-		`SELECT id, user_id, media_id, rating, watched_at, created_at
+	rows, err := db.conn.Query(
+		`SELECT id, user_id, media_id, rating, watched_at, created_at,
+		status, season, episode, rewatch_count, updated_at
 		FROM seen_media WHERE user_id = ? ORDER BY watched_at DESC`,
 		userID,
 	)
@@ -246,9 +202,12 @@ func (db *DB) GetSeenMedia(userID string) ([]models.SeenMedia, error) {
 	var seen []models.SeenMedia
 	for rows.Next() {
 		var s models.SeenMedia
-		if err := rows.Scan(&s.ID, &s.UserID, &s.MediaID, &s.Rating, &s.WatchedAt, &s.CreatedAt); err != nil {
+		var updated sql.NullTime
+		if err := rows.Scan(&s.ID, &s.UserID, &s.MediaID, &s.Rating, &s.WatchedAt, &s.CreatedAt,
+			&s.Status, &s.Season, &s.Episode, &s.RewatchCount, &updated); err != nil {
 			return nil, err
 		}
+		s.UpdatedAt = progressUpdatedAt(updated, s.WatchedAt)
 		seen = append(seen, s)
 	}
 	return seen, rows.Err()
@@ -256,9 +215,11 @@ func (db *DB) GetSeenMedia(userID string) ([]models.SeenMedia, error) {
 
 // GetSeenMediaWithDetails retrieves seen media with full media details
 func (db *DB) GetSeenMediaWithDetails(userID string) ([]models.Media, error) {
-	rows, err := db.Query(
+	rows, err := db.conn.Query(
 		`SELECT m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
-		m.quality_score, m.popularity_score, m.source_subreddit, m.external_id, m.created_at, m.updated_at
+		m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		m.created_at, m.updated_at
 		FROM media m
 		INNER JOIN seen_media s ON m.id = s.media_id
 		WHERE s.user_id = ?
@@ -275,7 +236,9 @@ func (db *DB) GetSeenMediaWithDetails(userID string) ([]models.Media, error) {
 		var m models.Media
 		if err := rows.Scan(&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary,
 			&m.VibeProfile, &m.QualityScore, &m.PopularityScore,
-			&m.SourceSubreddit, &m.ExternalID, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			&m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt); err != nil {
 			return nil, err
 		}
 		media = append(media, m)
@@ -286,7 +249,7 @@ func (db *DB) GetSeenMediaWithDetails(userID string) ([]models.Media, error) {
 // IsMediaSeen checks if a user has seen a specific media
 func (db *DB) IsMediaSeen(userID, mediaID string) (bool, error) {
 	var count int
-	err := db.QueryRow(
+	err := db.conn.QueryRow(
 		`SELECT COUNT(*) FROM seen_media WHERE user_id = ? AND media_id = ?`,
 		userID, mediaID,
 	).Scan(&count)
@@ -295,7 +258,7 @@ func (db *DB) IsMediaSeen(userID, mediaID string) (bool, error) {
 
 // GetSeenMediaIDs returns just the IDs of seen media for efficient filtering
 func (db *DB) GetSeenMediaIDs(userID string) (map[string]bool, error) {
-	rows, err := db.Query(`SELECT media_id FROM seen_media WHERE user_id = ?`, userID)
+	rows, err := db.conn.Query(`SELECT media_id FROM seen_media WHERE user_id = ?`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -324,7 +287,7 @@ func (db *DB) StoreEmbedding(mediaID string, embedding []float32, model string)
 		return fmt.Errorf("failed to serialize embedding: %w", err)
 	}
 
-	_, err = db.Exec(
+	_, err = db.conn.Exec(
 		`INSERT OR REPLACE INTO vibe_embeddings (media_id, embedding, model, created_at)
 		VALUES (?, ?, ?, ?)`,
 		mediaID, embBytes, model, time.Now(),
@@ -335,7 +298,7 @@ func (db *DB) StoreEmbedding(mediaID string, embedding []float32, model string)
 // GetEmbedding retrieves the embedding for a media entry
 func (db *DB) GetEmbedding(mediaID string) ([]float32, error) {
 	var embBytes []byte
-	err := db.QueryRow(
+	err := db.conn.QueryRow(
 		`SELECT embedding FROM vibe_embeddings WHERE media_id = ?`,
 		mediaID,
 	).Scan(&embBytes)
@@ -356,7 +319,7 @@ func (db *DB) GetEmbedding(mediaID string) ([]float32, error) {
 // GetAllEmbeddings retrieves all embeddings for vector search
 // Returns a map of mediaID -> embedding
 func (db *DB) GetAllEmbeddings() (map[string][]float32, error) {
-	rows, err := db.Query(`SELECT media_id, embedding FROM vibe_embeddings`)
+	rows, err := db.conn.Query(`SELECT media_id, embedding FROM vibe_embeddings`)
 	if err != nil {
 		return nil, err
 	}
@@ -379,10 +342,61 @@ func (db *DB) GetAllEmbeddings() (map[string][]float32, error) {
 	return embeddings, rows.Err()
 }
 
+// GetAllVibeProfiles returns every non-empty vibe profile keyed by media ID
+func (db *DB) GetAllVibeProfiles() (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT id, vibe_profile FROM media WHERE vibe_profile IS NOT NULL AND vibe_profile != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make(map[string]string)
+	for rows.Next() {
+		var id, profile string
+		if err := rows.Scan(&id, &profile); err != nil {
+			return nil, err
+		}
+		profiles[id] = profile
+	}
+	return profiles, rows.Err()
+}
+
+// GetVibeProfilesWithoutEmbedding returns the non-empty vibe profiles of
+// media entries that have no embedding yet, keyed by media ID
+func (db *DB) GetVibeProfilesWithoutEmbedding() (map[string]string, error) {
+	rows, err := db.conn.Query(
+		`SELECT m.id, m.vibe_profile
+		FROM media m
+		LEFT JOIN vibe_embeddings ve ON m.id = ve.media_id
+		WHERE ve.media_id IS NULL AND m.vibe_profile != ''`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make(map[string]string)
+	for rows.Next() {
+		var id, profile string
+		if err := rows.Scan(&id, &profile); err != nil {
+			return nil, err
+		}
+		profiles[id] = profile
+	}
+	return profiles, rows.Err()
+}
+
+// CountEmbeddings returns how many media entries have an embedding
+func (db *DB) CountEmbeddings() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM vibe_embeddings`).Scan(&count)
+	return count, err
+}
+
 // GetAllEmbeddingsExcludingSeen retrieves embeddings with ANTI-JOIN to exclude seen media
 // This is the crucial query that filters out what the user has already watched
 func (db *DB) GetAllEmbeddingsExcludingSeen(userID string) (map[string][]float32, error) {
-	rows, err := db.Query(
+	rows, err := db.conn.Query(
 		`SELECT ve.media_id, ve.embedding
 		FROM vibe_embeddings ve
 		LEFT JOIN seen_media sm ON ve.media_id = sm.media_id AND sm.user_id = ?
@@ -415,21 +429,31 @@ func (db *DB) GetAllEmbeddingsExcludingSeen(userID string) (map[string][]float32
 // Reddit Scraping Operations
 // ============================================================================
 
-// CreateRedditThread stores a scraped thread
+// CreateRedditThread stores a scraped thread, or refreshes its score and
+// comment count if it was scraped before
 func (db *DB) CreateRedditThread(thread *models.RedditThread) error {
-	_, err := db.Exec(
-		`INSERT OR IGNORE INTO reddit_threads
-		(id, subreddit, title, body, thread_type, reference_show, score, num_comments, scraped_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	// Rescrapes refresh engagement; posted_at is kept from the first sighting
+	var postedAt interface{}
+	if !thread.PostedAt.IsZero() {
+		postedAt = thread.PostedAt.UTC()
+	}
+	_, err := db.conn.Exec(
+		`INSERT INTO reddit_threads
+		(id, subreddit, title, body, thread_type, reference_show, score, num_comments, scraped_at, posted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			score = excluded.score, num_comments = excluded.num_comments,
+			posted_at = COALESCE(reddit_threads.posted_at, excluded.posted_at)`,
 		thread.ID, thread.Subreddit, thread.Title, thread.Body,
 		thread.ThreadType, thread.ReferenceShow, thread.Score, thread.NumComments, thread.ScrapedAt,
+		postedAt,
 	)
 	return err
 }
 
 // CreateRedditMention records a media mention in a thread
 func (db *DB) CreateRedditMention(mention *models.RedditMention) error {
-	_, err := db.Exec(
+	_, err := db.conn.Exec(
 		`INSERT OR IGNORE INTO reddit_mentions
 		(thread_id, media_id, mention_context, quality_boost)
 		VALUES (?, ?, ?, ?)`,
@@ -441,9 +465,45 @@ func (db *DB) CreateRedditMention(mention *models.RedditMention) error {
 // GetMentionCountForMedia returns how many times a media has been mentioned
 func (db *DB) GetMentionCountForMedia(mediaID string) (int, error) {
 	var count int
-	err := db.QueryRow(
+	err := db.conn.QueryRow(
 		`SELECT COUNT(*) FROM reddit_mentions WHERE media_id = ?`,
 		mediaID,
 	).Scan(&count)
 	return count, err
 }
+
+// CountRedditThreads returns how many threads have been scraped
+func (db *DB) CountRedditThreads() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM reddit_threads`).Scan(&count)
+	return count, err
+}
+
+// CountRedditMentions returns how many media mentions have been recorded
+func (db *DB) CountRedditMentions() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM reddit_mentions`).Scan(&count)
+	return count, err
+}
+
+// GetThreadCountsBySubreddit returns how many threads were scraped from
+// each subreddit, in subreddit order
+func (db *DB) GetThreadCountsBySubreddit() ([]models.SubredditCount, error) {
+	rows, err := db.conn.Query(
+		`SELECT subreddit, COUNT(*) FROM reddit_threads GROUP BY subreddit ORDER BY subreddit`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.SubredditCount
+	for rows.Next() {
+		var c models.SubredditCount
+		if err := rows.Scan(&c.Subreddit, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
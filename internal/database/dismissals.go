@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Dismissal Operations
+// ============================================================================
+
+// Dismiss records (or replaces) a user's dismissal of a media entry
+func (db *DB) Dismiss(d *models.Dismissal) error {
+	var until interface{}
+	if d.Until != nil {
+		until = d.Until.UTC()
+	}
+	_, err := db.conn.Exec(
+		`INSERT OR REPLACE INTO dismissals (user_id, media_id, reason, until, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		d.UserID, d.MediaID, d.Reason, until, time.Now().UTC(),
+	)
+	return err
+}
+
+// Undismiss removes a dismissal. Returns false if there was none.
+func (db *DB) Undismiss(userID, mediaID string) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM dismissals WHERE user_id = ? AND media_id = ?`, userID, mediaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetDismissals returns a user's dismissals with media details, newest
+// first. Expired snoozes are only included when includeExpired is set.
+func (db *DB) GetDismissals(userID string, includeExpired bool) ([]models.Dismissal, error) {
+	query := `
+		SELECT d.user_id, d.media_id, d.reason, d.until, d.created_at,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM dismissals d
+		JOIN media m ON d.media_id = m.id
+		WHERE d.user_id = ?`
+	args := []interface{}{userID}
+	if !includeExpired {
+		query += ` AND (d.until IS NULL OR d.until > ?)`
+		args = append(args, time.Now().UTC())
+	}
+	query += ` ORDER BY d.created_at DESC`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dismissals []models.Dismissal
+	for rows.Next() {
+		var d models.Dismissal
+		var until sql.NullTime
+		var m models.Media
+		if err := rows.Scan(
+			&d.UserID, &d.MediaID, &d.Reason, &until, &d.CreatedAt,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if until.Valid {
+			d.Until = &until.Time
+		}
+		d.Media = &m
+		dismissals = append(dismissals, d)
+	}
+	return dismissals, rows.Err()
+}
+
+// GetExcludedMediaIDs returns everything that must never be recommended to
+// the user: seen media plus active dismissals
+func (db *DB) GetExcludedMediaIDs(userID string) (map[string]bool, error) {
+	rows, err := db.conn.Query(
+		`SELECT media_id FROM seen_media WHERE user_id = ?
+		UNION
+		SELECT media_id FROM dismissals WHERE user_id = ? AND (until IS NULL OR until > ?)`,
+		userID, userID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	excluded := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		excluded[id] = true
+	}
+	return excluded, rows.Err()
+}
+
+// GetNotInterestedIDs returns the media a user marked "not interested"
+func (db *DB) GetNotInterestedIDs(userID string) ([]string, error) {
+	rows, err := db.conn.Query(
+		`SELECT media_id FROM dismissals WHERE user_id = ? AND reason = ?`,
+		userID, models.DismissNotInterested,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
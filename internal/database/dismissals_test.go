@@ -0,0 +1,72 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+func TestDismissalExpiry(t *testing.T) {
+	db := openMigrated(t)
+	must(t, db.CreateUser(&models.User{ID: "u1", Username: "ana"}))
+	for _, id := range []string{"boring", "known", "later", "lapsed", "seen"} {
+		must(t, db.CreateMedia(&models.Media{ID: id, Title: id, MediaType: "movie", VibeProfile: "x"}))
+	}
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+	must(t, db.Dismiss(&models.Dismissal{UserID: "u1", MediaID: "boring", Reason: models.DismissNotInterested}))
+	must(t, db.Dismiss(&models.Dismissal{UserID: "u1", MediaID: "known", Reason: models.DismissAlreadyKnow}))
+	must(t, db.Dismiss(&models.Dismissal{UserID: "u1", MediaID: "later", Reason: models.DismissSnooze, Until: &future}))
+	must(t, db.Dismiss(&models.Dismissal{UserID: "u1", MediaID: "lapsed", Reason: models.DismissSnooze, Until: &past}))
+	must(t, db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "seen"}))
+
+	// A lapsed snooze stops excluding the title; everything else still does
+	excluded, err := db.GetExcludedMediaIDs("u1")
+	must(t, err)
+	if len(excluded) != 4 || excluded["lapsed"] {
+		t.Errorf("excluded = %v, want all but lapsed", excluded)
+	}
+
+	active, err := db.GetDismissals("u1", false)
+	must(t, err)
+	if len(active) != 3 {
+		t.Errorf("%d active dismissals, want 3", len(active))
+	}
+	all, err := db.GetDismissals("u1", true)
+	must(t, err)
+	if len(all) != 4 {
+		t.Fatalf("%d dismissals with expired, want 4", len(all))
+	}
+	for _, d := range all {
+		if d.Media == nil || d.Media.ID != d.MediaID {
+			t.Errorf("%s listed without its media", d.MediaID)
+		}
+		if (d.Until != nil) != (d.Reason == models.DismissSnooze) {
+			t.Errorf("%s (%s) has until %v", d.MediaID, d.Reason, d.Until)
+		}
+	}
+	if ids, _ := db.GetNotInterestedIDs("u1"); len(ids) != 1 || ids[0] != "boring" {
+		t.Errorf("not interested = %v, want boring", ids)
+	}
+
+	// Snoozing again replaces the lapsed row instead of adding one
+	must(t, db.Dismiss(&models.Dismissal{UserID: "u1", MediaID: "lapsed", Reason: models.DismissSnooze, Until: &future}))
+	if excluded, _ := db.GetExcludedMediaIDs("u1"); !excluded["lapsed"] {
+		t.Error("re-snoozed title not excluded")
+	}
+	if all, _ := db.GetDismissals("u1", true); len(all) != 4 {
+		t.Errorf("%d dismissals after re-snoozing, want 4", len(all))
+	}
+
+	removed, err := db.Undismiss("u1", "later")
+	must(t, err)
+	if !removed {
+		t.Error("Undismiss found nothing")
+	}
+	if removed, _ := db.Undismiss("u1", "later"); removed {
+		t.Error("Undismiss removed the same row twice")
+	}
+	if excluded, _ := db.GetExcludedMediaIDs("u1"); excluded["later"] {
+		t.Error("undismissed title still excluded")
+	}
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Vibe Facet Operations
+// ============================================================================
+
+// UpsertFacet inserts or updates a vocabulary term
+func (db *DB) UpsertFacet(facet *models.Facet) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO vibe_facets (slug, category, label) VALUES (?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET category = excluded.category, label = excluded.label`,
+		facet.Slug, facet.Category, facet.Label,
+	)
+	return err
+}
+
+// ListFacets returns the vocabulary with the number of media carrying each
+// facet at or above minConfidence. An empty category returns every facet.
+func (db *DB) ListFacets(category string, minConfidence float64) ([]models.Facet, error) {
+	query := `SELECT f.slug, f.category, f.label, COUNT(mf.media_id)
+		FROM vibe_facets f
+		LEFT JOIN media_facets mf ON mf.facet_slug = f.slug AND mf.confidence >= ?`
+	args := []interface{}{minConfidence}
+	if category != "" {
+		query += ` WHERE f.category = ?`
+		args = append(args, category)
+	}
+	query += ` GROUP BY f.slug ORDER BY f.category, COUNT(mf.media_id) DESC, f.label`
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facets []models.Facet
+	for rows.Next() {
+		var f models.Facet
+		if err := rows.Scan(&f.Slug, &f.Category, &f.Label, &f.MediaCount); err != nil {
+			return nil, err
+		}
+		facets = append(facets, f)
+	}
+	return facets, rows.Err()
+}
+
+// ReplaceMediaFacets atomically swaps the facet set of a media entry
+func (db *DB) ReplaceMediaFacets(mediaID string, facets []models.MediaFacet) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM media_facets WHERE media_id = ?`, mediaID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, f := range facets {
+		if _, err := tx.Exec(
+			`INSERT INTO media_facets (media_id, facet_slug, confidence, source, created_at)
+			VALUES (?, ?, ?, ?, ?)`,
+			mediaID, f.FacetSlug, f.Confidence, f.Source, now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFacetChips returns the facets of each given media at or above
+// minConfidence, keyed by media ID and ordered by confidence
+func (db *DB) GetFacetChips(mediaIDs []string, minConfidence float64) (map[string][]models.FacetChip, error) {
+	chips := make(map[string][]models.FacetChip)
+	if len(mediaIDs) == 0 {
+		return chips, nil
+	}
+
+	args := make([]interface{}, 0, len(mediaIDs)+1)
+	for _, id := range mediaIDs {
+		args = append(args, id)
+	}
+	args = append(args, minConfidence)
+
+	rows, err := db.conn.Query(
+		`SELECT mf.media_id, f.slug, f.category, f.label, mf.confidence
+		FROM media_facets mf
+		INNER JOIN vibe_facets f ON f.slug = mf.facet_slug
+		WHERE mf.media_id IN (`+placeholders(len(mediaIDs))+`) AND mf.confidence >= ?
+		ORDER BY mf.media_id, mf.confidence DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mediaID string
+		var c models.FacetChip
+		if err := rows.Scan(&mediaID, &c.Slug, &c.Category, &c.Label, &c.Confidence); err != nil {
+			return nil, err
+		}
+		chips[mediaID] = append(chips[mediaID], c)
+	}
+	return chips, rows.Err()
+}
+
+// GetMediaIDsWithFacets returns the media carrying ALL of the given facets at
+// or above minConfidence, for restricting vector search
+func (db *DB) GetMediaIDsWithFacets(slugs []string, minConfidence float64) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	if len(slugs) == 0 {
+		return ids, nil
+	}
+
+	args := make([]interface{}, 0, len(slugs)+2)
+	args = append(args, minConfidence)
+	for _, s := range slugs {
+		args = append(args, s)
+	}
+	args = append(args, len(slugs))
+
+	rows, err := db.conn.Query(
+		`SELECT media_id FROM media_facets
+		WHERE confidence >= ? AND facet_slug IN (`+placeholders(len(slugs))+`)
+		GROUP BY media_id
+		HAVING COUNT(DISTINCT facet_slug) = ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// GetMediaIDsMissingFacets returns media that have never been facet-tagged.
+// With all set, every media ID is returned instead (for forced re-extraction).
+func (db *DB) GetMediaIDsMissingFacets(all bool) ([]string, error) {
+	query := `SELECT m.id FROM media m
+		LEFT JOIN media_facets mf ON mf.media_id = m.id
+		WHERE mf.media_id IS NULL
+		ORDER BY m.id`
+	if all {
+		query = `SELECT id FROM media ORDER BY id`
+	}
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// placeholders builds a "?, ?, ?" list for IN clauses
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Feed Operations
+// ============================================================================
+
+// CreateFeed gives the user a new feed, replacing (and so revoking) any
+// feed they already had
+func (db *DB) CreateFeed(feed *models.Feed) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM feeds WHERE user_id = ?`, feed.UserID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO feeds (id, user_id, created_at) VALUES (?, ?, ?)`,
+		feed.ID, feed.UserID, feed.CreatedAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetFeed returns a feed by ID, or nil if it does not exist (or was revoked)
+func (db *DB) GetFeed(id string) (*models.Feed, error) {
+	return db.scanFeed(db.conn.QueryRow(
+		`SELECT id, user_id, created_at, refreshed_at FROM feeds WHERE id = ?`, id,
+	))
+}
+
+// GetFeedByUser returns the user's feed, or nil if they have none
+func (db *DB) GetFeedByUser(userID string) (*models.Feed, error) {
+	return db.scanFeed(db.conn.QueryRow(
+		`SELECT id, user_id, created_at, refreshed_at FROM feeds WHERE user_id = ?`, userID,
+	))
+}
+
+func (db *DB) scanFeed(row *sql.Row) (*models.Feed, error) {
+	f := &models.Feed{}
+	var refreshed sql.NullTime
+	err := row.Scan(&f.ID, &f.UserID, &f.CreatedAt, &refreshed)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if refreshed.Valid {
+		f.RefreshedAt = &refreshed.Time
+	}
+	return f, err
+}
+
+// DeleteFeedByUser revokes the user's feed. Returns false if they had none.
+func (db *DB) DeleteFeedByUser(userID string) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM feeds WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RefreshFeed publishes new picks to a feed and marks it refreshed. Picks
+// already in the feed keep their original publish time; titles the user has
+// since seen or dismissed are dropped, and only the newest keep items are
+// retained.
+func (db *DB) RefreshFeed(feed *models.Feed, items []models.FeedItem, keep int) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	stmt, err := tx.Prepare(
+		`INSERT OR IGNORE INTO feed_items (feed_id, media_id, source, explanation, published_at)
+		VALUES (?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for i, item := range items {
+		// Step publish times down so readers keep the picks in order
+		published := now.Add(-time.Duration(i) * time.Millisecond)
+		if _, err := stmt.Exec(feed.ID, item.MediaID, item.Source, item.Explanation, published); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(
+		`DELETE FROM feed_items WHERE feed_id = ? AND media_id IN (
+			SELECT media_id FROM seen_media WHERE user_id = ?
+			UNION
+			SELECT media_id FROM dismissals WHERE user_id = ? AND (until IS NULL OR until > ?)
+		)`,
+		feed.ID, feed.UserID, feed.UserID, now,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`DELETE FROM feed_items WHERE feed_id = ? AND media_id NOT IN (
+			SELECT media_id FROM feed_items WHERE feed_id = ?
+			ORDER BY published_at DESC, media_id LIMIT ?
+		)`,
+		feed.ID, feed.ID, keep,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE feeds SET refreshed_at = ? WHERE id = ?`, now, feed.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	feed.RefreshedAt = &now
+	return nil
+}
+
+// GetFeedItems returns a feed's items with media details, newest first,
+// leaving out anything the user has seen or dismissed since it was published
+func (db *DB) GetFeedItems(feed *models.Feed) ([]models.FeedItem, error) {
+	rows, err := db.conn.Query(
+		`SELECT fi.feed_id, fi.media_id, fi.source, COALESCE(fi.explanation, ''), fi.published_at,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM feed_items fi
+		JOIN media m ON m.id = fi.media_id
+		LEFT JOIN seen_media sm ON sm.media_id = fi.media_id AND sm.user_id = ?
+		LEFT JOIN dismissals d ON d.media_id = fi.media_id AND d.user_id = ?
+			AND (d.until IS NULL OR d.until > ?)
+		WHERE fi.feed_id = ?
+		AND sm.media_id IS NULL
+		AND d.media_id IS NULL
+		ORDER BY fi.published_at DESC, fi.media_id`,
+		feed.UserID, feed.UserID, time.Now().UTC(), feed.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.FeedItem
+	for rows.Next() {
+		var item models.FeedItem
+		var m models.Media
+		if err := rows.Scan(
+			&item.FeedID, &item.MediaID, &item.Source, &item.Explanation, &item.PublishedAt,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		item.Media = &m
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetNewMedia returns the most recently added titles the user has not seen
+// or dismissed, newest first
+func (db *DB) GetNewMedia(userID string, limit int) ([]models.Media, error) {
+	rows, err := db.conn.Query(
+		`SELECT m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM media m
+		LEFT JOIN seen_media sm ON sm.media_id = m.id AND sm.user_id = ?
+		LEFT JOIN dismissals d ON d.media_id = m.id AND d.user_id = ?
+			AND (d.until IS NULL OR d.until > ?)
+		WHERE sm.media_id IS NULL
+		AND d.media_id IS NULL
+		ORDER BY m.created_at DESC, m.id
+		LIMIT ?`,
+		userID, userID, time.Now().UTC(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.Media
+	for rows.Next() {
+		var m models.Media
+		if err := rows.Scan(
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
@@ -0,0 +1,243 @@
+package database
+
+import (
+	"database/sql"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Franchise Operations
+// ============================================================================
+
+// LinkFranchiseEntry stores the franchise (refreshing its name) and places a
+// title in it, moving the title out of any franchise it was in before
+func (db *DB) LinkFranchiseEntry(f *models.Franchise, entry models.FranchiseEntry) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := upsertFranchise(tx, f); err != nil {
+		return err
+	}
+	if err := upsertFranchiseEntry(tx, f.ID, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceFranchise stores the franchise with exactly the given entries,
+// dropping any it had before
+func (db *DB) ReplaceFranchise(f *models.Franchise) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := upsertFranchise(tx, f); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM franchise_entries WHERE franchise_id = ?`, f.ID); err != nil {
+		return err
+	}
+	for _, e := range f.Entries {
+		if err := upsertFranchiseEntry(tx, f.ID, e); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func upsertFranchise(tx *sql.Tx, f *models.Franchise) error {
+	_, err := tx.Exec(
+		`INSERT INTO franchises (id, name, source, external_id) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET name = excluded.name, external_id = excluded.external_id`,
+		f.ID, f.Name, f.Source, f.ExternalID,
+	)
+	return err
+}
+
+func upsertFranchiseEntry(tx *sql.Tx, franchiseID string, e models.FranchiseEntry) error {
+	_, err := tx.Exec(
+		`INSERT INTO franchise_entries (media_id, franchise_id, watch_order, role) VALUES (?, ?, ?, ?)
+		ON CONFLICT(media_id) DO UPDATE SET
+			franchise_id = excluded.franchise_id, watch_order = excluded.watch_order, role = excluded.role`,
+		e.MediaID, franchiseID, e.WatchOrder, e.Role,
+	)
+	return err
+}
+
+const franchiseColumns = `f.id, f.name, f.source, f.external_id, f.created_at,
+	(SELECT COUNT(*) FROM franchise_entries fe WHERE fe.franchise_id = f.id)`
+
+func scanFranchise(row interface{ Scan(...interface{}) error }) (*models.Franchise, error) {
+	var f models.Franchise
+	var created sql.NullTime
+	if err := row.Scan(&f.ID, &f.Name, &f.Source, &f.ExternalID, &created, &f.EntryCount); err != nil {
+		return nil, err
+	}
+	f.CreatedAt = created.Time
+	return &f, nil
+}
+
+// GetFranchise returns a franchise without its entries, or nil
+func (db *DB) GetFranchise(id string) (*models.Franchise, error) {
+	f, err := scanFranchise(db.conn.QueryRow(`SELECT `+franchiseColumns+` FROM franchises f WHERE f.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return f, err
+}
+
+// GetMediaFranchiseID returns the ID of the franchise a title belongs to,
+// or "" if it belongs to none
+func (db *DB) GetMediaFranchiseID(mediaID string) (string, error) {
+	var id string
+	err := db.conn.QueryRow(`SELECT franchise_id FROM franchise_entries WHERE media_id = ?`, mediaID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
+
+// ListFranchises returns every franchise without entries, by name
+func (db *DB) ListFranchises() ([]models.Franchise, error) {
+	rows, err := db.conn.Query(`SELECT ` + franchiseColumns + ` FROM franchises f ORDER BY f.name, f.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var franchises []models.Franchise
+	for rows.Next() {
+		f, err := scanFranchise(rows)
+		if err != nil {
+			return nil, err
+		}
+		franchises = append(franchises, *f)
+	}
+	return franchises, rows.Err()
+}
+
+// DeleteFranchise removes a franchise and unlinks its titles. Returns false
+// if there was no such franchise.
+func (db *DB) DeleteFranchise(id string) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM franchises WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetFranchiseEntries returns a franchise's titles with media details, main
+// entries first, each in watch order
+func (db *DB) GetFranchiseEntries(franchiseID string) ([]models.FranchiseEntry, error) {
+	rows, err := db.conn.Query(
+		`SELECT fe.media_id, fe.watch_order, fe.role,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM franchise_entries fe
+		JOIN media m ON fe.media_id = m.id
+		WHERE fe.franchise_id = ?
+		ORDER BY fe.role = 'spinoff', fe.watch_order, m.year`,
+		franchiseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.FranchiseEntry
+	for rows.Next() {
+		var e models.FranchiseEntry
+		var m models.Media
+		if err := rows.Scan(
+			&e.MediaID, &e.WatchOrder, &e.Role,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.Media = &m
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetFranchisesOf returns the franchises the given titles belong to, keyed
+// by franchise ID, each with all of its entries (without media details),
+// main entries first, each in watch order
+func (db *DB) GetFranchisesOf(mediaIDs []string) (map[string]*models.Franchise, error) {
+	franchises := make(map[string]*models.Franchise)
+	if len(mediaIDs) == 0 {
+		return franchises, nil
+	}
+
+	args := make([]interface{}, len(mediaIDs))
+	for i, id := range mediaIDs {
+		args[i] = id
+	}
+	rows, err := db.conn.Query(
+		`SELECT f.id, f.name, f.source, fe.media_id, fe.watch_order, fe.role
+		FROM franchise_entries fe
+		JOIN franchises f ON fe.franchise_id = f.id
+		WHERE fe.franchise_id IN (
+			SELECT franchise_id FROM franchise_entries WHERE media_id IN (`+placeholders(len(mediaIDs))+`)
+		)
+		ORDER BY f.id, fe.role = 'spinoff', fe.watch_order`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, name, source string
+		var e models.FranchiseEntry
+		if err := rows.Scan(&id, &name, &source, &e.MediaID, &e.WatchOrder, &e.Role); err != nil {
+			return nil, err
+		}
+		f := franchises[id]
+		if f == nil {
+			f = &models.Franchise{ID: id, Name: name, Source: source}
+			franchises[id] = f
+		}
+		f.Entries = append(f.Entries, e)
+		f.EntryCount++
+	}
+	return franchises, rows.Err()
+}
+
+// GetTMDBMoviesWithoutFranchise returns imported TMDB movies not yet linked
+// to a franchise, for backfilling collections
+func (db *DB) GetTMDBMoviesWithoutFranchise() ([]models.Media, error) {
+	rows, err := db.conn.Query(
+		`SELECT id, title, media_type, external_id FROM media
+		WHERE id LIKE 'tmdb-movie-%' AND external_id LIKE 'tmdb:%'
+		AND id NOT IN (SELECT media_id FROM franchise_entries)
+		ORDER BY id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.Media
+	for rows.Next() {
+		var m models.Media
+		if err := rows.Scan(&m.ID, &m.Title, &m.MediaType, &m.ExternalID); err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Hidden Gem Operations
+// ============================================================================
+
+// SetMediaVotes stores a title's TMDB vote average and count
+func (db *DB) SetMediaVotes(mediaID string, average float64, count int) error {
+	_, err := db.conn.Exec(
+		`UPDATE media SET vote_average = ?, vote_count = ?, updated_at = ? WHERE id = ?`,
+		average, count, time.Now(), mediaID,
+	)
+	return err
+}
+
+// GetGemInputs returns ratings, popularity and hidden_gem thread mentions
+// for every title
+func (db *DB) GetGemInputs() ([]models.GemInput, error) {
+	rows, err := db.conn.Query(
+		`SELECT m.id, m.media_type, m.vote_average, m.vote_count, m.popularity_score,
+		       COALESCE(g.mentions, 0)
+		FROM media m
+		LEFT JOIN (
+			SELECT rm.media_id, COUNT(DISTINCT rm.thread_id) AS mentions
+			FROM reddit_mentions rm
+			JOIN reddit_threads t ON t.id = rm.thread_id
+			WHERE t.thread_type = 'hidden_gem'
+			GROUP BY rm.media_id
+		) g ON g.media_id = m.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var inputs []models.GemInput
+	for rows.Next() {
+		var in models.GemInput
+		if err := rows.Scan(&in.MediaID, &in.MediaType, &in.VoteAverage, &in.VoteCount,
+			&in.Popularity, &in.Mentions); err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, rows.Err()
+}
+
+// ReplaceGemScores swaps the stored gem scores for a fresh set
+func (db *DB) ReplaceGemScores(scores []models.GemScore) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM gem_scores`); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(
+		`INSERT INTO gem_scores (media_id, score, bayes_rating, rating_pct, popularity_pct, mentions, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, g := range scores {
+		if _, err := stmt.Exec(g.MediaID, g.Score, g.BayesRating, g.RatingPct, g.PopularityPct, g.Mentions, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetGemScores returns the gem scores of titles no more popular than
+// maxPopularityPct, optionally of one media type, keyed by media ID
+func (db *DB) GetGemScores(mediaType string, maxPopularityPct float64) (map[string]models.GemScore, error) {
+	rows, err := db.conn.Query(
+		`SELECT g.media_id, g.score, g.bayes_rating, g.rating_pct, g.popularity_pct, g.mentions, g.updated_at
+		FROM gem_scores g
+		JOIN media m ON m.id = g.media_id
+		WHERE g.popularity_pct <= ? AND (? = '' OR m.media_type = ?)`,
+		maxPopularityPct, mediaType, mediaType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scores := make(map[string]models.GemScore)
+	for rows.Next() {
+		var g models.GemScore
+		if err := rows.Scan(&g.MediaID, &g.Score, &g.BayesRating, &g.RatingPct, &g.PopularityPct,
+			&g.Mentions, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		scores[g.MediaID] = g
+	}
+	return scores, rows.Err()
+}
+
+// GetHiddenGemCandidates returns gems the user has not seen or dismissed,
+// best first, with media details. Same filters as GetGemScores.
+func (db *DB) GetHiddenGemCandidates(userID, mediaType string, maxPopularityPct float64) ([]models.GemScore, error) {
+	rows, err := db.conn.Query(
+		`SELECT g.media_id, g.score, g.bayes_rating, g.rating_pct, g.popularity_pct, g.mentions, g.updated_at,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM gem_scores g
+		JOIN media m ON m.id = g.media_id
+		LEFT JOIN seen_media sm ON m.id = sm.media_id AND sm.user_id = ?
+		LEFT JOIN dismissals d ON m.id = d.media_id AND d.user_id = ?
+			AND (d.until IS NULL OR d.until > ?)
+		WHERE sm.media_id IS NULL
+		AND d.media_id IS NULL
+		AND g.popularity_pct <= ?
+		AND (? = '' OR m.media_type = ?)
+		ORDER BY g.score DESC`,
+		userID, userID, time.Now().UTC(), maxPopularityPct, mediaType, mediaType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gems []models.GemScore
+	for rows.Next() {
+		var g models.GemScore
+		var m models.Media
+		if err := rows.Scan(
+			&g.MediaID, &g.Score, &g.BayesRating, &g.RatingPct, &g.PopularityPct, &g.Mentions, &g.UpdatedAt,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		g.Media = &m
+		gems = append(gems, g)
+	}
+	return gems, rows.Err()
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Watch-Party Group Operations
+// ============================================================================
+
+// CreateGroup inserts a group and makes its owner the first member
+func (db *DB) CreateGroup(g *models.Group, ownerNickname string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO watch_groups (id, name, invite_code, owner_id, created_at) VALUES (?, ?, ?, ?, ?)`,
+		g.ID, g.Name, g.InviteCode, g.OwnerID, g.CreatedAt,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO watch_group_members (group_id, user_id, nickname, joined_at) VALUES (?, ?, ?, ?)`,
+		g.ID, g.OwnerID, ownerNickname, g.CreatedAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetGroup retrieves a group (without members) by ID
+func (db *DB) GetGroup(id string) (*models.Group, error) {
+	return db.scanGroup(db.conn.QueryRow(
+		`SELECT id, name, invite_code, owner_id, created_at FROM watch_groups WHERE id = ?`, id,
+	))
+}
+
+// GetGroupByInviteCode retrieves a group (without members) by invite code
+func (db *DB) GetGroupByInviteCode(code string) (*models.Group, error) {
+	return db.scanGroup(db.conn.QueryRow(
+		`SELECT id, name, invite_code, owner_id, created_at FROM watch_groups WHERE invite_code = ?`, code,
+	))
+}
+
+func (db *DB) scanGroup(row *sql.Row) (*models.Group, error) {
+	g := &models.Group{}
+	err := row.Scan(&g.ID, &g.Name, &g.InviteCode, &g.OwnerID, &g.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return g, err
+}
+
+// ListGroupsForUser returns the groups a user belongs to, newest first
+func (db *DB) ListGroupsForUser(userID string) ([]models.Group, error) {
+	rows, err := db.conn.Query(
+		`SELECT g.id, g.name, g.invite_code, g.owner_id, g.created_at
+		FROM watch_groups g
+		JOIN watch_group_members m ON m.group_id = g.id
+		WHERE m.user_id = ?
+		ORDER BY g.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []models.Group
+	for rows.Next() {
+		var g models.Group
+		if err := rows.Scan(&g.ID, &g.Name, &g.InviteCode, &g.OwnerID, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// GetGroupMembers returns a group's members in join order
+func (db *DB) GetGroupMembers(groupID string) ([]models.GroupMember, error) {
+	rows, err := db.conn.Query(
+		`SELECT m.user_id, m.nickname, m.joined_at, m.user_id = g.owner_id
+		FROM watch_group_members m
+		JOIN watch_groups g ON g.id = m.group_id
+		WHERE m.group_id = ?
+		ORDER BY m.joined_at, m.nickname`,
+		groupID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.GroupMember
+	for rows.Next() {
+		var m models.GroupMember
+		if err := rows.Scan(&m.UserID, &m.Nickname, &m.JoinedAt, &m.IsOwner); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddGroupMember adds a user to a group, or renames them if already in it
+func (db *DB) AddGroupMember(groupID, userID, nickname string) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO watch_group_members (group_id, user_id, nickname, joined_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(group_id, user_id) DO UPDATE SET nickname = excluded.nickname`,
+		groupID, userID, nickname, time.Now(),
+	)
+	return err
+}
+
+// RemoveGroupMember takes a user out of a group, deleting the group once
+// it is empty. Returns false if the user was not a member.
+func (db *DB) RemoveGroupMember(groupID, userID string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM watch_group_members WHERE group_id = ? AND user_id = ?`, groupID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(
+		`DELETE FROM watch_groups
+		WHERE id = ? AND NOT EXISTS (SELECT 1 FROM watch_group_members WHERE group_id = ?)`,
+		groupID, groupID,
+	); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Impression Logging Operations
+// ============================================================================
+
+// CreateImpression stores a logged recommendation response
+func (db *DB) CreateImpression(imp *models.Impression) error {
+	options, err := json.Marshal(imp.Options)
+	if err != nil {
+		return fmt.Errorf("failed to serialize options: %w", err)
+	}
+	candidates, err := json.Marshal(imp.Candidates)
+	if err != nil {
+		return fmt.Errorf("failed to serialize candidates: %w", err)
+	}
+	results, err := json.Marshal(imp.Results)
+	if err != nil {
+		return fmt.Errorf("failed to serialize results: %w", err)
+	}
+
+	_, err = db.conn.Exec(
+		`INSERT INTO rec_impressions (request_id, user_id, surface, query, options, candidates, results, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		imp.RequestID, imp.UserID, imp.Surface, imp.Query,
+		string(options), string(candidates), string(results), imp.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// GetImpressionUserID returns who an impression was shown to, or "" if the
+// request ID is unknown (never logged or already pruned)
+func (db *DB) GetImpressionUserID(requestID string) (string, error) {
+	var userID string
+	err := db.conn.QueryRow(`SELECT user_id FROM rec_impressions WHERE request_id = ?`, requestID).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return userID, err
+}
+
+// CreateInteraction records a follow-up action against an impression
+func (db *DB) CreateInteraction(in *models.Interaction) error {
+	res, err := db.conn.Exec(
+		`INSERT INTO rec_interactions (request_id, user_id, media_id, action, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		in.RequestID, in.UserID, in.MediaID, in.Action, in.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	in.ID, err = res.LastInsertId()
+	return err
+}
+
+// PruneImpressions deletes impressions (and, by cascade, their
+// interactions) logged before the cutoff. Returns how many were removed.
+func (db *DB) PruneImpressions(before time.Time) (int64, error) {
+	res, err := db.conn.Exec(`DELETE FROM rec_impressions WHERE created_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ExportImpressions calls fn for every impression logged in [since, until),
+// oldest first, with its interactions attached
+func (db *DB) ExportImpressions(since, until time.Time, fn func(*models.Impression) error) error {
+	// Interactions for the window are small next to the impressions
+	// themselves; load them up front instead of querying per row
+	interactions := make(map[string][]models.Interaction)
+	irows, err := db.conn.Query(
+		`SELECT i.id, i.request_id, i.user_id, i.media_id, i.action, i.created_at
+		FROM rec_interactions i
+		JOIN rec_impressions r ON r.request_id = i.request_id
+		WHERE r.created_at >= ? AND r.created_at < ?
+		ORDER BY i.id`,
+		since.UTC(), until.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	for irows.Next() {
+		var in models.Interaction
+		if err := irows.Scan(&in.ID, &in.RequestID, &in.UserID, &in.MediaID, &in.Action, &in.CreatedAt); err != nil {
+			irows.Close()
+			return err
+		}
+		interactions[in.RequestID] = append(interactions[in.RequestID], in)
+	}
+	irows.Close()
+	if err := irows.Err(); err != nil {
+		return err
+	}
+
+	rows, err := db.conn.Query(
+		`SELECT request_id, user_id, surface, query, options, candidates, results, created_at
+		FROM rec_impressions
+		WHERE created_at >= ? AND created_at < ?
+		ORDER BY created_at`,
+		since.UTC(), until.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var imp models.Impression
+		var options, candidates, results string
+		if err := rows.Scan(&imp.RequestID, &imp.UserID, &imp.Surface, &imp.Query,
+			&options, &candidates, &results, &imp.CreatedAt); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(options), &imp.Options); err != nil {
+			return fmt.Errorf("failed to deserialize options: %w", err)
+		}
+		if err := json.Unmarshal([]byte(candidates), &imp.Candidates); err != nil {
+			return fmt.Errorf("failed to deserialize candidates: %w", err)
+		}
+		if err := json.Unmarshal([]byte(results), &imp.Results); err != nil {
+			return fmt.Errorf("failed to deserialize results: %w", err)
+		}
+		imp.Interactions = interactions[imp.RequestID]
+		if err := fn(&imp); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetShownCounts returns how many times each title appeared in the user's
+// logged results since the cutoff
+func (db *DB) GetShownCounts(userID string, since time.Time) (map[string]int, error) {
+	rows, err := db.conn.Query(
+		`SELECT results FROM rec_impressions WHERE user_id = ? AND created_at >= ?`,
+		userID, since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var raw string
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var items []models.ImpressionItem
+		if err := json.Unmarshal([]byte(raw), &items); err != nil {
+			return nil, fmt.Errorf("failed to deserialize results: %w", err)
+		}
+		for _, item := range items {
+			counts[item.MediaID]++
+		}
+	}
+	return counts, rows.Err()
+}
+
+// GetImpressionFeedback tallies, per title, how often it was shown in logged
+// results since the cutoff and how many of those showings drew a positive
+// interaction (anything but a dismissal). Both counts are aggregated in
+// SQLite, so no results list is decoded here.
+func (db *DB) GetImpressionFeedback(since time.Time) (map[string]models.ItemFeedback, error) {
+	// Interactions can name titles from the wider candidate pool; only
+	// titles we know were shown get engagement, capped at their showings
+	rows, err := db.conn.Query(
+		`WITH shown AS (
+			SELECT json_extract(item.value, '$.media_id') AS media_id, COUNT(*) AS n
+			FROM rec_impressions r, json_each(r.results) item
+			WHERE r.created_at >= ?
+			GROUP BY 1
+		), engaged AS (
+			SELECT i.media_id, COUNT(DISTINCT i.request_id) AS n
+			FROM rec_interactions i
+			JOIN rec_impressions r ON r.request_id = i.request_id
+			WHERE r.created_at >= ? AND i.action != ?
+			GROUP BY i.media_id
+		)
+		SELECT s.media_id, s.n, MIN(COALESCE(e.n, 0), s.n)
+		FROM shown s
+		LEFT JOIN engaged e ON e.media_id = s.media_id`,
+		since.UTC(), since.UTC(), models.ActionDismissed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := make(map[string]models.ItemFeedback)
+	for rows.Next() {
+		var mediaID string
+		var f models.ItemFeedback
+		if err := rows.Scan(&mediaID, &f.Shown, &f.Engaged); err != nil {
+			return nil, err
+		}
+		feedback[mediaID] = f
+	}
+	return feedback, rows.Err()
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// openMigrated opens a fully migrated database in a temporary directory
+func openMigrated(t *testing.T) *DB {
+	t.Helper()
+	db, err := New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func shownItems(ids ...string) []models.ImpressionItem {
+	items := make([]models.ImpressionItem, len(ids))
+	for i, id := range ids {
+		items[i] = models.ImpressionItem{MediaID: id, Rank: i + 1}
+	}
+	return items
+}
+
+func TestGetImpressionFeedback(t *testing.T) {
+	db := openMigrated(t)
+	now := time.Now()
+
+	for _, imp := range []models.Impression{
+		{RequestID: "r1", UserID: "u1", Surface: "search", Results: shownItems("a", "b"), Candidates: shownItems("a", "b", "c"), CreatedAt: now.Add(-time.Hour)},
+		{RequestID: "r2", UserID: "u2", Surface: "search", Results: shownItems("a"), CreatedAt: now.Add(-time.Hour)},
+		{RequestID: "old", UserID: "u1", Surface: "search", Results: shownItems("a", "b"), CreatedAt: now.Add(-48 * time.Hour)},
+	} {
+		if err := db.CreateImpression(&imp); err != nil {
+			t.Fatalf("CreateImpression %s: %v", imp.RequestID, err)
+		}
+	}
+	for _, in := range []models.Interaction{
+		// Two actions on one showing count once
+		{RequestID: "r1", UserID: "u1", MediaID: "a", Action: models.ActionExpanded},
+		{RequestID: "r1", UserID: "u1", MediaID: "a", Action: models.ActionClicked},
+		{RequestID: "r2", UserID: "u2", MediaID: "a", Action: models.ActionClicked},
+		// Dismissals are not engagement
+		{RequestID: "r1", UserID: "u1", MediaID: "b", Action: models.ActionDismissed},
+		// c was only a candidate, never shown
+		{RequestID: "r1", UserID: "u1", MediaID: "c", Action: models.ActionClicked},
+		// Outside the window
+		{RequestID: "old", UserID: "u1", MediaID: "b", Action: models.ActionClicked},
+	} {
+		in.CreatedAt = now
+		if err := db.CreateInteraction(&in); err != nil {
+			t.Fatalf("CreateInteraction: %v", err)
+		}
+	}
+
+	feedback, err := db.GetImpressionFeedback(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("GetImpressionFeedback: %v", err)
+	}
+	want := map[string]models.ItemFeedback{
+		"a": {Shown: 2, Engaged: 2},
+		"b": {Shown: 1, Engaged: 0},
+	}
+	if len(feedback) != len(want) {
+		t.Errorf("feedback = %v, want %v", feedback, want)
+	}
+	for id, w := range want {
+		if feedback[id] != w {
+			t.Errorf("%s feedback = %+v, want %+v", id, feedback[id], w)
+		}
+	}
+}
+
+func TestPruneImpressionsCascades(t *testing.T) {
+	db := openMigrated(t)
+	now := time.Now()
+	must(t, db.CreateImpression(&models.Impression{RequestID: "old", UserID: "u1", Surface: "search", Results: shownItems("a"), CreatedAt: now.Add(-2 * time.Hour)}))
+	must(t, db.CreateImpression(&models.Impression{RequestID: "new", UserID: "u1", Surface: "search", Results: shownItems("a"), CreatedAt: now}))
+	for _, id := range []string{"old", "new"} {
+		must(t, db.CreateInteraction(&models.Interaction{RequestID: id, UserID: "u1", MediaID: "a", Action: models.ActionClicked, CreatedAt: now}))
+	}
+
+	removed, err := db.PruneImpressions(now.Add(-time.Hour))
+	must(t, err)
+	if removed != 1 {
+		t.Errorf("pruned %d impressions, want 1", removed)
+	}
+	if owner, _ := db.GetImpressionUserID("old"); owner != "" {
+		t.Error("old impression survived pruning")
+	}
+	var left []string
+	rows, err := db.conn.Query(`SELECT request_id FROM rec_interactions`)
+	must(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		must(t, rows.Scan(&id))
+		left = append(left, id)
+	}
+	if len(left) != 1 || left[0] != "new" {
+		t.Errorf("interactions left = %v, want only the new one", left)
+	}
+}
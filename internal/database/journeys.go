@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Journey Operations
+// ============================================================================
+
+const journeyColumns = `j.id, j.user_id, j.slug, j.title, j.from_label, j.to_label, j.created_at,
+	(SELECT COUNT(*) FROM journey_steps js WHERE js.journey_id = j.id)`
+
+func scanJourney(row interface{ Scan(...interface{}) error }) (*models.Journey, error) {
+	var j models.Journey
+	if err := row.Scan(&j.ID, &j.UserID, &j.Slug, &j.Title, &j.FromLabel, &j.ToLabel,
+		&j.CreatedAt, &j.StepCount); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// CreateJourney saves a journey with its steps
+func (db *DB) CreateJourney(j *models.Journey) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`INSERT INTO journeys (id, user_id, slug, title, from_label, to_label, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		j.ID, j.UserID, j.Slug, j.Title, j.FromLabel, j.ToLabel, j.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO journey_steps (journey_id, position, media_id, note, step_similarity, progress)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, step := range j.Steps {
+		if _, err := stmt.Exec(j.ID, step.Position, step.MediaID, step.Note, step.StepSimilarity, step.Progress); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetJourney retrieves a journey (without steps) by ID
+func (db *DB) GetJourney(id string) (*models.Journey, error) {
+	j, err := scanJourney(db.conn.QueryRow(
+		`SELECT `+journeyColumns+` FROM journeys j WHERE j.id = ?`, id,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return j, err
+}
+
+// GetJourneyBySlug retrieves a journey (without steps) by its slug
+func (db *DB) GetJourneyBySlug(slug string) (*models.Journey, error) {
+	j, err := scanJourney(db.conn.QueryRow(
+		`SELECT `+journeyColumns+` FROM journeys j WHERE j.slug = ?`, slug,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return j, err
+}
+
+// ListJourneys returns a user's journeys, newest first
+func (db *DB) ListJourneys(userID string) ([]models.Journey, error) {
+	rows, err := db.conn.Query(
+		`SELECT `+journeyColumns+` FROM journeys j
+		WHERE j.user_id = ? ORDER BY j.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var journeys []models.Journey
+	for rows.Next() {
+		j, err := scanJourney(rows)
+		if err != nil {
+			return nil, err
+		}
+		journeys = append(journeys, *j)
+	}
+	return journeys, rows.Err()
+}
+
+// DeleteJourney removes a journey and its steps
+func (db *DB) DeleteJourney(id string) error {
+	_, err := db.conn.Exec(`DELETE FROM journeys WHERE id = ?`, id)
+	return err
+}
+
+// GetJourneySteps returns a journey's steps with media details, in order
+func (db *DB) GetJourneySteps(journeyID string) ([]models.JourneyStep, error) {
+	rows, err := db.conn.Query(
+		`SELECT js.position, js.media_id, js.note, js.step_similarity, js.progress,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM journey_steps js
+		JOIN media m ON js.media_id = m.id
+		WHERE js.journey_id = ?
+		ORDER BY js.position`,
+		journeyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var steps []models.JourneyStep
+	for rows.Next() {
+		var step models.JourneyStep
+		var m models.Media
+		if err := rows.Scan(
+			&step.Position, &step.MediaID, &step.Note, &step.StepSimilarity, &step.Progress,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		step.Media = &m
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Similar-To Judgment Operations
+// ============================================================================
+
+// GetSimilarMentions returns every catalog title mentioned in a similar_to
+// thread whose reference show matches a catalog title (case-insensitive).
+// The reference itself is skipped when a thread mentions it.
+func (db *DB) GetSimilarMentions() ([]models.SimilarMention, error) {
+	rows, err := db.conn.Query(
+		`SELECT ref.id, rm.media_id, t.id, t.score
+		FROM reddit_threads t
+		JOIN media ref ON ref.title = t.reference_show COLLATE NOCASE
+		JOIN reddit_mentions rm ON rm.thread_id = t.id
+		WHERE t.thread_type = 'similar_to' AND rm.media_id != ref.id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mentions []models.SimilarMention
+	for rows.Next() {
+		var m models.SimilarMention
+		if err := rows.Scan(&m.ReferenceID, &m.MediaID, &m.ThreadID, &m.Score); err != nil {
+			return nil, err
+		}
+		mentions = append(mentions, m)
+	}
+	return mentions, rows.Err()
+}
+
+// ReplaceSimilarJudgments swaps the whole judgment table for a fresh set
+func (db *DB) ReplaceSimilarJudgments(judgments []models.SimilarJudgment) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM similar_judgments`); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(
+		`INSERT INTO similar_judgments (reference_id, media_id, weight, thread_count, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, j := range judgments {
+		if _, err := stmt.Exec(j.ReferenceID, j.MediaID, j.Weight, j.ThreadCount, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSimilarJudgments returns all judgments grouped by reference media,
+// strongest first within each reference
+func (db *DB) GetSimilarJudgments() (map[string][]models.SimilarJudgment, error) {
+	rows, err := db.conn.Query(
+		`SELECT reference_id, media_id, weight, thread_count, updated_at
+		FROM similar_judgments
+		ORDER BY reference_id, weight DESC, media_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	judgments := make(map[string][]models.SimilarJudgment)
+	for rows.Next() {
+		var j models.SimilarJudgment
+		if err := rows.Scan(&j.ReferenceID, &j.MediaID, &j.Weight, &j.ThreadCount, &j.UpdatedAt); err != nil {
+			return nil, err
+		}
+		judgments[j.ReferenceID] = append(judgments[j.ReferenceID], j)
+	}
+	return judgments, rows.Err()
+}
+
+// CreateSimilarQualityRun records one scoring of the similar-titles surface
+func (db *DB) CreateSimilarQualityRun(run *models.SimilarQualityRun) error {
+	res, err := db.conn.Exec(
+		`INSERT INTO similar_quality_runs
+		(k, reference_count, judgment_count, ndcg, recall, mrr, embedding_model, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.K, run.ReferenceCount, run.JudgmentCount, run.NDCG, run.Recall, run.MRR,
+		run.EmbeddingModel, run.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+	run.ID, err = res.LastInsertId()
+	return err
+}
+
+// GetSimilarQualityRuns returns the most recent quality runs, newest first
+func (db *DB) GetSimilarQualityRuns(limit int) ([]models.SimilarQualityRun, error) {
+	rows, err := db.conn.Query(
+		`SELECT id, k, reference_count, judgment_count, ndcg, recall, mrr, embedding_model, created_at
+		FROM similar_quality_runs
+		ORDER BY id DESC
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []models.SimilarQualityRun
+	for rows.Next() {
+		var r models.SimilarQualityRun
+		if err := rows.Scan(&r.ID, &r.K, &r.ReferenceCount, &r.JudgmentCount,
+			&r.NDCG, &r.Recall, &r.MRR, &r.EmbeddingModel, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
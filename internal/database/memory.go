@@ -0,0 +1,628 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// In-Memory Store
+// ============================================================================
+
+// MemoryStore implements Store and FranchiseRepository in memory, for unit
+// tests and tools that should not touch a database file. It enforces the same keys, foreign keys
+// and CHECK constraints as the SQLite schema, and hands out copies so
+// callers cannot change stored rows behind its back.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	users      map[string]models.User
+	media      map[string]models.Media
+	seen       map[string]map[string]models.SeenMedia // User ID -> media ID -> row
+	nextSeenID int64
+	embeddings map[string][]float32
+	threads    map[string]models.RedditThread
+	mentions   map[string]map[string]models.RedditMention // Media ID -> thread ID -> mention
+	nextMentID int64
+	franchises map[string]models.Franchise
+	entries    map[string]franchiseEntry // Media ID -> its franchise entry
+}
+
+// franchiseEntry is a franchise_entries row
+type franchiseEntry struct {
+	franchiseID string
+	entry       models.FranchiseEntry
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:      make(map[string]models.User),
+		media:      make(map[string]models.Media),
+		seen:       make(map[string]map[string]models.SeenMedia),
+		embeddings: make(map[string][]float32),
+		threads:    make(map[string]models.RedditThread),
+		mentions:   make(map[string]map[string]models.RedditMention),
+		franchises: make(map[string]models.Franchise),
+		entries:    make(map[string]franchiseEntry),
+	}
+}
+
+// CreateUser adds a user; IDs and usernames are unique
+func (s *MemoryStore) CreateUser(user *models.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user.ID]; ok {
+		return fmt.Errorf("user %s already exists", user.ID)
+	}
+	for _, u := range s.users {
+		if u.Username == user.Username {
+			return fmt.Errorf("username %s is taken", user.Username)
+		}
+	}
+	u := *user
+	u.CreatedAt = time.Now()
+	s.users[u.ID] = u
+	return nil
+}
+
+// GetUser returns a user, or nil
+func (s *MemoryStore) GetUser(id string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return &u, nil
+}
+
+// CreateMedia adds a media entry
+func (s *MemoryStore) CreateMedia(media *models.Media) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.media[media.ID]; ok {
+		return fmt.Errorf("media %s already exists", media.ID)
+	}
+	switch media.MediaType {
+	case "movie", "tv", "anime", "documentary":
+	default:
+		return fmt.Errorf("invalid media type %q", media.MediaType)
+	}
+	m := *media
+	m.CreatedAt = time.Now()
+	m.UpdatedAt = m.CreatedAt
+	s.media[m.ID] = m
+	return nil
+}
+
+// GetMedia returns a media entry, or nil
+func (s *MemoryStore) GetMedia(id string) (*models.Media, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.media[id]
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+// GetMediaByTitle finds media by case-insensitive exact title, or nil
+func (s *MemoryStore) GetMediaByTitle(title string) (*models.Media, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range s.mediaIDs() {
+		if m := s.media[id]; strings.EqualFold(m.Title, title) {
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateQualityScore adds boost to a media entry's quality score
+func (s *MemoryStore) UpdateQualityScore(mediaID string, boost float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.media[mediaID]; ok {
+		m.QualityScore += boost
+		m.UpdatedAt = time.Now()
+		s.media[mediaID] = m
+	}
+	return nil
+}
+
+// UpdateVibeProfile replaces a media entry's vibe profile
+func (s *MemoryStore) UpdateVibeProfile(mediaID, vibeProfile string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok := s.media[mediaID]; ok {
+		m.VibeProfile = vibeProfile
+		m.UpdatedAt = time.Now()
+		s.media[mediaID] = m
+	}
+	return nil
+}
+
+// GetAllVibeProfiles returns every non-empty vibe profile keyed by media ID
+func (s *MemoryStore) GetAllVibeProfiles() (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := make(map[string]string)
+	for id, m := range s.media {
+		if m.VibeProfile != "" {
+			profiles[id] = m.VibeProfile
+		}
+	}
+	return profiles, nil
+}
+
+// CountMedia returns how many media entries there are
+func (s *MemoryStore) CountMedia() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.media), nil
+}
+
+// mediaIDs returns every media ID in order, so lookups that could match
+// several entries are deterministic. Callers hold the lock.
+func (s *MemoryStore) mediaIDs() []string {
+	ids := make([]string, 0, len(s.media))
+	for id := range s.media {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// MarkAsSeen adds a title to the user's seen list as completed, keeping the
+// progress and rewatch count of a title already tracked
+func (s *MemoryStore) MarkAsSeen(seen *models.SeenMedia) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[seen.UserID]; !ok {
+		return fmt.Errorf("no user %s", seen.UserID)
+	}
+	if _, ok := s.media[seen.MediaID]; !ok {
+		return fmt.Errorf("no media %s", seen.MediaID)
+	}
+	if seen.Rating != nil && (*seen.Rating < 1 || *seen.Rating > 10) {
+		return fmt.Errorf("rating %v is outside 1-10", *seen.Rating)
+	}
+
+	now := time.Now()
+	byMedia := s.seen[seen.UserID]
+	if byMedia == nil {
+		byMedia = make(map[string]models.SeenMedia)
+		s.seen[seen.UserID] = byMedia
+	}
+	row, ok := byMedia[seen.MediaID]
+	if !ok {
+		s.nextSeenID++
+		row = models.SeenMedia{ID: s.nextSeenID, UserID: seen.UserID, MediaID: seen.MediaID, CreatedAt: now}
+	}
+	if seen.Rating != nil {
+		rating := *seen.Rating
+		row.Rating = &rating
+	} else {
+		row.Rating = nil
+	}
+	row.WatchedAt = now
+	row.Status = models.WatchCompleted
+	row.UpdatedAt = now
+	byMedia[seen.MediaID] = row
+	return nil
+}
+
+// RemoveSeen drops a title from the user's seen list. Returns false if it
+// was not there.
+func (s *MemoryStore) RemoveSeen(userID, mediaID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.seen[userID][mediaID]; !ok {
+		return false, nil
+	}
+	delete(s.seen[userID], mediaID)
+	return true, nil
+}
+
+// GetSeenMedia returns the user's seen list, most recently watched first
+func (s *MemoryStore) GetSeenMedia(userID string) ([]models.SeenMedia, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.seenRows(userID), nil
+}
+
+// GetSeenMediaWithDetails returns the media the user has seen, most
+// recently watched first
+func (s *MemoryStore) GetSeenMediaWithDetails(userID string) ([]models.Media, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var media []models.Media
+	for _, row := range s.seenRows(userID) {
+		media = append(media, s.media[row.MediaID])
+	}
+	return media, nil
+}
+
+// IsMediaSeen reports whether the user has seen a title
+func (s *MemoryStore) IsMediaSeen(userID, mediaID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.seen[userID][mediaID]
+	return ok, nil
+}
+
+// GetSeenMediaIDs returns the IDs of every title the user has seen
+func (s *MemoryStore) GetSeenMediaIDs(userID string) (map[string]bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for id := range s.seen[userID] {
+		seen[id] = true
+	}
+	return seen, nil
+}
+
+// seenRows returns copies of the user's seen rows, most recently watched
+// first. Callers hold the lock.
+func (s *MemoryStore) seenRows(userID string) []models.SeenMedia {
+	var rows []models.SeenMedia
+	for _, row := range s.seen[userID] {
+		if row.Rating != nil {
+			rating := *row.Rating
+			row.Rating = &rating
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].WatchedAt.Equal(rows[j].WatchedAt) {
+			return rows[i].WatchedAt.After(rows[j].WatchedAt)
+		}
+		return rows[i].ID > rows[j].ID
+	})
+	return rows
+}
+
+// StoreEmbedding saves or replaces a media entry's embedding
+func (s *MemoryStore) StoreEmbedding(mediaID string, embedding []float32, model string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.media[mediaID]; !ok {
+		return fmt.Errorf("no media %s", mediaID)
+	}
+	s.embeddings[mediaID] = append([]float32(nil), embedding...)
+	return nil
+}
+
+// GetEmbedding returns a media entry's embedding, or nil
+func (s *MemoryStore) GetEmbedding(mediaID string) ([]float32, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	embedding, ok := s.embeddings[mediaID]
+	if !ok {
+		return nil, nil
+	}
+	return append([]float32(nil), embedding...), nil
+}
+
+// GetAllEmbeddings returns every embedding keyed by media ID
+func (s *MemoryStore) GetAllEmbeddings() (map[string][]float32, error) {
+	return s.GetAllEmbeddingsExcludingSeen("")
+}
+
+// GetAllEmbeddingsExcludingSeen returns the embeddings of every title the
+// user has not seen
+func (s *MemoryStore) GetAllEmbeddingsExcludingSeen(userID string) (map[string][]float32, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	embeddings := make(map[string][]float32)
+	for id, embedding := range s.embeddings {
+		if _, seen := s.seen[userID][id]; !seen {
+			embeddings[id] = append([]float32(nil), embedding...)
+		}
+	}
+	return embeddings, nil
+}
+
+// GetVibeProfilesWithoutEmbedding returns the non-empty vibe profiles of
+// media entries that have no embedding yet
+func (s *MemoryStore) GetVibeProfilesWithoutEmbedding() (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profiles := make(map[string]string)
+	for id, m := range s.media {
+		if _, ok := s.embeddings[id]; !ok && m.VibeProfile != "" {
+			profiles[id] = m.VibeProfile
+		}
+	}
+	return profiles, nil
+}
+
+// CountEmbeddings returns how many media entries have an embedding
+func (s *MemoryStore) CountEmbeddings() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.embeddings), nil
+}
+
+// CreateRedditThread stores a thread, or refreshes the score and comment
+// count of one stored before. The first posted_at seen is kept.
+func (s *MemoryStore) CreateRedditThread(thread *models.RedditThread) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch thread.ThreadType {
+	case "similar_to", "hidden_gem", "quality_discussion", "other":
+	default:
+		return fmt.Errorf("invalid thread type %q", thread.ThreadType)
+	}
+
+	if existing, ok := s.threads[thread.ID]; ok {
+		existing.Score = thread.Score
+		existing.NumComments = thread.NumComments
+		if existing.PostedAt.IsZero() {
+			existing.PostedAt = thread.PostedAt.UTC()
+		}
+		s.threads[thread.ID] = existing
+		return nil
+	}
+	t := *thread
+	if !t.PostedAt.IsZero() {
+		t.PostedAt = t.PostedAt.UTC()
+	}
+	s.threads[t.ID] = t
+	return nil
+}
+
+// CreateRedditMention records that a thread mentions a title; recording it
+// again is a no-op
+func (s *MemoryStore) CreateRedditMention(mention *models.RedditMention) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.threads[mention.ThreadID]; !ok {
+		return fmt.Errorf("no thread %s", mention.ThreadID)
+	}
+	if _, ok := s.media[mention.MediaID]; !ok {
+		return fmt.Errorf("no media %s", mention.MediaID)
+	}
+
+	byThread := s.mentions[mention.MediaID]
+	if byThread == nil {
+		byThread = make(map[string]models.RedditMention)
+		s.mentions[mention.MediaID] = byThread
+	}
+	if _, ok := byThread[mention.ThreadID]; ok {
+		return nil
+	}
+	s.nextMentID++
+	m := *mention
+	m.ID = s.nextMentID
+	byThread[m.ThreadID] = m
+	return nil
+}
+
+// GetMentionCountForMedia returns how many threads mention a title
+func (s *MemoryStore) GetMentionCountForMedia(mediaID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.mentions[mediaID]), nil
+}
+
+// CountRedditThreads returns how many threads are stored
+func (s *MemoryStore) CountRedditThreads() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.threads), nil
+}
+
+// CountRedditMentions returns how many mentions are stored
+func (s *MemoryStore) CountRedditMentions() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, byThread := range s.mentions {
+		count += len(byThread)
+	}
+	return count, nil
+}
+
+// GetThreadCountsBySubreddit returns how many threads are stored per
+// subreddit, in subreddit order
+func (s *MemoryStore) GetThreadCountsBySubreddit() ([]models.SubredditCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bySubreddit := make(map[string]int)
+	for _, t := range s.threads {
+		bySubreddit[t.Subreddit]++
+	}
+	var counts []models.SubredditCount
+	for sub, n := range bySubreddit {
+		counts = append(counts, models.SubredditCount{Subreddit: sub, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Subreddit < counts[j].Subreddit })
+	return counts, nil
+}
+
+// ReplaceFranchise stores the franchise with exactly the given entries,
+// dropping any it had before. Titles in another franchise move to this one.
+func (s *MemoryStore) ReplaceFranchise(f *models.Franchise) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch f.Source {
+	case models.FranchiseSourceTMDB, models.FranchiseSourceManual:
+	default:
+		return fmt.Errorf("invalid franchise source %q", f.Source)
+	}
+	for _, e := range f.Entries {
+		if _, ok := s.media[e.MediaID]; !ok {
+			return fmt.Errorf("no media %s", e.MediaID)
+		}
+		switch e.Role {
+		case models.FranchiseRoleMain, models.FranchiseRoleSpinoff:
+		default:
+			return fmt.Errorf("invalid franchise role %q", e.Role)
+		}
+	}
+
+	stored, ok := s.franchises[f.ID]
+	if !ok {
+		stored = models.Franchise{ID: f.ID, Source: f.Source, CreatedAt: time.Now()}
+	}
+	stored.Name = f.Name
+	stored.ExternalID = f.ExternalID
+	s.franchises[f.ID] = stored
+
+	for id, e := range s.entries {
+		if e.franchiseID == f.ID {
+			delete(s.entries, id)
+		}
+	}
+	for _, e := range f.Entries {
+		s.entries[e.MediaID] = franchiseEntry{
+			franchiseID: f.ID,
+			entry:       models.FranchiseEntry{MediaID: e.MediaID, WatchOrder: e.WatchOrder, Role: e.Role},
+		}
+	}
+	return nil
+}
+
+// GetFranchise returns a franchise without its entries, or nil
+func (s *MemoryStore) GetFranchise(id string) (*models.Franchise, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.franchises[id]
+	if !ok {
+		return nil, nil
+	}
+	f.EntryCount = len(s.franchiseEntries(id))
+	return &f, nil
+}
+
+// GetMediaFranchiseID returns the ID of the franchise a title belongs to,
+// or "" if it belongs to none
+func (s *MemoryStore) GetMediaFranchiseID(mediaID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries[mediaID].franchiseID, nil
+}
+
+// ListFranchises returns every franchise without entries, by name
+func (s *MemoryStore) ListFranchises() ([]models.Franchise, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var franchises []models.Franchise
+	for id, f := range s.franchises {
+		f.EntryCount = len(s.franchiseEntries(id))
+		franchises = append(franchises, f)
+	}
+	sort.Slice(franchises, func(i, j int) bool {
+		if franchises[i].Name != franchises[j].Name {
+			return franchises[i].Name < franchises[j].Name
+		}
+		return franchises[i].ID < franchises[j].ID
+	})
+	return franchises, nil
+}
+
+// DeleteFranchise removes a franchise and unlinks its titles. Returns false
+// if there was no such franchise.
+func (s *MemoryStore) DeleteFranchise(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.franchises[id]; !ok {
+		return false, nil
+	}
+	delete(s.franchises, id)
+	for mediaID, e := range s.entries {
+		if e.franchiseID == id {
+			delete(s.entries, mediaID)
+		}
+	}
+	return true, nil
+}
+
+// GetFranchiseEntries returns a franchise's titles with media details, main
+// entries first, each in watch order
+func (s *MemoryStore) GetFranchiseEntries(franchiseID string) ([]models.FranchiseEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.franchiseEntries(franchiseID)
+	for i := range entries {
+		m := s.media[entries[i].MediaID]
+		entries[i].Media = &m
+	}
+	return entries, nil
+}
+
+// GetFranchisesOf returns the franchises the given titles belong to, keyed
+// by franchise ID, each with all of its entries (without media details),
+// main entries first, each in watch order
+func (s *MemoryStore) GetFranchisesOf(mediaIDs []string) (map[string]*models.Franchise, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	franchises := make(map[string]*models.Franchise)
+	for _, mediaID := range mediaIDs {
+		e, ok := s.entries[mediaID]
+		if !ok || franchises[e.franchiseID] != nil {
+			continue
+		}
+		stored := s.franchises[e.franchiseID]
+		f := &models.Franchise{ID: stored.ID, Name: stored.Name, Source: stored.Source}
+		f.Entries = s.franchiseEntries(f.ID)
+		f.EntryCount = len(f.Entries)
+		franchises[f.ID] = f
+	}
+	return franchises, nil
+}
+
+// franchiseEntries returns copies of a franchise's entries, main entries
+// first, each in watch order. Callers hold the lock.
+func (s *MemoryStore) franchiseEntries(franchiseID string) []models.FranchiseEntry {
+	var entries []models.FranchiseEntry
+	for _, e := range s.entries {
+		if e.franchiseID == franchiseID {
+			entries = append(entries, e.entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.Role == models.FranchiseRoleSpinoff) != (b.Role == models.FranchiseRoleSpinoff) {
+			return b.Role == models.FranchiseRoleSpinoff
+		}
+		if a.WatchOrder != b.WatchOrder {
+			return a.WatchOrder < b.WatchOrder
+		}
+		return a.MediaID < b.MediaID
+	})
+	return entries
+}
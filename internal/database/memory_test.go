@@ -0,0 +1,294 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+// parityStore is what MemoryStore and DB both implement
+type parityStore interface {
+	Store
+	FranchiseRepository
+}
+
+// exercise runs the same calls against a store and describes every result
+// in a line of text, leaving out timestamps and generated IDs
+func exercise(t *testing.T, s parityStore) []string {
+	t.Helper()
+	var out []string
+	record := func(format string, args ...interface{}) {
+		out = append(out, fmt.Sprintf(format, args...))
+	}
+	rating := func(r float64) *float64 { return &r }
+
+	// Users
+	for _, u := range []models.User{{ID: "u1", Username: "ana"}, {ID: "u2", Username: "ben"}, {ID: "u3", Username: "ana"}, {ID: "u1", Username: "cat"}} {
+		record("CreateUser(%s, %s) failed=%v", u.ID, u.Username, s.CreateUser(&u) != nil)
+	}
+	for _, id := range []string{"u1", "nobody"} {
+		u, err := s.GetUser(id)
+		record("GetUser(%s) = %v err=%v", id, describeUser(u), err)
+	}
+
+	// Media
+	media := []models.Media{
+		{ID: "m1", Title: "Arrival", MediaType: "movie", Year: 2016, VibeProfile: "quiet awe"},
+		{ID: "m2", Title: "Cowboy Bebop", MediaType: "anime", Year: 1998, VibeProfile: "jazz noir"},
+		{ID: "m3", Title: "Severance", MediaType: "tv", Year: 2022, VibeProfile: "corporate dread"},
+		{ID: "m4", Title: "Cowboy Bebop: The Movie", MediaType: "anime", Year: 2001, VibeProfile: "jazz noir"},
+		{ID: "m5", Title: "Untitled", MediaType: "movie", VibeProfile: ""},
+		{ID: "m6", Title: "Podcast", MediaType: "podcast", VibeProfile: "talk"},
+		{ID: "m1", Title: "Arrival again", MediaType: "movie", VibeProfile: "dup"},
+	}
+	for _, m := range media {
+		record("CreateMedia(%s) failed=%v", m.ID, s.CreateMedia(&m) != nil)
+	}
+	must(t, s.UpdateQualityScore("m1", 0.5))
+	must(t, s.UpdateQualityScore("m1", 0.25))
+	must(t, s.UpdateVibeProfile("m3", "fluorescent corporate dread"))
+	for _, id := range []string{"m1", "m3", "m6"} {
+		m, err := s.GetMedia(id)
+		record("GetMedia(%s) = %s err=%v", id, describeMedia(m), err)
+	}
+	for _, title := range []string{"arrival", "COWBOY BEBOP", "Cowboy"} {
+		m, err := s.GetMediaByTitle(title)
+		record("GetMediaByTitle(%s) = %s err=%v", title, describeMedia(m), err)
+	}
+	profiles, err := s.GetAllVibeProfiles()
+	record("GetAllVibeProfiles = %v err=%v", profiles, err)
+	n, err := s.CountMedia()
+	record("CountMedia = %d err=%v", n, err)
+
+	// Seen
+	seen := []models.SeenMedia{
+		{UserID: "u1", MediaID: "m1", Rating: rating(9)},
+		{UserID: "u1", MediaID: "m2"},
+		{UserID: "u1", MediaID: "m1", Rating: rating(7)},
+		{UserID: "u2", MediaID: "m3", Rating: rating(2)},
+		{UserID: "u1", MediaID: "m3", Rating: rating(11)},
+		{UserID: "u1", MediaID: "missing"},
+		{UserID: "nobody", MediaID: "m1"},
+	}
+	for _, sm := range seen {
+		record("MarkAsSeen(%s, %s) failed=%v", sm.UserID, sm.MediaID, s.MarkAsSeen(&sm) != nil)
+	}
+	for _, id := range []string{"m2", "m2", "m4"} {
+		removed, err := s.RemoveSeen("u1", id)
+		record("RemoveSeen(u1, %s) = %v err=%v", id, removed, err)
+	}
+	for _, u := range []string{"u1", "u2", "nobody"} {
+		rows, err := s.GetSeenMedia(u)
+		var lines []string
+		for _, r := range rows {
+			lines = append(lines, fmt.Sprintf("%s:%s:%s", r.MediaID, describeRating(r.Rating), r.Status))
+		}
+		sort.Strings(lines)
+		record("GetSeenMedia(%s) = %v err=%v", u, lines, err)
+
+		details, err := s.GetSeenMediaWithDetails(u)
+		var titles []string
+		for _, m := range details {
+			titles = append(titles, m.Title)
+		}
+		sort.Strings(titles)
+		record("GetSeenMediaWithDetails(%s) = %v err=%v", u, titles, err)
+
+		ids, err := s.GetSeenMediaIDs(u)
+		record("GetSeenMediaIDs(%s) = %v err=%v", u, ids, err)
+	}
+	for _, id := range []string{"m1", "m2"} {
+		ok, err := s.IsMediaSeen("u1", id)
+		record("IsMediaSeen(u1, %s) = %v err=%v", id, ok, err)
+	}
+
+	// Embeddings
+	record("StoreEmbedding(m1) failed=%v", s.StoreEmbedding("m1", []float32{1, 0}, "test") != nil)
+	record("StoreEmbedding(m2) failed=%v", s.StoreEmbedding("m2", []float32{0, 1}, "test") != nil)
+	record("StoreEmbedding(m2 again) failed=%v", s.StoreEmbedding("m2", []float32{0.5, 0.5}, "test") != nil)
+	record("StoreEmbedding(missing) failed=%v", s.StoreEmbedding("missing", []float32{1}, "test") != nil)
+	for _, id := range []string{"m2", "m3"} {
+		e, err := s.GetEmbedding(id)
+		record("GetEmbedding(%s) = %v nil=%v err=%v", id, e, e == nil, err)
+	}
+	all, err := s.GetAllEmbeddings()
+	record("GetAllEmbeddings = %v err=%v", all, err)
+	unseen, err := s.GetAllEmbeddingsExcludingSeen("u1")
+	record("GetAllEmbeddingsExcludingSeen(u1) = %v err=%v", unseen, err)
+	missing, err := s.GetVibeProfilesWithoutEmbedding()
+	record("GetVibeProfilesWithoutEmbedding = %v err=%v", missing, err)
+	n, err = s.CountEmbeddings()
+	record("CountEmbeddings = %d err=%v", n, err)
+
+	// Reddit
+	threads := []models.RedditThread{
+		{ID: "t1", Subreddit: "animesuggest", Title: "Like Bebop?", ThreadType: "similar_to", Score: 10},
+		{ID: "t2", Subreddit: "MovieSuggestions", Title: "Gems", ThreadType: "hidden_gem", Score: 3},
+		{ID: "t1", Subreddit: "animesuggest", Title: "Like Bebop?", ThreadType: "similar_to", Score: 25, NumComments: 4},
+		{ID: "t3", Subreddit: "animesuggest", Title: "Bad", ThreadType: "rant"},
+	}
+	for _, th := range threads {
+		record("CreateRedditThread(%s) failed=%v", th.ID, s.CreateRedditThread(&th) != nil)
+	}
+	mentions := []models.RedditMention{
+		{ThreadID: "t1", MediaID: "m2", QualityBoost: 0.3},
+		{ThreadID: "t1", MediaID: "m2", QualityBoost: 0.3},
+		{ThreadID: "t2", MediaID: "m2"},
+		{ThreadID: "t2", MediaID: "m1"},
+		{ThreadID: "t9", MediaID: "m1"},
+		{ThreadID: "t1", MediaID: "missing"},
+	}
+	for _, m := range mentions {
+		record("CreateRedditMention(%s, %s) failed=%v", m.ThreadID, m.MediaID, s.CreateRedditMention(&m) != nil)
+	}
+	for _, id := range []string{"m1", "m2", "m3"} {
+		n, err := s.GetMentionCountForMedia(id)
+		record("GetMentionCountForMedia(%s) = %d err=%v", id, n, err)
+	}
+	n, err = s.CountRedditThreads()
+	record("CountRedditThreads = %d err=%v", n, err)
+	n, err = s.CountRedditMentions()
+	record("CountRedditMentions = %d err=%v", n, err)
+	counts, err := s.GetThreadCountsBySubreddit()
+	record("GetThreadCountsBySubreddit = %v err=%v", counts, err)
+
+	// Franchises
+	bebop := &models.Franchise{ID: "bebop", Name: "Cowboy Bebop", Source: models.FranchiseSourceManual, Entries: []models.FranchiseEntry{
+		{MediaID: "m4", WatchOrder: 2, Role: models.FranchiseRoleSpinoff},
+		{MediaID: "m2", WatchOrder: 1, Role: models.FranchiseRoleMain},
+		{MediaID: "m1", WatchOrder: 3, Role: models.FranchiseRoleMain},
+	}}
+	record("ReplaceFranchise(bebop) failed=%v", s.ReplaceFranchise(bebop) != nil)
+	work := &models.Franchise{ID: "work", Name: "Work", Source: models.FranchiseSourceTMDB, ExternalID: "tmdb:1", Entries: []models.FranchiseEntry{
+		{MediaID: "m3", WatchOrder: 1, Role: models.FranchiseRoleMain},
+		{MediaID: "m1", WatchOrder: 2, Role: models.FranchiseRoleMain},
+	}}
+	record("ReplaceFranchise(work) failed=%v", s.ReplaceFranchise(work) != nil)
+	bad := &models.Franchise{ID: "bad", Name: "Bad", Source: models.FranchiseSourceManual, Entries: []models.FranchiseEntry{
+		{MediaID: "missing", WatchOrder: 1, Role: models.FranchiseRoleMain},
+	}}
+	record("ReplaceFranchise(bad) failed=%v", s.ReplaceFranchise(bad) != nil)
+
+	for _, id := range []string{"bebop", "work", "none"} {
+		f, err := s.GetFranchise(id)
+		record("GetFranchise(%s) = %s err=%v", id, describeFranchise(f), err)
+		entries, err := s.GetFranchiseEntries(id)
+		var lines []string
+		for _, e := range entries {
+			lines = append(lines, fmt.Sprintf("%s:%d:%s:%s", e.MediaID, e.WatchOrder, e.Role, e.Media.Title))
+		}
+		record("GetFranchiseEntries(%s) = %v err=%v", id, lines, err)
+	}
+	for _, id := range []string{"m1", "m2", "m5"} {
+		fid, err := s.GetMediaFranchiseID(id)
+		record("GetMediaFranchiseID(%s) = %q err=%v", id, fid, err)
+	}
+	of, err := s.GetFranchisesOf([]string{"m1", "m4", "m5"})
+	var ofIDs []string
+	for id, f := range of {
+		ofIDs = append(ofIDs, id+"="+describeFranchise(f))
+	}
+	sort.Strings(ofIDs)
+	record("GetFranchisesOf = %v err=%v", ofIDs, err)
+	list, err := s.ListFranchises()
+	var listed []string
+	for i := range list {
+		listed = append(listed, describeFranchise(&list[i]))
+	}
+	record("ListFranchises = %v err=%v", listed, err)
+	for _, id := range []string{"bebop", "bebop"} {
+		deleted, err := s.DeleteFranchise(id)
+		record("DeleteFranchise(%s) = %v err=%v", id, deleted, err)
+	}
+	fid, err := s.GetMediaFranchiseID("m2")
+	record("GetMediaFranchiseID(m2) after delete = %q err=%v", fid, err)
+	return out
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func describeUser(u *models.User) string {
+	if u == nil {
+		return "nil"
+	}
+	return u.ID + "/" + u.Username
+}
+
+func describeMedia(m *models.Media) string {
+	if m == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%s %q %s %d %q q=%.2f", m.ID, m.Title, m.MediaType, m.Year, m.VibeProfile, m.QualityScore)
+}
+
+func describeRating(r *float64) string {
+	if r == nil {
+		return "-"
+	}
+	return fmt.Sprint(*r)
+}
+
+func describeFranchise(f *models.Franchise) string {
+	if f == nil {
+		return "nil"
+	}
+	var entries []string
+	for _, e := range f.Entries {
+		entries = append(entries, fmt.Sprintf("%s:%d:%s", e.MediaID, e.WatchOrder, e.Role))
+	}
+	return fmt.Sprintf("%s %q %s %q count=%d [%s]", f.ID, f.Name, f.Source, f.ExternalID, f.EntryCount, strings.Join(entries, " "))
+}
+
+func TestMemoryStoreMatchesSQLite(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "parity.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	want := exercise(t, db)
+	got := exercise(t, NewMemoryStore())
+	if len(got) != len(want) {
+		t.Fatalf("MemoryStore recorded %d results, SQLite %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d differs\n  sqlite: %s\n  memory: %s", i, want[i], got[i])
+		}
+	}
+}
+
+func TestMemoryStoreReturnsCopies(t *testing.T) {
+	s := NewMemoryStore()
+	must(t, s.CreateUser(&models.User{ID: "u1", Username: "ana"}))
+	must(t, s.CreateMedia(&models.Media{ID: "m1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}))
+	r := 8.0
+	must(t, s.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "m1", Rating: &r}))
+	must(t, s.StoreEmbedding("m1", []float32{1, 0}, "test"))
+
+	m, _ := s.GetMedia("m1")
+	m.Title = "changed"
+	rows, _ := s.GetSeenMedia("u1")
+	*rows[0].Rating = 1
+	e, _ := s.GetEmbedding("m1")
+	e[0] = 0
+
+	if m, _ := s.GetMedia("m1"); m.Title != "Arrival" {
+		t.Errorf("media title = %q, changed through a returned copy", m.Title)
+	}
+	if rows, _ := s.GetSeenMedia("u1"); *rows[0].Rating != 8 {
+		t.Errorf("rating = %v, changed through a returned copy", *rows[0].Rating)
+	}
+	if e, _ := s.GetEmbedding("m1"); e[0] != 1 {
+		t.Errorf("embedding = %v, changed through a returned copy", e)
+	}
+}
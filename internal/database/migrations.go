@@ -0,0 +1,979 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// Schema Migrations
+// ============================================================================
+
+// Migration is one numbered schema change. Released migrations are never
+// edited: the checksum of Up is recorded when a migration is applied, and a
+// database whose applied migrations no longer match refuses to migrate.
+// Change the schema by appending a migration with the next version.
+type Migration struct {
+	Version int
+	Name    string
+	// Up applies the change and may hold several statements. It is empty
+	// when Apply does all the work.
+	Up string
+	// Apply runs after Up in the same transaction, for changes SQL alone
+	// cannot express, such as backfills computed in Go. It is not part of
+	// the checksum.
+	Apply func(tx *sql.Tx) error
+	// Down undoes Up; a migration without one cannot be rolled back
+	Down string
+	// RebuildsTables turns foreign key enforcement off while the migration
+	// runs and checks every foreign key before committing. SQLite can only
+	// change a column type or CHECK constraint by creating a new table,
+	// copying the rows, dropping the old table and renaming the new one,
+	// and dropping a parent table with enforcement on cascades into its
+	// children.
+	RebuildsTables bool
+}
+
+// migrations lists every schema change in version order
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline",
+		Up:      strings.Join(baselineSchema, ";\n"),
+	},
+	{
+		Version: 2,
+		Name:    "vibe facets",
+		Up: `CREATE TABLE IF NOT EXISTS vibe_facets (
+			slug TEXT PRIMARY KEY,
+			category TEXT NOT NULL CHECK(category IN ('visual_style', 'pacing', 'emotional_texture', 'atmosphere')),
+			label TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS media_facets (
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			facet_slug TEXT NOT NULL REFERENCES vibe_facets(slug) ON DELETE CASCADE,
+			confidence REAL NOT NULL CHECK(confidence >= 0 AND confidence <= 1),
+			source TEXT NOT NULL DEFAULT 'keyword',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (media_id, facet_slug)
+		);
+		CREATE INDEX IF NOT EXISTS idx_media_facets_slug ON media_facets(facet_slug, confidence)`,
+		Down: `DROP TABLE media_facets;
+		DROP TABLE vibe_facets`,
+	},
+	{
+		// Per-user rating-weighted taste modes
+		Version: 3,
+		Name:    "taste centroids",
+		Up: `CREATE TABLE IF NOT EXISTS taste_centroids (
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			idx INTEGER NOT NULL,
+			vector BLOB NOT NULL,
+			weight_sum REAL NOT NULL,
+			members TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, idx)
+		)`,
+		Down: `DROP TABLE taste_centroids`,
+	},
+	{
+		// Collaborative-filtering neighbors from co-watches, and a log of
+		// seen changes so the job only recomputes what changed
+		Version: 4,
+		Name:    "item neighbors",
+		Up: `CREATE TABLE IF NOT EXISTS item_neighbors (
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			neighbor_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			score REAL NOT NULL,
+			co_count INTEGER NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (media_id, neighbor_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_item_neighbors_score ON item_neighbors(media_id, score DESC);
+		CREATE TABLE IF NOT EXISTS seen_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			media_id TEXT NOT NULL,
+			changed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TRIGGER IF NOT EXISTS trg_seen_media_insert AFTER INSERT ON seen_media
+		BEGIN
+			INSERT INTO seen_changes (user_id, media_id) VALUES (NEW.user_id, NEW.media_id);
+		END;
+		CREATE TRIGGER IF NOT EXISTS trg_seen_media_update AFTER UPDATE OF rating ON seen_media
+		BEGIN
+			INSERT INTO seen_changes (user_id, media_id) VALUES (NEW.user_id, NEW.media_id);
+		END;
+		CREATE TRIGGER IF NOT EXISTS trg_seen_media_delete AFTER DELETE ON seen_media
+		BEGIN
+			INSERT INTO seen_changes (user_id, media_id) VALUES (OLD.user_id, OLD.media_id);
+		END`,
+		Down: `DROP TRIGGER trg_seen_media_delete;
+		DROP TRIGGER trg_seen_media_update;
+		DROP TRIGGER trg_seen_media_insert;
+		DROP TABLE seen_changes;
+		DROP TABLE item_neighbors`,
+	},
+	{
+		// "Not interested", "already know it", or snoozed picks, kept apart
+		// from the watch history
+		Version: 5,
+		Name:    "dismissals",
+		Up: `CREATE TABLE IF NOT EXISTS dismissals (
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			reason TEXT NOT NULL CHECK(reason IN ('not_interested', 'already_know', 'snooze')),
+			until DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, media_id)
+		)`,
+		Down: `DROP TABLE dismissals`,
+	},
+	{
+		// Titles saved for later, in the user's own order. Watching
+		// something takes it off the watchlist.
+		Version: 6,
+		Name:    "watchlist",
+		Up: `CREATE TABLE IF NOT EXISTS watchlist (
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			priority INTEGER NOT NULL DEFAULT 2 CHECK(priority BETWEEN 1 AND 3),
+			position INTEGER NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, media_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_watchlist_position ON watchlist(user_id, position);
+		CREATE TRIGGER IF NOT EXISTS trg_seen_media_watchlist AFTER INSERT ON seen_media
+		BEGIN
+			DELETE FROM watchlist WHERE user_id = NEW.user_id AND media_id = NEW.media_id;
+		END`,
+		Down: `DROP TRIGGER trg_seen_media_watchlist;
+		DROP TABLE watchlist`,
+	},
+	{
+		// User-curated, ordered lists, private unless published under their
+		// unguessable slug
+		Version: 7,
+		Name:    "collections",
+		Up: `CREATE TABLE IF NOT EXISTS collections (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			is_public INTEGER NOT NULL DEFAULT 0,
+			slug TEXT NOT NULL UNIQUE,
+			vibe_summary TEXT NOT NULL DEFAULT '',
+			embedding BLOB,
+			embedding_model TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_collections_user ON collections(user_id);
+		CREATE TABLE IF NOT EXISTS collection_items (
+			collection_id TEXT NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (collection_id, media_id)
+		)`,
+		Down: `DROP TABLE collection_items;
+		DROP TABLE collections`,
+	},
+	{
+		// One row per logged recommendation response, with the candidate
+		// pool and final order as JSON for offline analysis
+		Version: 8,
+		Name:    "recommendation impressions",
+		Up: `CREATE TABLE IF NOT EXISTS rec_impressions (
+			request_id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			surface TEXT NOT NULL,
+			query TEXT NOT NULL DEFAULT '',
+			options TEXT NOT NULL DEFAULT '{}',
+			candidates TEXT NOT NULL DEFAULT '[]',
+			results TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_rec_impressions_created ON rec_impressions(created_at);
+		CREATE TABLE IF NOT EXISTS rec_interactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			request_id TEXT NOT NULL REFERENCES rec_impressions(request_id) ON DELETE CASCADE,
+			user_id TEXT NOT NULL,
+			media_id TEXT NOT NULL,
+			action TEXT NOT NULL CHECK(action IN ('expanded', 'clicked', 'seen', 'watchlist', 'dismissed')),
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_rec_interactions_request ON rec_interactions(request_id)`,
+		Down: `DROP TABLE rec_interactions;
+		DROP TABLE rec_impressions`,
+	},
+	{
+		// Reddit "shows like X" threads mined into judgments, and the
+		// history of /similar scored against them
+		Version: 9,
+		Name:    "similar judgments",
+		Up: `CREATE TABLE IF NOT EXISTS similar_judgments (
+			reference_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			weight REAL NOT NULL,
+			thread_count INTEGER NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (reference_id, media_id)
+		);
+		CREATE TABLE IF NOT EXISTS similar_quality_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			k INTEGER NOT NULL,
+			reference_count INTEGER NOT NULL,
+			judgment_count INTEGER NOT NULL,
+			ndcg REAL NOT NULL,
+			recall REAL NOT NULL,
+			mrr REAL NOT NULL,
+			embedding_model TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		Down: `DROP TABLE similar_quality_runs;
+		DROP TABLE similar_judgments`,
+	},
+	{
+		Version: 10,
+		Name:    "watch groups",
+		Up: `CREATE TABLE IF NOT EXISTS watch_groups (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			invite_code TEXT NOT NULL UNIQUE,
+			owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS watch_group_members (
+			group_id TEXT NOT NULL REFERENCES watch_groups(id) ON DELETE CASCADE,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			nickname TEXT NOT NULL,
+			joined_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (group_id, user_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_watch_group_members_user ON watch_group_members(user_id)`,
+		Down: `DROP TABLE watch_group_members;
+		DROP TABLE watch_groups`,
+	},
+	{
+		Version: 11,
+		Name:    "media runtimes",
+		Apply: addColumns("media",
+			column{"runtime_minutes", "INTEGER NOT NULL DEFAULT 0"},
+			column{"episode_runtime", "INTEGER NOT NULL DEFAULT 0"},
+			column{"episode_count", "INTEGER NOT NULL DEFAULT 0"},
+			column{"season_count", "INTEGER NOT NULL DEFAULT 0"},
+		),
+		Down: `ALTER TABLE media DROP COLUMN season_count;
+		ALTER TABLE media DROP COLUMN episode_count;
+		ALTER TABLE media DROP COLUMN episode_runtime;
+		ALTER TABLE media DROP COLUMN runtime_minutes`,
+	},
+	{
+		// Rows from before watch statuses were all finished viewings.
+		// Dropping a title changes its collaborative-filtering weight.
+		Version: 12,
+		Name:    "watch status",
+		Apply: func(tx *sql.Tx) error {
+			err := addColumns("seen_media",
+				column{"status", "TEXT NOT NULL DEFAULT 'completed' CHECK(status IN ('watching', 'on_hold', 'dropped', 'completed'))"},
+				column{"season", "INTEGER NOT NULL DEFAULT 0"},
+				column{"episode", "INTEGER NOT NULL DEFAULT 0"},
+				column{"rewatch_count", "INTEGER NOT NULL DEFAULT 0"},
+				column{"updated_at", "DATETIME"},
+			)(tx)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`CREATE TRIGGER IF NOT EXISTS trg_seen_media_status AFTER UPDATE OF status ON seen_media
+			BEGIN
+				INSERT INTO seen_changes (user_id, media_id) VALUES (NEW.user_id, NEW.media_id);
+			END`)
+			return err
+		},
+		Down: `DROP TRIGGER trg_seen_media_status;
+		ALTER TABLE seen_media DROP COLUMN updated_at;
+		ALTER TABLE seen_media DROP COLUMN rewatch_count;
+		ALTER TABLE seen_media DROP COLUMN episode;
+		ALTER TABLE seen_media DROP COLUMN season;
+		ALTER TABLE seen_media DROP COLUMN status`,
+	},
+	{
+		// How much of a hidden gem each title is, recomputed by the gem
+		// scoring job from TMDB votes, popularity and Reddit mentions
+		Version: 13,
+		Name:    "gem scores",
+		Up: `CREATE TABLE IF NOT EXISTS gem_scores (
+			media_id TEXT PRIMARY KEY REFERENCES media(id) ON DELETE CASCADE,
+			score REAL NOT NULL,
+			bayes_rating REAL NOT NULL,
+			rating_pct REAL NOT NULL,
+			popularity_pct REAL NOT NULL,
+			mentions INTEGER NOT NULL DEFAULT 0,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		Apply: addColumns("media",
+			column{"vote_average", "REAL NOT NULL DEFAULT 0"},
+			column{"vote_count", "INTEGER NOT NULL DEFAULT 0"},
+		),
+		Down: `DROP TABLE gem_scores;
+		ALTER TABLE media DROP COLUMN vote_count;
+		ALTER TABLE media DROP COLUMN vote_average`,
+	},
+	{
+		Version: 14,
+		Name:    "reddit post times",
+		Apply: func(tx *sql.Tx) error {
+			if err := addColumns("reddit_threads", column{"posted_at", "DATETIME"})(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_threads_posted ON reddit_threads(posted_at)`)
+			return err
+		},
+		Down: `DROP INDEX idx_threads_posted;
+		ALTER TABLE reddit_threads DROP COLUMN posted_at`,
+	},
+	{
+		// One Atom/RSS feed per session, addressed by a signed token, and
+		// the picks it published so entries stay stable between refreshes
+		Version: 15,
+		Name:    "feeds",
+		Up: `CREATE TABLE IF NOT EXISTS feeds (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			refreshed_at DATETIME
+		);
+		CREATE TABLE IF NOT EXISTS feed_items (
+			feed_id TEXT NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			source TEXT NOT NULL CHECK(source IN ('for_you', 'trending', 'new')),
+			explanation TEXT,
+			published_at DATETIME NOT NULL,
+			PRIMARY KEY (feed_id, media_id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_feed_items_published ON feed_items(feed_id, published_at)`,
+		Down: `DROP TABLE feed_items;
+		DROP TABLE feeds`,
+	},
+	{
+		// Sliders between two poles, each pole the centroid of its embedded
+		// anchor phrases, and each title's projection onto them. raw is
+		// cos(high) - cos(low); position rescales raw to 0-1 across the
+		// catalog.
+		Version: 16,
+		Name:    "vibe axes",
+		Up: `CREATE TABLE IF NOT EXISTS vibe_axes (
+			slug TEXT PRIMARY KEY,
+			low_label TEXT NOT NULL,
+			high_label TEXT NOT NULL,
+			low_anchors TEXT NOT NULL,
+			high_anchors TEXT NOT NULL,
+			low_centroid BLOB,
+			high_centroid BLOB,
+			model TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS media_axes (
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			axis_slug TEXT NOT NULL REFERENCES vibe_axes(slug) ON DELETE CASCADE,
+			raw REAL NOT NULL,
+			position REAL NOT NULL DEFAULT 0.5,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (media_id, axis_slug)
+		);
+		CREATE INDEX IF NOT EXISTS idx_media_axes_position ON media_axes(axis_slug, position)`,
+		Down: `DROP TABLE media_axes;
+		DROP TABLE vibe_axes`,
+	},
+	{
+		// Saved watch sequences drifting from one vibe to another,
+		// shareable by slug
+		Version: 17,
+		Name:    "journeys",
+		Up: `CREATE TABLE IF NOT EXISTS journeys (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			slug TEXT NOT NULL UNIQUE,
+			title TEXT NOT NULL,
+			from_label TEXT NOT NULL,
+			to_label TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_journeys_user ON journeys(user_id);
+		CREATE TABLE IF NOT EXISTS journey_steps (
+			journey_id TEXT NOT NULL REFERENCES journeys(id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			note TEXT NOT NULL DEFAULT '',
+			step_similarity REAL NOT NULL,
+			progress REAL NOT NULL,
+			PRIMARY KEY (journey_id, position)
+		)`,
+		Down: `DROP TABLE journey_steps;
+		DROP TABLE journeys`,
+	},
+	{
+		// TMDB movie collections and admin-linked series, seasons and
+		// spin-offs. A title belongs to at most one franchise; main entries
+		// in watch order come before spin-offs.
+		Version: 18,
+		Name:    "franchises",
+		Up: `CREATE TABLE IF NOT EXISTS franchises (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			source TEXT NOT NULL CHECK(source IN ('tmdb_collection', 'manual')),
+			external_id TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS franchise_entries (
+			media_id TEXT PRIMARY KEY REFERENCES media(id) ON DELETE CASCADE,
+			franchise_id TEXT NOT NULL REFERENCES franchises(id) ON DELETE CASCADE,
+			watch_order INTEGER NOT NULL,
+			role TEXT NOT NULL DEFAULT 'main' CHECK(role IN ('main', 'spinoff'))
+		);
+		CREATE INDEX IF NOT EXISTS idx_franchise_entries_franchise ON franchise_entries(franchise_id, watch_order)`,
+		Down: `DROP TABLE franchise_entries;
+		DROP TABLE franchises`,
+	},
+	{
+		// Onboarding quiz answers, including skips, so a title is asked
+		// once. Seen, loved and hated answers are also written to seen_media.
+		Version: 19,
+		Name:    "onboarding answers",
+		Up: `CREATE TABLE IF NOT EXISTS onboarding_answers (
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+			answer TEXT NOT NULL CHECK(answer IN ('seen', 'loved', 'hated', 'skip')),
+			answered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, media_id)
+		)`,
+		Down: `DROP TABLE onboarding_answers`,
+	},
+	{
+		// Rolling back fails while any documentaries are left, since they
+		// no longer fit the CHECK
+		Version:        20,
+		Name:           "documentary media type",
+		Up:             rebuildMediaTable(`'movie', 'tv', 'anime', 'documentary'`),
+		Down:           rebuildMediaTable(`'movie', 'tv', 'anime'`),
+		RebuildsTables: true,
+	},
+}
+
+// legacyBaselineChecksum is the checksum migration 1 was recorded with
+// while it held migrations 2 through legacyBaselineCovers as well.
+// Databases recorded with it already have their tables and columns.
+const (
+	legacyBaselineChecksum = "1d4610dd5fc40f2e51acf53904d8c7724f12b7742922041751e25ce0170b709b"
+	legacyBaselineCovers   = 19
+)
+
+// baselineSchema is the schema as it stood before versioned migrations.
+// Databases created before then already hold some or all of it, so every
+// statement is IF NOT EXISTS and they are recorded at version 1 once it has
+// run. Later migrations are IF NOT EXISTS too, for databases that picked up
+// their tables before they were versioned.
+var baselineSchema = []string{
+	// Users table
+	`CREATE TABLE IF NOT EXISTS users (
+		id TEXT PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Media table - stores movies, TV shows, anime with vibe profiles
+	`CREATE TABLE IF NOT EXISTS media (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		media_type TEXT NOT NULL CHECK(media_type IN ('movie', 'tv', 'anime')),
+		year INTEGER,
+		plot_summary TEXT,
+		vibe_profile TEXT NOT NULL,
+		quality_score REAL DEFAULT 0.0,
+		popularity_score REAL DEFAULT 0.0,
+		source_subreddit TEXT,
+		external_id TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Index for media lookup
+	`CREATE INDEX IF NOT EXISTS idx_media_title ON media(title)`,
+	`CREATE INDEX IF NOT EXISTS idx_media_type ON media(media_type)`,
+	`CREATE INDEX IF NOT EXISTS idx_media_external_id ON media(external_id)`,
+
+	// Seen media table - tracks what users have watched
+	`CREATE TABLE IF NOT EXISTS seen_media (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+		rating REAL CHECK(rating IS NULL OR (rating >= 1 AND rating <= 10)),
+		watched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, media_id)
+	)`,
+
+	// Index for efficient anti-join queries
+	`CREATE INDEX IF NOT EXISTS idx_seen_user_id ON seen_media(user_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_seen_media_id ON seen_media(media_id)`,
+
+	// Vibe embeddings table - stores vector representations
+	`CREATE TABLE IF NOT EXISTS vibe_embeddings (
+		media_id TEXT PRIMARY KEY REFERENCES media(id) ON DELETE CASCADE,
+		embedding BLOB NOT NULL,
+		model TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// Reddit threads table
+	`CREATE TABLE IF NOT EXISTS reddit_threads (
+		id TEXT PRIMARY KEY,
+		subreddit TEXT NOT NULL,
+		title TEXT NOT NULL,
+		body TEXT,
+		thread_type TEXT CHECK(thread_type IN ('similar_to', 'hidden_gem', 'quality_discussion', 'other')),
+		reference_show TEXT,
+		score INTEGER DEFAULT 0,
+		num_comments INTEGER DEFAULT 0,
+		scraped_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	`CREATE INDEX IF NOT EXISTS idx_threads_subreddit ON reddit_threads(subreddit)`,
+	`CREATE INDEX IF NOT EXISTS idx_threads_type ON reddit_threads(thread_type)`,
+
+	// Reddit mentions table - tracks show mentions in threads
+	`CREATE TABLE IF NOT EXISTS reddit_mentions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		thread_id TEXT NOT NULL REFERENCES reddit_threads(id) ON DELETE CASCADE,
+		media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+		mention_context TEXT,
+		quality_boost REAL DEFAULT 0.0,
+		UNIQUE(thread_id, media_id)
+	)`,
+
+	`CREATE INDEX IF NOT EXISTS idx_mentions_media ON reddit_mentions(media_id)`,
+}
+
+// rebuildMediaTable recreates the media table with the given media types
+// allowed, keeping its rows. Tables referring to media follow the rename.
+func rebuildMediaTable(types string) string {
+	const columns = `id, title, media_type, year, plot_summary, vibe_profile, quality_score,
+		popularity_score, source_subreddit, external_id, runtime_minutes, episode_runtime,
+		episode_count, season_count, vote_average, vote_count, created_at, updated_at`
+	return `CREATE TABLE media_rebuilt (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		media_type TEXT NOT NULL CHECK(media_type IN (` + types + `)),
+		year INTEGER,
+		plot_summary TEXT,
+		vibe_profile TEXT NOT NULL,
+		quality_score REAL DEFAULT 0.0,
+		popularity_score REAL DEFAULT 0.0,
+		source_subreddit TEXT,
+		external_id TEXT,
+		runtime_minutes INTEGER NOT NULL DEFAULT 0,
+		episode_runtime INTEGER NOT NULL DEFAULT 0,
+		episode_count INTEGER NOT NULL DEFAULT 0,
+		season_count INTEGER NOT NULL DEFAULT 0,
+		vote_average REAL NOT NULL DEFAULT 0,
+		vote_count INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	INSERT INTO media_rebuilt (` + columns + `) SELECT ` + columns + ` FROM media;
+	DROP TABLE media;
+	ALTER TABLE media_rebuilt RENAME TO media;
+	CREATE INDEX idx_media_title ON media(title);
+	CREATE INDEX idx_media_type ON media(media_type);
+	CREATE INDEX idx_media_external_id ON media(external_id)`
+}
+
+// column is a column added to a table after it was created
+type column struct{ name, definition string }
+
+// addColumns returns an Apply step adding columns to a table. Columns a
+// database picked up before migrations were versioned are left alone.
+func addColumns(table string, columns ...column) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, c := range columns {
+			if err := addColumnIfMissing(tx, table, c.name, c.definition); err != nil {
+				return fmt.Errorf("failed to add %s.%s: %w", table, c.name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is there
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition))
+	return err
+}
+
+// MigrationStatus describes one migration and whether the database has it
+type MigrationStatus struct {
+	Version    int
+	Name       string
+	Applied    bool
+	AppliedAt  time.Time
+	Reversible bool
+	// Modified means the migration was edited after it was applied
+	Modified bool
+	// Unknown means the database has a migration this build does not,
+	// because a newer build migrated it
+	Unknown bool
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+	// legacy marks a migration the legacy baseline record stands for
+	legacy bool
+}
+
+// LatestVersion returns the version of the newest migration
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+func migrationChecksum(m Migration) string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+func (db *DB) ensureMigrationsTable() error {
+	_, err := db.conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+// appliedMigrations returns the migrations recorded in the database by
+// version, without creating schema_migrations if it is not there yet
+func (db *DB) appliedMigrations() (map[int]appliedMigration, error) {
+	applied := make(map[int]appliedMigration)
+
+	var exists int
+	if err := db.conn.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`,
+	).Scan(&exists); err != nil || exists == 0 {
+		return applied, err
+	}
+
+	rows, err := db.conn.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &a.name, &a.checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		a.appliedAt = appliedAt.Time
+		applied[version] = a
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A legacy baseline record stands for every migration it held
+	if a, ok := applied[1]; ok && a.checksum == legacyBaselineChecksum {
+		for _, m := range migrations {
+			if m.Version > legacyBaselineCovers {
+				break
+			}
+			applied[m.Version] = appliedMigration{name: m.Name, checksum: migrationChecksum(m), appliedAt: a.appliedAt, legacy: true}
+		}
+	}
+	return applied, nil
+}
+
+// recordLegacyBaseline replaces a legacy baseline record with one row per
+// migration it stands for, so they can be rolled back one by one
+func (db *DB) recordLegacyBaseline() error {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for version, a := range applied {
+		if !a.legacy {
+			continue
+		}
+		if _, err := tx.Exec(
+			`INSERT OR REPLACE INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+			version, a.name, a.checksum, a.appliedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion returns the highest applied migration, or 0 for a database
+// that has never been migrated
+func (db *DB) SchemaVersion() (int, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// MigrationStatus lists every migration this build knows, and any the
+// database has that it does not, in version order
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	known := make(map[int]bool)
+	for _, m := range migrations {
+		known[m.Version] = true
+		s := MigrationStatus{Version: m.Version, Name: m.Name, Reversible: m.Down != ""}
+		if a, ok := applied[m.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = a.appliedAt
+			s.Modified = a.checksum != migrationChecksum(m)
+		}
+		statuses = append(statuses, s)
+	}
+	for v, a := range applied {
+		if !known[v] {
+			statuses = append(statuses, MigrationStatus{Version: v, Name: a.name, Applied: true, AppliedAt: a.appliedAt, Unknown: true})
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// MigrateTo applies or rolls back migrations until the database is at
+// target, each in its own transaction. Applied migrations are verified
+// against their checksums first. When anything will change and the
+// database already holds tables, it is copied to a backup file next to it
+// beforehand; the backup's path is returned, or "" if none was taken.
+func (db *DB) MigrateTo(target int) (string, error) {
+	if target < 0 || target > LatestVersion() {
+		return "", fmt.Errorf("no migration %d, the latest is %d", target, LatestVersion())
+	}
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		return "", fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	current := 0
+	for _, s := range statuses {
+		if s.Unknown {
+			return "", fmt.Errorf("database has migration %d (%s), which this build does not know; it was migrated by a newer version", s.Version, s.Name)
+		}
+		if s.Modified {
+			return "", fmt.Errorf("migration %d (%s) was changed after it was applied", s.Version, s.Name)
+		}
+		if s.Applied && s.Version > current {
+			current = s.Version
+		}
+	}
+
+	var up, down []Migration
+	for i, m := range migrations {
+		switch s := statuses[i]; {
+		case !s.Applied && m.Version <= target:
+			up = append(up, m)
+		case s.Applied && m.Version > target:
+			if m.Down == "" {
+				return "", fmt.Errorf("migration %d (%s) cannot be rolled back", m.Version, m.Name)
+			}
+			down = append([]Migration{m}, down...)
+		}
+	}
+	if len(up) == 0 && len(down) == 0 {
+		return "", nil
+	}
+
+	backup, err := db.backup(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := db.ensureMigrationsTable(); err != nil {
+		return backup, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	if err := db.recordLegacyBaseline(); err != nil {
+		return backup, fmt.Errorf("failed to record the legacy baseline: %w", err)
+	}
+
+	for _, m := range down {
+		if err := db.runMigration(m, false); err != nil {
+			return backup, fmt.Errorf("rolling back migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Rolled back migration %d (%s)", m.Version, m.Name)
+	}
+	for _, m := range up {
+		if err := db.runMigration(m, true); err != nil {
+			return backup, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %d (%s)", m.Version, m.Name)
+	}
+	return backup, nil
+}
+
+// runMigration applies or rolls back a migration and records it in
+// schema_migrations, in one transaction on a single connection
+func (db *DB) runMigration(m Migration, up bool) error {
+	ctx := context.Background()
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.RebuildsTables {
+		// The pragma is a no-op inside a transaction, so it is set on the
+		// connection first and restored before the connection is returned
+		if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err := tx.Exec(m.Up); err != nil {
+			return err
+		}
+		if m.Apply != nil {
+			if err := m.Apply(tx); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(
+			`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+			m.Version, m.Name, migrationChecksum(m), time.Now(),
+		)
+	} else {
+		if _, err := tx.Exec(m.Down); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if m.RebuildsTables {
+		if err := checkForeignKeys(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// checkForeignKeys fails on the first row whose foreign key points nowhere
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		return fmt.Errorf("row %d of %s references a missing %s row", rowID.Int64, table, parent)
+	}
+	return rows.Err()
+}
+
+// backup copies a database that already holds tables to
+// <path>.v<version>-<timestamp>.bak and returns that path. In-memory and
+// empty databases are not backed up.
+func (db *DB) backup(version int) (string, error) {
+	if db.path == "" || strings.HasPrefix(db.path, ":memory:") {
+		return "", nil
+	}
+
+	var tables int
+	if err := db.conn.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_migrations'`,
+	).Scan(&tables); err != nil {
+		return "", err
+	}
+	if tables == 0 {
+		return "", nil
+	}
+
+	// A retry within the same second gets its own file
+	base := fmt.Sprintf("%s.v%d-%s", db.path, version, time.Now().Format("20060102-150405"))
+	path := base + ".bak"
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = fmt.Sprintf("%s-%d.bak", base, n)
+	}
+	if _, err := db.conn.Exec(`VACUUM INTO ?`, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,359 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+// withMigrations swaps the migration list for the length of a test
+func withMigrations(t *testing.T, list []Migration) {
+	t.Helper()
+	saved := migrations
+	migrations = list
+	t.Cleanup(func() { migrations = saved })
+}
+
+// openTemp opens an empty, unmigrated database in a temporary directory
+func openTemp(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *DB, name string) bool {
+	t.Helper()
+	var n int
+	if err := db.conn.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name,
+	).Scan(&n); err != nil {
+		t.Fatalf("reading sqlite_master: %v", err)
+	}
+	return n > 0
+}
+
+func schemaVersion(t *testing.T, db *DB) int {
+	t.Helper()
+	v, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	return v
+}
+
+// notesMigration is a reversible migration on top of the real baseline
+var notesMigration = Migration{
+	Version: 2,
+	Name:    "notes",
+	Up:      `CREATE TABLE notes (id INTEGER PRIMARY KEY, media_id TEXT NOT NULL REFERENCES media(id) ON DELETE CASCADE, body TEXT NOT NULL)`,
+	Down:    `DROP TABLE notes`,
+}
+
+func TestNewMigratesToLatest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if v := schemaVersion(t, db); v != LatestVersion() {
+		t.Errorf("schema version = %d, want %d", v, LatestVersion())
+	}
+	db.Close()
+
+	// Reopening has nothing to do, so nothing is backed up
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	backup, err := db.MigrateTo(LatestVersion())
+	if err != nil {
+		t.Fatalf("MigrateTo: %v", err)
+	}
+	if backup != "" {
+		t.Errorf("backup = %q for a no-op migration, want none", backup)
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	withMigrations(t, []Migration{migrations[0], notesMigration})
+	db := openTemp(t)
+
+	if _, err := db.MigrateTo(2); err != nil {
+		t.Fatalf("MigrateTo(2): %v", err)
+	}
+	if v := schemaVersion(t, db); v != 2 {
+		t.Fatalf("schema version = %d, want 2", v)
+	}
+	if !tableExists(t, db, "notes") {
+		t.Fatal("notes table missing after migrating up")
+	}
+
+	backup, err := db.MigrateTo(1)
+	if err != nil {
+		t.Fatalf("MigrateTo(1): %v", err)
+	}
+	if v := schemaVersion(t, db); v != 1 {
+		t.Errorf("schema version = %d, want 1", v)
+	}
+	if tableExists(t, db, "notes") {
+		t.Error("notes table still there after rolling back")
+	}
+	if !tableExists(t, db, "media") {
+		t.Error("baseline media table dropped by rolling back migration 2")
+	}
+	if backup == "" {
+		t.Fatal("no backup taken before rolling back")
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("backup %s: %v", backup, err)
+	}
+	if !strings.Contains(backup, ".v2-") {
+		t.Errorf("backup %s is not named after version 2", backup)
+	}
+
+	// Back up again, then the baseline refuses to roll back
+	if _, err := db.MigrateTo(2); err != nil {
+		t.Fatalf("MigrateTo(2) again: %v", err)
+	}
+	if _, err := db.MigrateTo(0); err == nil || !strings.Contains(err.Error(), "cannot be rolled back") {
+		t.Fatalf("MigrateTo(0) error = %v, want a refusal to roll back the baseline", err)
+	}
+	if v := schemaVersion(t, db); v != 2 {
+		t.Errorf("schema version = %d after a refused rollback, want 2", v)
+	}
+}
+
+func TestMigrateRefusesChangedMigration(t *testing.T) {
+	withMigrations(t, []Migration{migrations[0], notesMigration})
+	db := openTemp(t)
+	if _, err := db.MigrateTo(2); err != nil {
+		t.Fatalf("MigrateTo(2): %v", err)
+	}
+
+	changed := notesMigration
+	changed.Up = `CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)`
+	migrations[1] = changed
+
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	if !statuses[1].Modified {
+		t.Error("changed migration not reported as modified")
+	}
+	if _, err := db.MigrateTo(1); err == nil || !strings.Contains(err.Error(), "was changed after it was applied") {
+		t.Fatalf("MigrateTo(1) error = %v, want a checksum mismatch", err)
+	}
+	if !tableExists(t, db, "notes") {
+		t.Error("notes table dropped despite the checksum mismatch")
+	}
+}
+
+func TestMigrateRefusesUnknownMigration(t *testing.T) {
+	withMigrations(t, []Migration{migrations[0], notesMigration})
+	db := openTemp(t)
+	if _, err := db.MigrateTo(2); err != nil {
+		t.Fatalf("MigrateTo(2): %v", err)
+	}
+
+	// An older build that only knows the baseline
+	migrations = migrations[:1]
+	if _, err := db.MigrateTo(1); err == nil || !strings.Contains(err.Error(), "does not know") {
+		t.Fatalf("MigrateTo(1) error = %v, want a refusal of the unknown migration", err)
+	}
+}
+
+func TestRebuildMigrationChecksForeignKeys(t *testing.T) {
+	// Enforcement is off while it runs, so the delete does not cascade and
+	// leaves a seen row pointing nowhere
+	latest := LatestVersion()
+	orphaning := Migration{
+		Version:        latest + 1,
+		Name:           "orphan seen rows",
+		Up:             `DELETE FROM media WHERE id = 'movie-1'`,
+		RebuildsTables: true,
+	}
+	withMigrations(t, append(migrations[:latest:latest], orphaning))
+	db := openTemp(t)
+	if _, err := db.MigrateTo(latest); err != nil {
+		t.Fatalf("MigrateTo(%d): %v", latest, err)
+	}
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "u1"}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := db.CreateMedia(&models.Media{ID: "movie-1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}); err != nil {
+		t.Fatalf("CreateMedia: %v", err)
+	}
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "movie-1"}); err != nil {
+		t.Fatalf("MarkAsSeen: %v", err)
+	}
+
+	if _, err := db.MigrateTo(latest + 1); err == nil || !strings.Contains(err.Error(), "references a missing") {
+		t.Fatalf("MigrateTo(%d) error = %v, want a foreign key failure", latest+1, err)
+	}
+	if v := schemaVersion(t, db); v != latest {
+		t.Errorf("schema version = %d after a failed migration, want %d", v, latest)
+	}
+	var n int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM media WHERE id = 'movie-1'`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("media row lost after a failed migration (count %d, err %v)", n, err)
+	}
+}
+
+func TestMigrationsRollBackToBaselineAndForward(t *testing.T) {
+	db := openTemp(t)
+	if _, err := db.MigrateTo(LatestVersion()); err != nil {
+		t.Fatalf("MigrateTo(latest): %v", err)
+	}
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "u1"}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if err := db.CreateMedia(&models.Media{ID: "movie-1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}); err != nil {
+		t.Fatalf("CreateMedia: %v", err)
+	}
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "movie-1"}); err != nil {
+		t.Fatalf("MarkAsSeen: %v", err)
+	}
+
+	// Every migration steps down to the pre-series schema, keeping the
+	// baseline rows
+	if _, err := db.MigrateTo(1); err != nil {
+		t.Fatalf("MigrateTo(1): %v", err)
+	}
+	for _, name := range []string{"vibe_facets", "watchlist", "feeds", "onboarding_answers"} {
+		if tableExists(t, db, name) {
+			t.Errorf("%s still there at the baseline", name)
+		}
+	}
+	var n int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM seen_media WHERE media_id = 'movie-1'`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("seen row lost rolling back (count %d, err %v)", n, err)
+	}
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('seen_media') WHERE name = 'status'`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("seen_media.status still there at the baseline (count %d, err %v)", n, err)
+	}
+
+	if _, err := db.MigrateTo(LatestVersion()); err != nil {
+		t.Fatalf("MigrateTo(latest) again: %v", err)
+	}
+	seen, err := db.GetSeenMedia("u1")
+	if err != nil || len(seen) != 1 || seen[0].Status != models.WatchCompleted {
+		t.Errorf("seen rows after migrating forward = %+v (%v), want one completed", seen, err)
+	}
+}
+
+func TestDocumentaryMediaType(t *testing.T) {
+	db := openTemp(t)
+	if _, err := db.MigrateTo(LatestVersion()); err != nil {
+		t.Fatalf("MigrateTo(latest): %v", err)
+	}
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "u1"}); err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	doc := &models.Media{ID: "doc-1", Title: "Free Solo", MediaType: "documentary", VibeProfile: "vertigo", RuntimeMinutes: 100}
+	if err := db.CreateMedia(doc); err != nil {
+		t.Fatalf("CreateMedia(documentary): %v", err)
+	}
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "doc-1"}); err != nil {
+		t.Fatalf("MarkAsSeen: %v", err)
+	}
+	if got, err := db.GetMedia("doc-1"); err != nil || got == nil || got.RuntimeMinutes != 100 {
+		t.Errorf("documentary after the rebuild = %+v (%v)", got, err)
+	}
+
+	// Rolling back fails while a documentary is left
+	if _, err := db.MigrateTo(LatestVersion() - 1); err == nil {
+		t.Fatal("rolled back past the documentary type with a documentary left")
+	}
+	if v := schemaVersion(t, db); v != LatestVersion() {
+		t.Errorf("schema version = %d after a failed rollback, want %d", v, LatestVersion())
+	}
+
+	// The rebuilt table still cascades to seen_media
+	if _, err := db.conn.Exec(`DELETE FROM media WHERE id = 'doc-1'`); err != nil {
+		t.Fatalf("deleting the documentary: %v", err)
+	}
+	var n int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM seen_media`).Scan(&n); err != nil || n != 0 {
+		t.Errorf("%d seen rows left after deleting their title (%v)", n, err)
+	}
+	if _, err := db.MigrateTo(LatestVersion() - 1); err != nil {
+		t.Fatalf("rolling back without documentaries: %v", err)
+	}
+	if err := db.CreateMedia(doc); err == nil {
+		t.Error("documentary accepted after rolling back")
+	}
+}
+
+func TestLegacyBaselineRecord(t *testing.T) {
+	// Migration 1 used to hold everything up to legacyBaselineCovers
+	db := openTemp(t)
+	if _, err := db.MigrateTo(legacyBaselineCovers); err != nil {
+		t.Fatalf("MigrateTo(%d): %v", legacyBaselineCovers, err)
+	}
+	if _, err := db.conn.Exec(`DELETE FROM schema_migrations`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.conn.Exec(
+		`INSERT INTO schema_migrations (version, name, checksum) VALUES (1, 'baseline', ?)`, legacyBaselineChecksum,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := schemaVersion(t, db); v != legacyBaselineCovers {
+		t.Errorf("schema version = %d, want %d", v, legacyBaselineCovers)
+	}
+	statuses, err := db.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Modified {
+			t.Errorf("migration %d reported as modified", s.Version)
+		}
+		if applied := s.Version <= legacyBaselineCovers; s.Applied != applied {
+			t.Errorf("migration %d applied = %v, want %v", s.Version, s.Applied, applied)
+		}
+	}
+
+	// Migrating writes out the rows the record stands for, so each can be
+	// rolled back on its own
+	if _, err := db.MigrateTo(LatestVersion()); err != nil {
+		t.Fatalf("MigrateTo(latest): %v", err)
+	}
+	var n int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&n); err != nil || n != LatestVersion() {
+		t.Errorf("%d schema_migrations rows, want %d (%v)", n, LatestVersion(), err)
+	}
+	if _, err := db.MigrateTo(legacyBaselineCovers - 1); err != nil {
+		t.Fatalf("MigrateTo(%d): %v", legacyBaselineCovers-1, err)
+	}
+	if tableExists(t, db, "onboarding_answers") {
+		t.Error("onboarding_answers still there after rolling it back")
+	}
+}
+
+func TestMigrateUnversionedDatabase(t *testing.T) {
+	// Databases from before versioning have the tables but no record
+	db := openTemp(t)
+	if _, err := db.MigrateTo(legacyBaselineCovers); err != nil {
+		t.Fatalf("MigrateTo(%d): %v", legacyBaselineCovers, err)
+	}
+	if _, err := db.conn.Exec(`DROP TABLE schema_migrations`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.MigrateTo(LatestVersion()); err != nil {
+		t.Fatalf("MigrateTo(latest): %v", err)
+	}
+	if v := schemaVersion(t, db); v != LatestVersion() {
+		t.Errorf("schema version = %d, want %d", v, LatestVersion())
+	}
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Onboarding Operations
+// ============================================================================
+
+// SaveOnboardingAnswer records (or changes) the user's answer for a title
+func (db *DB) SaveOnboardingAnswer(userID, mediaID, answer string) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO onboarding_answers (user_id, media_id, answer, answered_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id, media_id) DO UPDATE SET answer = excluded.answer, answered_at = excluded.answered_at`,
+		userID, mediaID, answer, time.Now(),
+	)
+	return err
+}
+
+// GetOnboardingAnswers returns the user's onboarding answers, oldest first
+func (db *DB) GetOnboardingAnswers(userID string) ([]models.OnboardingAnswer, error) {
+	rows, err := db.conn.Query(
+		`SELECT media_id, answer, answered_at FROM onboarding_answers
+		WHERE user_id = ? ORDER BY answered_at, media_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var answers []models.OnboardingAnswer
+	for rows.Next() {
+		var a models.OnboardingAnswer
+		var answered sql.NullTime
+		if err := rows.Scan(&a.MediaID, &a.Answer, &answered); err != nil {
+			return nil, err
+		}
+		a.AnsweredAt = answered.Time
+		answers = append(answers, a)
+	}
+	return answers, rows.Err()
+}
+
+// GetOnboardingAnswer returns the user's answer for a title, or "" if they
+// have not answered it
+func (db *DB) GetOnboardingAnswer(userID, mediaID string) (string, error) {
+	var answer string
+	err := db.conn.QueryRow(
+		`SELECT answer FROM onboarding_answers WHERE user_id = ? AND media_id = ?`,
+		userID, mediaID,
+	).Scan(&answer)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return answer, err
+}
+
+// ResetOnboarding forgets the user's onboarding answers so the quiz starts
+// over. Titles already written to seen_media stay there.
+func (db *DB) ResetOnboarding(userID string) error {
+	_, err := db.conn.Exec(`DELETE FROM onboarding_answers WHERE user_id = ?`, userID)
+	return err
+}
+
+// GetWellKnownMedia returns the most popular titles with an embedding, most
+// voted-on first, as onboarding candidates
+func (db *DB) GetWellKnownMedia(limit int) ([]models.Media, error) {
+	rows, err := db.conn.Query(
+		`SELECT m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM media m
+		WHERE m.id IN (SELECT media_id FROM vibe_embeddings)
+		ORDER BY m.popularity_score DESC, m.vote_count DESC, m.quality_score DESC, m.id
+		LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.Media
+	for rows.Next() {
+		var m models.Media
+		if err := rows.Scan(
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
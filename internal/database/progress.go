@@ -0,0 +1,123 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Watch Progress Operations
+// ============================================================================
+
+// GetProgress returns the user's seen_media row for a title, or nil if the
+// title is not tracked
+func (db *DB) GetProgress(userID, mediaID string) (*models.SeenMedia, error) {
+	s := &models.SeenMedia{}
+	var updated sql.NullTime
+	err := db.conn.QueryRow(
+		`SELECT id, user_id, media_id, rating, watched_at, created_at,
+		status, season, episode, rewatch_count, updated_at
+		FROM seen_media WHERE user_id = ? AND media_id = ?`,
+		userID, mediaID,
+	).Scan(&s.ID, &s.UserID, &s.MediaID, &s.Rating, &s.WatchedAt, &s.CreatedAt,
+		&s.Status, &s.Season, &s.Episode, &s.RewatchCount, &updated)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	s.UpdatedAt = progressUpdatedAt(updated, s.WatchedAt)
+	return s, err
+}
+
+// progressUpdatedAt falls back to watched_at for rows saved before progress
+// was tracked
+func progressUpdatedAt(updated sql.NullTime, watchedAt time.Time) time.Time {
+	if updated.Valid {
+		return updated.Time
+	}
+	return watchedAt
+}
+
+// SaveProgress inserts or overwrites the user's status and progress for a
+// title. watched_at is set when the row is created and left alone after.
+func (db *DB) SaveProgress(p *models.SeenMedia) error {
+	now := time.Now()
+	_, err := db.conn.Exec(
+		`INSERT INTO seen_media (user_id, media_id, rating, watched_at, created_at,
+			status, season, episode, rewatch_count, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, media_id) DO UPDATE SET
+			rating = excluded.rating, status = excluded.status,
+			season = excluded.season, episode = excluded.episode,
+			rewatch_count = excluded.rewatch_count, updated_at = excluded.updated_at`,
+		p.UserID, p.MediaID, p.Rating, now, now,
+		p.Status, p.Season, p.Episode, p.RewatchCount, now,
+	)
+	return err
+}
+
+// GetProgressList returns the user's tracked titles with media details,
+// most recently updated first. An empty statuses list returns every status.
+func (db *DB) GetProgressList(userID string, statuses []string, limit int) ([]models.SeenMedia, error) {
+	query := `SELECT s.id, s.user_id, s.media_id, s.rating, s.watched_at, s.created_at,
+		       s.status, s.season, s.episode, s.rewatch_count, s.updated_at,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM seen_media s
+		JOIN media m ON s.media_id = m.id
+		WHERE s.user_id = ?`
+	args := []interface{}{userID}
+	if len(statuses) > 0 {
+		query += ` AND s.status IN (?` + strings.Repeat(`, ?`, len(statuses)-1) + `)`
+		for _, status := range statuses {
+			args = append(args, status)
+		}
+	}
+	query += ` ORDER BY COALESCE(s.updated_at, s.watched_at) DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.SeenMedia
+	for rows.Next() {
+		var s models.SeenMedia
+		var m models.Media
+		var updated sql.NullTime
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &s.MediaID, &s.Rating, &s.WatchedAt, &s.CreatedAt,
+			&s.Status, &s.Season, &s.Episode, &s.RewatchCount, &updated,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		s.UpdatedAt = progressUpdatedAt(updated, s.WatchedAt)
+		s.Media = &m
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// RemoveSeen deletes the user's seen_media row for a title. Returns false if
+// there was none.
+func (db *DB) RemoveSeen(userID, mediaID string) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM seen_media WHERE user_id = ? AND media_id = ?`, userID, mediaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Repositories
+// ============================================================================
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	CreateUser(user *models.User) error
+	// GetUser returns nil when there is no such user
+	GetUser(id string) (*models.User, error)
+}
+
+// MediaRepository stores the catalog of movies, shows and anime
+type MediaRepository interface {
+	CreateMedia(media *models.Media) error
+	// GetMedia and GetMediaByTitle return nil when there is no match
+	GetMedia(id string) (*models.Media, error)
+	GetMediaByTitle(title string) (*models.Media, error)
+	UpdateQualityScore(mediaID string, boost float64) error
+	UpdateVibeProfile(mediaID, vibeProfile string) error
+	GetAllVibeProfiles() (map[string]string, error)
+	CountMedia() (int, error)
+}
+
+// SeenRepository stores what each user has watched
+type SeenRepository interface {
+	MarkAsSeen(seen *models.SeenMedia) error
+	RemoveSeen(userID, mediaID string) (bool, error)
+	GetSeenMedia(userID string) ([]models.SeenMedia, error)
+	GetSeenMediaWithDetails(userID string) ([]models.Media, error)
+	IsMediaSeen(userID, mediaID string) (bool, error)
+	GetSeenMediaIDs(userID string) (map[string]bool, error)
+}
+
+// EmbeddingRepository stores the vibe embedding of each media entry
+type EmbeddingRepository interface {
+	StoreEmbedding(mediaID string, embedding []float32, model string) error
+	// GetEmbedding returns nil when the media entry has no embedding
+	GetEmbedding(mediaID string) ([]float32, error)
+	GetAllEmbeddings() (map[string][]float32, error)
+	GetAllEmbeddingsExcludingSeen(userID string) (map[string][]float32, error)
+	GetVibeProfilesWithoutEmbedding() (map[string]string, error)
+	CountEmbeddings() (int, error)
+}
+
+// RedditRepository stores scraped threads and the titles they mention
+type RedditRepository interface {
+	CreateRedditThread(thread *models.RedditThread) error
+	CreateRedditMention(mention *models.RedditMention) error
+	GetMentionCountForMedia(mediaID string) (int, error)
+	CountRedditThreads() (int, error)
+	CountRedditMentions() (int, error)
+	GetThreadCountsBySubreddit() ([]models.SubredditCount, error)
+}
+
+// Store is every core repository. DB implements it on SQLite and
+// MemoryStore in memory.
+type Store interface {
+	UserRepository
+	MediaRepository
+	SeenRepository
+	EmbeddingRepository
+	RedditRepository
+}
+
+// ============================================================================
+// Feature Repositories
+// ============================================================================
+
+// DismissalRepository stores "not interested" and snoozed titles
+type DismissalRepository interface {
+	Dismiss(d *models.Dismissal) error
+	Undismiss(userID, mediaID string) (bool, error)
+	GetDismissals(userID string, includeExpired bool) ([]models.Dismissal, error)
+	// GetExcludedMediaIDs is everything seen or currently dismissed
+	GetExcludedMediaIDs(userID string) (map[string]bool, error)
+	GetNotInterestedIDs(userID string) ([]string, error)
+}
+
+// WatchlistRepository stores each user's ordered watchlist
+type WatchlistRepository interface {
+	AddToWatchlist(item *models.WatchlistItem) error
+	UpdateWatchlistItem(userID, mediaID string, priority *int, note *string) (bool, error)
+	RemoveFromWatchlist(userID, mediaID string) (bool, error)
+	ReorderWatchlist(userID string, mediaIDs []string) error
+	GetWatchlist(userID string) ([]models.WatchlistItem, error)
+	GetWatchlistIDs(userID string) (map[string]bool, error)
+}
+
+// ProgressRepository stores watch status and episode progress
+type ProgressRepository interface {
+	GetProgress(userID, mediaID string) (*models.SeenMedia, error)
+	SaveProgress(p *models.SeenMedia) error
+	GetProgressList(userID string, statuses []string, limit int) ([]models.SeenMedia, error)
+}
+
+// TasteRepository stores each user's taste centroids
+type TasteRepository interface {
+	GetTasteCentroids(userID string) ([]models.TasteCentroid, error)
+	ReplaceTasteCentroids(userID string, centroids []models.TasteCentroid) error
+}
+
+// OnboardingRepository stores the cold-start quiz answers
+type OnboardingRepository interface {
+	SaveOnboardingAnswer(userID, mediaID, answer string) error
+	GetOnboardingAnswers(userID string) ([]models.OnboardingAnswer, error)
+	GetOnboardingAnswer(userID, mediaID string) (string, error)
+	ResetOnboarding(userID string) error
+	GetWellKnownMedia(limit int) ([]models.Media, error)
+}
+
+// ImpressionRepository stores what was shown and what users did with it
+type ImpressionRepository interface {
+	CreateImpression(imp *models.Impression) error
+	GetImpressionUserID(requestID string) (string, error)
+	CreateInteraction(in *models.Interaction) error
+	PruneImpressions(before time.Time) (int64, error)
+	ExportImpressions(since, until time.Time, fn func(*models.Impression) error) error
+	GetShownCounts(userID string, since time.Time) (map[string]int, error)
+	GetImpressionFeedback(since time.Time) (map[string]models.ItemFeedback, error)
+}
+
+// CollabRepository stores co-watch neighbours and the seen changes that
+// feed them
+type CollabRepository interface {
+	GetAllSeenRatings() ([]models.SeenMedia, error)
+	GetPendingSeenChanges() (int64, []string, []string, error)
+	ClearSeenChanges(upToID int64) error
+	CountItemNeighbors() (int, error)
+	ReplaceItemNeighbors(neighbors map[string][]models.ItemNeighbor, all bool) error
+	GetItemNeighbors(mediaID string, limit int) ([]models.ItemNeighbor, error)
+}
+
+// RuntimeRepository filters titles by runtime
+type RuntimeRepository interface {
+	GetMediaIDsWithinDuration(f models.DurationFilter) (map[string]bool, error)
+}
+
+// GemRepository stores hidden-gem scores
+type GemRepository interface {
+	GetGemInputs() ([]models.GemInput, error)
+	ReplaceGemScores(scores []models.GemScore) error
+	GetGemScores(mediaType string, maxPopularityPct float64) (map[string]models.GemScore, error)
+	GetHiddenGemCandidates(userID, mediaType string, maxPopularityPct float64) ([]models.GemScore, error)
+}
+
+// TrendingRepository reads Reddit mention activity over time
+type TrendingRepository interface {
+	GetMentionActivity(since time.Time) ([]models.MentionActivity, error)
+}
+
+// FacetRepository stores the facet vocabulary and each title's facets
+type FacetRepository interface {
+	UpsertFacet(facet *models.Facet) error
+	ListFacets(category string, minConfidence float64) ([]models.Facet, error)
+	ReplaceMediaFacets(mediaID string, facets []models.MediaFacet) error
+	GetFacetChips(mediaIDs []string, minConfidence float64) (map[string][]models.FacetChip, error)
+	GetMediaIDsWithFacets(slugs []string, minConfidence float64) (map[string]bool, error)
+	GetMediaIDsMissingFacets(all bool) ([]string, error)
+}
+
+// CollectionRepository stores user-curated collections
+type CollectionRepository interface {
+	CreateCollection(c *models.Collection) error
+	GetCollection(id string) (*models.Collection, error)
+	GetCollectionBySlug(slug string) (*models.Collection, error)
+	GetCollectionsByIDs(ids []string) (map[string]*models.Collection, error)
+	ListCollections(userID string) ([]models.Collection, error)
+	UpdateCollection(c *models.Collection) error
+	DeleteCollection(id string) error
+	GetCollectionItems(collectionID string) ([]models.CollectionItem, error)
+	AddCollectionItem(collectionID, mediaID, note string) error
+	UpdateCollectionItemNote(collectionID, mediaID, note string) (bool, error)
+	RemoveCollectionItem(collectionID, mediaID string) (bool, error)
+	ReorderCollection(collectionID string, mediaIDs []string) error
+	SetCollectionVibe(id, summary string, embedding []float32, model string) error
+	GetAllCollectionEmbeddings() (map[string][]float32, error)
+	ImportCollectionToWatchlist(userID, collectionID string) (int, error)
+}
+
+// AxisRepository stores vibe axes and each title's position on them
+type AxisRepository interface {
+	InsertAxisIfMissing(axis *models.VibeAxis) error
+	SaveAxis(axis *models.VibeAxis) error
+	GetAxes() ([]models.VibeAxis, error)
+	DeleteAxis(slug string) (bool, error)
+	GetAxisProjectionTimes() (map[string]map[string]time.Time, error)
+	GetEmbeddingTimes() (map[string]time.Time, error)
+	SaveAxisProjections(slug string, raw map[string]float64) error
+	GetAxisPositions(slugs, mediaIDs []string) (map[string]map[string]float64, error)
+	GetMediaIDsOnAxis(slug string, lo, hi float64) (map[string]bool, error)
+}
+
+// JourneyRepository stores planned watch journeys
+type JourneyRepository interface {
+	CreateJourney(j *models.Journey) error
+	GetJourney(id string) (*models.Journey, error)
+	GetJourneyBySlug(slug string) (*models.Journey, error)
+	ListJourneys(userID string) ([]models.Journey, error)
+	DeleteJourney(id string) error
+	GetJourneySteps(journeyID string) ([]models.JourneyStep, error)
+}
+
+// FranchiseRepository stores franchises and their entries
+type FranchiseRepository interface {
+	ReplaceFranchise(f *models.Franchise) error
+	// GetFranchise returns nil when there is no such franchise
+	GetFranchise(id string) (*models.Franchise, error)
+	GetMediaFranchiseID(mediaID string) (string, error)
+	ListFranchises() ([]models.Franchise, error)
+	DeleteFranchise(id string) (bool, error)
+	GetFranchiseEntries(franchiseID string) ([]models.FranchiseEntry, error)
+	GetFranchisesOf(mediaIDs []string) (map[string]*models.Franchise, error)
+}
+
+// GroupRepository stores watch-party groups and their members
+type GroupRepository interface {
+	CreateGroup(g *models.Group, ownerNickname string) error
+	GetGroup(id string) (*models.Group, error)
+	GetGroupByInviteCode(code string) (*models.Group, error)
+	ListGroupsForUser(userID string) ([]models.Group, error)
+	GetGroupMembers(groupID string) ([]models.GroupMember, error)
+	AddGroupMember(groupID, userID, nickname string) error
+	RemoveGroupMember(groupID, userID string) (bool, error)
+}
+
+// FeedRepository stores per-session Atom/RSS feeds
+type FeedRepository interface {
+	CreateFeed(feed *models.Feed) error
+	GetFeed(id string) (*models.Feed, error)
+	GetFeedByUser(userID string) (*models.Feed, error)
+	DeleteFeedByUser(userID string) (bool, error)
+	RefreshFeed(feed *models.Feed, items []models.FeedItem, keep int) error
+	GetFeedItems(feed *models.Feed) ([]models.FeedItem, error)
+	GetNewMedia(userID string, limit int) ([]models.Media, error)
+}
+
+// JudgmentRepository stores Reddit similar_to judgments and /similar
+// quality runs
+type JudgmentRepository interface {
+	GetSimilarMentions() ([]models.SimilarMention, error)
+	ReplaceSimilarJudgments(judgments []models.SimilarJudgment) error
+	GetSimilarJudgments() (map[string][]models.SimilarJudgment, error)
+	CreateSimilarQualityRun(run *models.SimilarQualityRun) error
+	GetSimilarQualityRuns(limit int) ([]models.SimilarQualityRun, error)
+}
+
+// Repositories is every repository the server uses. DB implements it;
+// MemoryStore implements Store and FranchiseRepository.
+type Repositories interface {
+	Store
+	DismissalRepository
+	WatchlistRepository
+	ProgressRepository
+	TasteRepository
+	OnboardingRepository
+	ImpressionRepository
+	CollabRepository
+	RuntimeRepository
+	GemRepository
+	TrendingRepository
+	FacetRepository
+	CollectionRepository
+	AxisRepository
+	JourneyRepository
+	FranchiseRepository
+	GroupRepository
+	FeedRepository
+	JudgmentRepository
+}
+
+var (
+	_ Repositories        = (*DB)(nil)
+	_ Store               = (*MemoryStore)(nil)
+	_ FranchiseRepository = (*MemoryStore)(nil)
+)
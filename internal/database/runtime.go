@@ -0,0 +1,93 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Runtime Operations
+// ============================================================================
+
+// seriesClause matches media stored with episode data
+const seriesClause = `(episode_count > 0 OR episode_runtime > 0)`
+
+// GetMediaIDsWithinDuration returns the IDs of media that satisfy every set
+// field of the filter. Media with unknown durations never match.
+func (db *DB) GetMediaIDsWithinDuration(f models.DurationFilter) (map[string]bool, error) {
+	var where []string
+	var args []interface{}
+
+	if f.MaxMinutes > 0 {
+		where = append(where, `((NOT `+seriesClause+` AND runtime_minutes > 0 AND runtime_minutes <= ?)
+			OR (`+seriesClause+` AND episode_runtime > 0 AND episode_runtime <= ?))`)
+		args = append(args, f.MaxMinutes, f.MaxMinutes)
+	}
+	if f.MaxEpisodes > 0 {
+		where = append(where, `((NOT `+seriesClause+` AND runtime_minutes > 0)
+			OR (episode_count > 0 AND episode_count <= ?))`)
+		args = append(args, f.MaxEpisodes)
+	}
+	if f.Finishable {
+		where = append(where, `((NOT `+seriesClause+` AND runtime_minutes > 0 AND runtime_minutes <= ?)
+			OR (episode_count > 0 AND episode_runtime > 0 AND episode_count * episode_runtime <= ?))`)
+		args = append(args, models.WeekendMinutes, models.WeekendMinutes)
+	}
+
+	query := `SELECT id FROM media`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// GetMediaMissingDetails returns media that have an external ID but no
+// duration data or TMDB votes yet, for backfilling
+func (db *DB) GetMediaMissingDetails() ([]models.Media, error) {
+	rows, err := db.conn.Query(
+		`SELECT id, title, media_type, external_id FROM media
+		WHERE external_id != ''
+		AND ((runtime_minutes = 0 AND NOT ` + seriesClause + `) OR vote_count = 0)
+		ORDER BY id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []models.Media
+	for rows.Next() {
+		var m models.Media
+		if err := rows.Scan(&m.ID, &m.Title, &m.MediaType, &m.ExternalID); err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
+
+// SetMediaRuntime stores a media entry's duration fields
+func (db *DB) SetMediaRuntime(m *models.Media) error {
+	_, err := db.conn.Exec(
+		`UPDATE media SET runtime_minutes = ?, episode_runtime = ?, episode_count = ?, season_count = ?, updated_at = ?
+		WHERE id = ?`,
+		m.RuntimeMinutes, m.EpisodeRuntime, m.EpisodeCount, m.SeasonCount, time.Now(), m.ID,
+	)
+	return err
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestGetMediaIDsWithinDuration(t *testing.T) {
+	db := openMigrated(t)
+	for _, m := range []models.Media{
+		{ID: "film-90", MediaType: "movie", RuntimeMinutes: 90},
+		{ID: "film-150", MediaType: "movie", RuntimeMinutes: 150},
+		{ID: "film-unknown", MediaType: "movie"},
+		// 6 x 50 = 300 minutes in total
+		{ID: "mini", MediaType: "tv", EpisodeCount: 6, EpisodeRuntime: 50},
+		// 24 x 45 = 1080 minutes, more than a weekend
+		{ID: "long", MediaType: "tv", EpisodeCount: 24, EpisodeRuntime: 45},
+		// Episode length known, episode count not
+		{ID: "ongoing", MediaType: "anime", EpisodeRuntime: 24},
+	} {
+		m.Title, m.VibeProfile = m.ID, "vibe"
+		if err := db.CreateMedia(&m); err != nil {
+			t.Fatalf("CreateMedia %s: %v", m.ID, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter models.DurationFilter
+		want   string
+	}{
+		{"no filter", models.DurationFilter{}, "film-150 film-90 film-unknown long mini ongoing"},
+		// Films by runtime, series by episode runtime
+		{"under 2 hours", models.DurationFilter{MaxMinutes: 120}, "film-90 long mini ongoing"},
+		// Films with a known runtime pass an episode cap
+		{"short series", models.DurationFilter{MaxEpisodes: 10}, "film-150 film-90 mini"},
+		{"finishable", models.DurationFilter{Finishable: true}, "film-150 film-90 mini"},
+		{"every field", models.DurationFilter{MaxMinutes: 100, MaxEpisodes: 10, Finishable: true}, "film-90 mini"},
+	}
+	for _, tt := range tests {
+		ids, err := db.GetMediaIDsWithinDuration(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := make([]string, 0, len(ids))
+		for id := range ids {
+			got = append(got, id)
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
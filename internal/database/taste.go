@@ -0,0 +1,79 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Taste Profile Operations
+// ============================================================================
+
+// GetTasteCentroids returns a user's taste centroids ordered by index
+func (db *DB) GetTasteCentroids(userID string) ([]models.TasteCentroid, error) {
+	rows, err := db.conn.Query(
+		`SELECT user_id, idx, vector, weight_sum, members, updated_at
+		FROM taste_centroids WHERE user_id = ? ORDER BY idx`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var centroids []models.TasteCentroid
+	for rows.Next() {
+		var c models.TasteCentroid
+		var vecBytes []byte
+		var membersJSON string
+		if err := rows.Scan(&c.UserID, &c.Index, &vecBytes, &c.WeightSum, &membersJSON, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(vecBytes, &c.Vector); err != nil {
+			return nil, fmt.Errorf("failed to deserialize taste vector: %w", err)
+		}
+		if err := json.Unmarshal([]byte(membersJSON), &c.Members); err != nil {
+			return nil, fmt.Errorf("failed to deserialize taste members: %w", err)
+		}
+		centroids = append(centroids, c)
+	}
+	return centroids, rows.Err()
+}
+
+// ReplaceTasteCentroids atomically swaps a user's taste profile. Centroids
+// are re-indexed in the order given.
+func (db *DB) ReplaceTasteCentroids(userID string, centroids []models.TasteCentroid) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM taste_centroids WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i, c := range centroids {
+		vecBytes, err := json.Marshal(c.Vector)
+		if err != nil {
+			return fmt.Errorf("failed to serialize taste vector: %w", err)
+		}
+		membersJSON, err := json.Marshal(c.Members)
+		if err != nil {
+			return fmt.Errorf("failed to serialize taste members: %w", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO taste_centroids (user_id, idx, vector, weight_sum, members, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			userID, i, vecBytes, c.WeightSum, string(membersJSON), now,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Trending Operations
+// ============================================================================
+
+// GetMentionActivity returns every thread mention posted since the given
+// time, with the thread's engagement. Threads stored before posted_at was
+// recorded count from when they were scraped.
+func (db *DB) GetMentionActivity(since time.Time) ([]models.MentionActivity, error) {
+	since = since.UTC()
+	rows, err := db.conn.Query(
+		`SELECT rm.media_id, m.media_type, t.id, t.subreddit, t.title, COALESCE(t.thread_type, 'other'),
+		       COALESCE(t.score, 0), COALESCE(t.num_comments, 0), t.posted_at, t.scraped_at
+		FROM reddit_mentions rm
+		JOIN reddit_threads t ON t.id = rm.thread_id
+		JOIN media m ON m.id = rm.media_id
+		WHERE t.posted_at >= ? OR (t.posted_at IS NULL AND t.scraped_at >= ?)`,
+		since, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activity []models.MentionActivity
+	for rows.Next() {
+		var a models.MentionActivity
+		var posted, scraped sql.NullTime
+		if err := rows.Scan(&a.MediaID, &a.MediaType, &a.ThreadID, &a.Subreddit, &a.Title, &a.ThreadType,
+			&a.Score, &a.NumComments, &posted, &scraped); err != nil {
+			return nil, err
+		}
+		if posted.Valid {
+			a.PostedAt = posted.Time
+		} else {
+			a.PostedAt = scraped.Time
+		}
+		activity = append(activity, a)
+	}
+	return activity, rows.Err()
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Watchlist Operations
+// ============================================================================
+
+// AddToWatchlist saves a title at the end of the user's watchlist. Adding a
+// title that is already there updates its priority and note in place.
+func (db *DB) AddToWatchlist(item *models.WatchlistItem) error {
+	_, err := db.conn.Exec(
+		`INSERT INTO watchlist (user_id, media_id, priority, position, note, added_at)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(position) + 1, 0) FROM watchlist WHERE user_id = ?), ?, ?)
+		ON CONFLICT(user_id, media_id) DO UPDATE SET priority = excluded.priority, note = excluded.note`,
+		item.UserID, item.MediaID, item.Priority, item.UserID, item.Note, time.Now(),
+	)
+	return err
+}
+
+// UpdateWatchlistItem changes an item's priority and/or note. Returns false
+// if the item is not on the watchlist.
+func (db *DB) UpdateWatchlistItem(userID, mediaID string, priority *int, note *string) (bool, error) {
+	res, err := db.conn.Exec(
+		`UPDATE watchlist SET priority = COALESCE(?, priority), note = COALESCE(?, note)
+		WHERE user_id = ? AND media_id = ?`,
+		priority, note, userID, mediaID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RemoveFromWatchlist deletes an item. Returns false if it was not there.
+func (db *DB) RemoveFromWatchlist(userID, mediaID string) (bool, error) {
+	res, err := db.conn.Exec(`DELETE FROM watchlist WHERE user_id = ? AND media_id = ?`, userID, mediaID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ReorderWatchlist moves the given media to the top in the given order and
+// renumbers everything else after them, keeping their relative order
+func (db *DB) ReorderWatchlist(userID string, mediaIDs []string) error {
+	return db.reorderPositions("watchlist", "user_id", userID, mediaIDs)
+}
+
+// reorderPositions renumbers the position column of the rows owned by
+// ownerID: mediaIDs first, in order, then the rest in their current order.
+// IDs that are not present are ignored. table and ownerCol are trusted.
+func (db *DB) reorderPositions(table, ownerCol, ownerID string, mediaIDs []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(
+		fmt.Sprintf(`SELECT media_id FROM %s WHERE %s = ? ORDER BY position`, table, ownerCol),
+		ownerID,
+	)
+	if err != nil {
+		return err
+	}
+	var current []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		current = append(current, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	present := make(map[string]bool, len(current))
+	for _, id := range current {
+		present[id] = true
+	}
+	placed := make(map[string]bool, len(mediaIDs))
+	order := make([]string, 0, len(current))
+	for _, id := range mediaIDs {
+		if present[id] && !placed[id] {
+			order = append(order, id)
+			placed[id] = true
+		}
+	}
+	for _, id := range current {
+		if !placed[id] {
+			order = append(order, id)
+		}
+	}
+
+	update := fmt.Sprintf(`UPDATE %s SET position = ? WHERE %s = ? AND media_id = ?`, table, ownerCol)
+	for i, id := range order {
+		if _, err := tx.Exec(update, i, ownerID, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetWatchlist returns the user's watchlist with media details, in order
+func (db *DB) GetWatchlist(userID string) ([]models.WatchlistItem, error) {
+	rows, err := db.conn.Query(
+		`SELECT w.user_id, w.media_id, w.priority, w.position, w.note, w.added_at,
+		       m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
+		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
+		       m.runtime_minutes, m.episode_runtime, m.episode_count, m.season_count,
+		       m.created_at, m.updated_at
+		FROM watchlist w
+		JOIN media m ON w.media_id = m.id
+		WHERE w.user_id = ?
+		ORDER BY w.position`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.WatchlistItem
+	for rows.Next() {
+		var w models.WatchlistItem
+		var m models.Media
+		if err := rows.Scan(
+			&w.UserID, &w.MediaID, &w.Priority, &w.Position, &w.Note, &w.AddedAt,
+			&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary, &m.VibeProfile,
+			&m.QualityScore, &m.PopularityScore, &m.SourceSubreddit, &m.ExternalID,
+			&m.RuntimeMinutes, &m.EpisodeRuntime, &m.EpisodeCount, &m.SeasonCount,
+			&m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		w.Media = &m
+		items = append(items, w)
+	}
+	return items, rows.Err()
+}
+
+// GetWatchlistIDs returns just the media IDs on the user's watchlist
+func (db *DB) GetWatchlistIDs(userID string) (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT media_id FROM watchlist WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
@@ -143,6 +143,14 @@ func (vs *VectorStore) LoadFromMap(embeddings map[string][]float32) {
 	vs.vectors = embeddings
 }
 
+// Get returns the vector stored for an ID
+func (vs *VectorStore) Get(id string) ([]float32, bool) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+	vec, ok := vs.vectors[id]
+	return vec, ok
+}
+
 // Size returns the number of vectors in the store
 func (vs *VectorStore) Size() int {
 	vs.mu.RLock()
@@ -150,6 +158,15 @@ func (vs *VectorStore) Size() int {
 	return len(vs.vectors)
 }
 
+// Each calls fn for every stored vector. fn must not modify the store.
+func (vs *VectorStore) Each(fn func(id string, vec []float32)) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+	for id, vec := range vs.vectors {
+		fn(id, vec)
+	}
+}
+
 // SearchResult represents a single search result with similarity score
 type SearchResult struct {
 	MediaID    string
@@ -159,6 +176,12 @@ type SearchResult struct {
 // Search finds the top-k most similar vectors to the query
 // excludeIDs allows filtering out specific media (for anti-join of seen items)
 func (vs *VectorStore) Search(query []float32, topK int, excludeIDs map[string]bool) []SearchResult {
+	return vs.SearchWithin(query, topK, nil, excludeIDs)
+}
+
+// SearchWithin is Search restricted to an allow-list of IDs (e.g. media
+// matching a facet filter). A nil allowIDs means no restriction.
+func (vs *VectorStore) SearchWithin(query []float32, topK int, allowIDs, excludeIDs map[string]bool) []SearchResult {
 	vs.mu.RLock()
 	defer vs.mu.RUnlock()
 
@@ -169,6 +192,10 @@ func (vs *VectorStore) Search(query []float32, topK int, excludeIDs map[string]b
 	var results []SearchResult
 
 	for mediaID, embedding := range vs.vectors {
+		// Skip IDs outside the allow-list
+		if allowIDs != nil && !allowIDs[mediaID] {
+			continue
+		}
 		// Skip excluded IDs (anti-join for seen media)
 		if excludeIDs != nil && excludeIDs[mediaID] {
 			continue
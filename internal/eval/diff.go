@@ -0,0 +1,70 @@
+package eval
+
+// MetricDelta compares one config's metrics between a baseline and the
+// current run
+type MetricDelta struct {
+	Config   string
+	Baseline Metrics
+	Current  Metrics
+}
+
+// Delta returns current minus baseline for every metric
+func (d MetricDelta) Delta() Metrics {
+	return Metrics{
+		Recall:    d.Current.Recall - d.Baseline.Recall,
+		NDCG:      d.Current.NDCG - d.Baseline.NDCG,
+		MRR:       d.Current.MRR - d.Baseline.MRR,
+		Coverage:  d.Current.Coverage - d.Baseline.Coverage,
+		Diversity: d.Current.Diversity - d.Baseline.Diversity,
+	}
+}
+
+// QueryRegression is a golden query whose nDCG dropped against the baseline
+type QueryRegression struct {
+	Config   string
+	ID       string
+	Query    string
+	Baseline float64
+	Current  float64
+}
+
+// Diff pairs configs that appear in both runs (by name) and lists the
+// queries that got worse
+func Diff(baseline, current *Report) ([]MetricDelta, []QueryRegression) {
+	base := make(map[string]ConfigReport, len(baseline.Configs))
+	for _, c := range baseline.Configs {
+		base[c.Config.Name] = c
+	}
+
+	var deltas []MetricDelta
+	var regressions []QueryRegression
+	for _, cur := range current.Configs {
+		prev, ok := base[cur.Config.Name]
+		if !ok {
+			continue
+		}
+		deltas = append(deltas, MetricDelta{
+			Config:   cur.Config.Name,
+			Baseline: prev.Metrics,
+			Current:  cur.Metrics,
+		})
+
+		prevQueries := make(map[string]QueryResult, len(prev.Queries))
+		for _, q := range prev.Queries {
+			prevQueries[q.ID] = q
+		}
+		for _, q := range cur.Queries {
+			p, ok := prevQueries[q.ID]
+			if ok && q.Metrics.NDCG < p.Metrics.NDCG {
+				regressions = append(regressions, QueryRegression{
+					Config:   cur.Config.Name,
+					ID:       q.ID,
+					Query:    q.Query,
+					Baseline: p.Metrics.NDCG,
+					Current:  q.Metrics.NDCG,
+				})
+			}
+		}
+	}
+	return deltas, regressions
+}
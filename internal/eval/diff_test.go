@@ -0,0 +1,61 @@
+package eval
+
+import (
+	"math"
+	"testing"
+)
+
+func configReport(name string, m Metrics, ndcgByQuery map[string]float64) ConfigReport {
+	cr := ConfigReport{Config: RunConfig{Name: name}, Metrics: m}
+	for _, id := range []string{"q1", "q2"} {
+		if ndcg, ok := ndcgByQuery[id]; ok {
+			cr.Queries = append(cr.Queries, QueryResult{ID: id, Query: "query " + id, Metrics: Metrics{NDCG: ndcg}})
+		}
+	}
+	return cr
+}
+
+func TestDiff(t *testing.T) {
+	baseline := &Report{Configs: []ConfigReport{
+		configReport("vector", Metrics{Recall: 0.5, NDCG: 0.65, MRR: 0.5, Coverage: 0.2, Diversity: 0.3},
+			map[string]float64{"q1": 0.5, "q2": 0.8}),
+		configReport("mmr", Metrics{NDCG: 0.7}, map[string]float64{"q1": 0.7}),
+	}}
+	current := &Report{Configs: []ConfigReport{
+		configReport("vector", Metrics{Recall: 0.75, NDCG: 0.65, MRR: 0.25, Coverage: 0.3, Diversity: 0.3},
+			map[string]float64{"q1": 0.4, "q2": 0.9}),
+		// Only in the current run, so there is nothing to compare
+		configReport("hybrid", Metrics{NDCG: 0.1}, map[string]float64{"q1": 0.1}),
+	}}
+
+	deltas, regressions := Diff(baseline, current)
+
+	if len(deltas) != 1 || deltas[0].Config != "vector" {
+		t.Fatalf("deltas = %+v, want only the vector config", deltas)
+	}
+	want := Metrics{Recall: 0.25, NDCG: 0, MRR: -0.25, Coverage: 0.1, Diversity: 0}
+	got := deltas[0].Delta()
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"recall", got.Recall, want.Recall},
+		{"ndcg", got.NDCG, want.NDCG},
+		{"mrr", got.MRR, want.MRR},
+		{"coverage", got.Coverage, want.Coverage},
+		{"diversity", got.Diversity, want.Diversity},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s delta = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// q2 improved, so only q1 regressed
+	if len(regressions) != 1 {
+		t.Fatalf("regressions = %+v, want one", regressions)
+	}
+	r := regressions[0]
+	if r.Config != "vector" || r.ID != "q1" || r.Query != "query q1" || r.Baseline != 0.5 || r.Current != 0.4 {
+		t.Errorf("regression = %+v, want vector q1 from 0.5 to 0.4", r)
+	}
+}
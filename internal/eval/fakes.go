@@ -0,0 +1,108 @@
+package eval
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"w2w/internal/llm"
+)
+
+// hashDimensions is the width of HashEmbedder vectors
+const hashDimensions = 512
+
+// HashEmbedder is an offline embeddings.Provider: a hashed bag of words.
+// Texts sharing words land close together, which is enough to exercise the
+// pipeline deterministically with no network.
+type HashEmbedder struct{}
+
+// Embed hashes each word (and its 5-letter prefix, so "dreamy" and
+// "dreams" overlap) into a fixed-width vector and normalises it
+func (HashEmbedder) Embed(text string) ([]float32, error) {
+	vec := make([]float32, hashDimensions)
+	for _, w := range words(text) {
+		vec[bucket(w)] += 1
+		if len(w) > 5 {
+			vec[bucket(w[:5])] += 0.5
+		}
+	}
+
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum > 0 {
+		norm := float32(1 / math.Sqrt(sum))
+		for i := range vec {
+			vec[i] *= norm
+		}
+	}
+	return vec, nil
+}
+
+// ModelName identifies the fake in reports
+func (HashEmbedder) ModelName() string {
+	return "offline-hash"
+}
+
+// OverlapReranker is an offline stand-in for the LLM curator: it keeps the
+// top three candidates by query-word overlap with their vibe profile,
+// breaking ties on the incoming vibe score
+type OverlapReranker struct{}
+
+// RerankByVibe implements services.Reranker
+func (OverlapReranker) RerankByVibe(query string, candidates []llm.RerankCandidate) ([]llm.RerankResult, error) {
+	queryWords := make(map[string]bool)
+	for _, w := range words(query) {
+		queryWords[w] = true
+	}
+
+	type scored struct {
+		id      string
+		overlap int
+		vibe    float64
+	}
+	ranked := make([]scored, len(candidates))
+	for i, c := range candidates {
+		overlap := 0
+		for _, w := range words(c.Media.Title + " " + c.Media.VibeProfile) {
+			if queryWords[w] {
+				overlap++
+			}
+		}
+		ranked[i] = scored{id: c.Media.ID, overlap: overlap, vibe: c.VibeScore}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].overlap != ranked[j].overlap {
+			return ranked[i].overlap > ranked[j].overlap
+		}
+		return ranked[i].vibe > ranked[j].vibe
+	})
+
+	var results []llm.RerankResult
+	for i, r := range ranked {
+		if i >= 3 {
+			break
+		}
+		results = append(results, llm.RerankResult{
+			MediaID:     r.id,
+			Rank:        i + 1,
+			Explanation: "Offline rerank by word overlap",
+		})
+	}
+	return results, nil
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func bucket(w string) int {
+	h := fnv.New32a()
+	h.Write([]byte(w))
+	return int(h.Sum32() % hashDimensions)
+}
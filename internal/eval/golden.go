@@ -0,0 +1,121 @@
+// Package eval runs golden vibe queries through the search pipeline and
+// scores the results, so ranking changes can be compared before they ship.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// GoldenQuery is one query with the media IDs a good answer should contain
+type GoldenQuery struct {
+	ID       string   `json:"id" yaml:"id"`
+	Query    string   `json:"query" yaml:"query"`
+	Relevant []string `json:"relevant" yaml:"relevant"`
+	Facets   []string `json:"facets,omitempty" yaml:"facets,omitempty"`
+}
+
+// GoldenSet is a versioned list of golden queries
+type GoldenSet struct {
+	Name    string        `json:"name" yaml:"name"`
+	Queries []GoldenQuery `json:"queries" yaml:"queries"`
+}
+
+// RunConfig is one pipeline variant to evaluate
+type RunConfig struct {
+	Name          string  `json:"name" yaml:"name"`
+	TopK          int     `json:"top_k,omitempty" yaml:"top_k,omitempty"`
+	Rerank        bool    `json:"rerank,omitempty" yaml:"rerank,omitempty"`
+	LexicalWeight float64 `json:"lexical_weight,omitempty" yaml:"lexical_weight,omitempty"`
+	MMRLambda     float64 `json:"mmr_lambda,omitempty" yaml:"mmr_lambda,omitempty"`
+}
+
+// Plan is what to evaluate: the cutoff K and the variants to compare
+type Plan struct {
+	K       int         `json:"k" yaml:"k"`
+	Configs []RunConfig `json:"configs" yaml:"configs"`
+}
+
+// DefaultPlan compares plain vector search against each pipeline option
+func DefaultPlan() Plan {
+	return Plan{
+		K: 10,
+		Configs: []RunConfig{
+			{Name: "vector"},
+			{Name: "rerank", Rerank: true},
+			{Name: "hybrid", LexicalWeight: 0.3},
+			{Name: "mmr", MMRLambda: 0.7},
+		},
+	}
+}
+
+// LoadGoldenSet reads a golden set from a YAML or JSON file
+func LoadGoldenSet(path string) (*GoldenSet, error) {
+	var set GoldenSet
+	if err := decodeFile(path, &set); err != nil {
+		return nil, err
+	}
+	if len(set.Queries) == 0 {
+		return nil, fmt.Errorf("%s: no queries", path)
+	}
+	for i, q := range set.Queries {
+		if strings.TrimSpace(q.Query) == "" {
+			return nil, fmt.Errorf("%s: query %d is empty", path, i+1)
+		}
+		if len(q.Relevant) == 0 {
+			return nil, fmt.Errorf("%s: query %q lists no relevant media", path, q.Query)
+		}
+		if q.ID == "" {
+			set.Queries[i].ID = fmt.Sprintf("q%d", i+1)
+		}
+	}
+	if set.Name == "" {
+		set.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &set, nil
+}
+
+// LoadPlan reads run configs from a YAML or JSON file
+func LoadPlan(path string) (*Plan, error) {
+	plan := DefaultPlan()
+	plan.Configs = nil
+	if err := decodeFile(path, &plan); err != nil {
+		return nil, err
+	}
+	if len(plan.Configs) == 0 {
+		return nil, fmt.Errorf("%s: no configs", path)
+	}
+	seen := make(map[string]bool)
+	for _, c := range plan.Configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: every config needs a name", path)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("%s: duplicate config %q", path, c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return &plan, nil
+}
+
+// decodeFile unmarshals JSON for .json files and YAML for anything else
+func decodeFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, v)
+	} else {
+		err = yaml.Unmarshal(data, v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
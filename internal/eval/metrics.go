@@ -0,0 +1,86 @@
+package eval
+
+import (
+	"math"
+
+	"w2w/internal/embeddings"
+)
+
+// Metrics are the ranking-quality scores for one query, or their mean over
+// a run
+type Metrics struct {
+	Recall    float64 `json:"recall"`
+	NDCG      float64 `json:"ndcg"`
+	MRR       float64 `json:"mrr"`
+	Coverage  float64 `json:"coverage,omitempty"` // Run level only
+	Diversity float64 `json:"diversity"`          // Intra-list diversity
+}
+
+// recallAtK is the share of relevant IDs that appear in the top k results
+func recallAtK(results []string, relevant map[string]bool, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	hits := 0
+	for i, id := range results {
+		if i >= k {
+			break
+		}
+		if relevant[id] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(relevant))
+}
+
+// ndcgAtK is binary-relevance normalised discounted cumulative gain
+func ndcgAtK(results []string, relevant map[string]bool, k int) float64 {
+	var dcg float64
+	for i, id := range results {
+		if i >= k {
+			break
+		}
+		if relevant[id] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+
+	var ideal float64
+	for i := 0; i < len(relevant) && i < k; i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+	if ideal == 0 {
+		return 0
+	}
+	return dcg / ideal
+}
+
+// reciprocalRank is 1/rank of the first relevant result within k, or 0
+func reciprocalRank(results []string, relevant map[string]bool, k int) float64 {
+	for i, id := range results {
+		if i >= k {
+			break
+		}
+		if relevant[id] {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// intraListDiversity is the mean pairwise cosine distance between the
+// results' embeddings. Results without a vector are skipped.
+func intraListDiversity(vecs [][]float32) float64 {
+	var sum float64
+	pairs := 0
+	for i := 0; i < len(vecs); i++ {
+		for j := i + 1; j < len(vecs); j++ {
+			sum += 1 - embeddings.CosineSimilarity(vecs[i], vecs[j])
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return sum / float64(pairs)
+}
@@ -0,0 +1,105 @@
+package eval
+
+import (
+	"math"
+	"testing"
+)
+
+func relevantSet(ids ...string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func TestRankingMetrics(t *testing.T) {
+	// log2 discounts: rank 1 = 1, rank 2 = 0.6309, rank 3 = 0.5, rank 4 = 0.4307
+	tests := []struct {
+		name     string
+		results  []string
+		relevant map[string]bool
+		k        int
+		recall   float64
+		ndcg     float64
+		mrr      float64
+	}{
+		{
+			name:     "perfect",
+			results:  []string{"a", "b", "c"},
+			relevant: relevantSet("a", "b"),
+			k:        10,
+			recall:   1, ndcg: 1, mrr: 1,
+		},
+		{
+			// dcg 0.6309 over an ideal of 1 + 0.6309 + 0.5
+			name:     "one of three within k",
+			results:  []string{"a", "b", "c", "d"},
+			relevant: relevantSet("b", "d", "x"),
+			k:        3,
+			recall:   1.0 / 3, ndcg: 0.2960819, mrr: 0.5,
+		},
+		{
+			// dcg 0.6309 + 0.4307 over the same ideal
+			name:     "second hit inside a wider cutoff",
+			results:  []string{"a", "b", "c", "d"},
+			relevant: relevantSet("b", "d", "x"),
+			k:        4,
+			recall:   2.0 / 3, ndcg: 0.4981893, mrr: 0.5,
+		},
+		{
+			// dcg 0.5 over an ideal of 1 + 0.6309
+			name:     "hit at the cutoff",
+			results:  []string{"x", "y", "a"},
+			relevant: relevantSet("a", "b"),
+			k:        3,
+			recall:   0.5, ndcg: 0.3065736, mrr: 1.0 / 3,
+		},
+		{
+			name:     "hit past the cutoff",
+			results:  []string{"x", "y", "a"},
+			relevant: relevantSet("a"),
+			k:        2,
+		},
+		{
+			name:    "nothing relevant",
+			results: []string{"a"},
+			k:       10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recallAtK(tt.results, tt.relevant, tt.k); math.Abs(got-tt.recall) > 1e-6 {
+				t.Errorf("recall = %.7f, want %.7f", got, tt.recall)
+			}
+			if got := ndcgAtK(tt.results, tt.relevant, tt.k); math.Abs(got-tt.ndcg) > 1e-6 {
+				t.Errorf("ndcg = %.7f, want %.7f", got, tt.ndcg)
+			}
+			if got := reciprocalRank(tt.results, tt.relevant, tt.k); math.Abs(got-tt.mrr) > 1e-6 {
+				t.Errorf("mrr = %.7f, want %.7f", got, tt.mrr)
+			}
+		})
+	}
+}
+
+func TestIntraListDiversity(t *testing.T) {
+	tests := []struct {
+		name string
+		vecs [][]float32
+		want float64
+	}{
+		{"orthogonal pair", [][]float32{{1, 0}, {0, 1}}, 1},
+		{"identical pair", [][]float32{{1, 0}, {1, 0}}, 0},
+		// Pair distances 0, 1 and 1
+		{"duplicate plus one", [][]float32{{1, 0}, {1, 0}, {0, 1}}, 2.0 / 3},
+		// cos 45 degrees is 0.7071
+		{"diagonal", [][]float32{{1, 0}, {1, 1}}, 1 - math.Sqrt2/2},
+		{"single result", [][]float32{{1, 0}}, 0},
+		{"no results", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := intraListDiversity(tt.vecs); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: diversity = %.7f, want %.7f", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"w2w/internal/services"
+)
+
+// evalUserID is a user with no history, so nothing is filtered as seen
+const evalUserID = "eval"
+
+// QueryResult records what one golden query returned under one config
+type QueryResult struct {
+	ID      string   `json:"id"`
+	Query   string   `json:"query"`
+	Results []string `json:"results"`
+	Metrics Metrics  `json:"metrics"`
+}
+
+// ConfigReport is one config's mean metrics and per-query detail
+type ConfigReport struct {
+	Config  RunConfig     `json:"config"`
+	Metrics Metrics       `json:"metrics"`
+	Queries []QueryResult `json:"queries"`
+}
+
+// Report is a full evaluation run, saved as JSON so later runs can be
+// diffed against it
+type Report struct {
+	GoldenSet string         `json:"golden_set"`
+	Embedder  string         `json:"embedder"`
+	K         int            `json:"k"`
+	CreatedAt time.Time      `json:"created_at"`
+	Configs   []ConfigReport `json:"configs"`
+}
+
+// Runner evaluates a search service against a golden set
+type Runner struct {
+	svc      *services.VibeSearchService
+	embedder string
+}
+
+// NewRunner creates a runner; embedder names the model for the report
+func NewRunner(svc *services.VibeSearchService, embedder string) *Runner {
+	return &Runner{svc: svc, embedder: embedder}
+}
+
+// Run scores every config in the plan against the golden set
+func (r *Runner) Run(set *GoldenSet, plan Plan) (*Report, error) {
+	k := plan.K
+	if k <= 0 {
+		k = 10
+	}
+
+	report := &Report{
+		GoldenSet: set.Name,
+		Embedder:  r.embedder,
+		K:         k,
+		CreatedAt: time.Now(),
+	}
+	for _, cfg := range plan.Configs {
+		cr, err := r.runConfig(set, cfg, k)
+		if err != nil {
+			return nil, fmt.Errorf("config %s: %w", cfg.Name, err)
+		}
+		report.Configs = append(report.Configs, *cr)
+	}
+	return report, nil
+}
+
+func (r *Runner) runConfig(set *GoldenSet, cfg RunConfig, k int) (*ConfigReport, error) {
+	topK := cfg.TopK
+	if topK <= 0 {
+		topK = k * 2
+	}
+
+	cr := &ConfigReport{Config: cfg}
+	recommended := make(map[string]bool)
+
+	for _, q := range set.Queries {
+		result, err := r.svc.Search(services.SearchConfig{
+			UserID:        evalUserID,
+			Query:         q.Query,
+			TopK:          topK,
+			FinalResults:  k,
+			UseReranking:  cfg.Rerank,
+			Facets:        q.Facets,
+			LexicalWeight: cfg.LexicalWeight,
+			MMRLambda:     cfg.MMRLambda,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", q.ID, err)
+		}
+
+		relevant := make(map[string]bool, len(q.Relevant))
+		for _, id := range q.Relevant {
+			relevant[id] = true
+		}
+
+		ids := make([]string, len(result.Recommendations))
+		var vecs [][]float32
+		for i, rec := range result.Recommendations {
+			ids[i] = rec.Media.ID
+			recommended[rec.Media.ID] = true
+			if vec, ok := r.svc.Embedding(rec.Media.ID); ok {
+				vecs = append(vecs, vec)
+			}
+		}
+
+		qr := QueryResult{
+			ID:      q.ID,
+			Query:   q.Query,
+			Results: ids,
+			Metrics: Metrics{
+				Recall:    recallAtK(ids, relevant, k),
+				NDCG:      ndcgAtK(ids, relevant, k),
+				MRR:       reciprocalRank(ids, relevant, k),
+				Diversity: intraListDiversity(vecs),
+			},
+		}
+		cr.Queries = append(cr.Queries, qr)
+
+		cr.Metrics.Recall += qr.Metrics.Recall
+		cr.Metrics.NDCG += qr.Metrics.NDCG
+		cr.Metrics.MRR += qr.Metrics.MRR
+		cr.Metrics.Diversity += qr.Metrics.Diversity
+	}
+
+	n := float64(len(set.Queries))
+	cr.Metrics.Recall /= n
+	cr.Metrics.NDCG /= n
+	cr.Metrics.MRR /= n
+	cr.Metrics.Diversity /= n
+	if size := r.svc.IndexSize(); size > 0 {
+		cr.Metrics.Coverage = float64(len(recommended)) / float64(size)
+	}
+	return cr, nil
+}
+
+// Save writes the report as indented JSON
+func (rep *Report) Save(path string) error {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadReport reads a report saved by Save
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rep Report
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &rep, nil
+}
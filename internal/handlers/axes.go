@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Vibe Axis Endpoints
+// ============================================================================
+
+// axesUsage explains the ?axes= syntax of GET /vibe
+const axesUsage = "axes must be slug:target[:tolerance[:filter]] entries separated by commas, e.g. dark-light:0.2,slow-frenetic:0.8:0.1:filter"
+
+// GetAxes lists the vibe axes with how many titles sit on each
+// GET /axes
+func (h *Handler) GetAxes(c *gin.Context) {
+	axes, err := h.vibeSearch.Axes().List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list axes"})
+		return
+	}
+	if axes == nil {
+		axes = []models.VibeAxis{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(axes),
+		"axes":  axes,
+	})
+}
+
+// PostAxis defines or redefines a vibe axis and places every title on it
+// POST /admin/axes
+func (h *Handler) PostAxis(c *gin.Context) {
+	var req models.AxisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	axis, err := h.vibeSearch.DefineAxis(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Axis defined",
+		"axis":    axis,
+	})
+}
+
+// DeleteAxis removes a vibe axis
+// DELETE /admin/axes/:slug
+func (h *Handler) DeleteAxis(c *gin.Context) {
+	removed, err := h.vibeSearch.Axes().Delete(c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete axis"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Axis not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Axis deleted"})
+}
+
+// parseAxisTargets reads the ?axes= list of GET /vibe. Each entry is
+// slug:target, optionally followed by :tolerance and :filter (or :soft).
+func parseAxisTargets(value string) ([]models.AxisTarget, error) {
+	var targets []models.AxisTarget
+	for _, entry := range splitQueryList(value) {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 4 {
+			return nil, errors.New(axesUsage)
+		}
+		t := models.AxisTarget{Axis: parts[0]}
+		var err error
+		if t.Target, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return nil, errors.New(axesUsage)
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			if t.Tolerance, err = strconv.ParseFloat(parts[2], 64); err != nil {
+				return nil, errors.New(axesUsage)
+			}
+		}
+		if len(parts) > 3 {
+			t.Mode = parts[3]
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Bridge Endpoints
+// ============================================================================
+
+// maxBridgeRequestBytes bounds a bridge request body; a handful of IDs and
+// titles fits comfortably
+const maxBridgeRequestBytes = 16 << 10
+
+// PostBridge finds titles that sit between two or more anchors ("something
+// between Twin Peaks and Bojack"). With a group ID, titles any member has
+// seen or dismissed are left out; otherwise just the session's.
+// POST /bridge
+func (h *Handler) PostBridge(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBridgeRequestBytes)
+	var req models.BridgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	// Checked before any lookups; repeats are caught once anchors resolve
+	if n := len(req.MediaIDs) + len(req.Titles); n < 2 || n > services.MaxBridgeAnchors {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrAnchorCount.Error()})
+		return
+	}
+	limit := req.Limit
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+
+	userIDs := []string{userID}
+	if req.GroupID != "" {
+		group, err := h.vibeSearch.MemberGroup(userID, req.GroupID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if group == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		userIDs = userIDs[:0]
+		for _, m := range group.Members {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+
+	result, err := h.vibeSearch.Bridge(services.BridgeConfig{
+		UserIDs:  userIDs,
+		MediaIDs: req.MediaIDs,
+		Titles:   req.Titles,
+		Limit:    limit,
+	})
+	switch {
+	case errors.Is(err, services.ErrUnknownAnchor):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrAnchorCount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logImpression(c, models.SurfaceBridge, result.Query, map[string]interface{}{
+		"final_results": limit,
+		"media_ids":     req.MediaIDs,
+		"titles":        req.Titles,
+		"group_id":      req.GroupID,
+	}, result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"request_id":      middleware.GetRequestID(c),
+		"between":         result.Query,
+		"filtered_seen":   result.FilteredCount,
+		"recommendations": result.Recommendations,
+	})
+}
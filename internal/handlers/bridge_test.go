@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestPostBridge(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "peaks", Title: "Twin Peaks", MediaType: "tv", VibeProfile: "surreal small-town dread"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "bojack", Title: "BoJack Horseman", MediaType: "tv", VibeProfile: "sad-funny showbiz"}, []float32{0, 1, 0}},
+		testMedia{models.Media{ID: "fargo", Title: "Fargo", MediaType: "tv", VibeProfile: "dark midwestern comedy"}, []float32{0.7, 0.7, 0.1}},
+		testMedia{models.Media{ID: "lynch", Title: "Lost Highway", MediaType: "movie", VibeProfile: "surreal noir"}, []float32{0.95, 0.1, 0.3}},
+	)
+	env.router.POST("/bridge", env.h.PostBridge)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	var resp struct {
+		Between         string                  `json:"between"`
+		Recommendations []models.Recommendation `json:"recommendations"`
+		Error           string                  `json:"error"`
+	}
+	status := c.do(http.MethodPost, "/bridge", models.BridgeRequest{MediaIDs: []string{"peaks"}, Titles: []string{"bojack horseman"}}, &resp)
+	if status != http.StatusOK {
+		t.Fatalf("status %d: %s", status, resp.Error)
+	}
+	if resp.Between != "Twin Peaks + BoJack Horseman" {
+		t.Errorf("between = %q", resp.Between)
+	}
+	// Fargo sits near both anchors; Lost Highway only near one
+	if len(resp.Recommendations) != 2 || resp.Recommendations[0].Media.ID != "fargo" {
+		t.Fatalf("recommendations = %+v, want fargo first", resp.Recommendations)
+	}
+	if fits := resp.Recommendations[0].Bridge; len(fits) != 2 || fits[0].MediaID != "peaks" || fits[1].MediaID != "bojack" {
+		t.Errorf("fargo's anchor fits = %+v", fits)
+	}
+
+	tests := []struct {
+		name   string
+		req    models.BridgeRequest
+		status int
+	}{
+		{"one anchor", models.BridgeRequest{MediaIDs: []string{"peaks"}}, http.StatusBadRequest},
+		// Too many anchors is refused before any of them are looked up,
+		// so unknown IDs don't turn it into a 404
+		{"too many anchors", models.BridgeRequest{MediaIDs: []string{"a", "b", "c"}, Titles: []string{"d", "e", "f"}}, http.StatusBadRequest},
+		{"the same title twice", models.BridgeRequest{MediaIDs: []string{"peaks"}, Titles: []string{"Twin Peaks"}}, http.StatusBadRequest},
+		{"unknown anchor", models.BridgeRequest{MediaIDs: []string{"peaks", "nope"}}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := c.do(http.MethodPost, "/bridge", tt.req, nil); status != tt.status {
+				t.Errorf("status %d, want %d", status, tt.status)
+			}
+		})
+	}
+
+	t.Run("oversized body", func(t *testing.T) {
+		body := `{"titles": ["` + strings.Repeat("x", maxBridgeRequestBytes) + `", "y"]}`
+		resp, err := http.Post(server.URL+"/bridge", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Errorf("status %d, want %d", resp.StatusCode, http.StatusRequestEntityTooLarge)
+		}
+	})
+}
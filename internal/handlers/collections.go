@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Collection Endpoints
+// ============================================================================
+
+// PostCollection creates an empty collection
+// POST /collections
+func (h *Handler) PostCollection(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Title == nil || strings.TrimSpace(*req.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	var description string
+	if req.Description != nil {
+		description = *req.Description
+	}
+	public := req.Public != nil && *req.Public
+
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	collection, err := h.vibeSearch.Collections().Create(userID, strings.TrimSpace(*req.Title), description, public)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+// GetCollections lists the user's own collections
+// GET /collections
+func (h *Handler) GetCollections(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	collections, err := h.db.ListCollections(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+	if collections == nil {
+		collections = []models.Collection{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":       len(collections),
+		"collections": collections,
+	})
+}
+
+// GetCollection returns one of the user's collections with its items
+// GET /collections/:id
+func (h *Handler) GetCollection(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := h.vibeSearch.Collections().WithItems(collection); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// PatchCollection edits a collection's title, description or visibility
+// PATCH /collections/:id
+func (h *Handler) PatchCollection(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req models.CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	textChanged := false
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "title cannot be empty"})
+			return
+		}
+		textChanged = textChanged || title != collection.Title
+		collection.Title = title
+	}
+	if req.Description != nil {
+		textChanged = textChanged || *req.Description != collection.Description
+		collection.Description = *req.Description
+	}
+	if req.Public != nil {
+		collection.Public = *req.Public
+	}
+
+	if err := h.vibeSearch.Collections().Update(collection, textChanged); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// DeleteCollection deletes a collection
+// DELETE /collections/:id
+func (h *Handler) DeleteCollection(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := h.vibeSearch.Collections().Delete(collection.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted"})
+}
+
+// PostCollectionItem adds a title (with an optional note) to a collection
+// POST /collections/:id/items
+func (h *Handler) PostCollectionItem(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req models.CollectionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.MediaID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "media_id is required"})
+		return
+	}
+
+	media, err := h.db.GetMedia(req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+
+	if err := h.vibeSearch.Collections().AddItem(collection, req.MediaID, req.Note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Added to collection",
+		"media":        media.Title,
+		"vibe_summary": collection.VibeSummary,
+	})
+}
+
+// PatchCollectionItem changes the note on a collection item
+// PATCH /collections/:id/items/:media_id
+func (h *Handler) PatchCollectionItem(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req models.CollectionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	updated, err := h.vibeSearch.Collections().UpdateItemNote(collection, c.Param("media_id"), req.Note)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not in collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Note updated"})
+}
+
+// DeleteCollectionItem removes a title from a collection
+// DELETE /collections/:id/items/:media_id
+func (h *Handler) DeleteCollectionItem(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	removed, err := h.vibeSearch.Collections().RemoveItem(collection, c.Param("media_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not in collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from collection"})
+}
+
+// PostCollectionReorder moves the listed titles to the top, in order
+// POST /collections/:id/reorder
+func (h *Handler) PostCollectionReorder(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req models.WatchlistReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := h.db.ReorderCollection(collection.ID, req.MediaIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder"})
+		return
+	}
+	if err := h.vibeSearch.Collections().WithItems(collection); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+// GetCollectionSearch finds public collections (and your own) by vibe
+// GET /collections/search?q=comfort+for+rainy+days&limit=10
+func (h *Handler) GetCollectionSearch(c *gin.Context) {
+	query := c.Query("q")
+	userID := middleware.GetUserID(c)
+
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Describe the kind of list you want: ?q=your+vibe"})
+		return
+	}
+
+	matches, err := h.vibeSearch.Collections().Search(userID, query, collectionLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"input":       query,
+		"collections": matches,
+	})
+}
+
+// GetRecommendedCollections suggests public collections that fit your taste
+// GET /collections/recommended?limit=10
+func (h *Handler) GetRecommendedCollections(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	matches, err := h.vibeSearch.RecommendCollections(userID, collectionLimit(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := gin.H{"collections": matches}
+	if len(matches) == 0 {
+		resp["message"] = "Rate a few titles to get collection suggestions"
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetSharedCollection shows a collection by its slug: public collections to
+// anyone, private ones only to their owner
+// GET /shared/:slug
+func (h *Handler) GetSharedCollection(c *gin.Context) {
+	collection, ok := h.sharedCollection(c)
+	if !ok {
+		return
+	}
+
+	if err := h.vibeSearch.Collections().WithItems(collection); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": collection,
+		"owned":      collection.UserID == middleware.GetUserID(c),
+	})
+}
+
+// PostImportCollection copies a collection's unseen titles to your watchlist
+// POST /shared/:slug/import
+func (h *Handler) PostImportCollection(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	collection, ok := h.sharedCollection(c)
+	if !ok {
+		return
+	}
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	added, err := h.vibeSearch.Collections().ImportToWatchlist(userID, collection)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Imported to watchlist",
+		"added":   added,
+		"skipped": collection.ItemCount - added,
+	})
+}
+
+// ownedCollection loads the :id collection if it belongs to the session's
+// user, writing a 404 otherwise
+func (h *Handler) ownedCollection(c *gin.Context) (*models.Collection, bool) {
+	collection, err := h.vibeSearch.Collections().Owned(middleware.GetUserID(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	if collection == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return nil, false
+	}
+	return collection, true
+}
+
+// sharedCollection loads the :slug collection if the session may see it,
+// writing a 404 otherwise
+func (h *Handler) sharedCollection(c *gin.Context) (*models.Collection, bool) {
+	collection, err := h.vibeSearch.Collections().Shared(middleware.GetUserID(c), c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	if collection == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return nil, false
+	}
+	return collection, true
+}
+
+func collectionLimit(c *gin.Context) int {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	return limit
+}
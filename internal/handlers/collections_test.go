@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func collectionItemIDs(c models.Collection) string {
+	ids := make([]string, len(c.Items))
+	for i, item := range c.Items {
+		ids[i] = item.MediaID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestCollections(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "m1", Title: "Paddington 2", MediaType: "movie", VibeProfile: "warm marmalade kindness"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "m2", Title: "Totoro", MediaType: "anime", VibeProfile: "gentle rainy bus stop"}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "m3", Title: "Amelie", MediaType: "movie", VibeProfile: "whimsical paris"}, []float32{0.8, 0.2, 0}},
+	)
+	env.router.POST("/collections", env.h.PostCollection)
+	env.router.GET("/collections/search", env.h.GetCollectionSearch)
+	env.router.GET("/collections/:id", env.h.GetCollection)
+	env.router.PATCH("/collections/:id", env.h.PatchCollection)
+	env.router.POST("/collections/:id/items", env.h.PostCollectionItem)
+	env.router.DELETE("/collections/:id/items/:media_id", env.h.DeleteCollectionItem)
+	env.router.POST("/collections/:id/reorder", env.h.PostCollectionReorder)
+	env.router.GET("/shared/:slug", env.h.GetSharedCollection)
+	env.router.POST("/shared/:slug/import", env.h.PostImportCollection)
+	env.router.POST("/seen", env.h.PostSeen)
+	env.router.GET("/watchlist", env.h.GetWatchlist)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	ana, sam := newTestClient(t, server), newTestClient(t, server)
+
+	title, public := "Rainy day comfort", true
+	if status := ana.do(http.MethodPost, "/collections", models.CollectionRequest{}, nil); status != http.StatusBadRequest {
+		t.Errorf("untitled collection: status %d, want 400", status)
+	}
+	var comfort models.Collection
+	if status := ana.do(http.MethodPost, "/collections", models.CollectionRequest{Title: &title, Public: &public}, &comfort); status != http.StatusCreated {
+		t.Fatalf("create: status %d", status)
+	}
+	secret := "Guilty pleasures"
+	var private models.Collection
+	ana.do(http.MethodPost, "/collections", models.CollectionRequest{Title: &secret}, &private)
+	if comfort.Slug == "" || comfort.Slug == private.Slug {
+		t.Fatalf("slugs %q and %q, want distinct unguessable slugs", comfort.Slug, private.Slug)
+	}
+
+	for _, item := range []models.CollectionItemRequest{
+		{MediaID: "m1", Note: "the prison laundry scene"},
+		{MediaID: "m2"},
+		{MediaID: "m3"},
+	} {
+		if status := ana.do(http.MethodPost, "/collections/"+comfort.ID+"/items", item, nil); status != http.StatusOK {
+			t.Fatalf("adding %s: status %d", item.MediaID, status)
+		}
+	}
+	if status := ana.do(http.MethodPost, "/collections/"+comfort.ID+"/items", models.CollectionItemRequest{MediaID: "nope"}, nil); status != http.StatusNotFound {
+		t.Errorf("adding an unknown title: status %d, want 404", status)
+	}
+	ana.do(http.MethodPost, "/collections/"+private.ID+"/items", models.CollectionItemRequest{MediaID: "m3"}, nil)
+
+	var got models.Collection
+	ana.do(http.MethodGet, "/collections/"+comfort.ID, nil, &got)
+	if ids := collectionItemIDs(got); ids != "m1,m2,m3" {
+		t.Errorf("items = %s, want m1,m2,m3 in the order added", ids)
+	}
+	// Without an LLM the vibe is the title plus the item profiles
+	if !strings.HasPrefix(got.VibeSummary, "Rainy day comfort warm marmalade kindness") {
+		t.Errorf("vibe summary = %q", got.VibeSummary)
+	}
+
+	ana.do(http.MethodPost, "/collections/"+comfort.ID+"/reorder", models.WatchlistReorderRequest{MediaIDs: []string{"m3"}}, &got)
+	if ids := collectionItemIDs(got); ids != "m3,m1,m2" {
+		t.Errorf("after reorder items = %s, want m3,m1,m2", ids)
+	}
+
+	// Only the owner can see or edit a collection by ID
+	if status := sam.do(http.MethodGet, "/collections/"+comfort.ID, nil, nil); status != http.StatusNotFound {
+		t.Errorf("someone else's collection: status %d, want 404", status)
+	}
+	if status := sam.do(http.MethodDelete, "/collections/"+comfort.ID+"/items/m1", nil, nil); status != http.StatusNotFound {
+		t.Errorf("removing from someone else's collection: status %d, want 404", status)
+	}
+
+	// The slug opens public collections to anyone, private ones only to
+	// their owner
+	var shared struct {
+		Collection models.Collection `json:"collection"`
+		Owned      bool              `json:"owned"`
+	}
+	if status := sam.do(http.MethodGet, "/shared/"+comfort.Slug, nil, &shared); status != http.StatusOK || shared.Owned {
+		t.Errorf("public collection by slug: status %d, owned %v", status, shared.Owned)
+	}
+	if status := sam.do(http.MethodGet, "/shared/"+private.Slug, nil, nil); status != http.StatusNotFound {
+		t.Errorf("private collection by slug: status %d, want 404", status)
+	}
+	if status := ana.do(http.MethodGet, "/shared/"+private.Slug, nil, &shared); status != http.StatusOK || !shared.Owned {
+		t.Errorf("own private collection by slug: status %d, owned %v", status, shared.Owned)
+	}
+
+	t.Run("search", func(t *testing.T) {
+		var resp struct {
+			Collections []models.CollectionMatch `json:"collections"`
+		}
+		if status := sam.do(http.MethodGet, "/collections/search", nil, nil); status != http.StatusBadRequest {
+			t.Errorf("search without q: status %d, want 400", status)
+		}
+		sam.do(http.MethodGet, "/collections/search?q=cosy", nil, &resp)
+		if len(resp.Collections) != 1 || resp.Collections[0].Collection.ID != comfort.ID {
+			t.Errorf("Sam's search = %+v, want only the public collection", resp.Collections)
+		}
+		ana.do(http.MethodGet, "/collections/search?q=cosy", nil, &resp)
+		if len(resp.Collections) != 2 {
+			t.Errorf("Ana's search found %d collections, want both of theirs", len(resp.Collections))
+		}
+
+		// A collection made private drops out of other people's searches
+		hidden := false
+		ana.do(http.MethodPatch, "/collections/"+comfort.ID, models.CollectionRequest{Public: &hidden}, nil)
+		sam.do(http.MethodGet, "/collections/search?q=cosy", nil, &resp)
+		if len(resp.Collections) != 0 {
+			t.Errorf("Sam's search after unpublishing = %+v", resp.Collections)
+		}
+		ana.do(http.MethodPatch, "/collections/"+comfort.ID, models.CollectionRequest{Public: &public}, nil)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		if status := sam.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "m1"}, nil); status != http.StatusOK {
+			t.Fatalf("marking seen: status %d", status)
+		}
+		var resp struct {
+			Added   int `json:"added"`
+			Skipped int `json:"skipped"`
+		}
+		if status := sam.do(http.MethodPost, "/shared/"+comfort.Slug+"/import", nil, &resp); status != http.StatusOK {
+			t.Fatalf("import: status %d", status)
+		}
+		if resp.Added != 2 || resp.Skipped != 1 {
+			t.Errorf("import added %d, skipped %d; want 2 and 1 (m1 is seen)", resp.Added, resp.Skipped)
+		}
+
+		var list struct {
+			Watchlist []models.WatchlistItem `json:"watchlist"`
+		}
+		sam.do(http.MethodGet, "/watchlist", nil, &list)
+		if len(list.Watchlist) != 2 || list.Watchlist[0].MediaID != "m3" || list.Watchlist[1].MediaID != "m2" {
+			t.Fatalf("watchlist = %+v, want m3 then m2 in collection order", list.Watchlist)
+		}
+
+		// Importing again adds nothing new
+		sam.do(http.MethodPost, "/shared/"+comfort.Slug+"/import", nil, &resp)
+		if resp.Added != 0 {
+			t.Errorf("second import added %d", resp.Added)
+		}
+		if status := sam.do(http.MethodPost, "/shared/"+private.Slug+"/import", nil, nil); status != http.StatusNotFound {
+			t.Errorf("importing a private collection: status %d, want 404", status)
+		}
+	})
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Feed Endpoints
+// ============================================================================
+
+// feedSourceLabels name each feed item source for readers
+var feedSourceLabels = map[string]string{
+	models.FeedSourceForYou:   "For You",
+	models.FeedSourceTrending: "Trending",
+	models.FeedSourceNew:      "New",
+}
+
+// PostFeed creates the session's feed, replacing (and revoking) any earlier
+// feed URL
+// POST /feed
+func (h *Handler) PostFeed(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	feed := &models.Feed{ID: h.feedTokens.NewID(), UserID: userID, CreatedAt: time.Now()}
+	if err := h.db.CreateFeed(feed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, h.feedResponse(feed))
+}
+
+// GetFeedInfo returns the session's feed URLs
+// GET /feed
+func (h *Handler) GetFeedInfo(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	feed, err := h.db.GetFeedByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if feed == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No feed yet; POST /feed to create one"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.feedResponse(feed))
+}
+
+// DeleteFeed revokes the session's feed URL
+// DELETE /feed
+func (h *Handler) DeleteFeed(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	removed, err := h.db.DeleteFeedByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke feed"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No feed to revoke"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feed revoked"})
+}
+
+func (h *Handler) feedResponse(feed *models.Feed) gin.H {
+	base := h.feeds.BaseURL() + "/api/feeds/" + h.feedTokens.Sign(feed.ID)
+	return gin.H{
+		"atom_url":     base + "/atom.xml",
+		"rss_url":      base + "/rss.xml",
+		"created_at":   feed.CreatedAt,
+		"refreshed_at": feed.RefreshedAt,
+	}
+}
+
+// GetFeedAtom serves the feed as Atom
+// GET /feeds/:token/atom.xml
+func (h *Handler) GetFeedAtom(c *gin.Context) {
+	h.serveFeed(c, "application/atom+xml; charset=utf-8", renderAtom)
+}
+
+// GetFeedRSS serves the feed as RSS 2.0
+// GET /feeds/:token/rss.xml
+func (h *Handler) GetFeedRSS(c *gin.Context) {
+	h.serveFeed(c, "application/rss+xml; charset=utf-8", renderRSS)
+}
+
+// feedRenderer turns a feed's items into a document. base is the site URL.
+type feedRenderer func(feed *models.Feed, items []models.FeedItem, base string, ttl time.Duration) interface{}
+
+// serveFeed resolves the token, refreshes the picks if due, and answers
+// conditional requests with 304 so pollers stay cheap
+func (h *Handler) serveFeed(c *gin.Context, contentType string, render feedRenderer) {
+	id, ok := h.feedTokens.Verify(c.Param("token"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		return
+	}
+	feed, err := h.db.GetFeed(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if feed == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		return
+	}
+
+	items, err := h.feeds.Items(feed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build feed"})
+		return
+	}
+
+	refresh := h.feeds.RefreshInterval()
+	lastModified := feed.RefreshedAt.UTC().Truncate(time.Second)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(refresh.Seconds())))
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !lastModified.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	out, err := xml.MarshalIndent(render(feed, items, h.feeds.BaseURL(), refresh), "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render feed"})
+		return
+	}
+	c.Data(http.StatusOK, contentType, append([]byte(xml.Header), out...))
+}
+
+// feedEntryID is an item's permanent ID. It names the feed and the title,
+// so a pick republished on a later refresh is not a new entry.
+func feedEntryID(feed *models.Feed, mediaID string) string {
+	return "urn:w2w:feed:" + feed.ID + ":" + mediaID
+}
+
+func feedEntryTitle(item models.FeedItem) string {
+	if item.Media.Year > 0 {
+		return fmt.Sprintf("%s (%d)", item.Media.Title, item.Media.Year)
+	}
+	return item.Media.Title
+}
+
+func feedEntryText(item models.FeedItem) string {
+	if item.Media.PlotSummary == "" {
+		return item.Explanation
+	}
+	return item.Explanation + "\n\n" + item.Media.PlotSummary
+}
+
+// ----------------------------------------------------------------------------
+// Atom
+// ----------------------------------------------------------------------------
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string       `xml:"id"`
+	Title     string       `xml:"title"`
+	Published string       `xml:"published"`
+	Updated   string       `xml:"updated"`
+	Link      atomLink     `xml:"link"`
+	Category  atomCategory `xml:"category"`
+	Summary   atomText     `xml:"summary"`
+	Content   atomText     `xml:"content"`
+}
+
+func renderAtom(feed *models.Feed, items []models.FeedItem, base string, _ time.Duration) interface{} {
+	doc := atomFeed{
+		ID:      "urn:w2w:feed:" + feed.ID,
+		Title:   "w2w: fresh picks for you",
+		Updated: feed.RefreshedAt.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "w2w"},
+		Link:    atomLink{Href: base, Rel: "alternate"},
+	}
+	for _, item := range items {
+		published := item.PublishedAt.UTC().Format(time.RFC3339Nano)
+		doc.Entries = append(doc.Entries, atomEntry{
+			ID:        feedEntryID(feed, item.MediaID),
+			Title:     feedEntryTitle(item),
+			Published: published,
+			Updated:   published,
+			Link:      atomLink{Href: base + "/api/media/" + item.MediaID, Rel: "alternate"},
+			Category:  atomCategory{Term: item.Source, Label: feedSourceLabels[item.Source]},
+			Summary:   atomText{Type: "text", Body: item.Explanation},
+			Content:   atomText{Type: "text", Body: feedEntryText(item)},
+		})
+	}
+	return doc
+}
+
+// ----------------------------------------------------------------------------
+// RSS 2.0
+// ----------------------------------------------------------------------------
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"` // Minutes
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	Category    string  `xml:"category"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+func renderRSS(feed *models.Feed, items []models.FeedItem, base string, ttl time.Duration) interface{} {
+	doc := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         "w2w: fresh picks for you",
+			Link:          base,
+			Description:   "For-You, trending and new titles you have not seen yet",
+			LastBuildDate: feed.RefreshedAt.UTC().Format(time.RFC1123Z),
+			TTL:           int(ttl.Minutes()),
+		},
+	}
+	for _, item := range items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       feedEntryTitle(item),
+			Link:        base + "/api/media/" + item.MediaID,
+			Description: feedEntryText(item),
+			Category:    feedSourceLabels[item.Source],
+			GUID:        rssGUID{IsPermaLink: "false", Value: feedEntryID(feed, item.MediaID)},
+			PubDate:     item.PublishedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return doc
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+type feedURLs struct {
+	AtomURL string `json:"atom_url"`
+	RSSURL  string `json:"rss_url"`
+}
+
+// fetchFeed fetches a feed URL from the test server, returning the status
+// and the parsed document if it was served
+func fetchFeed(t *testing.T, server *httptest.Server, feedURL string, doc interface{}) int {
+	t.Helper()
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(server.URL + u.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK && doc != nil {
+		if err := xml.NewDecoder(resp.Body).Decode(doc); err != nil {
+			t.Fatalf("decoding %s: %v", u.Path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestFeedTokens(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "m1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}, []float32{1, 0, 0}},
+	)
+	env.router.POST("/feed", env.h.PostFeed)
+	env.router.DELETE("/feed", env.h.DeleteFeed)
+	env.router.GET("/api/feeds/:token/atom.xml", env.h.GetFeedAtom)
+	env.router.GET("/api/feeds/:token/rss.xml", env.h.GetFeedRSS)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	// A spoofed Host header doesn't leak into the URLs handed out. The jar
+	// files cookies under the Host, so the session goes in by hand.
+	if status := c.do(http.MethodPost, "/feed", nil, nil); status != http.StatusCreated {
+		t.Fatalf("POST /feed: status %d", status)
+	}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/feed", nil)
+	req.Host = "evil.example"
+	req.AddCookie(&http.Cookie{Name: middleware.SessionCookieName, Value: c.session()})
+	resp, err := c.http.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first feedURLs
+	err = json.NewDecoder(resp.Body).Decode(&first)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || err != nil {
+		t.Fatalf("POST /feed: status %d (%v)", resp.StatusCode, err)
+	}
+	if !strings.HasPrefix(first.AtomURL, "https://w2w.test/api/feeds/") || !strings.HasSuffix(first.RSSURL, "/rss.xml") {
+		t.Errorf("feed URLs = %+v, want them on the public base URL", first)
+	}
+	if status := fetchFeed(t, server, first.AtomURL, nil); status != http.StatusOK {
+		t.Errorf("atom feed: status %d", status)
+	}
+	if status := fetchFeed(t, server, first.RSSURL, nil); status != http.StatusOK {
+		t.Errorf("rss feed: status %d", status)
+	}
+
+	token := strings.TrimSuffix(strings.TrimPrefix(first.AtomURL, "https://w2w.test/api/feeds/"), "/atom.xml")
+	tampered := []byte(token)
+	if tampered[len(tampered)-1] == '0' {
+		tampered[len(tampered)-1] = '1'
+	} else {
+		tampered[len(tampered)-1] = '0'
+	}
+	for name, bad := range map[string]string{
+		"tampered token":       string(tampered),
+		"other secret":         middleware.NewFeedTokens("other-secret").Sign(strings.SplitN(token, ".", 2)[0]),
+		"session cookie value": c.session(),
+	} {
+		if status := fetchFeed(t, server, "/api/feeds/"+url.PathEscape(bad)+"/atom.xml", nil); status != http.StatusNotFound {
+			t.Errorf("%s: status %d, want 404", name, status)
+		}
+	}
+
+	// Posting again rotates the token, and deleting revokes it
+	var second feedURLs
+	c.do(http.MethodPost, "/feed", nil, &second)
+	if second.AtomURL == first.AtomURL {
+		t.Fatal("POST /feed did not rotate the feed URL")
+	}
+	if status := fetchFeed(t, server, first.AtomURL, nil); status != http.StatusNotFound {
+		t.Errorf("rotated-out feed: status %d, want 404", status)
+	}
+	if status := fetchFeed(t, server, second.AtomURL, nil); status != http.StatusOK {
+		t.Errorf("current feed: status %d", status)
+	}
+	if status := c.do(http.MethodDelete, "/feed", nil, nil); status != http.StatusOK {
+		t.Fatalf("DELETE /feed: status %d", status)
+	}
+	if status := fetchFeed(t, server, second.AtomURL, nil); status != http.StatusNotFound {
+		t.Errorf("revoked feed: status %d, want 404", status)
+	}
+}
+
+func TestFeedEntryIDsAreStable(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "m1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "m2", Title: "Contact", MediaType: "movie", VibeProfile: "hopeful wonder"}, []float32{0.9, 0.1, 0}},
+	)
+	// Refresh on every poll, so each fetch republishes the picks
+	env.h.feeds = services.NewFeedService(env.db, env.svc, 0, "https://w2w.test/")
+	env.router.POST("/feed", env.h.PostFeed)
+	env.router.GET("/api/feeds/:token/atom.xml", env.h.GetFeedAtom)
+	env.router.GET("/api/feeds/:token/rss.xml", env.h.GetFeedRSS)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	var urls feedURLs
+	if status := c.do(http.MethodPost, "/feed", nil, &urls); status != http.StatusCreated {
+		t.Fatalf("POST /feed: status %d", status)
+	}
+	if !strings.HasPrefix(urls.AtomURL, "https://w2w.test/api/") {
+		t.Errorf("atom URL = %s, want the trailing slash trimmed", urls.AtomURL)
+	}
+	userID := c.session()[:strings.LastIndex(c.session(), ".")]
+	feed, err := env.db.GetFeedByUser(userID)
+	if err != nil || feed == nil {
+		t.Fatalf("GetFeedByUser: %v", err)
+	}
+
+	var before, after atomFeed
+	fetchFeed(t, server, urls.AtomURL, &before)
+	time.Sleep(10 * time.Millisecond)
+	fetchFeed(t, server, urls.AtomURL, &after)
+	if len(before.Entries) != 2 {
+		t.Fatalf("feed has %d entries, want both new titles", len(before.Entries))
+	}
+	published := make(map[string]string)
+	for _, e := range before.Entries {
+		published[e.ID] = e.Published
+	}
+	for _, e := range after.Entries {
+		if published[e.ID] != e.Published {
+			t.Errorf("entry %s republished at %s, first published %s", e.ID, e.Published, published[e.ID])
+		}
+		if !strings.HasPrefix(e.ID, "urn:w2w:feed:"+feed.ID+":") {
+			t.Errorf("entry ID %s does not name the feed and title", e.ID)
+		}
+		if !strings.HasPrefix(e.Link.Href, "https://w2w.test/api/media/") {
+			t.Errorf("entry link %s is not on the public base URL", e.Link.Href)
+		}
+	}
+	if len(after.Entries) != len(before.Entries) {
+		t.Errorf("a refresh turned %d entries into %d", len(before.Entries), len(after.Entries))
+	}
+
+	// RSS readers see the same IDs as GUIDs
+	var rss rssFeed
+	fetchFeed(t, server, urls.RSSURL, &rss)
+	for _, item := range rss.Channel.Items {
+		if _, ok := published[item.GUID.Value]; !ok || item.GUID.IsPermaLink != "false" {
+			t.Errorf("rss guid %+v does not match an atom entry", item.GUID)
+		}
+	}
+}
+
+// session returns the client's signed session cookie value
+func (c *testClient) session() string {
+	base, _ := url.Parse(c.base)
+	for _, cookie := range c.http.Jar.Cookies(base) {
+		if cookie.Name == middleware.SessionCookieName {
+			return cookie.Value
+		}
+	}
+	c.t.Fatal("no session cookie")
+	return ""
+}
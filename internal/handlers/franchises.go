@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Franchise Endpoints
+// ============================================================================
+
+// GetFranchises lists every franchise with how many titles it holds
+// GET /franchises
+func (h *Handler) GetFranchises(c *gin.Context) {
+	franchises, err := h.vibeSearch.Franchises().List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list franchises"})
+		return
+	}
+	if franchises == nil {
+		franchises = []models.Franchise{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(franchises),
+		"franchises": franchises,
+	})
+}
+
+// GetFranchise shows a franchise's watch order, with the titles you have
+// seen and where you should start
+// GET /franchises/:id
+func (h *Handler) GetFranchise(c *gin.Context) {
+	h.writeWatchOrder(c, c.Param("id"))
+}
+
+// GetMediaFranchise shows the watch order of the franchise a title belongs to
+// GET /media/:id/franchise
+func (h *Handler) GetMediaFranchise(c *gin.Context) {
+	id, err := h.vibeSearch.Franchises().OfMedia(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch franchise"})
+		return
+	}
+	if id == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Title is not part of a franchise"})
+		return
+	}
+	h.writeWatchOrder(c, id)
+}
+
+func (h *Handler) writeWatchOrder(c *gin.Context, id string) {
+	userID := middleware.GetUserID(c)
+
+	f, err := h.vibeSearch.Franchises().WatchOrder(userID, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch franchise"})
+		return
+	}
+	if f == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Franchise not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, f)
+}
+
+// PostFranchise links titles into a franchise by hand, e.g. a series whose
+// seasons are separate titles, or its spin-offs
+// POST /admin/franchises
+func (h *Handler) PostFranchise(c *gin.Context) {
+	var req models.FranchiseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	f, err := h.vibeSearch.Franchises().Define(req)
+	if errors.Is(err, services.ErrFranchiseEntry) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save franchise"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Franchise saved",
+		"franchise": f,
+	})
+}
+
+// DeleteFranchise removes a franchise, leaving its titles standalone
+// DELETE /admin/franchises/:id
+func (h *Handler) DeleteFranchise(c *gin.Context) {
+	removed, err := h.vibeSearch.Franchises().Delete(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete franchise"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Franchise not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Franchise deleted"})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Watch-Party Group Endpoints
+// ============================================================================
+
+// PostGroup creates a group and returns its invite code
+// POST /groups
+func (h *Handler) PostGroup(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	group, err := h.vibeSearch.CreateGroup(userID, strings.TrimSpace(req.Name), strings.TrimSpace(req.Nickname))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// PostJoinGroup joins a group by invite code
+// POST /groups/join
+func (h *Handler) PostJoinGroup(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.GroupJoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invite_code is required"})
+		return
+	}
+
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	group, err := h.vibeSearch.JoinGroup(userID, req.InviteCode, strings.TrimSpace(req.Nickname))
+	if errors.Is(err, services.ErrGroupFull) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Group is full"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join group"})
+		return
+	}
+	if group == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown invite code"})
+		return
+	}
+
+	group, err = h.vibeSearch.MemberGroup(userID, group.ID)
+	if err != nil || group == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// GetGroups lists the groups the user belongs to
+// GET /groups
+func (h *Handler) GetGroups(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	groups, err := h.db.ListGroupsForUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+		return
+	}
+	if groups == nil {
+		groups = []models.Group{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":  len(groups),
+		"groups": groups,
+	})
+}
+
+// GetGroup returns one of the user's groups with its members
+// GET /groups/:id
+func (h *Handler) GetGroup(c *gin.Context) {
+	group, ok := h.memberGroup(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroupMembership leaves a group; the last member out deletes it
+// DELETE /groups/:id/members/me
+func (h *Handler) DeleteGroupMembership(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	left, err := h.db.RemoveGroupMember(c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave group"})
+		return
+	}
+	if !left {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	h.rooms.RemoveMember(c.Param("id"), userID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left group"})
+}
+
+// GetGroupRecommend picks titles for the whole group
+// GET /groups/:id/recommend?q=something+fun&strategy=least_misery&limit=5
+func (h *Handler) GetGroupRecommend(c *gin.Context) {
+	group, ok := h.memberGroup(c)
+	if !ok {
+		return
+	}
+
+	strategy := c.DefaultQuery("strategy", models.GroupStrategyAverage)
+	if !services.ValidGroupStrategy(strategy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "strategy must be average, least_misery or most_pleasure"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	result, err := h.vibeSearch.GroupRecommend(group, query, strategy, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp := gin.H{
+		"group":           group.Name,
+		"strategy":        strategy,
+		"members":         len(group.Members),
+		"filtered_seen":   result.FilteredCount,
+		"recommendations": result.Recommendations,
+	}
+	if len(result.Recommendations) == 0 && query == "" {
+		resp["message"] = "Nobody in the group has rated anything yet — add a vibe with ?q="
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// memberGroup loads the :id group if the session's user belongs to it,
+// writing a 404 otherwise
+func (h *Handler) memberGroup(c *gin.Context) (*models.Group, bool) {
+	group, err := h.vibeSearch.MemberGroup(middleware.GetUserID(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	if group == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return nil, false
+	}
+	return group, true
+}
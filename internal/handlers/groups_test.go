@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+type groupPicks struct {
+	Members         int                     `json:"members"`
+	FilteredSeen    int                     `json:"filtered_seen"`
+	Recommendations []models.Recommendation `json:"recommendations"`
+}
+
+func TestGroups(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "ana-fav", Title: "Paddington 2", MediaType: "movie", VibeProfile: "warm"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "sam-fav", Title: "Alien", MediaType: "movie", VibeProfile: "dread"}, []float32{0, 1, 0}},
+		testMedia{models.Media{ID: "for-ana", Title: "Amelie", MediaType: "movie", VibeProfile: "whimsy"}, []float32{0.99, 0.05, 0}},
+		testMedia{models.Media{ID: "for-sam", Title: "The Thing", MediaType: "movie", VibeProfile: "cold dread"}, []float32{0.05, 0.99, 0}},
+		testMedia{models.Media{ID: "for-both", Title: "Gremlins", MediaType: "movie", VibeProfile: "cosy horror"}, []float32{0.7, 0.7, 0}},
+	)
+	env.router.POST("/seen", env.h.PostSeen)
+	env.router.POST("/groups", env.h.PostGroup)
+	env.router.POST("/groups/join", env.h.PostJoinGroup)
+	env.router.GET("/groups", env.h.GetGroups)
+	env.router.GET("/groups/:id", env.h.GetGroup)
+	env.router.DELETE("/groups/:id/members/me", env.h.DeleteGroupMembership)
+	env.router.GET("/groups/:id/recommend", env.h.GetGroupRecommend)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	ana, sam, lee, outsider := newTestClient(t, server), newTestClient(t, server), newTestClient(t, server), newTestClient(t, server)
+
+	if status := ana.do(http.MethodPost, "/groups", models.GroupRequest{Name: "  "}, nil); status != http.StatusBadRequest {
+		t.Errorf("unnamed group: status %d, want 400", status)
+	}
+	var group models.Group
+	if status := ana.do(http.MethodPost, "/groups", models.GroupRequest{Name: "Friday", Nickname: "Ana"}, &group); status != http.StatusCreated {
+		t.Fatalf("create: status %d", status)
+	}
+	if len(group.InviteCode) != 6 {
+		t.Errorf("invite code %q", group.InviteCode)
+	}
+
+	// Codes are read out loud, so case and stray spaces don't matter
+	if status := sam.do(http.MethodPost, "/groups/join", models.GroupJoinRequest{InviteCode: " " + strings.ToLower(group.InviteCode)}, &group); status != http.StatusOK {
+		t.Fatalf("join: status %d", status)
+	}
+	if status := lee.do(http.MethodPost, "/groups/join", models.GroupJoinRequest{InviteCode: group.InviteCode, Nickname: "Lee"}, nil); status != http.StatusOK {
+		t.Fatalf("join: status %d", status)
+	}
+	if status := outsider.do(http.MethodPost, "/groups/join", models.GroupJoinRequest{InviteCode: "ZZZZZZ"}, nil); status != http.StatusNotFound {
+		t.Errorf("unknown invite code: status %d, want 404", status)
+	}
+	if status := outsider.do(http.MethodGet, "/groups/"+group.ID, nil, nil); status != http.StatusNotFound {
+		t.Errorf("someone else's group: status %d, want 404", status)
+	}
+	ana.do(http.MethodGet, "/groups/"+group.ID, nil, &group)
+	if len(group.Members) != 3 || group.Members[1].Nickname != "Member 1" || !group.Members[0].IsYou || group.Members[1].IsYou {
+		t.Errorf("members = %+v, want Ana (you), Member 1 and Lee", group.Members)
+	}
+
+	// Ana and Sam each love one title; Lee has no history
+	nine := 9.0
+	ana.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "ana-fav", Rating: &nine}, nil)
+	sam.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "sam-fav", Rating: &nine}, nil)
+
+	if status := ana.do(http.MethodGet, "/groups/"+group.ID+"/recommend?strategy=chaos", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown strategy: status %d, want 400", status)
+	}
+	if status := outsider.do(http.MethodGet, "/groups/"+group.ID+"/recommend", nil, nil); status != http.StatusNotFound {
+		t.Errorf("someone else's picks: status %d, want 404", status)
+	}
+
+	picks := func(c *testClient, query string) groupPicks {
+		t.Helper()
+		var resp groupPicks
+		if status := c.do(http.MethodGet, "/groups/"+group.ID+"/recommend?"+query, nil, &resp); status != http.StatusOK {
+			t.Fatalf("recommend?%s: status %d", query, status)
+		}
+		return resp
+	}
+
+	t.Run("exclusions and per-member fits", func(t *testing.T) {
+		resp := picks(lee, "strategy=least_misery")
+		if resp.Members != 3 || resp.FilteredSeen != 2 {
+			t.Errorf("members %d, filtered %d; want 3 and both members' seen titles", resp.Members, resp.FilteredSeen)
+		}
+		if len(resp.Recommendations) != 3 {
+			t.Fatalf("got %d picks, want the three unseen titles", len(resp.Recommendations))
+		}
+		for _, r := range resp.Recommendations {
+			if r.Media.ID == "ana-fav" || r.Media.ID == "sam-fav" {
+				t.Errorf("%s was seen by a member", r.Media.ID)
+			}
+		}
+		best := resp.Recommendations[0]
+		if best.Media.ID != "for-both" {
+			t.Fatalf("least misery picked %s first, want for-both", best.Media.ID)
+		}
+		fits := best.MemberFits
+		if len(fits) != 3 || fits[0].Fit == nil || fits[1].Fit == nil || fits[2].Fit != nil {
+			t.Fatalf("fits = %+v, want Ana and Member 1 scored and Lee unknown", fits)
+		}
+		if math.Abs(*fits[0].Fit-1/math.Sqrt2) > 1e-3 || math.Abs(*fits[1].Fit-1/math.Sqrt2) > 1e-3 {
+			t.Errorf("for-both fits %v and %v, want about 0.707 each", *fits[0].Fit, *fits[1].Fit)
+		}
+		if math.Abs(best.Score-*fits[0].Fit) > 1e-9 && math.Abs(best.Score-*fits[1].Fit) > 1e-9 {
+			t.Errorf("least misery score %v is neither member's fit", best.Score)
+		}
+	})
+
+	t.Run("most pleasure favours one member's match", func(t *testing.T) {
+		resp := picks(ana, "strategy=most_pleasure")
+		if len(resp.Recommendations) == 0 || resp.Recommendations[0].Media.ID == "for-both" {
+			t.Fatalf("most pleasure picks = %v, want a single member's match first", resp.Recommendations)
+		}
+	})
+
+	t.Run("a query gives members without history a fit", func(t *testing.T) {
+		resp := picks(ana, "q=something+warm")
+		for _, r := range resp.Recommendations {
+			if len(r.MemberFits) != 3 || r.MemberFits[2].Fit == nil {
+				t.Fatalf("%s fits = %+v, want Lee scored by the query", r.Media.ID, r.MemberFits)
+			}
+		}
+	})
+
+	// Leaving drops the group from your list; the last one out deletes it
+	for _, c := range []*testClient{sam, lee, ana} {
+		if status := c.do(http.MethodDelete, "/groups/"+group.ID+"/members/me", nil, nil); status != http.StatusOK {
+			t.Fatalf("leave: status %d", status)
+		}
+	}
+	var list struct {
+		Count int `json:"count"`
+	}
+	ana.do(http.MethodGet, "/groups", nil, &list)
+	if list.Count != 0 {
+		t.Errorf("Ana still lists %d groups after leaving", list.Count)
+	}
+	if status := ana.do(http.MethodPost, "/groups/join", models.GroupJoinRequest{InviteCode: group.InviteCode}, nil); status != http.StatusNotFound {
+		t.Errorf("joining a deleted group: status %d, want 404", status)
+	}
+}
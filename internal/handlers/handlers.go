@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,19 +14,42 @@ import (
 	"w2w/internal/services"
 )
 
+// Store is what the handlers read and write directly; everything else goes
+// through the services
+type Store interface {
+	database.Store
+	database.DismissalRepository
+	database.WatchlistRepository
+	database.ProgressRepository
+	database.CollectionRepository
+	database.GroupRepository
+	database.FeedRepository
+	database.JudgmentRepository
+}
+
 // Handler holds dependencies for HTTP handlers
 type Handler struct {
-	db         *database.DB
-	vibeSearch *services.VibeSearchService
-	scraper    *services.RedditScraper
+	db          Store
+	vibeSearch  *services.VibeSearchService
+	scraper     *services.RedditScraper
+	impressions *services.ImpressionLogger
+	judgments   *services.JudgmentJob
+	rooms       *services.RoomHub
+	feeds       *services.FeedService
+	feedTokens  *middleware.FeedTokens
 }
 
 // NewHandler creates a new handler with dependencies
-func NewHandler(db *database.DB, vibeSearch *services.VibeSearchService, scraper *services.RedditScraper) *Handler {
+func NewHandler(db Store, vibeSearch *services.VibeSearchService, scraper *services.RedditScraper, impressions *services.ImpressionLogger, judgments *services.JudgmentJob, rooms *services.RoomHub, feeds *services.FeedService, feedTokens *middleware.FeedTokens) *Handler {
 	return &Handler{
-		db:         db,
-		vibeSearch: vibeSearch,
-		scraper:    scraper,
+		db:          db,
+		vibeSearch:  vibeSearch,
+		scraper:     scraper,
+		impressions: impressions,
+		judgments:   judgments,
+		rooms:       rooms,
+		feeds:       feeds,
+		feedTokens:  feedTokens,
 	}
 }
 
@@ -31,7 +57,7 @@ func NewHandler(db *database.DB, vibeSearch *services.VibeSearchService, scraper
 // Seen Media Endpoints
 // ============================================================================
 
-// PostSeen marks a media item as seen by the user
+// PostSeen marks a media item as seen (completed) by the user
 // POST /seen
 func (h *Handler) PostSeen(c *gin.Context) {
 	userID := middleware.GetUserID(c)
@@ -43,22 +69,9 @@ func (h *Handler) PostSeen(c *gin.Context) {
 	}
 
 	// Verify the session's user exists (or create on first write)
-	user, err := h.db.GetUser(userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	if !h.ensureUser(c, userID) {
 		return
 	}
-	if user == nil {
-		user = &models.User{
-			ID:        userID,
-			Username:  userID,
-			CreatedAt: time.Now(),
-		}
-		if err := h.db.CreateUser(user); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
-			return
-		}
-	}
 
 	// Verify media exists
 	media, err := h.db.GetMedia(req.MediaID)
@@ -84,6 +97,11 @@ func (h *Handler) PostSeen(c *gin.Context) {
 		return
 	}
 
+	// Keep the taste profile in step; the seen row is already saved
+	if err := h.vibeSearch.UpdateTasteOnSeen(userID, req.MediaID, req.Rating, models.WatchCompleted); err != nil {
+		log.Printf("Failed to update taste profile for %s: %v", userID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Marked as seen",
 		"media":   media.Title,
@@ -119,16 +137,142 @@ func (h *Handler) DeleteSeen(c *gin.Context) {
 		return
 	}
 
-	_, err := h.db.Exec(`DELETE FROM seen_media WHERE user_id = ? AND media_id = ?`,
-		userID, req.MediaID)
-	if err != nil {
+	if _, err := h.db.RemoveSeen(userID, req.MediaID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove"})
 		return
 	}
 
+	if err := h.vibeSearch.UpdateTasteOnUnseen(userID, req.MediaID); err != nil {
+		log.Printf("Failed to update taste profile for %s: %v", userID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Removed from seen list"})
 }
 
+// ensureUser creates the session's user on first write. It writes the error
+// response and returns false on failure.
+func (h *Handler) ensureUser(c *gin.Context, userID string) bool {
+	user, err := h.db.GetUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	if user == nil {
+		user = &models.User{
+			ID:        userID,
+			Username:  userID,
+			CreatedAt: time.Now(),
+		}
+		if err := h.db.CreateUser(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================================
+// Dismissal Endpoints
+// ============================================================================
+
+// PostDismissal hides a title from the user's recommendations
+// POST /dismissals
+func (h *Handler) PostDismissal(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.DismissRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	switch req.Reason {
+	case models.DismissNotInterested, models.DismissAlreadyKnow:
+		if req.Until != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until is only valid for snooze"})
+			return
+		}
+	case models.DismissSnooze:
+		if req.Until == nil || !req.Until.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "snooze requires a future until"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason must be not_interested, already_know or snooze"})
+		return
+	}
+
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	media, err := h.db.GetMedia(req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+
+	dismissal := &models.Dismissal{
+		UserID:  userID,
+		MediaID: req.MediaID,
+		Reason:  req.Reason,
+		Until:   req.Until,
+	}
+	if err := h.db.Dismiss(dismissal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dismiss"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dismissed",
+		"media":   media.Title,
+		"reason":  req.Reason,
+	})
+}
+
+// GetDismissals lists the user's dismissals
+// GET /dismissals?include_expired=true
+func (h *Handler) GetDismissals(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	includeExpired := c.Query("include_expired") == "true"
+
+	dismissals, err := h.db.GetDismissals(userID, includeExpired)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch dismissals"})
+		return
+	}
+	if dismissals == nil {
+		dismissals = []models.Dismissal{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":      len(dismissals),
+		"dismissals": dismissals,
+	})
+}
+
+// DeleteDismissal undoes a dismissal
+// DELETE /dismissals/:media_id
+func (h *Handler) DeleteDismissal(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	removed, err := h.db.Undismiss(userID, c.Param("media_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dismissal not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dismissal removed"})
+}
+
 // ============================================================================
 // Recommendation Endpoints
 // ============================================================================
@@ -150,29 +294,91 @@ func (h *Handler) PostRecommend(c *gin.Context) {
 		limit = 10
 	}
 
+	if err := h.vibeSearch.Facets().ValidateSlugs(req.Facets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var personalization float64
+	if req.Personalization != nil {
+		if *req.Personalization < 0 || *req.Personalization > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "personalization must be between 0 and 1"})
+			return
+		}
+		personalization = *req.Personalization
+	}
+	if req.RatingSignal != nil && (*req.RatingSignal < 0 || *req.RatingSignal > 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rating_signal must be between 0 and 1"})
+		return
+	}
+	watchlist, ok := services.ParseWatchlistMode(req.Watchlist)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "watchlist must be exclude or highlight"})
+		return
+	}
+	explore, ok := h.vibeSearch.ExplorePolicy(req.Explore)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": exploreUsage})
+		return
+	}
+	intent, msg := queryIntent(req.Query, req.MaxMinutes, req.MaxEpisodes, req.Finishable)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if err := h.vibeSearch.Axes().Validate(req.Axes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Perform vibe search with anti-join
 	result, err := h.vibeSearch.Search(services.SearchConfig{
 		UserID:       userID,
-		Query:        req.Query,
+		Query:        intent.Query,
 		TopK:         20,
 		FinalResults: limit,
 		UseReranking: true, // Use LLM reranking for best results
+		Facets:       req.Facets,
+
+		PersonalizationWeight: personalization,
+		RatingSignalStrength:  req.RatingSignal,
+		Watchlist:             watchlist,
+		Explore:               explore,
+		Duration:              intent.Duration,
+		Axes:                  req.Axes,
+		ExpandFranchises:      req.ExpandFranchises,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
 		return
 	}
 
+	h.logImpression(c, models.SurfaceSearch, req.Query, map[string]interface{}{
+		"endpoint":          "recommend",
+		"top_k":             20,
+		"final_results":     limit,
+		"reranking":         true,
+		"facets":            req.Facets,
+		"personalization":   personalization,
+		"rating_signal":     req.RatingSignal,
+		"watchlist":         watchlist,
+		"duration":          intent.Duration,
+		"axes":              req.Axes,
+		"expand_franchises": req.ExpandFranchises,
+	}, result)
+
 	c.JSON(http.StatusOK, gin.H{
+		"request_id":       middleware.GetRequestID(c),
 		"query":            result.Query,
 		"total_candidates": result.TotalCandidates,
 		"filtered_seen":    result.FilteredCount,
+		"duration":         intent.Duration,
 		"recommendations":  result.Recommendations,
 	})
 }
 
 // GetRecommendSimple handles simple GET-based recommendations
-// GET /vibe?q=xxx&user_id=xxx
+// GET /vibe?q=xxx&facets=neon-noir,dreamlike&watchlist=highlight&explore=off&max_minutes=90&max_episodes=10&finishable=true&axes=dark-light:0.2&expand_franchises=true
 func (h *Handler) GetRecommendSimple(c *gin.Context) {
 	query := c.Query("q")
 	userID := middleware.GetUserID(c)
@@ -182,20 +388,79 @@ func (h *Handler) GetRecommendSimple(c *gin.Context) {
 		return
 	}
 
+	facets := splitQueryList(c.Query("facets"))
+	if err := h.vibeSearch.Facets().ValidateSlugs(facets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	watchlist, ok := services.ParseWatchlistMode(c.Query("watchlist"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "watchlist must be exclude or highlight"})
+		return
+	}
+	explore, ok := h.vibeSearch.ExplorePolicy(c.Query("explore"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": exploreUsage})
+		return
+	}
+	maxMinutes, err1 := optionalIntParam(c, "max_minutes")
+	maxEpisodes, err2 := optionalIntParam(c, "max_episodes")
+	finishable, err3 := optionalBoolParam(c, "finishable")
+	if err1 != nil || err2 != nil || err3 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_minutes and max_episodes must be integers, finishable a boolean"})
+		return
+	}
+	expandFranchises := c.Query("expand_franchises") == "true"
+	intent, msg := queryIntent(query, maxMinutes, maxEpisodes, finishable)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	axes, err := parseAxisTargets(c.Query("axes"))
+	if err == nil {
+		err = h.vibeSearch.Axes().Validate(axes)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	result, err := h.vibeSearch.Search(services.SearchConfig{
 		UserID:       userID,
-		Query:        query,
+		Query:        intent.Query,
 		TopK:         15,
 		FinalResults: 5,
 		UseReranking: true,
+		Facets:       facets,
+		Watchlist:    watchlist,
+		Explore:      explore,
+		Duration:     intent.Duration,
+		Axes:         axes,
+
+		ExpandFranchises: expandFranchises,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
 		return
 	}
 
+	h.logImpression(c, models.SurfaceSearch, query, map[string]interface{}{
+		"endpoint":          "vibe",
+		"top_k":             15,
+		"final_results":     5,
+		"reranking":         true,
+		"facets":            facets,
+		"watchlist":         watchlist,
+		"duration":          intent.Duration,
+		"axes":              axes,
+		"expand_franchises": expandFranchises,
+	}, result)
+
 	c.JSON(http.StatusOK, gin.H{
+		"request_id":      middleware.GetRequestID(c),
 		"input":           query,
+		"duration":        intent.Duration,
 		"recommendations": result.Recommendations,
 	})
 }
@@ -206,7 +471,35 @@ func (h *Handler) GetSimilar(c *gin.Context) {
 	mediaID := c.Param("media_id")
 	userID := middleware.GetUserID(c)
 
-	recs, err := h.vibeSearch.GetSimilarToMedia(userID, mediaID, 10)
+	result, err := h.vibeSearch.GetSimilarToMedia(userID, mediaID, 10)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logImpression(c, models.SurfaceSimilar, mediaID, map[string]interface{}{
+		"final_results": 10,
+	}, result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"request_id":      middleware.GetRequestID(c),
+		"source_id":       mediaID,
+		"recommendations": result.Recommendations,
+	})
+}
+
+// GetAlsoWatched returns "people who watched this also watched" picks
+// GET /media/:id/also-watched?limit=10
+func (h *Handler) GetAlsoWatched(c *gin.Context) {
+	mediaID := c.Param("id")
+	userID := middleware.GetUserID(c)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	recs, err := h.vibeSearch.AlsoWatched(userID, mediaID, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -218,19 +511,125 @@ func (h *Handler) GetSimilar(c *gin.Context) {
 	})
 }
 
-// GetHiddenGems returns high-quality but less popular recommendations
-// GET /hidden-gems?user_id=xxx
+// GetForYou returns query-free picks driven by the user's taste profile
+// GET /for-you?limit=10&explore=off
+func (h *Handler) GetForYou(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	explore, ok := h.vibeSearch.ExplorePolicy(c.Query("explore"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": exploreUsage})
+		return
+	}
+
+	result, err := h.vibeSearch.ForYou(userID, limit, explore)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logImpression(c, models.SurfaceForYou, "", map[string]interface{}{
+		"final_results": limit,
+	}, result)
+
+	resp := gin.H{
+		"request_id":      middleware.GetRequestID(c),
+		"filtered_seen":   result.FilteredCount,
+		"recommendations": result.Recommendations,
+	}
+	if len(result.Recommendations) == 0 {
+		resp["message"] = "Mark a few titles as seen (and rate them) to unlock picks for you"
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetHiddenGems returns well-regarded but little-known recommendations,
+// optionally matching a vibe and filters
+// GET /hidden-gems?q=feels+like+a+fever+dream&type=anime&facets=dreamlike&max_minutes=100&limit=10
 func (h *Handler) GetHiddenGems(c *gin.Context) {
 	userID := middleware.GetUserID(c)
 
-	gems, err := h.vibeSearch.GetHiddenGems(userID, 10)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	mediaType := c.Query("type")
+	switch mediaType {
+	case "", "movie", "tv", "anime", "documentary":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be movie, tv, anime or documentary"})
+		return
+	}
+	facets := splitQueryList(c.Query("facets"))
+	if err := h.vibeSearch.Facets().ValidateSlugs(facets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	maxMinutes, err1 := optionalIntParam(c, "max_minutes")
+	maxEpisodes, err2 := optionalIntParam(c, "max_episodes")
+	finishable, err3 := optionalBoolParam(c, "finishable")
+	if err1 != nil || err2 != nil || err3 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_minutes and max_episodes must be integers, finishable a boolean"})
+		return
+	}
+	query := c.Query("q")
+	intent, msg := queryIntent(query, maxMinutes, maxEpisodes, finishable)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	result, err := h.vibeSearch.GetHiddenGems(services.GemConfig{
+		UserID:    userID,
+		Query:     intent.Query,
+		Limit:     limit,
+		MediaType: mediaType,
+		Facets:    facets,
+		Duration:  intent.Duration,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	h.logImpression(c, models.SurfaceHiddenGems, query, map[string]interface{}{
+		"final_results": limit,
+		"type":          mediaType,
+		"facets":        facets,
+		"duration":      intent.Duration,
+	}, result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"request_id":  middleware.GetRequestID(c),
+		"hidden_gems": result.Recommendations,
+	})
+}
+
+// GetFacets lists the vibe facet vocabulary with media counts
+// GET /facets?category=pacing
+func (h *Handler) GetFacets(c *gin.Context) {
+	category := c.Query("category")
+
+	facets, err := h.vibeSearch.Facets().ListFacets(category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list facets"})
+		return
+	}
+
+	// Group by category for the UI
+	byCategory := make(map[string][]models.Facet)
+	for _, f := range facets {
+		byCategory[f.Category] = append(byCategory[f.Category], f)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"hidden_gems": gems,
+		"count":       len(facets),
+		"facets":      facets,
+		"by_category": byCategory,
 	})
 }
 
@@ -332,3 +731,69 @@ func (h *Handler) GetHealth(c *gin.Context) {
 		"time":   time.Now().Format(time.RFC3339),
 	})
 }
+
+// exploreUsage is the 400 message for an invalid explore value
+const exploreUsage = "explore must be thompson, epsilon, on or off"
+
+// queryIntent reads duration constraints from the query text, letting any
+// explicit max_minutes, max_episodes or finishable value override them.
+// A non-empty message means the explicit values were invalid.
+func queryIntent(query string, maxMinutes, maxEpisodes *int, finishable *bool) (services.QueryIntent, string) {
+	intent := services.ParseQueryIntent(query)
+	if maxMinutes != nil {
+		if *maxMinutes < 0 {
+			return intent, "max_minutes must not be negative"
+		}
+		intent.Duration.MaxMinutes = *maxMinutes
+	}
+	if maxEpisodes != nil {
+		if *maxEpisodes < 0 {
+			return intent, "max_episodes must not be negative"
+		}
+		intent.Duration.MaxEpisodes = *maxEpisodes
+	}
+	if finishable != nil {
+		intent.Duration.Finishable = *finishable
+	}
+	return intent, ""
+}
+
+// splitQueryList parses a comma-separated query parameter
+func splitQueryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// optionalIntParam parses an integer query parameter, nil when absent
+func optionalIntParam(c *gin.Context, name string) (*int, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// optionalBoolParam parses a boolean query parameter, nil when absent
+func optionalBoolParam(c *gin.Context, name string) (*bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/database"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// fixedEmbedder embeds every query as the same vector
+type fixedEmbedder []float32
+
+func (e fixedEmbedder) Embed(string) ([]float32, error) { return e, nil }
+func (e fixedEmbedder) ModelName() string               { return "fixed" }
+
+// testEnv is a handler over a fresh SQLite database, served with the real
+// session middleware. Tests mount the routes they exercise on router.
+type testEnv struct {
+	db     *database.DB
+	svc    *services.VibeSearchService
+	h      *Handler
+	router *gin.Engine
+}
+
+// testMedia is a title to index, placed at vec relative to the query
+// vector (1,0,0)
+type testMedia struct {
+	media models.Media
+	vec   []float32
+}
+
+func newTestEnv(t *testing.T, catalog ...testMedia) *testEnv {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, m := range catalog {
+		if err := db.CreateMedia(&m.media); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.StoreEmbedding(m.media.ID, m.vec, "fixed"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc, err := services.NewVibeSearchService(db, fixedEmbedder{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+	rooms := services.NewRoomHub(svc)
+	t.Cleanup(rooms.Stop)
+
+	h := NewHandler(db, svc, services.NewRedditScraper(db, nil),
+		services.NewImpressionLogger(db, time.Hour), services.NewJudgmentJob(db, svc), rooms,
+		services.NewFeedService(db, svc, time.Hour, "https://w2w.test"), middleware.NewFeedTokens("test-secret"))
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Session("test-secret"))
+	return &testEnv{db: db, svc: svc, h: h, router: router}
+}
+
+// testClient is one browser session against a test server
+type testClient struct {
+	t    *testing.T
+	base string
+	http *http.Client
+}
+
+func newTestClient(t *testing.T, server *httptest.Server) *testClient {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testClient{t: t, base: server.URL, http: &http.Client{Jar: jar}}
+}
+
+// do sends a JSON request and decodes the response into out, if given,
+// returning the status code
+func (c *testClient) do(method, path string, body, out interface{}) int {
+	c.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, c.base+path, reader)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			c.t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestFacets(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "m1", Title: "Blade Runner", MediaType: "movie", VibeProfile: "neon noir city in the rain"}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "m2", Title: "Paddington 2", MediaType: "movie", VibeProfile: "cozy melancholy"}, []float32{1, 0, 0}},
+	)
+	if _, err := env.svc.Facets().Backfill(false, nil); err != nil {
+		t.Fatal(err)
+	}
+	env.router.GET("/facets", env.h.GetFacets)
+	env.router.GET("/vibe", env.h.GetRecommendSimple)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	var list struct {
+		Facets     []models.Facet            `json:"facets"`
+		ByCategory map[string][]models.Facet `json:"by_category"`
+	}
+	if status := c.do(http.MethodGet, "/facets?category=atmosphere", nil, &list); status != http.StatusOK {
+		t.Fatalf("GET /facets: status %d", status)
+	}
+	if len(list.ByCategory) != 1 || len(list.ByCategory["atmosphere"]) != len(list.Facets) {
+		t.Errorf("by category = %v, want atmosphere only", list.ByCategory)
+	}
+	for _, f := range list.Facets {
+		if f.Slug == "rain-soaked-streets" && f.MediaCount != 1 {
+			t.Errorf("rain-soaked-streets counts %d titles, want 1", f.MediaCount)
+		}
+	}
+
+	// The facet filter keeps out the closer, untagged match
+	var resp struct {
+		Recommendations []models.Recommendation `json:"recommendations"`
+	}
+	if status := c.do(http.MethodGet, "/vibe?q=rainy&facets=neon-noir", nil, &resp); status != http.StatusOK {
+		t.Fatalf("GET /vibe: status %d", status)
+	}
+	if len(resp.Recommendations) != 1 || resp.Recommendations[0].Media.ID != "m1" {
+		t.Fatalf("neon-noir picks = %+v, want only m1", resp.Recommendations)
+	}
+	if chips := resp.Recommendations[0].Facets; len(chips) != 2 || chips[0].Slug != "neon-noir" {
+		t.Errorf("m1 chips = %+v, want neon-noir first", chips)
+	}
+	if status := c.do(http.MethodGet, "/vibe?q=rainy&facets=vaporwave", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("unknown facet: status %d, want 400", status)
+	}
+}
+
+func TestAlsoWatched(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "heat", Title: "Heat", MediaType: "movie", VibeProfile: "cool crime"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "thief", Title: "Thief", MediaType: "movie", VibeProfile: "neon heist"}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "ronin", Title: "Ronin", MediaType: "movie", VibeProfile: "car chases"}, []float32{0.8, 0.2, 0}},
+	)
+	for _, user := range []string{"u1", "u2", "u3"} {
+		if err := env.db.CreateUser(&models.User{ID: user, Username: user}); err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range []string{"heat", "thief", "ronin"} {
+			if user == "u3" && id == "ronin" {
+				continue
+			}
+			if err := env.db.MarkAsSeen(&models.SeenMedia{UserID: user, MediaID: id}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err := services.NewCollabFilter(env.db).Recompute(); err != nil {
+		t.Fatal(err)
+	}
+	env.router.POST("/seen", env.h.PostSeen)
+	env.router.GET("/media/:id/also-watched", env.h.GetAlsoWatched)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	var resp struct {
+		SourceID        string                  `json:"source_id"`
+		Recommendations []models.Recommendation `json:"recommendations"`
+	}
+	if status := c.do(http.MethodGet, "/media/heat/also-watched", nil, &resp); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	if len(resp.Recommendations) != 2 || resp.Recommendations[0].Media.ID != "thief" || resp.Recommendations[1].Media.ID != "ronin" {
+		t.Fatalf("also watched = %+v, want thief (3 co-watchers) then ronin (2)", resp.Recommendations)
+	}
+	if rec := resp.Recommendations[0]; rec.Explanation != "Watched by 3 people who also watched this" || rec.CollabScore != rec.Score || rec.Rank != 1 {
+		t.Errorf("thief = %+v", rec)
+	}
+
+	// Titles the viewer has seen are left out
+	c.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "thief"}, nil)
+	c.do(http.MethodGet, "/media/heat/also-watched", nil, &resp)
+	if len(resp.Recommendations) != 1 || resp.Recommendations[0].Media.ID != "ronin" || resp.Recommendations[0].Rank != 1 {
+		t.Errorf("after seeing thief = %+v, want only ronin", resp.Recommendations)
+	}
+	if c.do(http.MethodGet, "/media/nope/also-watched", nil, &resp); len(resp.Recommendations) != 0 {
+		t.Errorf("unknown title has picks %+v", resp.Recommendations)
+	}
+}
+
+func TestDismissals(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "heat", Title: "Heat", MediaType: "movie", VibeProfile: "cool crime"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "thief", Title: "Thief", MediaType: "movie", VibeProfile: "neon heist"}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "ronin", Title: "Ronin", MediaType: "movie", VibeProfile: "car chases"}, []float32{0.8, 0.2, 0}},
+	)
+	env.router.POST("/dismissals", env.h.PostDismissal)
+	env.router.GET("/dismissals", env.h.GetDismissals)
+	env.router.DELETE("/dismissals/:media_id", env.h.DeleteDismissal)
+	env.router.GET("/vibe", env.h.GetRecommendSimple)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		req    models.DismissRequest
+		status int
+	}{
+		{"unknown reason", models.DismissRequest{MediaID: "heat", Reason: "meh"}, http.StatusBadRequest},
+		{"snooze without until", models.DismissRequest{MediaID: "heat", Reason: models.DismissSnooze}, http.StatusBadRequest},
+		{"snooze into the past", models.DismissRequest{MediaID: "heat", Reason: models.DismissSnooze, Until: &past}, http.StatusBadRequest},
+		{"until on a permanent dismissal", models.DismissRequest{MediaID: "heat", Reason: models.DismissAlreadyKnow, Until: &future}, http.StatusBadRequest},
+		{"unknown title", models.DismissRequest{MediaID: "nope", Reason: models.DismissAlreadyKnow}, http.StatusNotFound},
+		{"snooze", models.DismissRequest{MediaID: "heat", Reason: models.DismissSnooze, Until: &future}, http.StatusOK},
+		{"not interested", models.DismissRequest{MediaID: "thief", Reason: models.DismissNotInterested}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if status := c.do(http.MethodPost, "/dismissals", tt.req, nil); status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.status)
+		}
+	}
+
+	picks := func() []string {
+		t.Helper()
+		var resp struct {
+			Recommendations []models.Recommendation `json:"recommendations"`
+		}
+		if status := c.do(http.MethodGet, "/vibe?q=crime", nil, &resp); status != http.StatusOK {
+			t.Fatalf("GET /vibe: status %d", status)
+		}
+		var ids []string
+		for _, r := range resp.Recommendations {
+			ids = append(ids, r.Media.ID)
+		}
+		return ids
+	}
+	if got := picks(); len(got) != 1 || got[0] != "ronin" {
+		t.Errorf("picks = %v, want only ronin", got)
+	}
+
+	// Once a snooze lapses the title comes back, and only the full list
+	// still shows it
+	userID := c.session()[:strings.LastIndex(c.session(), ".")]
+	if err := env.db.Dismiss(&models.Dismissal{UserID: userID, MediaID: "heat", Reason: models.DismissSnooze, Until: &past}); err != nil {
+		t.Fatal(err)
+	}
+	if got := picks(); len(got) != 2 || got[0] != "heat" {
+		t.Errorf("picks after the snooze lapsed = %v, want heat back on top", got)
+	}
+	var list struct {
+		Count int `json:"count"`
+	}
+	c.do(http.MethodGet, "/dismissals", nil, &list)
+	if list.Count != 1 {
+		t.Errorf("%d active dismissals, want thief only", list.Count)
+	}
+	c.do(http.MethodGet, "/dismissals?include_expired=true", nil, &list)
+	if list.Count != 2 {
+		t.Errorf("%d dismissals with expired, want 2", list.Count)
+	}
+
+	if status := c.do(http.MethodDelete, "/dismissals/thief", nil, nil); status != http.StatusOK {
+		t.Errorf("undo: status %d", status)
+	}
+	if status := c.do(http.MethodDelete, "/dismissals/thief", nil, nil); status != http.StatusNotFound {
+		t.Errorf("second undo: status %d, want 404", status)
+	}
+	if got := picks(); len(got) != 3 {
+		t.Errorf("picks after undoing = %v, want all three", got)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Impression & Interaction Endpoints
+// ============================================================================
+
+// validActions are the interactions clients may report
+var validActions = map[string]bool{
+	models.ActionExpanded:  true,
+	models.ActionClicked:   true,
+	models.ActionSeen:      true,
+	models.ActionWatchlist: true,
+	models.ActionDismissed: true,
+}
+
+// PostInteraction records what the user did with a recommendation
+// POST /interactions
+func (h *Handler) PostInteraction(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.InteractionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !validActions[req.Action] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be expanded, clicked, seen, watchlist or dismissed"})
+		return
+	}
+	if !h.impressions.Enabled() {
+		c.JSON(http.StatusOK, gin.H{"message": "Impression logging is disabled"})
+		return
+	}
+
+	interaction, err := h.impressions.RecordInteraction(userID, req.RequestID, req.MediaID, req.Action)
+	if errors.Is(err, services.ErrUnknownImpression) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown request_id"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record interaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recorded",
+		"id":      interaction.ID,
+	})
+}
+
+// GetImpressionExport streams logged impressions, with their interactions,
+// as JSON Lines
+// GET /admin/impressions/export?since=2024-01-01T00:00:00Z&until=...
+func (h *Handler) GetImpressionExport(c *gin.Context) {
+	until := time.Now()
+	since := until.Add(-24 * time.Hour)
+
+	if v := c.Query("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "since must be RFC 3339"})
+			return
+		}
+		since = t
+	}
+	if v := c.Query("until"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "until must be RFC 3339"})
+			return
+		}
+		until = t
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="impressions.jsonl"`)
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	err := h.impressions.Export(since, until, func(imp *models.Impression) error {
+		return enc.Encode(imp)
+	})
+	if err != nil {
+		// Headers are already out; all we can do is stop and log
+		log.Printf("Impression export failed: %v", err)
+	}
+}
+
+// logImpression records a recommendation response; failures are logged,
+// never surfaced to the client
+func (h *Handler) logImpression(c *gin.Context, surface, query string, options map[string]interface{}, result *services.SearchResult) {
+	err := h.impressions.Log(middleware.GetRequestID(c), middleware.GetUserID(c), surface, query, options, result)
+	if err != nil {
+		log.Printf("Failed to log impression: %v", err)
+	}
+}
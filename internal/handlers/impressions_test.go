@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+)
+
+func TestInteractions(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "heat", Title: "Heat", MediaType: "movie", VibeProfile: "cool crime"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "thief", Title: "Thief", MediaType: "movie", VibeProfile: "neon heist"}, []float32{0.9, 0.1, 0}},
+	)
+	env.router.GET("/vibe", env.h.GetRecommendSimple)
+	env.router.POST("/interactions", env.h.PostInteraction)
+	env.router.GET("/export", env.h.GetImpressionExport)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	ana, sam := newTestClient(t, server), newTestClient(t, server)
+
+	// The request ID in the body is the one the response was logged under
+	resp, err := ana.http.Get(server.URL + "/vibe?q=crime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var search struct {
+		RequestID string `json:"request_id"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&search)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if search.RequestID == "" || resp.Header.Get(middleware.RequestIDHeader) != search.RequestID {
+		t.Fatalf("request_id %q, header %q", search.RequestID, resp.Header.Get(middleware.RequestIDHeader))
+	}
+
+	tests := []struct {
+		name   string
+		client *testClient
+		req    models.InteractionRequest
+		status int
+	}{
+		{"unknown action", ana, models.InteractionRequest{RequestID: search.RequestID, MediaID: "heat", Action: "liked"}, http.StatusBadRequest},
+		{"unknown request", ana, models.InteractionRequest{RequestID: "nope", MediaID: "heat", Action: models.ActionClicked}, http.StatusNotFound},
+		{"someone else's request", sam, models.InteractionRequest{RequestID: search.RequestID, MediaID: "heat", Action: models.ActionClicked}, http.StatusNotFound},
+		{"click", ana, models.InteractionRequest{RequestID: search.RequestID, MediaID: "thief", Action: models.ActionClicked}, http.StatusOK},
+		{"watchlist", ana, models.InteractionRequest{RequestID: search.RequestID, MediaID: "thief", Action: models.ActionWatchlist}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if status := tt.client.do(http.MethodPost, "/interactions", tt.req, nil); status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.status)
+		}
+	}
+
+	resp, err = ana.http.Get(server.URL + "/export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("export: status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var exported []models.Impression
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var imp models.Impression
+		if err := json.Unmarshal(scanner.Bytes(), &imp); err != nil {
+			t.Fatalf("export line %q: %v", scanner.Text(), err)
+		}
+		exported = append(exported, imp)
+	}
+	if len(exported) != 1 {
+		t.Fatalf("%d impressions exported, want the one search", len(exported))
+	}
+	imp := exported[0]
+	if imp.RequestID != search.RequestID || imp.Surface != models.SurfaceSearch || imp.Query != "crime" || imp.Options["endpoint"] != "vibe" {
+		t.Errorf("impression = %+v", imp)
+	}
+	if len(imp.Results) != 2 || imp.Results[0].MediaID != "heat" {
+		t.Errorf("results = %+v, want heat then thief", imp.Results)
+	}
+	if len(imp.Interactions) != 2 || imp.Interactions[0].Action != models.ActionClicked || imp.Interactions[1].Action != models.ActionWatchlist {
+		t.Errorf("interactions = %+v, want ana's click then watchlist", imp.Interactions)
+	}
+	for _, in := range imp.Interactions {
+		if in.UserID != imp.UserID || in.MediaID != "thief" {
+			t.Errorf("interaction %+v not attributed to the searcher's pick", in)
+		}
+	}
+
+	if status := ana.do(http.MethodGet, "/export?since=yesterday", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("bad since: status %d, want 400", status)
+	}
+}
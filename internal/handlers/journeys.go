@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Journey Endpoints
+// ============================================================================
+
+// PostJourney plans and saves a watch sequence drifting from one vibe to
+// another. Each end is a query or a media ID.
+// POST /journeys
+func (h *Handler) PostJourney(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.JourneyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	req.FromQuery, req.ToQuery = strings.TrimSpace(req.FromQuery), strings.TrimSpace(req.ToQuery)
+	req.Title = strings.TrimSpace(req.Title)
+	if (req.FromQuery == "") == (req.FromMediaID == "") || (req.ToQuery == "") == (req.ToMediaID == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "give each end as either a query or a media ID: from_query or from_media_id, and to_query or to_media_id"})
+		return
+	}
+	if req.Length != 0 && (req.Length < services.MinJourneyLength || req.Length > services.MaxJourneyLength) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("length must be between %d and %d",
+			services.MinJourneyLength, services.MaxJourneyLength)})
+		return
+	}
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	journey, err := h.vibeSearch.PlanJourney(userID, req)
+	if errors.Is(err, services.ErrUnknownJourneyEnd) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to plan journey: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, journey)
+}
+
+// GetJourneys lists the user's journeys
+// GET /journeys
+func (h *Handler) GetJourneys(c *gin.Context) {
+	journeys, err := h.vibeSearch.Journeys().List(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch journeys"})
+		return
+	}
+	if journeys == nil {
+		journeys = []models.Journey{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(journeys),
+		"journeys": journeys,
+	})
+}
+
+// GetJourney returns one of the user's journeys with its steps
+// GET /journeys/:id
+func (h *Handler) GetJourney(c *gin.Context) {
+	journey, err := h.vibeSearch.Journeys().Owned(middleware.GetUserID(c), c.Param("id"))
+	h.writeJourney(c, journey, err)
+}
+
+// GetSharedJourney returns a journey by its share slug
+// GET /journeys/shared/:slug
+func (h *Handler) GetSharedJourney(c *gin.Context) {
+	journey, err := h.vibeSearch.Journeys().Shared(c.Param("slug"))
+	h.writeJourney(c, journey, err)
+}
+
+func (h *Handler) writeJourney(c *gin.Context, journey *models.Journey, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if journey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journey not found"})
+		return
+	}
+	if err := h.vibeSearch.Journeys().WithSteps(journey); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch steps"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"journey": journey,
+		"owned":   journey.UserID == middleware.GetUserID(c),
+	})
+}
+
+// DeleteJourney deletes one of the user's journeys
+// DELETE /journeys/:id
+func (h *Handler) DeleteJourney(c *gin.Context) {
+	journey, err := h.vibeSearch.Journeys().Owned(middleware.GetUserID(c), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if journey == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Journey not found"})
+		return
+	}
+
+	if err := h.vibeSearch.Journeys().Delete(journey.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete journey"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Journey deleted"})
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestJourneys(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "ghibli", Title: "Totoro", MediaType: "anime", VibeProfile: "gentle"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "mid1", Title: "Paprika", MediaType: "anime", VibeProfile: "dreamy"}, []float32{0.9, 0.4, 0}},
+		testMedia{models.Media{ID: "mid2", Title: "Akira", MediaType: "anime", VibeProfile: "neon"}, []float32{0.4, 0.9, 0}},
+		testMedia{models.Media{ID: "cyber", Title: "Ghost in the Shell", MediaType: "anime", VibeProfile: "cold cyber"}, []float32{0, 1, 0}},
+	)
+	env.router.POST("/journeys", env.h.PostJourney)
+	env.router.GET("/journeys", env.h.GetJourneys)
+	env.router.GET("/journeys/shared/:slug", env.h.GetSharedJourney)
+	env.router.GET("/journeys/:id", env.h.GetJourney)
+	env.router.DELETE("/journeys/:id", env.h.DeleteJourney)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	ana, sam := newTestClient(t, server), newTestClient(t, server)
+
+	tests := []struct {
+		name   string
+		req    models.JourneyRequest
+		status int
+	}{
+		{"no start", models.JourneyRequest{ToMediaID: "cyber"}, http.StatusBadRequest},
+		{"both a query and a title", models.JourneyRequest{FromQuery: "cosy", FromMediaID: "ghibli", ToMediaID: "cyber"}, http.StatusBadRequest},
+		{"too short", models.JourneyRequest{FromMediaID: "ghibli", ToMediaID: "cyber", Length: 2}, http.StatusBadRequest},
+		{"too long", models.JourneyRequest{FromMediaID: "ghibli", ToMediaID: "cyber", Length: 13}, http.StatusBadRequest},
+		{"unknown title", models.JourneyRequest{FromMediaID: "ghibli", ToMediaID: "nope"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if status := ana.do(http.MethodPost, "/journeys", tt.req, nil); status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.status)
+		}
+	}
+
+	var journey models.Journey
+	if status := ana.do(http.MethodPost, "/journeys", models.JourneyRequest{FromMediaID: "ghibli", ToMediaID: "cyber", Length: 3}, &journey); status != http.StatusCreated {
+		t.Fatalf("create: status %d", status)
+	}
+	if journey.Title != "Totoro → Ghost in the Shell" || len(journey.Steps) != 2 || journey.Steps[0].MediaID != "mid1" {
+		t.Errorf("journey = %+v, want Paprika then Akira between the ends", journey)
+	}
+
+	var resp struct {
+		Journey models.Journey `json:"journey"`
+		Owned   bool           `json:"owned"`
+	}
+	if status := ana.do(http.MethodGet, "/journeys/"+journey.ID, nil, &resp); status != http.StatusOK || !resp.Owned || len(resp.Journey.Steps) != 2 {
+		t.Errorf("own journey: status %d, %+v", status, resp)
+	}
+	if status := sam.do(http.MethodGet, "/journeys/"+journey.ID, nil, nil); status != http.StatusNotFound {
+		t.Errorf("someone else's journey by ID: status %d, want 404", status)
+	}
+	if status := sam.do(http.MethodGet, "/journeys/shared/"+journey.Slug, nil, &resp); status != http.StatusOK || resp.Owned || len(resp.Journey.Steps) != 2 {
+		t.Errorf("shared journey: status %d, %+v", status, resp)
+	}
+	if status := sam.do(http.MethodDelete, "/journeys/"+journey.ID, nil, nil); status != http.StatusNotFound {
+		t.Errorf("deleting someone else's journey: status %d, want 404", status)
+	}
+
+	if status := ana.do(http.MethodDelete, "/journeys/"+journey.ID, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete: status %d", status)
+	}
+	if status := sam.do(http.MethodGet, "/journeys/shared/"+journey.Slug, nil, nil); status != http.StatusNotFound {
+		t.Errorf("deleted journey by slug: status %d, want 404", status)
+	}
+	var list struct {
+		Count int `json:"count"`
+	}
+	ana.do(http.MethodGet, "/journeys", nil, &list)
+	if list.Count != 0 {
+		t.Errorf("%d journeys listed after deleting the only one", list.Count)
+	}
+}
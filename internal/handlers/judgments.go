@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Similar-To Judgment Endpoints (admin)
+// ============================================================================
+
+// judgmentRecord is one line of the judgment export: a reference title and
+// everything Reddit recommended to its fans
+type judgmentRecord struct {
+	ReferenceID string                   `json:"reference_id"`
+	Relevant    []models.SimilarJudgment `json:"relevant"`
+}
+
+// GetJudgmentExport streams the mined similar_to judgments as JSON Lines,
+// one reference per line
+// GET /admin/judgments/export
+func (h *Handler) GetJudgmentExport(c *gin.Context) {
+	judgments, err := h.db.GetSimilarJudgments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch judgments"})
+		return
+	}
+
+	refs := make([]string, 0, len(judgments))
+	for ref := range judgments {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="similar_judgments.jsonl"`)
+	c.Status(http.StatusOK)
+
+	enc := json.NewEncoder(c.Writer)
+	for _, ref := range refs {
+		if err := enc.Encode(judgmentRecord{ReferenceID: ref, Relevant: judgments[ref]}); err != nil {
+			log.Printf("Judgment export failed: %v", err)
+			return
+		}
+	}
+}
+
+// GetSimilarQuality returns the history of similar-title quality runs
+// GET /admin/similar-quality?limit=30
+func (h *Handler) GetSimilarQuality(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	if limit <= 0 || limit > 365 {
+		limit = 30
+	}
+
+	runs, err := h.db.GetSimilarQualityRuns(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quality runs"})
+		return
+	}
+	if runs == nil {
+		runs = []models.SimilarQualityRun{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// PostSimilarQualityRun re-mines judgments and scores the similar-titles
+// surface now
+// POST /admin/similar-quality/run
+func (h *Handler) PostSimilarQualityRun(c *gin.Context) {
+	run, err := h.judgments.Run()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if run == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Not enough similar_to judgments to score yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
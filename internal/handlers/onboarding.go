@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Onboarding Endpoints
+// ============================================================================
+
+// GetOnboarding returns the next onboarding question, or your taste summary
+// once the quiz is done
+// GET /onboarding
+func (h *Handler) GetOnboarding(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	state, err := h.vibeSearch.Onboarding(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load onboarding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// PostOnboardingAnswer answers an onboarding question with seen, loved,
+// hated or skip, and returns the next question or the taste summary
+// POST /onboarding/answers
+func (h *Handler) PostOnboardingAnswer(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.OnboardingAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	state, err := h.vibeSearch.AnswerOnboarding(userID, req)
+	if errors.Is(err, services.ErrOnboardingAnswer) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save answer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// DeleteOnboarding starts the onboarding quiz over; titles already marked
+// seen stay seen
+// DELETE /onboarding
+func (h *Handler) DeleteOnboarding(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	if err := h.vibeSearch.ResetOnboarding(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset onboarding"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Onboarding reset"})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+func TestOnboarding(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "totoro", Title: "Totoro", MediaType: "anime", VibeProfile: "gentle"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "alien", Title: "Alien", MediaType: "movie", VibeProfile: "dread"}, []float32{0, 0, 1}},
+		testMedia{models.Media{ID: "heat", Title: "Heat", MediaType: "movie", VibeProfile: "cool crime"}, []float32{0, 1, 0}},
+	)
+	env.router.POST("/seen", env.h.PostSeen)
+	env.router.GET("/seen", env.h.GetSeen)
+	env.router.GET("/onboarding", env.h.GetOnboarding)
+	env.router.POST("/onboarding/answers", env.h.PostOnboardingAnswer)
+	env.router.DELETE("/onboarding", env.h.DeleteOnboarding)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	seenIDs := func() map[string]bool {
+		t.Helper()
+		var resp struct {
+			Seen []models.Media `json:"seen"`
+		}
+		if status := c.do(http.MethodGet, "/seen", nil, &resp); status != http.StatusOK {
+			t.Fatalf("GET /seen: status %d", status)
+		}
+		ids := make(map[string]bool)
+		for _, m := range resp.Seen {
+			ids[m.ID] = true
+		}
+		return ids
+	}
+
+	var state models.OnboardingState
+	if status := c.do(http.MethodGet, "/onboarding", nil, &state); status != http.StatusOK {
+		t.Fatalf("GET /onboarding: status %d", status)
+	}
+	if state.Done || state.Question == nil || state.Target != services.OnboardingTarget {
+		t.Fatalf("opening state = %+v, want a first question", state)
+	}
+
+	// Heat goes into the seen list outside the quiz, so the quiz leaves it be
+	c.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "heat"}, nil)
+	tests := []struct {
+		name   string
+		req    models.OnboardingAnswerRequest
+		status int
+	}{
+		{"unknown answer", models.OnboardingAnswerRequest{MediaID: "totoro", Answer: "meh"}, http.StatusBadRequest},
+		{"unknown title", models.OnboardingAnswerRequest{MediaID: "nope", Answer: models.AnswerLoved}, http.StatusBadRequest},
+		{"already seen", models.OnboardingAnswerRequest{MediaID: "heat", Answer: models.AnswerHated}, http.StatusBadRequest},
+		{"loved", models.OnboardingAnswerRequest{MediaID: "totoro", Answer: models.AnswerLoved}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if status := c.do(http.MethodPost, "/onboarding/answers", tt.req, &state); status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.status)
+		}
+	}
+	if state.Rated != 1 || state.Question == nil || state.Question.Media.ID != "alien" {
+		t.Errorf("state = %+v, want alien asked next", state)
+	}
+	if ids := seenIDs(); !ids["totoro"] || !ids["heat"] {
+		t.Errorf("seen = %v, want totoro and heat", ids)
+	}
+
+	// Changing the answer to skip takes totoro back out of the seen list
+	c.do(http.MethodPost, "/onboarding/answers", models.OnboardingAnswerRequest{MediaID: "totoro", Answer: models.AnswerSkip}, &state)
+	if state.Rated != 0 || state.Answered != 1 {
+		t.Errorf("state after skipping = %+v", state)
+	}
+	if ids := seenIDs(); ids["totoro"] || !ids["heat"] {
+		t.Errorf("seen after skipping = %v, want heat only", ids)
+	}
+
+	c.do(http.MethodPost, "/onboarding/answers", models.OnboardingAnswerRequest{MediaID: "alien", Answer: models.AnswerHated}, &state)
+	if !state.Done || state.Summary == nil || len(state.Summary.Hated) != 1 || state.Summary.Hated[0] != "Alien" {
+		t.Errorf("final state = %+v, want done with Alien hated", state)
+	}
+
+	if status := c.do(http.MethodDelete, "/onboarding", nil, nil); status != http.StatusOK {
+		t.Fatalf("reset: status %d", status)
+	}
+	c.do(http.MethodGet, "/onboarding", nil, &state)
+	if state.Answered != 0 || state.Question == nil || state.Question.Media.ID != "totoro" {
+		t.Errorf("state after reset = %+v, want totoro asked again", state)
+	}
+	if ids := seenIDs(); !ids["alien"] {
+		t.Error("reset took alien out of the seen list")
+	}
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Watch Progress Endpoints
+// ============================================================================
+
+// watchStatuses are the statuses a client may set or filter by
+var watchStatuses = map[string]bool{
+	models.WatchPlanning:  true,
+	models.WatchWatching:  true,
+	models.WatchOnHold:    true,
+	models.WatchDropped:   true,
+	models.WatchCompleted: true,
+}
+
+const statusUsage = "status must be planning, watching, on_hold, dropped or completed"
+
+// PutProgress sets a title's watch status, season/episode progress, rating
+// or rewatch count. Planning moves the title to the watchlist; going from
+// completed back to watching starts a rewatch.
+// PUT /progress
+func (h *Handler) PutProgress(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.ProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if req.Status != "" && !watchStatuses[req.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": statusUsage})
+		return
+	}
+	if (req.Season != nil && *req.Season < 0) || (req.Episode != nil && *req.Episode < 0) ||
+		(req.RewatchCount != nil && *req.RewatchCount < 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "season, episode and rewatch_count must not be negative"})
+		return
+	}
+	if req.Rating != nil && (*req.Rating < 1 || *req.Rating > 10) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rating must be between 1 and 10"})
+		return
+	}
+
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	media, err := h.db.GetMedia(req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+	if req.Season != nil && media.SeasonCount > 0 && *req.Season > media.SeasonCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "season is past the last season (" + strconv.Itoa(media.SeasonCount) + ")"})
+		return
+	}
+	if req.Episode != nil && media.EpisodeCount > 0 && *req.Episode > media.EpisodeCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "episode is past the last episode (" + strconv.Itoa(media.EpisodeCount) + ")"})
+		return
+	}
+
+	if req.Status == models.WatchPlanning {
+		h.moveToWatchlist(c, userID, media)
+		return
+	}
+
+	entry, err := h.db.GetProgress(userID, req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	previous := ""
+	if entry == nil {
+		entry = &models.SeenMedia{UserID: userID, MediaID: req.MediaID, Status: models.WatchWatching}
+	} else {
+		previous = entry.Status
+	}
+
+	if req.Status != "" {
+		entry.Status = req.Status
+	}
+	if previous == models.WatchCompleted && entry.Status == models.WatchWatching {
+		// A rewatch starts from the top unless told otherwise
+		entry.RewatchCount++
+		entry.Season, entry.Episode = 0, 0
+	}
+	if req.Season != nil {
+		entry.Season = *req.Season
+	}
+	if req.Episode != nil {
+		entry.Episode = *req.Episode
+	}
+	if req.Rating != nil {
+		entry.Rating = req.Rating
+	}
+	if req.RewatchCount != nil {
+		entry.RewatchCount = *req.RewatchCount
+	}
+
+	if err := h.db.SaveProgress(entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress"})
+		return
+	}
+
+	// Keep the taste profile in step; dropping a title takes it out
+	if err := h.vibeSearch.UpdateTasteOnSeen(userID, req.MediaID, entry.Rating, entry.Status); err != nil {
+		log.Printf("Failed to update taste profile for %s: %v", userID, err)
+	}
+
+	saved, err := h.db.GetProgress(userID, req.MediaID)
+	if err != nil || saved == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+		return
+	}
+	saved.Media = media
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Progress saved",
+		"progress": saved,
+	})
+}
+
+// moveToWatchlist handles status "planning": the title leaves the seen list
+// (progress and all) and joins the watchlist if it is not there already
+func (h *Handler) moveToWatchlist(c *gin.Context, userID string, media *models.Media) {
+	removed, err := h.db.RemoveSeen(userID, media.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update progress"})
+		return
+	}
+	if removed {
+		if err := h.vibeSearch.UpdateTasteOnUnseen(userID, media.ID); err != nil {
+			log.Printf("Failed to update taste profile for %s: %v", userID, err)
+		}
+	}
+
+	saved, err := h.db.GetWatchlistIDs(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !saved[media.ID] {
+		item := &models.WatchlistItem{UserID: userID, MediaID: media.ID, Priority: models.PriorityNormal}
+		if err := h.db.AddToWatchlist(item); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to watchlist"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moved to watchlist",
+		"status":  models.WatchPlanning,
+		"media":   media.Title,
+	})
+}
+
+// GetProgress lists the user's tracked titles, most recently updated first.
+// Planning entries come from the watchlist, after the rest.
+// GET /progress?status=watching,on_hold
+func (h *Handler) GetProgress(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	statuses := splitQueryList(c.Query("status"))
+	includePlanning := len(statuses) == 0
+	var tracked []string
+	for _, status := range statuses {
+		if !watchStatuses[status] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": statusUsage})
+			return
+		}
+		if status == models.WatchPlanning {
+			includePlanning = true
+		} else {
+			tracked = append(tracked, status)
+		}
+	}
+
+	list := []models.SeenMedia{}
+	if len(statuses) == 0 || len(tracked) > 0 {
+		entries, err := h.db.GetProgressList(userID, tracked, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+			return
+		}
+		list = append(list, entries...)
+	}
+	if includePlanning {
+		items, err := h.db.GetWatchlist(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist"})
+			return
+		}
+		for _, item := range items {
+			list = append(list, models.SeenMedia{
+				UserID:    userID,
+				MediaID:   item.MediaID,
+				Status:    models.WatchPlanning,
+				CreatedAt: item.AddedAt,
+				UpdatedAt: item.AddedAt,
+				Media:     item.Media,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(list),
+		"progress": list,
+	})
+}
+
+// GetContinueWatching lists titles in progress with the episode that comes
+// next, most recently watched first
+// GET /continue-watching?limit=10
+func (h *Handler) GetContinueWatching(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	entries, err := h.db.GetProgressList(userID, []string{models.WatchWatching}, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+		return
+	}
+
+	items := make([]models.ContinueWatchingItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, continueItem(e))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count": len(items),
+		"items": items,
+	})
+}
+
+// continueItem works out the next episode. Per-season episode counts are not
+// stored, so the next episode is the one after the last logged, unless a
+// single-season series is already at its final episode.
+func continueItem(e models.SeenMedia) models.ContinueWatchingItem {
+	item := models.ContinueWatchingItem{SeenMedia: e}
+	m := e.Media
+	episodic := e.Season > 0 || e.Episode > 0 ||
+		(m != nil && (m.EpisodeCount > 0 || m.EpisodeRuntime > 0))
+	if !episodic {
+		return item
+	}
+	if m != nil && m.SeasonCount <= 1 && m.EpisodeCount > 0 && e.Episode >= m.EpisodeCount {
+		return item
+	}
+	item.NextSeason = e.Season
+	if item.NextSeason == 0 {
+		item.NextSeason = 1
+	}
+	item.NextEpisode = e.Episode + 1
+	return item
+}
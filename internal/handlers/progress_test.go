@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+func TestContinueItem(t *testing.T) {
+	movie := &models.Media{MediaType: "movie", RuntimeMinutes: 110}
+	series := &models.Media{MediaType: "tv", SeasonCount: 3, EpisodeCount: 30}
+	miniseries := &models.Media{MediaType: "tv", SeasonCount: 1, EpisodeCount: 6}
+	tests := []struct {
+		name                    string
+		entry                   models.SeenMedia
+		wantSeason, wantEpisode int
+	}{
+		{"movie", models.SeenMedia{Media: movie}, 0, 0},
+		{"series not started", models.SeenMedia{Media: series}, 1, 1},
+		{"mid-season", models.SeenMedia{Season: 2, Episode: 4, Media: series}, 2, 5},
+		{"miniseries finale", models.SeenMedia{Season: 1, Episode: 6, Media: miniseries}, 0, 0},
+		// Per-season counts are unknown, so a long series just carries on
+		{"series past a season's length", models.SeenMedia{Season: 2, Episode: 30, Media: series}, 2, 31},
+		{"episodes without details", models.SeenMedia{Episode: 2}, 1, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := continueItem(tt.entry)
+			if item.NextSeason != tt.wantSeason || item.NextEpisode != tt.wantEpisode {
+				t.Errorf("next = S%dE%d, want S%dE%d", item.NextSeason, item.NextEpisode, tt.wantSeason, tt.wantEpisode)
+			}
+		})
+	}
+}
+
+type progressResponse struct {
+	Progress models.SeenMedia `json:"progress"`
+}
+
+type progressList struct {
+	Progress []models.SeenMedia `json:"progress"`
+}
+
+func progressIDs(list []models.SeenMedia) []string {
+	ids := make([]string, len(list))
+	for i, p := range list {
+		ids[i] = p.MediaID + ":" + p.Status
+	}
+	return ids
+}
+
+func TestProgress(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "severance", Title: "Severance", MediaType: "tv", VibeProfile: "office dread", SeasonCount: 2, EpisodeCount: 19}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "dark", Title: "Dark", MediaType: "tv", VibeProfile: "time knots", SeasonCount: 3, EpisodeCount: 26}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "heat", Title: "Heat", MediaType: "movie", VibeProfile: "cool crime", RuntimeMinutes: 170}, []float32{0.8, 0.2, 0}},
+	)
+	env.router.POST("/seen", env.h.PostSeen)
+	env.router.PUT("/progress", env.h.PutProgress)
+	env.router.GET("/progress", env.h.GetProgress)
+	env.router.GET("/continue-watching", env.h.GetContinueWatching)
+	env.router.GET("/watchlist", env.h.GetWatchlist)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	intp := func(n int) *int { return &n }
+	put := func(req models.ProgressRequest) models.SeenMedia {
+		t.Helper()
+		var resp progressResponse
+		if status := c.do(http.MethodPut, "/progress", req, &resp); status != http.StatusOK {
+			t.Fatalf("PUT /progress %+v: status %d", req, status)
+		}
+		// Keep updated_at strictly ordered between calls
+		time.Sleep(5 * time.Millisecond)
+		return resp.Progress
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			req    models.ProgressRequest
+			status int
+		}{
+			{"unknown status", models.ProgressRequest{MediaID: "dark", Status: "binging"}, http.StatusBadRequest},
+			{"negative episode", models.ProgressRequest{MediaID: "dark", Episode: intp(-1)}, http.StatusBadRequest},
+			{"season past the last", models.ProgressRequest{MediaID: "dark", Season: intp(4)}, http.StatusBadRequest},
+			{"episode past the last", models.ProgressRequest{MediaID: "dark", Episode: intp(27)}, http.StatusBadRequest},
+			{"unknown title", models.ProgressRequest{MediaID: "nope", Status: models.WatchWatching}, http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			if status := c.do(http.MethodPut, "/progress", tt.req, nil); status != tt.status {
+				t.Errorf("%s: status %d, want %d", tt.name, status, tt.status)
+			}
+		}
+	})
+
+	// A title logged without a status starts out watching
+	p := put(models.ProgressRequest{MediaID: "severance", Season: intp(1), Episode: intp(9)})
+	if p.Status != models.WatchWatching || p.Season != 1 || p.Episode != 9 {
+		t.Errorf("new progress = %+v, want watching S1E9", p)
+	}
+
+	// Going from completed back to watching starts a rewatch from the top
+	put(models.ProgressRequest{MediaID: "severance", Status: models.WatchCompleted})
+	p = put(models.ProgressRequest{MediaID: "severance", Status: models.WatchWatching})
+	if p.RewatchCount != 1 || p.Season != 0 || p.Episode != 0 {
+		t.Errorf("after a rewatch progress = %+v, want rewatch 1 at the start", p)
+	}
+	// Other moves keep the counter and the position
+	put(models.ProgressRequest{MediaID: "dark", Status: models.WatchWatching, Season: intp(2), Episode: intp(3)})
+	p = put(models.ProgressRequest{MediaID: "dark", Status: models.WatchOnHold})
+	if p.RewatchCount != 0 || p.Season != 2 || p.Episode != 3 {
+		t.Errorf("on hold progress = %+v, want S2E3 kept", p)
+	}
+	p = put(models.ProgressRequest{MediaID: "dark", Status: models.WatchWatching})
+	if p.RewatchCount != 0 || p.Episode != 3 {
+		t.Errorf("resumed progress = %+v, want no rewatch", p)
+	}
+
+	t.Run("continue watching", func(t *testing.T) {
+		var resp struct {
+			Items []models.ContinueWatchingItem `json:"items"`
+		}
+		c.do(http.MethodGet, "/continue-watching", nil, &resp)
+		if len(resp.Items) != 2 || resp.Items[0].MediaID != "dark" || resp.Items[1].MediaID != "severance" {
+			t.Fatalf("continue watching = %+v, want dark then severance", resp.Items)
+		}
+		if resp.Items[0].NextSeason != 2 || resp.Items[0].NextEpisode != 4 {
+			t.Errorf("dark next = S%dE%d, want S2E4", resp.Items[0].NextSeason, resp.Items[0].NextEpisode)
+		}
+		if resp.Items[1].NextSeason != 1 || resp.Items[1].NextEpisode != 1 {
+			t.Errorf("severance rewatch next = S%dE%d, want S1E1", resp.Items[1].NextSeason, resp.Items[1].NextEpisode)
+		}
+
+		// Anything not being watched drops out
+		put(models.ProgressRequest{MediaID: "dark", Status: models.WatchDropped})
+		c.do(http.MethodGet, "/continue-watching", nil, &resp)
+		if len(resp.Items) != 1 || resp.Items[0].MediaID != "severance" {
+			t.Errorf("after dropping dark continue watching = %+v", resp.Items)
+		}
+	})
+
+	t.Run("planning moves a title to the watchlist", func(t *testing.T) {
+		c.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "heat"}, nil)
+		if status := c.do(http.MethodPut, "/progress", models.ProgressRequest{MediaID: "heat", Status: models.WatchPlanning}, nil); status != http.StatusOK {
+			t.Fatalf("planning: status %d", status)
+		}
+		var list struct {
+			Watchlist []models.WatchlistItem `json:"watchlist"`
+		}
+		c.do(http.MethodGet, "/watchlist", nil, &list)
+		if len(list.Watchlist) != 1 || list.Watchlist[0].MediaID != "heat" {
+			t.Errorf("watchlist = %+v, want heat", list.Watchlist)
+		}
+
+		var all progressList
+		c.do(http.MethodGet, "/progress", nil, &all)
+		if got := progressIDs(all.Progress); len(got) != 3 || got[0] != "dark:dropped" || got[1] != "severance:watching" || got[2] != "heat:planning" {
+			t.Errorf("progress = %v, want tracked titles newest first, then planning", got)
+		}
+	})
+
+	t.Run("status filter", func(t *testing.T) {
+		var list progressList
+		c.do(http.MethodGet, "/progress?status=dropped,planning", nil, &list)
+		if got := progressIDs(list.Progress); len(got) != 2 || got[0] != "dark:dropped" || got[1] != "heat:planning" {
+			t.Errorf("dropped and planning = %v", got)
+		}
+		c.do(http.MethodGet, "/progress?status=completed", nil, &list)
+		if len(list.Progress) != 0 {
+			t.Errorf("completed = %v, want none", progressIDs(list.Progress))
+		}
+		if status := c.do(http.MethodGet, "/progress?status=binging", nil, nil); status != http.StatusBadRequest {
+			t.Errorf("unknown status filter: status %d, want 400", status)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+)
+
+// ============================================================================
+// Watch-Party Room Endpoints
+// ============================================================================
+
+// GetGroupRoom upgrades to a WebSocket for the group's live voting room.
+// Clients send models.RoomMessage frames and receive models.RoomEvent
+// frames; see the README for the protocol.
+// GET /groups/:id/room
+func (h *Handler) GetGroupRoom(c *gin.Context) {
+	group, ok := h.memberGroup(c)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+
+	server := websocket.Server{
+		// Origin is checked by middleware.WebSocketOrigin before we get here;
+		// the library default would reject clients that send none
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			conn := h.rooms.Join(group, userID)
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range conn.Events {
+					if err := websocket.JSON.Send(ws, event); err != nil {
+						break
+					}
+				}
+				// Unblock the reader if the hub dropped us or the send failed
+				ws.Close()
+			}()
+
+			for {
+				var msg models.RoomMessage
+				if err := websocket.JSON.Receive(ws, &msg); err != nil {
+					break
+				}
+				h.rooms.Handle(conn, msg)
+			}
+
+			h.rooms.Leave(conn)
+			<-done
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
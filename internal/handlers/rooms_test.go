@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+	"w2w/internal/models"
+)
+
+// dialRoom opens the group's room socket with the client's session cookie
+func (c *testClient) dialRoom(groupID string) *websocket.Conn {
+	c.t.Helper()
+	base, _ := url.Parse(c.base)
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(c.base, "http")+"/groups/"+groupID+"/room", c.base)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	for _, cookie := range c.http.Jar.Cookies(base) {
+		config.Header.Add("Cookie", cookie.String())
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		c.t.Fatalf("dialing room: %v", err)
+	}
+	c.t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// awaitEvent reads events until one satisfies match
+func awaitEvent(t *testing.T, ws *websocket.Conn, what string, match func(models.RoomEvent) bool) models.RoomEvent {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var event models.RoomEvent
+		if err := websocket.JSON.Receive(ws, &event); err != nil {
+			t.Fatalf("waiting for %s: %v", what, err)
+		}
+		if match(event) {
+			return event
+		}
+	}
+}
+
+// awaitState reads events until the room state satisfies match
+func awaitState(t *testing.T, ws *websocket.Conn, what string, match func(*models.RoomState) bool) *models.RoomState {
+	t.Helper()
+	return awaitEvent(t, ws, what, func(e models.RoomEvent) bool {
+		return e.Type == "state" && match(e.State)
+	}).State
+}
+
+func send(t *testing.T, ws *websocket.Conn, msg models.RoomMessage) {
+	t.Helper()
+	if err := websocket.JSON.Send(ws, msg); err != nil {
+		t.Fatalf("sending %s: %v", msg.Type, err)
+	}
+}
+
+func participant(state *models.RoomState, nickname string) *models.RoomParticipant {
+	for i := range state.Participants {
+		if state.Participants[i].Nickname == nickname {
+			return &state.Participants[i]
+		}
+	}
+	return nil
+}
+
+func TestGroupRoomVoting(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "m1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "m2", Title: "Contact", MediaType: "movie", VibeProfile: "hopeful wonder"}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "m3", Title: "Solaris", MediaType: "movie", VibeProfile: "grief in orbit"}, []float32{0.8, 0.2, 0}},
+		testMedia{models.Media{ID: "m4", Title: "Heat", MediaType: "movie", VibeProfile: "slick heist"}, []float32{0, 0, 1}},
+	)
+	env.router.POST("/groups", env.h.PostGroup)
+	env.router.POST("/groups/join", env.h.PostJoinGroup)
+	env.router.DELETE("/groups/:id/members/me", env.h.DeleteGroupMembership)
+	env.router.GET("/groups/:id/room", env.h.GetGroupRoom)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+
+	ana, sam, kim := newTestClient(t, server), newTestClient(t, server), newTestClient(t, server)
+	var group models.Group
+	if status := ana.do(http.MethodPost, "/groups", models.GroupRequest{Name: "Movie night", Nickname: "Ana"}, &group); status != http.StatusCreated {
+		t.Fatalf("create group: status %d", status)
+	}
+	for nickname, c := range map[string]*testClient{"Sam": sam, "Kim": kim} {
+		if status := c.do(http.MethodPost, "/groups/join", models.GroupJoinRequest{InviteCode: group.InviteCode, Nickname: nickname}, nil); status != http.StatusOK {
+			t.Fatalf("%s joining: status %d", nickname, status)
+		}
+	}
+
+	anaWS, samWS, kimWS := ana.dialRoom(group.ID), sam.dialRoom(group.ID), kim.dialRoom(group.ID)
+	awaitState(t, anaWS, "everyone online", func(s *models.RoomState) bool {
+		return len(s.Participants) == 3 && participant(s, "Sam").Online && participant(s, "Kim").Online
+	})
+
+	// Nothing can be voted on before a round starts
+	send(t, samWS, models.RoomMessage{Type: "vote", MediaID: "m1", Value: 1})
+	if e := awaitEvent(t, samWS, "an error", func(e models.RoomEvent) bool { return e.Type == "error" }); e.Error != "no round is running" {
+		t.Errorf("voting in the lobby: %q", e.Error)
+	}
+
+	send(t, anaWS, models.RoomMessage{Type: "start", Query: "something awe-inspiring", Size: 3, Countdown: 60})
+	voting := func(s *models.RoomState) bool { return s.Phase == models.RoomVoting }
+	state := awaitState(t, anaWS, "voting", voting)
+	awaitState(t, samWS, "voting", voting)
+	awaitState(t, kimWS, "voting", voting)
+	if len(state.Shortlist) != 3 {
+		t.Fatalf("shortlist has %d titles, want 3", len(state.Shortlist))
+	}
+	first, second := state.Shortlist[1].MediaID, state.Shortlist[2].MediaID
+
+	// Ana and Kim back the later title, Sam the earlier one
+	send(t, anaWS, models.RoomMessage{Type: "vote", MediaID: second, Value: 1})
+	send(t, samWS, models.RoomMessage{Type: "vote", MediaID: first, Value: 1})
+	send(t, kimWS, models.RoomMessage{Type: "vote", MediaID: second, Value: 1})
+	awaitState(t, anaWS, "three votes", func(s *models.RoomState) bool {
+		return s.Shortlist[1].Up == 1 && s.Shortlist[2].Up == 2
+	})
+
+	send(t, samWS, models.RoomMessage{Type: "finish"})
+	if e := awaitEvent(t, samWS, "an error", func(e models.RoomEvent) bool { return e.Type == "error" }); e.Error != "only the group owner can do that" {
+		t.Errorf("non-owner finishing: %q", e.Error)
+	}
+
+	// Kim leaves the group: their socket is closed and their vote discarded,
+	// which leaves the two titles tied
+	if status := kim.do(http.MethodDelete, "/groups/"+group.ID+"/members/me", nil, nil); status != http.StatusOK {
+		t.Fatalf("Kim leaving: status %d", status)
+	}
+	kimWS.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var event models.RoomEvent
+		if err := websocket.JSON.Receive(kimWS, &event); err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Error("Kim's socket stayed open after leaving the group")
+			}
+			break
+		}
+	}
+	awaitState(t, anaWS, "Kim gone", func(s *models.RoomState) bool {
+		return len(s.Participants) == 2 && participant(s, "Kim") == nil && s.Shortlist[2].Up == 1
+	})
+
+	// Sam only disconnects, so their vote stands
+	samWS.Close()
+	state = awaitState(t, anaWS, "Sam offline", func(s *models.RoomState) bool {
+		return !participant(s, "Sam").Online
+	})
+	if state.Shortlist[1].Up != 1 {
+		t.Errorf("Sam's vote was dropped on disconnecting")
+	}
+
+	// A tie goes to the title higher on the shortlist
+	send(t, anaWS, models.RoomMessage{Type: "finish"})
+	state = awaitState(t, anaWS, "decided", func(s *models.RoomState) bool { return s.Phase == models.RoomDecided })
+	if state.Winner == nil || state.Winner.MediaID != first {
+		t.Errorf("winner = %+v, want %s on the tie", state.Winner, first)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Trending Endpoints
+// ============================================================================
+
+// GetTrending returns the titles gaining the most Reddit mentions, with the
+// threads behind them, optionally matching a vibe and filters
+// GET /trending?window=7d&type=anime&facets=neon-noir&q=rainy+city&limit=10
+func (h *Handler) GetTrending(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	window := c.DefaultQuery("window", services.DefaultTrendingWindow)
+	if !services.IsTrendingWindow(window) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be 24h, 7d or 30d"})
+		return
+	}
+	mediaType := c.Query("type")
+	switch mediaType {
+	case "", "movie", "tv", "anime", "documentary":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be movie, tv, anime or documentary"})
+		return
+	}
+	facets := splitQueryList(c.Query("facets"))
+	if err := h.vibeSearch.Facets().ValidateSlugs(facets); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := c.Query("q")
+
+	result, err := h.vibeSearch.GetTrending(services.TrendingConfig{
+		UserID:    userID,
+		Query:     query,
+		Window:    window,
+		Limit:     limit,
+		MediaType: mediaType,
+		Facets:    facets,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logImpression(c, models.SurfaceTrending, query, map[string]interface{}{
+		"final_results": limit,
+		"window":        window,
+		"type":          mediaType,
+		"facets":        facets,
+	}, result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"request_id": middleware.GetRequestID(c),
+		"window":     window,
+		"trending":   result.Recommendations,
+	})
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"w2w/internal/middleware"
+	"w2w/internal/models"
+	"w2w/internal/services"
+)
+
+// ============================================================================
+// Watchlist Endpoints
+// ============================================================================
+
+// PostWatchlist saves a title for later
+// POST /watchlist
+func (h *Handler) PostWatchlist(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.WatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	priority := models.PriorityNormal
+	if req.Priority != nil {
+		priority = *req.Priority
+	}
+	if !validPriority(priority) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be 1 (high), 2 or 3 (low)"})
+		return
+	}
+
+	if !h.ensureUser(c, userID) {
+		return
+	}
+
+	media, err := h.db.GetMedia(req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if media == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+		return
+	}
+
+	seen, err := h.db.IsMediaSeen(userID, req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if seen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Already seen"})
+		return
+	}
+
+	item := &models.WatchlistItem{
+		UserID:   userID,
+		MediaID:  req.MediaID,
+		Priority: priority,
+		Note:     req.Note,
+	}
+	if err := h.db.AddToWatchlist(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Added to watchlist",
+		"media":   media.Title,
+	})
+}
+
+// GetWatchlist returns the user's watchlist in their chosen order
+// GET /watchlist
+func (h *Handler) GetWatchlist(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	items, err := h.db.GetWatchlist(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist"})
+		return
+	}
+	if items == nil {
+		items = []models.WatchlistItem{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(items),
+		"watchlist": items,
+	})
+}
+
+// PatchWatchlistItem changes an item's priority and/or note
+// PATCH /watchlist/:media_id
+func (h *Handler) PatchWatchlistItem(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.WatchlistUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Priority == nil && req.Note == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update: send priority and/or note"})
+		return
+	}
+	if req.Priority != nil && !validPriority(*req.Priority) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be 1 (high), 2 or 3 (low)"})
+		return
+	}
+
+	updated, err := h.db.UpdateWatchlistItem(userID, c.Param("media_id"), req.Priority, req.Note)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update"})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not on watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Watchlist item updated"})
+}
+
+// DeleteWatchlistItem removes a title from the watchlist
+// DELETE /watchlist/:media_id
+func (h *Handler) DeleteWatchlistItem(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	removed, err := h.db.RemoveFromWatchlist(userID, c.Param("media_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not on watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Removed from watchlist"})
+}
+
+// PostWatchlistReorder moves the listed titles to the top, in order
+// POST /watchlist/reorder
+func (h *Handler) PostWatchlistReorder(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var req models.WatchlistReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := h.db.ReorderWatchlist(userID, req.MediaIDs); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder"})
+		return
+	}
+
+	items, err := h.db.GetWatchlist(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchlist"})
+		return
+	}
+	if items == nil {
+		items = []models.WatchlistItem{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(items),
+		"watchlist": items,
+	})
+}
+
+// GetWatchlistPick runs a vibe search restricted to the user's watchlist
+// GET /watchlist/pick?q=something+cozy&limit=3
+func (h *Handler) GetWatchlistPick(c *gin.Context) {
+	query := c.Query("q")
+	userID := middleware.GetUserID(c)
+
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "What are you in the mood for? Use ?q=your+vibe+description"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "3"))
+	if limit <= 0 || limit > 20 {
+		limit = 3
+	}
+
+	// "I have 90 minutes" narrows the pick to what fits
+	intent := services.ParseQueryIntent(query)
+	result, err := h.vibeSearch.Search(services.SearchConfig{
+		UserID:       userID,
+		Query:        intent.Query,
+		TopK:         limit * 3,
+		FinalResults: limit,
+		UseReranking: true,
+		Watchlist:    services.WatchlistOnly,
+		Duration:     intent.Duration,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed: " + err.Error()})
+		return
+	}
+
+	h.logImpression(c, models.SurfaceSearch, query, map[string]interface{}{
+		"endpoint":      "watchlist_pick",
+		"top_k":         limit * 3,
+		"final_results": limit,
+		"reranking":     true,
+		"watchlist":     services.WatchlistOnly,
+	}, result)
+
+	resp := gin.H{
+		"request_id":      middleware.GetRequestID(c),
+		"input":           query,
+		"recommendations": result.Recommendations,
+	}
+	if len(result.Recommendations) == 0 {
+		resp["message"] = "Your watchlist is empty — save a few picks first"
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func validPriority(p int) bool {
+	return p >= models.PriorityHigh && p <= models.PriorityLow
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+type watchlistResponse struct {
+	Count     int                    `json:"count"`
+	Watchlist []models.WatchlistItem `json:"watchlist"`
+}
+
+// watchlistOrder renders a watchlist as media IDs, in order
+func watchlistOrder(list []models.WatchlistItem) string {
+	ids := make([]string, len(list))
+	for i, item := range list {
+		ids[i] = item.MediaID
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestWatchlist(t *testing.T) {
+	env := newTestEnv(t,
+		testMedia{models.Media{ID: "heat", Title: "Heat", MediaType: "movie", VibeProfile: "cool crime"}, []float32{1, 0, 0}},
+		testMedia{models.Media{ID: "thief", Title: "Thief", MediaType: "movie", VibeProfile: "neon heist"}, []float32{0.9, 0.1, 0}},
+		testMedia{models.Media{ID: "ronin", Title: "Ronin", MediaType: "movie", VibeProfile: "car chases"}, []float32{0.8, 0.2, 0}},
+		testMedia{models.Media{ID: "amelie", Title: "Amélie", MediaType: "movie", VibeProfile: "whimsy"}, []float32{0, 1, 0}},
+	)
+	env.router.POST("/seen", env.h.PostSeen)
+	env.router.POST("/watchlist", env.h.PostWatchlist)
+	env.router.GET("/watchlist", env.h.GetWatchlist)
+	env.router.PATCH("/watchlist/:media_id", env.h.PatchWatchlistItem)
+	env.router.DELETE("/watchlist/:media_id", env.h.DeleteWatchlistItem)
+	env.router.POST("/watchlist/reorder", env.h.PostWatchlistReorder)
+	env.router.GET("/watchlist/pick", env.h.GetWatchlistPick)
+	server := httptest.NewServer(env.router)
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	high, bad := models.PriorityHigh, 4
+	c.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "amelie"}, nil)
+	tests := []struct {
+		name   string
+		req    models.WatchlistRequest
+		status int
+	}{
+		{"bad priority", models.WatchlistRequest{MediaID: "heat", Priority: &bad}, http.StatusBadRequest},
+		{"unknown title", models.WatchlistRequest{MediaID: "nope"}, http.StatusNotFound},
+		{"already seen", models.WatchlistRequest{MediaID: "amelie"}, http.StatusConflict},
+		{"first", models.WatchlistRequest{MediaID: "heat"}, http.StatusOK},
+		{"second", models.WatchlistRequest{MediaID: "thief", Priority: &high, Note: "Mann's debut"}, http.StatusOK},
+		{"third", models.WatchlistRequest{MediaID: "ronin"}, http.StatusOK},
+		// Adding again edits in place and keeps the position
+		{"again", models.WatchlistRequest{MediaID: "heat", Note: "with Dad"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if status := c.do(http.MethodPost, "/watchlist", tt.req, nil); status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, status, tt.status)
+		}
+	}
+
+	var list watchlistResponse
+	c.do(http.MethodGet, "/watchlist", nil, &list)
+	if got := watchlistOrder(list.Watchlist); got != "heat thief ronin" {
+		t.Fatalf("watchlist = %s, want in the order added", got)
+	}
+	if heat := list.Watchlist[0]; heat.Note != "with Dad" || heat.Priority != models.PriorityNormal || heat.Media == nil || heat.Media.Title != "Heat" {
+		t.Errorf("heat = %+v, want the new note at normal priority", heat)
+	}
+	if list.Watchlist[1].Priority != models.PriorityHigh {
+		t.Errorf("thief priority = %d, want high", list.Watchlist[1].Priority)
+	}
+
+	t.Run("reorder", func(t *testing.T) {
+		// Unknown and repeated IDs are ignored; unlisted items keep their order
+		if status := c.do(http.MethodPost, "/watchlist/reorder", models.WatchlistReorderRequest{MediaIDs: []string{"ronin", "nope", "ronin"}}, &list); status != http.StatusOK {
+			t.Fatalf("reorder: status %d", status)
+		}
+		if got := watchlistOrder(list.Watchlist); got != "ronin heat thief" {
+			t.Errorf("after moving ronin up = %s, want ronin heat thief", got)
+		}
+		for i, item := range list.Watchlist {
+			if item.Position != i {
+				t.Errorf("%s at position %d, want %d", item.MediaID, item.Position, i)
+			}
+		}
+		c.do(http.MethodPost, "/watchlist/reorder", models.WatchlistReorderRequest{MediaIDs: []string{"thief", "heat"}}, &list)
+		if got := watchlistOrder(list.Watchlist); got != "thief heat ronin" {
+			t.Errorf("after moving two = %s, want thief heat ronin", got)
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		note := ""
+		if status := c.do(http.MethodPatch, "/watchlist/heat", models.WatchlistUpdateRequest{Note: &note}, nil); status != http.StatusOK {
+			t.Errorf("clearing the note: status %d", status)
+		}
+		if status := c.do(http.MethodPatch, "/watchlist/heat", models.WatchlistUpdateRequest{}, nil); status != http.StatusBadRequest {
+			t.Errorf("empty update: status %d, want 400", status)
+		}
+		if status := c.do(http.MethodPatch, "/watchlist/heat", models.WatchlistUpdateRequest{Priority: &bad}, nil); status != http.StatusBadRequest {
+			t.Errorf("bad priority: status %d, want 400", status)
+		}
+		if status := c.do(http.MethodPatch, "/watchlist/amelie", models.WatchlistUpdateRequest{Priority: &high}, nil); status != http.StatusNotFound {
+			t.Errorf("title not on the watchlist: status %d, want 404", status)
+		}
+		c.do(http.MethodGet, "/watchlist", nil, &list)
+		if heat := list.Watchlist[1]; heat.Note != "" || heat.Priority != models.PriorityNormal {
+			t.Errorf("heat = %+v, want the note cleared and the priority kept", heat)
+		}
+	})
+
+	t.Run("pick", func(t *testing.T) {
+		var resp struct {
+			Recommendations []models.Recommendation `json:"recommendations"`
+		}
+		if status := c.do(http.MethodGet, "/watchlist/pick?q=crime&limit=5", nil, &resp); status != http.StatusOK {
+			t.Fatalf("pick: status %d", status)
+		}
+		for _, r := range resp.Recommendations {
+			if r.Media.ID != "heat" && r.Media.ID != "thief" && r.Media.ID != "ronin" {
+				t.Errorf("picked %s, which is not on the watchlist", r.Media.ID)
+			}
+		}
+		if len(resp.Recommendations) != 3 || resp.Recommendations[0].Media.ID != "heat" {
+			t.Errorf("picks = %+v, want all three with heat first", resp.Recommendations)
+		}
+		if status := c.do(http.MethodGet, "/watchlist/pick", nil, nil); status != http.StatusBadRequest {
+			t.Errorf("pick without a query: status %d, want 400", status)
+		}
+	})
+
+	// Watching a title takes it off; removing leaves the rest in order
+	c.do(http.MethodPost, "/seen", models.SeenRequest{MediaID: "thief"}, nil)
+	if status := c.do(http.MethodDelete, "/watchlist/ronin", nil, nil); status != http.StatusOK {
+		t.Errorf("remove: status %d", status)
+	}
+	if status := c.do(http.MethodDelete, "/watchlist/ronin", nil, nil); status != http.StatusNotFound {
+		t.Errorf("second remove: status %d, want 404", status)
+	}
+	c.do(http.MethodGet, "/watchlist", nil, &list)
+	if got := watchlistOrder(list.Watchlist); got != "heat" || list.Count != 1 {
+		t.Errorf("watchlist = %s, want only heat", got)
+	}
+
+	// New additions go to the end, past any gap left by removals
+	c.do(http.MethodPost, "/watchlist", models.WatchlistRequest{MediaID: "ronin"}, nil)
+	c.do(http.MethodGet, "/watchlist", nil, &list)
+	if got := watchlistOrder(list.Watchlist); got != "heat ronin" {
+		t.Errorf("watchlist = %s, want ronin re-added last", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -246,6 +247,410 @@ Rank the TOP 3 that best capture the user's requested vibe. Explain why each mat
 	return results, nil
 }
 
+// FacetScore is a single facet assignment returned by ExtractFacets
+type FacetScore struct {
+	Slug       string
+	Confidence float64
+}
+
+// ExtractFacets maps a free-prose vibe profile onto a controlled vocabulary.
+// vocabulary is keyed by facet category and lists the allowed slugs; anything
+// the model returns outside of it is dropped.
+func (c *Client) ExtractFacets(vibeProfile string, vocabulary map[string][]string) ([]FacetScore, error) {
+	systemPrompt := `You tag film/TV vibe descriptions with terms from a FIXED vocabulary.
+Only use slugs that appear in the vocabulary. Pick at most 2 per category, and skip a
+category entirely if nothing fits. Confidence is 0.0-1.0: how clearly the description
+expresses that term.
+
+Respond in this exact JSON format:
+{
+  "facets": [
+    {"slug": "...", "confidence": 0.9}
+  ]
+}`
+
+	var vocab strings.Builder
+	categories := make([]string, 0, len(vocabulary))
+	for category := range vocabulary {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	allowed := make(map[string]bool)
+	for _, category := range categories {
+		vocab.WriteString(fmt.Sprintf("%s: %s\n", category, strings.Join(vocabulary[category], ", ")))
+		for _, slug := range vocabulary[category] {
+			allowed[slug] = true
+		}
+	}
+
+	userPrompt := fmt.Sprintf(`Vocabulary:
+%s
+Vibe description:
+"%s"`, vocab.String(), vibeProfile)
+
+	response, err := c.complete(systemPrompt, userPrompt, 0.1)
+	if err != nil {
+		return nil, fmt.Errorf("facet extraction failed: %w", err)
+	}
+
+	jsonStr := response
+	if idx := strings.Index(response, "{"); idx != -1 {
+		jsonStr = response[idx:]
+		if endIdx := strings.LastIndex(jsonStr, "}"); endIdx != -1 {
+			jsonStr = jsonStr[:endIdx+1]
+		}
+	}
+
+	var result struct {
+		Facets []struct {
+			Slug       string  `json:"slug"`
+			Confidence float64 `json:"confidence"`
+		} `json:"facets"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse facet response: %w", err)
+	}
+
+	var scores []FacetScore
+	for _, f := range result.Facets {
+		if !allowed[f.Slug] {
+			continue
+		}
+		conf := f.Confidence
+		if conf < 0 {
+			conf = 0
+		} else if conf > 1 {
+			conf = 1
+		}
+		scores = append(scores, FacetScore{Slug: f.Slug, Confidence: conf})
+	}
+
+	return scores, nil
+}
+
+// CollectionEntry is one title of a collection passed to SummarizeCollection
+type CollectionEntry struct {
+	Title       string
+	VibeProfile string
+	Note        string // Curator's note, may be empty
+}
+
+// SummarizeCollection writes a collection-level vibe profile: the feeling the
+// curator's picks share, in the same register as per-title vibe profiles
+func (c *Client) SummarizeCollection(title, description string, entries []CollectionEntry) (string, error) {
+	systemPrompt := `You are a film/TV critic who describes the shared AESTHETIC and FEELING of a
+curated list, not its plots. Read the curator's title, description, notes and each title's
+vibe, then describe the common thread: style, pacing, emotional texture, atmosphere.
+
+DO NOT list the titles or summarize plots. Focus ONLY on how the list FEELS as a whole.
+Keep the response to 2-3 sentences maximum.`
+
+	var list strings.Builder
+	for _, e := range entries {
+		list.WriteString(fmt.Sprintf("- %s: %s", e.Title, e.VibeProfile))
+		if e.Note != "" {
+			list.WriteString(fmt.Sprintf(" (curator: %s)", e.Note))
+		}
+		list.WriteString("\n")
+	}
+
+	userPrompt := fmt.Sprintf(`List: "%s"
+%s
+
+Titles:
+%s
+What vibe ties this list together?`, title, description, list.String())
+
+	return c.complete(systemPrompt, userPrompt, 0.7)
+}
+
+// GroupMemberTaste describes one watch-party member for ExplainGroupPicks
+type GroupMemberTaste struct {
+	UserID    string
+	Nickname  string
+	Favorites []string // Titles that best represent their taste
+}
+
+// GroupPick is one candidate for the group, with how well it fits each
+// member (0-1; members without history are omitted)
+type GroupPick struct {
+	MediaID     string
+	Title       string
+	VibeProfile string
+	Fits        map[string]float64 // User ID -> fit
+}
+
+// ExplainGroupPicks writes, for each pick, one or two sentences on why it
+// works for this particular group. Returns explanations keyed by media ID.
+func (c *Client) ExplainGroupPicks(members []GroupMemberTaste, picks []GroupPick) (map[string]string, error) {
+	if len(picks) == 0 {
+		return map[string]string{}, nil
+	}
+
+	systemPrompt := `You help a group of friends choose something to watch together.
+For each pick, explain in 1-2 sentences why it works for THIS group: name members,
+connect the pick's vibe to what each of them likes, and be honest when it is a
+compromise for someone. Focus on feeling and aesthetic, not plot.
+
+Respond in this exact JSON format:
+{
+  "explanations": [
+    {"media_id": "...", "explanation": "..."}
+  ]
+}`
+
+	var group strings.Builder
+	for _, m := range members {
+		if len(m.Favorites) == 0 {
+			group.WriteString(fmt.Sprintf("- %s: no history yet\n", m.Nickname))
+			continue
+		}
+		group.WriteString(fmt.Sprintf("- %s likes: %s\n", m.Nickname, strings.Join(m.Favorites, ", ")))
+	}
+
+	var list strings.Builder
+	for i, p := range picks {
+		var fits []string
+		for _, m := range members {
+			if fit, ok := p.Fits[m.UserID]; ok {
+				fits = append(fits, fmt.Sprintf("%s %.2f", m.Nickname, fit))
+			}
+		}
+		list.WriteString(fmt.Sprintf("%d. [ID: %s] %s - Vibe: %s - Fit: %s\n",
+			i+1, p.MediaID, p.Title, p.VibeProfile, strings.Join(fits, ", ")))
+	}
+
+	userPrompt := fmt.Sprintf(`The group:
+%s
+Picks (fit is 0-1, higher suits that member better):
+%s
+Explain why each pick works for the group.`, group.String(), list.String())
+
+	response, err := c.complete(systemPrompt, userPrompt, 0.5)
+	if err != nil {
+		return nil, fmt.Errorf("group explanation request failed: %w", err)
+	}
+
+	jsonStr := response
+	if idx := strings.Index(response, "{"); idx != -1 {
+		jsonStr = response[idx:]
+		if endIdx := strings.LastIndex(jsonStr, "}"); endIdx != -1 {
+			jsonStr = jsonStr[:endIdx+1]
+		}
+	}
+
+	var result struct {
+		Explanations []struct {
+			MediaID     string `json:"media_id"`
+			Explanation string `json:"explanation"`
+		} `json:"explanations"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse group explanations: %w", err)
+	}
+
+	explanations := make(map[string]string, len(result.Explanations))
+	for _, e := range result.Explanations {
+		explanations[e.MediaID] = e.Explanation
+	}
+	return explanations, nil
+}
+
+// BridgeAnchor is one of the titles a bridge search sits between
+type BridgeAnchor struct {
+	Title       string
+	VibeProfile string
+}
+
+// BridgePick is one title found between the anchors, with its similarity to
+// each (keyed by anchor title)
+type BridgePick struct {
+	MediaID      string
+	Title        string
+	VibeProfile  string
+	Similarities map[string]float64
+}
+
+// ExplainBridgePicks writes, for each pick, one or two sentences on what it
+// borrows from each anchor. Returns explanations keyed by media ID.
+func (c *Client) ExplainBridgePicks(anchors []BridgeAnchor, picks []BridgePick) (map[string]string, error) {
+	if len(picks) == 0 {
+		return map[string]string{}, nil
+	}
+
+	systemPrompt := `You help people who each love a different title find something in between.
+For each pick, explain in 1-2 sentences what it borrows from EACH of the anchor
+titles, naming them, so it is clear why it sits between them rather than next to
+one. Focus on feeling and aesthetic, not plot.
+
+Respond in this exact JSON format:
+{
+  "explanations": [
+    {"media_id": "...", "explanation": "..."}
+  ]
+}`
+
+	var between strings.Builder
+	for _, a := range anchors {
+		between.WriteString(fmt.Sprintf("- %s - Vibe: %s\n", a.Title, a.VibeProfile))
+	}
+
+	var list strings.Builder
+	for i, p := range picks {
+		titles := make([]string, 0, len(p.Similarities))
+		for t := range p.Similarities {
+			titles = append(titles, t)
+		}
+		sort.Strings(titles)
+		sims := make([]string, len(titles))
+		for j, t := range titles {
+			sims[j] = fmt.Sprintf("%s %.2f", t, p.Similarities[t])
+		}
+		list.WriteString(fmt.Sprintf("%d. [ID: %s] %s - Vibe: %s - Similarity: %s\n",
+			i+1, p.MediaID, p.Title, p.VibeProfile, strings.Join(sims, ", ")))
+	}
+
+	userPrompt := fmt.Sprintf(`Anchor titles:
+%s
+Picks (similarity is 0-1 to each anchor):
+%s
+Explain what each pick borrows from each anchor.`, between.String(), list.String())
+
+	response, err := c.complete(systemPrompt, userPrompt, 0.5)
+	if err != nil {
+		return nil, fmt.Errorf("bridge explanation request failed: %w", err)
+	}
+
+	jsonStr := response
+	if idx := strings.Index(response, "{"); idx != -1 {
+		jsonStr = response[idx:]
+		if endIdx := strings.LastIndex(jsonStr, "}"); endIdx != -1 {
+			jsonStr = jsonStr[:endIdx+1]
+		}
+	}
+
+	var result struct {
+		Explanations []struct {
+			MediaID     string `json:"media_id"`
+			Explanation string `json:"explanation"`
+		} `json:"explanations"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse bridge explanations: %w", err)
+	}
+
+	explanations := make(map[string]string, len(result.Explanations))
+	for _, e := range result.Explanations {
+		explanations[e.MediaID] = e.Explanation
+	}
+	return explanations, nil
+}
+
+// JourneyStop is one title on a vibe journey, in watch order
+type JourneyStop struct {
+	MediaID     string
+	Title       string
+	VibeProfile string
+}
+
+// WriteJourneyNotes writes a transition note for each stop of a journey from
+// one vibe to another: how it carries on from the previous title and nudges
+// toward the destination. Returns notes keyed by media ID.
+func (c *Client) WriteJourneyNotes(from, to string, stops []JourneyStop) (map[string]string, error) {
+	if len(stops) == 0 {
+		return map[string]string{}, nil
+	}
+
+	systemPrompt := `You narrate a watch-through that drifts gradually from one vibe to another.
+For each title, write one sentence on the transition: what it keeps from the title
+before it (or from the starting vibe, for the first) and how it moves the mood toward
+the destination. Focus on feeling and aesthetic, not plot.
+
+Respond in this exact JSON format:
+{
+  "notes": [
+    {"media_id": "...", "note": "..."}
+  ]
+}`
+
+	var list strings.Builder
+	for i, s := range stops {
+		list.WriteString(fmt.Sprintf("%d. [ID: %s] %s - Vibe: %s\n", i+1, s.MediaID, s.Title, s.VibeProfile))
+	}
+
+	userPrompt := fmt.Sprintf(`Starting vibe: %s
+Destination vibe: %s
+
+Titles in watch order:
+%s
+Write the transition note for each title.`, from, to, list.String())
+
+	response, err := c.complete(systemPrompt, userPrompt, 0.6)
+	if err != nil {
+		return nil, fmt.Errorf("journey notes request failed: %w", err)
+	}
+
+	jsonStr := response
+	if idx := strings.Index(response, "{"); idx != -1 {
+		jsonStr = response[idx:]
+		if endIdx := strings.LastIndex(jsonStr, "}"); endIdx != -1 {
+			jsonStr = jsonStr[:endIdx+1]
+		}
+	}
+
+	var result struct {
+		Notes []struct {
+			MediaID string `json:"media_id"`
+			Note    string `json:"note"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse journey notes: %w", err)
+	}
+
+	notes := make(map[string]string, len(result.Notes))
+	for _, n := range result.Notes {
+		notes[n.MediaID] = n.Note
+	}
+	return notes, nil
+}
+
+// TasteTitle is a title a new user rated, for SummarizeTaste
+type TasteTitle struct {
+	Title       string
+	VibeProfile string
+}
+
+// SummarizeTaste describes, in two or three sentences addressed to the
+// user, what their loved, liked and hated titles say about their taste
+func (c *Client) SummarizeTaste(loved, liked, hated []TasteTitle) (string, error) {
+	systemPrompt := `You are a film/TV critic meeting a new viewer. From the titles they loved,
+liked and hated, describe their taste: the moods, pacing, atmosphere and styles
+they are drawn to, and what they steer clear of.
+
+Speak to the viewer as "you". DO NOT summarize plots or simply list the titles.
+Keep the response to 2-3 sentences maximum.`
+
+	var list strings.Builder
+	for _, group := range []struct {
+		label  string
+		titles []TasteTitle
+	}{{"Loved", loved}, {"Liked", liked}, {"Hated", hated}} {
+		if len(group.titles) == 0 {
+			continue
+		}
+		list.WriteString(group.label + ":\n")
+		for _, t := range group.titles {
+			list.WriteString(fmt.Sprintf("- %s: %s\n", t.Title, t.VibeProfile))
+		}
+	}
+
+	userPrompt := fmt.Sprintf(`%s
+What is this viewer's taste?`, list.String())
+
+	return c.complete(systemPrompt, userPrompt, 0.7)
+}
+
 // ClassifyThreadType analyzes a Reddit thread title to determine its type
 func (c *Client) ClassifyThreadType(title, body string) (string, string, error) {
 	systemPrompt := `You analyze Reddit recommendation thread titles and bodies.
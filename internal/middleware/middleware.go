@@ -1,6 +1,6 @@
 // Package middleware provides cross-cutting HTTP middleware: anonymous
-// server-issued sessions, security headers, per-session rate limiting, and
-// admin authentication.
+// server-issued sessions, request IDs, security headers, per-session rate
+// limiting, WebSocket origin checks, and admin authentication.
 package middleware
 
 import (
@@ -10,6 +10,7 @@ import (
 	"crypto/subtle"
 	"encoding/hex"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -24,6 +25,10 @@ const (
 	userIDKey = "user_id"
 	// sessionMaxAge is how long a session cookie remains valid (1 year).
 	sessionMaxAge = 365 * 24 * 60 * 60
+	// RequestIDHeader carries the request ID back to the client.
+	RequestIDHeader = "X-Request-ID"
+	// requestIDKey is the gin context key under which the request ID lives.
+	requestIDKey = "request_id"
 )
 
 // GetUserID returns the session-derived user ID for the current request.
@@ -62,6 +67,28 @@ func Session(secret string) gin.HandlerFunc {
 	}
 }
 
+// RequestID assigns every request a fresh server-generated ID, exposed via
+// the X-Request-ID response header and GetRequestID. Recommendation
+// impressions are logged under it so follow-up interactions can refer back.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := newID()
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned by the RequestID middleware.
+func GetRequestID(c *gin.Context) string {
+	if v, ok := c.Get(requestIDKey); ok {
+		if id, ok := v.(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
 // newID returns a 128-bit opaque random identifier as hex.
 func newID() string {
 	b := make([]byte, 16)
@@ -98,6 +125,35 @@ func verifyToken(token string, key []byte) (string, bool) {
 	return id, true
 }
 
+// FeedTokens signs and verifies the IDs in feed URLs. Its key is derived
+// from the session secret under a separate label, so a session cookie never
+// verifies as a feed token or the other way round.
+type FeedTokens struct {
+	key []byte
+}
+
+// NewFeedTokens derives the feed-token key from the session secret
+func NewFeedTokens(secret string) *FeedTokens {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("w2w feed token"))
+	return &FeedTokens{key: mac.Sum(nil)}
+}
+
+// NewID returns a fresh random feed ID
+func (t *FeedTokens) NewID() string {
+	return newID()
+}
+
+// Sign returns the token to put in a feed URL
+func (t *FeedTokens) Sign(id string) string {
+	return signToken(id, t.key)
+}
+
+// Verify validates a feed token and returns the feed ID it carries
+func (t *FeedTokens) Verify(token string) (string, bool) {
+	return verifyToken(token, t.key)
+}
+
 // setSessionCookie writes the signed session cookie. It is marked Secure when
 // the request arrived over HTTPS (directly, or via a proxy that set
 // X-Forwarded-Proto), so it still works for plain-HTTP local development.
@@ -151,6 +207,34 @@ func AdminAuth(secret string) gin.HandlerFunc {
 	}
 }
 
+// WebSocketOrigin guards WebSocket upgrades against cross-site hijacking.
+// Browsers attach the session cookie to cross-origin WebSocket handshakes
+// and CORS does not apply to them, so the Origin header must be either this
+// host or one of the allowed CORS origins. Non-browser clients that send no
+// Origin are let through: they can only present their own cookie.
+func WebSocketOrigin(allowed []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || originAllowed(origin, c.Request.Host, allowed) {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Origin not allowed"})
+	}
+}
+
+func originAllowed(origin, host string, allowed []string) bool {
+	if u, err := url.Parse(origin); err == nil && u.Host == host {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
 // rateLimiter is a fixed-window, in-memory limiter keyed by an arbitrary string
 // (session ID, falling back to client IP).
 type rateLimiter struct {
@@ -168,6 +252,24 @@ type windowCounter struct {
 // RateLimit returns middleware allowing at most perMinute requests per key in
 // any rolling one-minute fixed window. A value <= 0 disables limiting.
 func RateLimit(perMinute int) gin.HandlerFunc {
+	return RateLimitBy(perMinute, func(c *gin.Context) string {
+		if key := GetUserID(c); key != "" {
+			return key
+		}
+		return "ip:" + c.ClientIP()
+	})
+}
+
+// RateLimitParam is RateLimit keyed by a path parameter instead of the
+// session, for clients such as feed readers that carry no cookie
+func RateLimitParam(perMinute int, param string) gin.HandlerFunc {
+	return RateLimitBy(perMinute, func(c *gin.Context) string {
+		return param + ":" + c.Param(param)
+	})
+}
+
+// RateLimitBy is RateLimit with a caller-chosen key
+func RateLimitBy(perMinute int, keyOf func(c *gin.Context) string) gin.HandlerFunc {
 	if perMinute <= 0 {
 		return func(c *gin.Context) { c.Next() }
 	}
@@ -180,12 +282,7 @@ func RateLimit(perMinute int) gin.HandlerFunc {
 	rl.startJanitor()
 
 	return func(c *gin.Context) {
-		key := GetUserID(c)
-		if key == "" {
-			key = "ip:" + c.ClientIP()
-		}
-
-		if !rl.allow(key) {
+		if !rl.allow(keyOf(c)) {
 			c.Header("Retry-After", "60")
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error": "Rate limit exceeded. Please slow down.",
@@ -15,7 +15,7 @@ type User struct {
 type Media struct {
 	ID              string    `json:"id" db:"id"`
 	Title           string    `json:"title" db:"title"`
-	MediaType       string    `json:"media_type" db:"media_type"` // "movie", "tv", "anime"
+	MediaType       string    `json:"media_type" db:"media_type"` // "movie", "tv", "anime", "documentary"
 	Year            int       `json:"year,omitempty" db:"year"`
 	PlotSummary     string    `json:"plot_summary,omitempty" db:"plot_summary"`
 	VibeProfile     string    `json:"vibe_profile" db:"vibe_profile"` // LLM-generated aesthetic description
@@ -25,9 +25,36 @@ type Media struct {
 	ExternalID      string    `json:"external_id,omitempty" db:"external_id"` // TMDB/IMDB ID
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+
+	// Durations in minutes; 0 means unknown. Series carry an episode
+	// runtime and counts, films a runtime.
+	RuntimeMinutes int `json:"runtime_minutes,omitempty" db:"runtime_minutes"`
+	EpisodeRuntime int `json:"episode_runtime,omitempty" db:"episode_runtime"`
+	EpisodeCount   int `json:"episode_count,omitempty" db:"episode_count"`
+	SeasonCount    int `json:"season_count,omitempty" db:"season_count"`
+}
+
+// WeekendMinutes is the viewing budget behind "finishable this weekend"
+const WeekendMinutes = 12 * 60
+
+// DurationFilter restricts results by how long they take to watch. Zero
+// fields are ignored; titles with unknown durations never pass a filter.
+type DurationFilter struct {
+	// MaxMinutes caps one sitting: a film's runtime or a series' episode runtime
+	MaxMinutes int `json:"max_minutes,omitempty"`
+	// MaxEpisodes caps a series' total episode count (films pass)
+	MaxEpisodes int `json:"max_episodes,omitempty"`
+	// Finishable keeps titles whose total runtime fits in WeekendMinutes
+	Finishable bool `json:"finishable,omitempty"`
+}
+
+// Active reports whether the filter restricts anything
+func (f DurationFilter) Active() bool {
+	return f.MaxMinutes > 0 || f.MaxEpisodes > 0 || f.Finishable
 }
 
-// SeenMedia tracks what a user has already watched
+// SeenMedia tracks what a user has watched, is watching or gave up on.
+// Any status keeps the title out of recommendations.
 type SeenMedia struct {
 	ID        int64     `json:"id" db:"id"`
 	UserID    string    `json:"user_id" db:"user_id"`
@@ -35,6 +62,29 @@ type SeenMedia struct {
 	Rating    *float64  `json:"rating,omitempty" db:"rating"` // Optional user rating 1-10
 	WatchedAt time.Time `json:"watched_at" db:"watched_at"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	Status       string    `json:"status" db:"status"`               // watching, on_hold, dropped or completed
+	Season       int       `json:"season,omitempty" db:"season"`     // Current season, 0 if not tracked
+	Episode      int       `json:"episode,omitempty" db:"episode"`   // Last episode watched within Season
+	RewatchCount int       `json:"rewatch_count" db:"rewatch_count"` // Times restarted after being completed
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	Media        *Media    `json:"media,omitempty"`
+}
+
+// Watch statuses. Planning is the watchlist; the rest are seen_media rows.
+const (
+	WatchPlanning  = "planning"
+	WatchWatching  = "watching"
+	WatchOnHold    = "on_hold"
+	WatchDropped   = "dropped"   // Gave up on it: a soft negative signal
+	WatchCompleted = "completed" // What POST /seen records
+)
+
+// ContinueWatchingItem is an in-progress title and the episode that comes next
+type ContinueWatchingItem struct {
+	SeenMedia
+	NextSeason  int `json:"next_season,omitempty"`
+	NextEpisode int `json:"next_episode"`
 }
 
 // VibeEmbedding stores the vector representation of a media's vibe profile
@@ -56,6 +106,7 @@ type RedditThread struct {
 	Score         int       `json:"score" db:"score"`
 	NumComments   int       `json:"num_comments" db:"num_comments"`
 	ScrapedAt     time.Time `json:"scraped_at" db:"scraped_at"`
+	PostedAt      time.Time `json:"posted_at" db:"posted_at"` // created_utc on Reddit
 }
 
 // RedditMention tracks when a show is mentioned in a thread
@@ -67,19 +118,271 @@ type RedditMention struct {
 	QualityBoost   float64 `json:"quality_boost" db:"quality_boost"`     // Boost from quality-related threads
 }
 
+// SubredditCount is how many threads were scraped from a subreddit
+type SubredditCount struct {
+	Subreddit string `json:"subreddit"`
+	Count     int    `json:"count"`
+}
+
+// Facet is one term of the controlled vibe vocabulary (e.g. "neon-noir")
+type Facet struct {
+	Slug       string `json:"slug" db:"slug"`
+	Category   string `json:"category" db:"category"` // "visual_style", "pacing", "emotional_texture", "atmosphere"
+	Label      string `json:"label" db:"label"`
+	MediaCount int    `json:"media_count" db:"media_count"` // Only populated by listing queries
+}
+
+// MediaFacet links a media entry to a facet with an extraction confidence
+type MediaFacet struct {
+	MediaID    string    `json:"media_id" db:"media_id"`
+	FacetSlug  string    `json:"facet_slug" db:"facet_slug"`
+	Confidence float64   `json:"confidence" db:"confidence"` // 0-1
+	Source     string    `json:"source" db:"source"`         // "llm" or "keyword"
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// FacetChip is the compact facet representation attached to recommendations
+type FacetChip struct {
+	Slug       string  `json:"slug"`
+	Category   string  `json:"category"`
+	Label      string  `json:"label"`
+	Confidence float64 `json:"confidence"`
+}
+
+// TasteCentroid is one mode of a user's taste: the rating-weighted sum of the
+// embeddings of the seen titles assigned to it
+type TasteCentroid struct {
+	UserID    string             `json:"user_id" db:"user_id"`
+	Index     int                `json:"index" db:"idx"`
+	Vector    []float32          `json:"-" db:"vector"` // Weighted sum; direction is what matters
+	WeightSum float64            `json:"weight_sum" db:"weight_sum"`
+	Members   map[string]float64 `json:"members" db:"members"` // media ID -> weight it contributed
+	UpdatedAt time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// ItemNeighbor is a collaborative-filtering neighbor: people who watched
+// MediaID also watched NeighborID
+type ItemNeighbor struct {
+	MediaID    string    `json:"media_id" db:"media_id"`
+	NeighborID string    `json:"neighbor_id" db:"neighbor_id"`
+	Score      float64   `json:"score" db:"score"`       // Shrunk rating-weighted cosine
+	CoCount    int       `json:"co_count" db:"co_count"` // Users who saw both
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// GemInput is what the gem scoring job knows about one title
+type GemInput struct {
+	MediaID     string
+	MediaType   string
+	VoteAverage float64 // TMDB 0-10; 0 with VoteCount 0 when unknown
+	VoteCount   int
+	Popularity  float64 // popularity_score
+	Mentions    int     // Distinct Reddit hidden_gem threads naming the title
+}
+
+// GemScore rates how much of a hidden gem a title is: well regarded
+// relative to the catalog, little known relative to its media type
+type GemScore struct {
+	MediaID       string    `json:"-" db:"media_id"`
+	Score         float64   `json:"score" db:"score"`               // 0-1
+	BayesRating   float64   `json:"bayes_rating" db:"bayes_rating"` // Vote average shrunk toward the catalog mean
+	RatingPct     float64   `json:"rating_pct" db:"rating_pct"`     // Percentile of BayesRating in the catalog
+	PopularityPct float64   `json:"popularity_pct" db:"popularity_pct"`
+	Mentions      int       `json:"mentions" db:"mentions"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	Media         *Media    `json:"-"`
+}
+
+// MentionActivity is one Reddit thread mentioning a title, as read by the
+// trending computation
+type MentionActivity struct {
+	MediaID     string
+	MediaType   string
+	ThreadID    string
+	Subreddit   string
+	Title       string
+	ThreadType  string
+	Score       int
+	NumComments int
+	PostedAt    time.Time // Falls back to scraped_at for threads stored before posted_at
+}
+
+// TrendingScore is a title's Reddit mention velocity: time-decayed thread
+// mentions per day, weighted by thread score and comment count
+type TrendingScore struct {
+	MediaID    string             `json:"-"`
+	Window     string             `json:"window"`     // "24h", "7d" or "30d"
+	Velocity   float64            `json:"velocity"`   // In Window
+	Velocities map[string]float64 `json:"velocities"` // Every window, for comparison
+	Mentions   int                `json:"mentions"`   // Threads in Window
+	Threads    []TrendingThread   `json:"threads"`    // Biggest contributors, most first
+}
+
+// TrendingThread is a thread that drove a title's velocity
+type TrendingThread struct {
+	ID           string    `json:"id"`
+	Subreddit    string    `json:"subreddit"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	ThreadType   string    `json:"thread_type"`
+	Score        int       `json:"score"`
+	NumComments  int       `json:"num_comments"`
+	PostedAt     time.Time `json:"posted_at"`
+	Contribution float64   `json:"contribution"` // Share of the title's velocity (0-1)
+}
+
+// Feed is a session's Atom/RSS feed of fresh picks. Its URL carries the
+// signed ID, so deleting the row revokes the URL.
+type Feed struct {
+	ID          string     `json:"-" db:"id"`
+	UserID      string     `json:"-" db:"user_id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	RefreshedAt *time.Time `json:"refreshed_at,omitempty" db:"refreshed_at"` // Nil until first fetched
+}
+
+// Where a feed item came from
+const (
+	FeedSourceForYou   = "for_you"
+	FeedSourceTrending = "trending"
+	FeedSourceNew      = "new"
+)
+
+// FeedItem is one pick published in a feed. PublishedAt is set when the pick
+// first appears and never changes, so readers see each title once.
+type FeedItem struct {
+	FeedID      string    `json:"-" db:"feed_id"`
+	MediaID     string    `json:"media_id" db:"media_id"`
+	Source      string    `json:"source" db:"source"`
+	Explanation string    `json:"explanation" db:"explanation"`
+	PublishedAt time.Time `json:"published_at" db:"published_at"`
+	Media       *Media    `json:"media,omitempty"`
+}
+
+// VibeAxis is a slider between two poles of a vibe (dark ↔ light, slow ↔
+// frenetic). Each pole is defined by anchor phrases; media positions run from
+// 0 at the low pole to 1 at the high pole.
+type VibeAxis struct {
+	Slug        string    `json:"slug" db:"slug"`
+	LowLabel    string    `json:"low_label" db:"low_label"`
+	HighLabel   string    `json:"high_label" db:"high_label"`
+	LowAnchors  []string  `json:"low_anchors" db:"low_anchors"`
+	HighAnchors []string  `json:"high_anchors" db:"high_anchors"`
+	Model       string    `json:"model" db:"model"` // Embedding model the anchors were embedded with
+	MediaCount  int       `json:"media_count"`      // Only populated by listing queries
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Pole centroids of the embedded anchors; nil until embedded
+	LowCentroid  []float32 `json:"-" db:"low_centroid"`
+	HighCentroid []float32 `json:"-" db:"high_centroid"`
+}
+
+// AxisRequest defines or redefines a vibe axis
+type AxisRequest struct {
+	Slug        string   `json:"slug" binding:"required"`
+	LowLabel    string   `json:"low_label" binding:"required"`
+	HighLabel   string   `json:"high_label" binding:"required"`
+	LowAnchors  []string `json:"low_anchors" binding:"required"`
+	HighAnchors []string `json:"high_anchors" binding:"required"`
+}
+
+// How an axis target applies
+const (
+	AxisModeSoft   = "soft"   // Rank by distance from the target
+	AxisModeFilter = "filter" // Drop titles outside the tolerance
+)
+
+// AxisTarget is a slider setting on a recommendation request
+type AxisTarget struct {
+	Axis      string  `json:"axis"`
+	Target    float64 `json:"target"`              // 0 = low pole, 1 = high pole
+	Tolerance float64 `json:"tolerance,omitempty"` // Distance that costs nothing (default 0.15)
+	Mode      string  `json:"mode,omitempty"`      // "soft" (default) or "filter"
+}
+
+// TasteDriver is a past title that pulled a recommendation into the results
+type TasteDriver struct {
+	MediaID    string  `json:"media_id"`
+	Title      string  `json:"title"`
+	Similarity float64 `json:"similarity"`
+}
+
 // Recommendation is the output format for the API
 type Recommendation struct {
-	Media       Media   `json:"media"`
-	VibeScore   float64 `json:"vibe_score"`  // Cosine similarity to query
-	Explanation string  `json:"explanation"` // LLM-generated reason for recommendation
-	Rank        int     `json:"rank"`
+	Media       Media         `json:"media"`
+	VibeScore   float64       `json:"vibe_score"`             // Cosine similarity to query
+	Score       float64       `json:"score,omitempty"`        // Final ranking score after personalization
+	CollabScore float64       `json:"collab_score,omitempty"` // Co-watch similarity, when it contributed
+	Explanation string        `json:"explanation"`            // LLM-generated reason for recommendation
+	Rank        int           `json:"rank"`
+	Facets      []FacetChip   `json:"facets,omitempty"`     // Structured vibe facets for UI chips
+	BecauseOf   []TasteDriver `json:"because_of,omitempty"` // Seen titles that drove this pick
+	OnWatchlist bool          `json:"on_watchlist,omitempty"`
+	MemberFits  []MemberFit   `json:"member_fits,omitempty"` // Group picks: how well it suits each member
+	Explored    bool          `json:"explored,omitempty"`    // Swapped in by the exploration policy
+	Gem         *GemScore     `json:"gem,omitempty"`         // Hidden-gem breakdown, on /hidden-gems
+
+	RatingSignal *RatingSignal      `json:"rating_signal,omitempty"` // Set when past ratings moved this pick
+	Trending     *TrendingScore     `json:"trending,omitempty"`      // Mention velocity and its threads, on /trending
+	Axes         map[string]float64 `json:"axes,omitempty"`          // Positions on the requested vibe axes
+	Bridge       []AnchorFit        `json:"bridge,omitempty"`        // Similarity to each anchor, on /bridge
+	Franchise    *FranchiseRef      `json:"franchise,omitempty"`     // Set when the pick stands in for its franchise
+}
+
+// AnchorFit is how close a bridge pick sits to one of the titles it bridges
+type AnchorFit struct {
+	MediaID    string  `json:"media_id"`
+	Title      string  `json:"title"`
+	Similarity float64 `json:"similarity"`
+}
+
+// RatingSignal explains a boost or demotion caused by the user's own ratings
+type RatingSignal struct {
+	Effect       string  `json:"effect"` // "boosted" or "demoted"
+	Delta        float64 `json:"delta"`  // Change applied to the ranking score
+	AnchorID     string  `json:"anchor_id"`
+	AnchorTitle  string  `json:"anchor_title"`
+	AnchorRating float64 `json:"anchor_rating"`
+	Similarity   float64 `json:"similarity"`          // Cosine between the pick and the anchor
+	Dismissed    bool    `json:"dismissed,omitempty"` // Anchor is a "not interested" dismissal, not a rating
+	Dropped      bool    `json:"dropped,omitempty"`   // Anchor is a title the user dropped, not a rating
 }
 
 // RecommendRequest is the input for the recommend endpoint.
 // Identity is derived server-side from the session cookie, never from the body.
 type RecommendRequest struct {
-	Query string `json:"query" binding:"required"` // Natural language vibe query
-	Limit int    `json:"limit,omitempty"`          // Max results (default 10)
+	Query  string   `json:"query" binding:"required"` // Natural language vibe query
+	Limit  int      `json:"limit,omitempty"`          // Max results (default 10)
+	Facets []string `json:"facets,omitempty"`         // Facet slugs every result must carry
+	// Personalization blends the user's taste profile into ranking (0-1, default 0)
+	Personalization *float64 `json:"personalization,omitempty"`
+	// RatingSignal overrides how strongly past ratings boost/demote similar titles
+	RatingSignal *float64 `json:"rating_signal,omitempty"`
+	// Watchlist controls saved titles: "exclude" drops them, "highlight"
+	// flags them with on_watchlist (default: treated like any other title)
+	Watchlist string `json:"watchlist,omitempty"`
+	// Explore picks the exploration policy: "thompson", "epsilon", "off"
+	// for a deterministic ranking, or "on" (default: the server setting)
+	Explore string `json:"explore,omitempty"`
+	// Duration filters; when unset, phrases in the query such as "I have
+	// 90 minutes" or "something I can finish this weekend" fill them in
+	MaxMinutes  *int  `json:"max_minutes,omitempty"`
+	MaxEpisodes *int  `json:"max_episodes,omitempty"`
+	Finishable  *bool `json:"finishable,omitempty"`
+	// Axes are vibe slider targets, as filters or soft ranking terms
+	Axes []AxisTarget `json:"axes,omitempty"`
+	// ExpandFranchises lists every matching entry of a franchise instead of
+	// one entry point
+	ExpandFranchises bool `json:"expand_franchises,omitempty"`
+}
+
+// BridgeRequest asks for titles that sit between two or more anchors, given
+// by media ID or by title. With a group ID, anything any member has seen or
+// dismissed is excluded.
+type BridgeRequest struct {
+	MediaIDs []string `json:"media_ids,omitempty"`
+	Titles   []string `json:"titles,omitempty"`
+	GroupID  string   `json:"group_id,omitempty"`
+	Limit    int      `json:"limit,omitempty"` // Max results (default 5)
 }
 
 // SeenRequest is the input for marking media as seen.
@@ -89,10 +392,498 @@ type SeenRequest struct {
 	Rating  *float64 `json:"rating,omitempty"` // Optional 1-10 rating
 }
 
+// ProgressRequest sets a title's watch status and/or progress. Omitted
+// fields keep their current value.
+// Identity is derived server-side from the session cookie, never from the body.
+type ProgressRequest struct {
+	MediaID string `json:"media_id" binding:"required"`
+	// Status is planning, watching, on_hold, dropped or completed (default:
+	// unchanged, or watching for a title not yet tracked)
+	Status       string   `json:"status,omitempty"`
+	Season       *int     `json:"season,omitempty"`
+	Episode      *int     `json:"episode,omitempty"`
+	Rating       *float64 `json:"rating,omitempty"`
+	RewatchCount *int     `json:"rewatch_count,omitempty"`
+}
+
+// Dismissal reasons
+const (
+	DismissNotInterested = "not_interested" // Permanent, and a soft negative signal
+	DismissAlreadyKnow   = "already_know"   // Permanent, no ranking effect
+	DismissSnooze        = "snooze"         // Hidden until Until passes
+)
+
+// Dismissal hides a title from a user's recommendations without touching
+// their watch history
+type Dismissal struct {
+	UserID    string     `json:"user_id" db:"user_id"`
+	MediaID   string     `json:"media_id" db:"media_id"`
+	Reason    string     `json:"reason" db:"reason"`
+	Until     *time.Time `json:"until,omitempty" db:"until"` // Only set for snoozes
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	Media     *Media     `json:"media,omitempty"`
+}
+
+// DismissRequest is the input for dismissing a recommendation.
+// Identity is derived server-side from the session cookie, never from the body.
+type DismissRequest struct {
+	MediaID string     `json:"media_id" binding:"required"`
+	Reason  string     `json:"reason" binding:"required"` // not_interested, already_know or snooze
+	Until   *time.Time `json:"until,omitempty"`           // Required for snooze (RFC 3339)
+}
+
+// Watchlist priorities
+const (
+	PriorityHigh   = 1
+	PriorityNormal = 2
+	PriorityLow    = 3
+)
+
+// WatchlistItem is a title the user saved to watch later
+type WatchlistItem struct {
+	UserID   string    `json:"user_id" db:"user_id"`
+	MediaID  string    `json:"media_id" db:"media_id"`
+	Priority int       `json:"priority" db:"priority"` // 1 (high) to 3 (low)
+	Position int       `json:"position" db:"position"` // User-defined order, 0 first
+	Note     string    `json:"note" db:"note"`
+	AddedAt  time.Time `json:"added_at" db:"added_at"`
+	Media    *Media    `json:"media,omitempty"`
+}
+
+// WatchlistRequest is the input for adding to the watchlist.
+// Identity is derived server-side from the session cookie, never from the body.
+type WatchlistRequest struct {
+	MediaID  string `json:"media_id" binding:"required"`
+	Priority *int   `json:"priority,omitempty"` // Default 2
+	Note     string `json:"note,omitempty"`
+}
+
+// WatchlistUpdateRequest changes an item's priority and/or note
+type WatchlistUpdateRequest struct {
+	Priority *int    `json:"priority,omitempty"`
+	Note     *string `json:"note,omitempty"`
+}
+
+// WatchlistReorderRequest moves the listed items to the top, in order;
+// unlisted items keep their relative order below them
+type WatchlistReorderRequest struct {
+	MediaIDs []string `json:"media_ids" binding:"required"`
+}
+
+// Collection is a user-curated, ordered list of titles. Private collections
+// are visible only to their owner; public ones to anyone with the slug.
+type Collection struct {
+	ID          string           `json:"id" db:"id"`
+	UserID      string           `json:"-" db:"user_id"` // Session IDs are never exposed
+	Title       string           `json:"title" db:"title"`
+	Description string           `json:"description" db:"description"`
+	Public      bool             `json:"public" db:"is_public"`
+	Slug        string           `json:"slug" db:"slug"`
+	VibeSummary string           `json:"vibe_summary" db:"vibe_summary"` // Generated from the items
+	ItemCount   int              `json:"item_count"`
+	Items       []CollectionItem `json:"items,omitempty"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// CollectionItem is one title in a collection
+type CollectionItem struct {
+	MediaID  string    `json:"media_id" db:"media_id"`
+	Position int       `json:"position" db:"position"`
+	Note     string    `json:"note" db:"note"`
+	AddedAt  time.Time `json:"added_at" db:"added_at"`
+	Media    *Media    `json:"media,omitempty"`
+}
+
+// CollectionMatch is a collection returned by collection search or
+// recommendation
+type CollectionMatch struct {
+	Collection Collection `json:"collection"`
+	Similarity float64    `json:"similarity"`
+}
+
+// CollectionRequest creates a collection or (with pointers left nil)
+// updates some of its fields
+type CollectionRequest struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Public      *bool   `json:"public,omitempty"`
+}
+
+// CollectionItemRequest adds a title to a collection or updates its note
+type CollectionItemRequest struct {
+	MediaID string `json:"media_id,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// Franchise sources and entry roles
+const (
+	FranchiseSourceTMDB   = "tmdb_collection"
+	FranchiseSourceManual = "manual"
+
+	FranchiseRoleMain    = "main"
+	FranchiseRoleSpinoff = "spinoff"
+)
+
+// Franchise groups sequels, seasons released as separate titles and
+// spin-offs, so search can show one entry point instead of every part
+type Franchise struct {
+	ID         string           `json:"id" db:"id"`
+	Name       string           `json:"name" db:"name"`
+	Source     string           `json:"source" db:"source"`
+	ExternalID string           `json:"external_id,omitempty" db:"external_id"`
+	EntryCount int              `json:"entry_count"`
+	Entries    []FranchiseEntry `json:"entries,omitempty"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+}
+
+// FranchiseEntry is one title of a franchise. Seen and EntryPoint are set
+// for the requesting user on the watch-order view.
+type FranchiseEntry struct {
+	MediaID    string `json:"media_id" db:"media_id"`
+	WatchOrder int    `json:"watch_order" db:"watch_order"`
+	Role       string `json:"role" db:"role"` // "main" or "spinoff"
+	Seen       bool   `json:"seen"`
+	EntryPoint bool   `json:"entry_point,omitempty"`
+	Media      *Media `json:"media,omitempty"`
+}
+
+// FranchiseRef marks a search result that stands in for its franchise
+type FranchiseRef struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Position  int      `json:"position"`             // Watch order of the title shown
+	Total     int      `json:"total"`                // Titles in the franchise
+	Seen      int      `json:"seen"`                 // Of those, seen or dismissed
+	MatchedID string   `json:"matched_id,omitempty"` // Later entry that matched, when the entry point replaced it
+	Collapsed []string `json:"collapsed,omitempty"`  // Other entries that matched and were folded in
+}
+
+// FranchiseRequest links titles into a franchise by hand, for series whose
+// seasons are separate titles and for spin-offs TMDB has no collection for
+type FranchiseRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	MediaIDs   []string `json:"media_ids" binding:"required"` // Main entries, in watch order
+	SpinoffIDs []string `json:"spinoff_ids,omitempty"`        // Watched after the main entries
+}
+
+// Onboarding quiz answers
+const (
+	AnswerSeen  = "seen"
+	AnswerLoved = "loved"
+	AnswerHated = "hated"
+	AnswerSkip  = "skip"
+)
+
+// OnboardingAnswer is one answered onboarding question
+type OnboardingAnswer struct {
+	MediaID    string    `json:"media_id" db:"media_id"`
+	Answer     string    `json:"answer" db:"answer"`
+	AnsweredAt time.Time `json:"answered_at" db:"answered_at"`
+}
+
+// OnboardingAnswerRequest answers the current onboarding question
+type OnboardingAnswerRequest struct {
+	MediaID string `json:"media_id" binding:"required"`
+	Answer  string `json:"answer" binding:"required"` // seen, loved, hated or skip
+}
+
+// OnboardingQuestion is the next title the quiz asks about
+type OnboardingQuestion struct {
+	Media  Media  `json:"media"`
+	Reason string `json:"reason"` // Why this title is the most informative next
+}
+
+// OnboardingState is where a session stands in the onboarding quiz: the
+// next question while it runs, the taste summary once it is done
+type OnboardingState struct {
+	Done     bool                `json:"done"`
+	Answered int                 `json:"answered"` // Questions answered, skips included
+	Rated    int                 `json:"rated"`    // Answers other than skip
+	Target   int                 `json:"target"`   // Rated answers that finish the quiz
+	Question *OnboardingQuestion `json:"question,omitempty"`
+	Summary  *TasteSummary       `json:"summary,omitempty"`
+}
+
+// TasteSummary is a first read of a user's taste
+type TasteSummary struct {
+	Text  string      `json:"text"`
+	Loved []string    `json:"loved"`
+	Liked []string    `json:"liked"`
+	Hated []string    `json:"hated"`
+	Modes []TasteMode `json:"modes"`
+}
+
+// TasteMode is one cluster of a user's taste, described by its titles and
+// the facets they share
+type TasteMode struct {
+	Share  float64  `json:"share"` // Fraction of the profile's weight
+	Titles []string `json:"titles"`
+	Facets []string `json:"facets,omitempty"`
+}
+
+// Journey is a saved watch sequence that drifts from one vibe to another.
+// Anyone with the slug can view it.
+type Journey struct {
+	ID        string        `json:"id" db:"id"`
+	UserID    string        `json:"-" db:"user_id"` // Session IDs are never exposed
+	Slug      string        `json:"slug" db:"slug"`
+	Title     string        `json:"title" db:"title"`
+	FromLabel string        `json:"from" db:"from_label"` // Starting query or title
+	ToLabel   string        `json:"to" db:"to_label"`     // Ending query or title
+	StepCount int           `json:"step_count"`
+	Steps     []JourneyStep `json:"steps,omitempty"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+// JourneyStep is one title on a journey
+type JourneyStep struct {
+	Position int    `json:"position" db:"position"` // 1-based
+	MediaID  string `json:"media_id" db:"media_id"`
+	Note     string `json:"note" db:"note"` // How this title carries on from the last
+	// StepSimilarity is the cosine to the previous title (to the starting
+	// vibe for the first step)
+	StepSimilarity float64 `json:"step_similarity" db:"step_similarity"`
+	// Progress is how far along the path from start to end this title sits
+	// (0 = starting vibe, 1 = ending vibe)
+	Progress float64 `json:"progress" db:"progress"`
+	Media    *Media  `json:"media,omitempty"`
+}
+
+// JourneyRequest asks for a journey. Each end is a vibe query or a media ID.
+type JourneyRequest struct {
+	FromQuery   string `json:"from_query,omitempty"`
+	FromMediaID string `json:"from_media_id,omitempty"`
+	ToQuery     string `json:"to_query,omitempty"`
+	ToMediaID   string `json:"to_media_id,omitempty"`
+	Length      int    `json:"length,omitempty"` // Titles on the journey (default 6)
+	Title       string `json:"title,omitempty"`  // Defaults to "<from> → <to>"
+}
+
+// Recommendation surfaces, as recorded on impressions
+const (
+	SurfaceSearch     = "search"
+	SurfaceSimilar    = "similar"
+	SurfaceHiddenGems = "hidden_gems"
+	SurfaceForYou     = "for_you"
+	SurfaceTrending   = "trending"
+	SurfaceBridge     = "bridge"
+)
+
+// Impression is the log of one recommendation response: what was asked,
+// what the pipeline considered, and what it finally showed
+type Impression struct {
+	RequestID  string                 `json:"request_id" db:"request_id"`
+	UserID     string                 `json:"user_id" db:"user_id"`
+	Surface    string                 `json:"surface" db:"surface"`
+	Query      string                 `json:"query,omitempty" db:"query"` // Search text or source media ID
+	Options    map[string]interface{} `json:"options" db:"options"`       // Pipeline settings in effect
+	Candidates []ImpressionItem       `json:"candidates" db:"candidates"` // Ranked pool before rerank/trim
+	Results    []ImpressionItem       `json:"results" db:"results"`       // Final order shown
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+
+	Interactions []Interaction `json:"interactions,omitempty"` // Filled in for export
+}
+
+// ImpressionItem is one title's position and scores within an impression
+type ImpressionItem struct {
+	MediaID   string  `json:"media_id"`
+	Rank      int     `json:"rank"`
+	VibeScore float64 `json:"vibe_score"`
+	Score     float64 `json:"score"`
+	Explored  bool    `json:"explored,omitempty"`
+}
+
+// ItemFeedback is how often a title was recommended and how often that led
+// to a positive interaction, across everyone
+type ItemFeedback struct {
+	Shown   int
+	Engaged int
+}
+
+// Interaction actions
+const (
+	ActionExpanded  = "expanded"
+	ActionClicked   = "clicked"
+	ActionSeen      = "seen"
+	ActionWatchlist = "watchlist"
+	ActionDismissed = "dismissed"
+)
+
+// Interaction is a follow-up action on a recommendation from an impression
+type Interaction struct {
+	ID        int64     `json:"id" db:"id"`
+	RequestID string    `json:"request_id" db:"request_id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	MediaID   string    `json:"media_id" db:"media_id"`
+	Action    string    `json:"action" db:"action"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// InteractionRequest is the input for logging an interaction.
+// Identity is derived server-side from the session cookie, never from the body.
+type InteractionRequest struct {
+	RequestID string `json:"request_id" binding:"required"` // From the recommendation response
+	MediaID   string `json:"media_id" binding:"required"`
+	Action    string `json:"action" binding:"required"` // expanded, clicked, seen, watchlist or dismissed
+}
+
+// Group aggregation strategies: how per-member fit becomes a group score
+const (
+	GroupStrategyAverage      = "average"       // Mean fit across members
+	GroupStrategyLeastMisery  = "least_misery"  // Lowest member fit: nobody hates it
+	GroupStrategyMostPleasure = "most_pleasure" // Highest member fit: someone loves it
+)
+
+// Group is a watch party: several sessions pooling their history to pick
+// something together. Members join with the invite code.
+type Group struct {
+	ID         string        `json:"id" db:"id"`
+	Name       string        `json:"name" db:"name"`
+	InviteCode string        `json:"invite_code" db:"invite_code"`
+	OwnerID    string        `json:"-" db:"owner_id"` // Session IDs are never exposed
+	Members    []GroupMember `json:"members,omitempty"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+}
+
+// GroupMember is one session in a group, known to the others by nickname
+type GroupMember struct {
+	UserID   string    `json:"-" db:"user_id"`
+	Nickname string    `json:"nickname" db:"nickname"`
+	IsOwner  bool      `json:"is_owner"`
+	IsYou    bool      `json:"is_you"`
+	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// MemberFit is how well a group pick suits one member (0-1, higher is
+// better). Fit is nil when the member has no history to judge by.
+type MemberFit struct {
+	UserID   string   `json:"-"`
+	Nickname string   `json:"nickname"`
+	Fit      *float64 `json:"fit"`
+}
+
+// GroupRequest is the input for creating a group
+type GroupRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Nickname string `json:"nickname"` // How the creator appears to others
+}
+
+// GroupJoinRequest is the input for joining a group by invite code
+type GroupJoinRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+	Nickname   string `json:"nickname"`
+}
+
+// Watch-party room phases
+const (
+	RoomLobby     = "lobby"     // Waiting for someone to start a round
+	RoomSearching = "searching" // Building the shortlist
+	RoomVoting    = "voting"    // Countdown running
+	RoomDecided   = "decided"   // Winner picked; a new round can start
+)
+
+// RoomMessage is a client-to-server message in a watch-party room.
+// Type is one of start, vote, veto, suggest, finish or state.
+type RoomMessage struct {
+	Type      string `json:"type"`
+	Query     string `json:"query,omitempty"`     // start: the vibe to shortlist for
+	Size      int    `json:"size,omitempty"`      // start: shortlist length
+	Countdown int    `json:"countdown,omitempty"` // start: voting time in seconds
+	MarkSeen  bool   `json:"mark_seen,omitempty"` // start: mark the winner seen for everyone who took part
+	MediaID   string `json:"media_id,omitempty"`  // vote, veto, suggest
+	Value     int    `json:"value,omitempty"`     // vote: 1 up, -1 down, 0 clear
+}
+
+// RoomEvent is a server-to-client message: the room state after any
+// change, or an error for the sender
+type RoomEvent struct {
+	Type  string     `json:"type"` // "state" or "error"
+	State *RoomState `json:"state,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// RoomState is a watch-party room as seen by one participant
+type RoomState struct {
+	GroupID      string            `json:"group_id"`
+	Phase        string            `json:"phase"`
+	Round        int               `json:"round"`
+	Query        string            `json:"query,omitempty"`
+	Shortlist    []RoomCandidate   `json:"shortlist"`
+	Participants []RoomParticipant `json:"participants"`
+	Deadline     *time.Time        `json:"deadline,omitempty"`
+	SecondsLeft  int               `json:"seconds_left,omitempty"`
+	Winner       *RoomCandidate    `json:"winner,omitempty"`
+	MarkSeen     bool              `json:"mark_seen"`
+	YourVeto     string            `json:"your_veto,omitempty"`
+}
+
+// RoomCandidate is one title on a room's shortlist
+type RoomCandidate struct {
+	MediaID     string `json:"media_id"`
+	Title       string `json:"title"`
+	MediaType   string `json:"media_type"`
+	Year        int    `json:"year,omitempty"`
+	Up          int    `json:"up"`
+	Down        int    `json:"down"`
+	Score       int    `json:"score"`
+	Vetoed      bool   `json:"vetoed"`
+	VetoedBy    string `json:"vetoed_by,omitempty"`
+	SuggestedBy string `json:"suggested_by,omitempty"`
+	YourVote    int    `json:"your_vote"`
+}
+
+// RoomParticipant is a group member as shown in a room
+type RoomParticipant struct {
+	Nickname string `json:"nickname"`
+	Online   bool   `json:"online"`
+	Voted    bool   `json:"voted"`
+	IsYou    bool   `json:"is_you"`
+}
+
+// SimilarJudgment is human-labelled "if you liked X, watch Y" evidence
+// mined from Reddit similar_to threads
+type SimilarJudgment struct {
+	ReferenceID string    `json:"reference_id" db:"reference_id"`
+	MediaID     string    `json:"media_id" db:"media_id"`
+	Weight      float64   `json:"weight" db:"weight"`             // Sum of log-scaled thread scores
+	ThreadCount int       `json:"thread_count" db:"thread_count"` // Threads recommending it
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SimilarMention is one catalog title mentioned in a similar_to thread
+// whose reference show resolved to a catalog title
+type SimilarMention struct {
+	ReferenceID string
+	MediaID     string
+	ThreadID    string
+	Score       int
+}
+
+// SimilarQualityRun is one scoring of GetSimilarToMedia against the mined
+// judgments
+type SimilarQualityRun struct {
+	ID             int64     `json:"id" db:"id"`
+	K              int       `json:"k" db:"k"`
+	ReferenceCount int       `json:"reference_count" db:"reference_count"`
+	JudgmentCount  int       `json:"judgment_count" db:"judgment_count"`
+	NDCG           float64   `json:"ndcg" db:"ndcg"`
+	Recall         float64   `json:"recall" db:"recall"`
+	MRR            float64   `json:"mrr" db:"mrr"`
+	EmbeddingModel string    `json:"embedding_model" db:"embedding_model"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
 // VibeProfileRequest is used when generating a vibe profile for new media
 type VibeProfileRequest struct {
 	Title     string `json:"title" binding:"required"`
 	MediaType string `json:"media_type" binding:"required"`
 	Year      int    `json:"year,omitempty"`
 	Synopsis  string `json:"synopsis,omitempty"`
+
+	// Optional durations in minutes (see Media)
+	RuntimeMinutes int `json:"runtime_minutes,omitempty"`
+	EpisodeRuntime int `json:"episode_runtime,omitempty"`
+	EpisodeCount   int `json:"episode_count,omitempty"`
+	SeasonCount    int `json:"season_count,omitempty"`
 }
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"w2w/internal/database"
+	"w2w/internal/embeddings"
+	"w2w/internal/models"
+)
+
+const (
+	// DefaultAxisTolerance is how far from a target a title may sit at no
+	// cost when the request does not say
+	DefaultAxisTolerance = 0.15
+	// axisSoftWeight scales the score penalty per unit of distance beyond
+	// the tolerance for soft axis targets
+	axisSoftWeight = 0.5
+	// maxAxisAnchors caps the anchor phrases per pole
+	maxAxisAnchors = 10
+)
+
+var axisSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// defaultAxes are seeded into an empty catalog; admins can redefine or
+// delete them
+var defaultAxes = []models.VibeAxis{
+	{
+		Slug: "dark-light", LowLabel: "Dark", HighLabel: "Light",
+		LowAnchors: []string{
+			"bleak, grim and nihilistic",
+			"oppressive darkness and despair",
+			"violent, brutal and morally bankrupt",
+		},
+		HighAnchors: []string{
+			"warm, hopeful and uplifting",
+			"lighthearted, playful and sunny",
+			"gentle comfort and kindness",
+		},
+	},
+	{
+		Slug: "slow-frenetic", LowLabel: "Slow", HighLabel: "Frenetic",
+		LowAnchors: []string{
+			"meditative slow burn with long quiet takes",
+			"patient, contemplative and unhurried",
+			"glacial pacing and lingering silence",
+		},
+		HighAnchors: []string{
+			"frenetic, breakneck and relentless",
+			"hyperkinetic action and rapid-fire cuts",
+			"manic energy that never lets up",
+		},
+	},
+	{
+		Slug: "cozy-unsettling", LowLabel: "Cozy", HighLabel: "Unsettling",
+		LowAnchors: []string{
+			"cozy, comforting and safe",
+			"found family and warm everyday moments",
+			"a soothing blanket of a story",
+		},
+		HighAnchors: []string{
+			"unsettling, paranoid and eerie",
+			"creeping dread and psychological horror",
+			"disturbing, surreal and uncanny",
+		},
+	},
+}
+
+// AxisService defines vibe axes from anchor phrases and keeps every title's
+// position on them in step with its embedding. An axis is the direction from
+// the low pole's anchor centroid to the high pole's; a title's raw
+// projection is how much closer it sits to the high pole, rescaled to 0-1
+// across the catalog.
+type AxisService struct {
+	db       database.AxisRepository
+	embedder embeddings.Provider
+	mu       sync.RWMutex
+	axes     map[string]*models.VibeAxis
+}
+
+// NewAxisService creates an axis service and loads the defined axes,
+// seeding the defaults when none are defined. Anchors are embedded by Sync.
+func NewAxisService(db database.AxisRepository, embedder embeddings.Provider) (*AxisService, error) {
+	return newAxisService(db, embedder, true)
+}
+
+// newAxisService creates an axis service, seeding the default axes only
+// when seed is set
+func newAxisService(db database.AxisRepository, embedder embeddings.Provider, seed bool) (*AxisService, error) {
+	axes, err := db.GetAxes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load axes: %w", err)
+	}
+	if len(axes) == 0 && seed {
+		for i := range defaultAxes {
+			if err := db.InsertAxisIfMissing(&defaultAxes[i]); err != nil {
+				return nil, fmt.Errorf("failed to seed axis %s: %w", defaultAxes[i].Slug, err)
+			}
+		}
+		if axes, err = db.GetAxes(); err != nil {
+			return nil, fmt.Errorf("failed to load axes: %w", err)
+		}
+	}
+
+	svc := &AxisService{db: db, embedder: embedder, axes: make(map[string]*models.VibeAxis, len(axes))}
+	for i := range axes {
+		svc.axes[axes[i].Slug] = &axes[i]
+	}
+	return svc, nil
+}
+
+// embedAnchors (re)computes an axis's pole centroids with the current model
+func (s *AxisService) embedAnchors(axis *models.VibeAxis) error {
+	low, err := s.centroid(axis.LowAnchors)
+	if err != nil {
+		return fmt.Errorf("failed to embed %s anchors: %w", axis.LowLabel, err)
+	}
+	high, err := s.centroid(axis.HighAnchors)
+	if err != nil {
+		return fmt.Errorf("failed to embed %s anchors: %w", axis.HighLabel, err)
+	}
+	axis.LowCentroid = low
+	axis.HighCentroid = high
+	axis.Model = s.embedder.ModelName()
+	return nil
+}
+
+// centroid is the mean of the anchor phrases' embeddings
+func (s *AxisService) centroid(anchors []string) ([]float32, error) {
+	var sum []float64
+	for _, anchor := range anchors {
+		vec, err := s.embedder.Embed(anchor)
+		if err != nil {
+			return nil, err
+		}
+		if sum == nil {
+			sum = make([]float64, len(vec))
+		}
+		if len(vec) != len(sum) {
+			return nil, fmt.Errorf("anchor %q has %d dimensions, want %d", anchor, len(vec), len(sum))
+		}
+		for i, v := range vec {
+			sum[i] += float64(v)
+		}
+	}
+	centroid := make([]float32, len(sum))
+	for i, v := range sum {
+		centroid[i] = float32(v / float64(len(anchors)))
+	}
+	return centroid, nil
+}
+
+// project is a title's raw position on an axis: its cosine to the high pole
+// minus its cosine to the low pole
+func project(axis *models.VibeAxis, vec []float32) float64 {
+	return embeddings.CosineSimilarity(vec, axis.HighCentroid) - embeddings.CosineSimilarity(vec, axis.LowCentroid)
+}
+
+// Sync embeds anchors that are missing or were embedded with another model,
+// then projects every title whose embedding is newer than its positions.
+// Returns how many title positions were written.
+func (s *AxisService) Sync(store *embeddings.VectorStore) (int, error) {
+	embeddedAt, err := s.db.GetEmbeddingTimes()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load embedding times: %w", err)
+	}
+	projectedAt, err := s.db.GetAxisProjectionTimes()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load axis positions: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	written := 0
+	for _, axis := range s.sortedAxes() {
+		reembedded := false
+		if axis.Model != s.embedder.ModelName() || axis.LowCentroid == nil || axis.HighCentroid == nil {
+			if err := s.embedAnchors(axis); err != nil {
+				return written, fmt.Errorf("axis %s: %w", axis.Slug, err)
+			}
+			if err := s.db.SaveAxis(axis); err != nil {
+				return written, fmt.Errorf("failed to save axis %s: %w", axis.Slug, err)
+			}
+			reembedded = true
+		}
+
+		raw := make(map[string]float64)
+		store.Each(func(id string, vec []float32) {
+			at, ok := projectedAt[axis.Slug][id]
+			if reembedded || !ok || at.Before(embeddedAt[id]) {
+				raw[id] = project(axis, vec)
+			}
+		})
+		if len(raw) == 0 {
+			continue
+		}
+		if err := s.db.SaveAxisProjections(axis.Slug, raw); err != nil {
+			return written, fmt.Errorf("failed to save positions on %s: %w", axis.Slug, err)
+		}
+		written += len(raw)
+	}
+	return written, nil
+}
+
+// sortedAxes returns the axes in slug order. Callers hold the lock.
+func (s *AxisService) sortedAxes() []*models.VibeAxis {
+	axes := make([]*models.VibeAxis, 0, len(s.axes))
+	for _, a := range s.axes {
+		axes = append(axes, a)
+	}
+	sort.Slice(axes, func(i, j int) bool { return axes[i].Slug < axes[j].Slug })
+	return axes
+}
+
+// ProjectMedia places a newly (re)embedded title on every axis
+func (s *AxisService) ProjectMedia(mediaID string, vec []float32) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, axis := range s.axes {
+		if axis.LowCentroid == nil || axis.HighCentroid == nil {
+			continue
+		}
+		if err := s.db.SaveAxisProjections(axis.Slug, map[string]float64{mediaID: project(axis, vec)}); err != nil {
+			return fmt.Errorf("failed to save position on %s: %w", axis.Slug, err)
+		}
+	}
+	return nil
+}
+
+// Define creates or redefines an axis, embeds its anchors and projects every
+// title in the store onto it
+func (s *AxisService) Define(req models.AxisRequest, store *embeddings.VectorStore) (*models.VibeAxis, error) {
+	axis := &models.VibeAxis{
+		Slug:        strings.TrimSpace(req.Slug),
+		LowLabel:    strings.TrimSpace(req.LowLabel),
+		HighLabel:   strings.TrimSpace(req.HighLabel),
+		LowAnchors:  cleanAnchors(req.LowAnchors),
+		HighAnchors: cleanAnchors(req.HighAnchors),
+	}
+	if !axisSlugPattern.MatchString(axis.Slug) {
+		return nil, fmt.Errorf("slug must be lowercase letters, digits and dashes")
+	}
+	if axis.LowLabel == "" || axis.HighLabel == "" {
+		return nil, fmt.Errorf("both poles need a label")
+	}
+	for _, anchors := range [][]string{axis.LowAnchors, axis.HighAnchors} {
+		if len(anchors) == 0 || len(anchors) > maxAxisAnchors {
+			return nil, fmt.Errorf("each pole needs 1-%d anchor phrases", maxAxisAnchors)
+		}
+	}
+
+	if err := s.embedAnchors(axis); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.db.SaveAxis(axis); err != nil {
+		return nil, fmt.Errorf("failed to save axis: %w", err)
+	}
+	raw := make(map[string]float64, store.Size())
+	store.Each(func(id string, vec []float32) {
+		raw[id] = project(axis, vec)
+	})
+	if len(raw) > 0 {
+		if err := s.db.SaveAxisProjections(axis.Slug, raw); err != nil {
+			return nil, fmt.Errorf("failed to save positions: %w", err)
+		}
+	}
+	axis.MediaCount = len(raw)
+	s.axes[axis.Slug] = axis
+	return axis, nil
+}
+
+func cleanAnchors(anchors []string) []string {
+	var cleaned []string
+	for _, a := range anchors {
+		if a = strings.TrimSpace(a); a != "" {
+			cleaned = append(cleaned, a)
+		}
+	}
+	return cleaned
+}
+
+// Delete removes an axis and its positions. Returns false if there was no
+// such axis.
+func (s *AxisService) Delete(slug string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed, err := s.db.DeleteAxis(slug)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete axis: %w", err)
+	}
+	delete(s.axes, slug)
+	return removed, nil
+}
+
+// List returns every axis with how many titles have a position on it
+func (s *AxisService) List() ([]models.VibeAxis, error) {
+	return s.db.GetAxes()
+}
+
+// Validate checks slider targets against the defined axes, filling in the
+// default tolerance and mode
+func (s *AxisService) Validate(targets []models.AxisTarget) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool, len(targets))
+	for i := range targets {
+		t := &targets[i]
+		if _, ok := s.axes[t.Axis]; !ok {
+			return fmt.Errorf("unknown axis: %s", t.Axis)
+		}
+		if seen[t.Axis] {
+			return fmt.Errorf("axis %s given more than once", t.Axis)
+		}
+		seen[t.Axis] = true
+		if t.Target < 0 || t.Target > 1 {
+			return fmt.Errorf("axis %s: target must be between 0 and 1", t.Axis)
+		}
+		if t.Tolerance < 0 || t.Tolerance > 1 {
+			return fmt.Errorf("axis %s: tolerance must be between 0 and 1", t.Axis)
+		}
+		if t.Tolerance == 0 {
+			t.Tolerance = DefaultAxisTolerance
+		}
+		switch t.Mode {
+		case "":
+			t.Mode = models.AxisModeSoft
+		case models.AxisModeSoft, models.AxisModeFilter:
+		default:
+			return fmt.Errorf("axis %s: mode must be %q or %q", t.Axis, models.AxisModeSoft, models.AxisModeFilter)
+		}
+	}
+	return nil
+}
+
+// MatchingMediaIDs returns titles within tolerance of every filter-mode
+// target, or nil if no target filters
+func (s *AxisService) MatchingMediaIDs(targets []models.AxisTarget) (map[string]bool, error) {
+	var allowIDs map[string]bool
+	for _, t := range targets {
+		if t.Mode != models.AxisModeFilter {
+			continue
+		}
+		ids, err := s.db.GetMediaIDsOnAxis(t.Axis, t.Target-t.Tolerance, t.Target+t.Tolerance)
+		if err != nil {
+			return nil, err
+		}
+		allowIDs = intersectIDs(allowIDs, ids)
+	}
+	return allowIDs, nil
+}
+
+// apply attaches each candidate's positions on the requested axes and, for
+// soft targets, demotes it by how far it sits beyond the tolerance
+func (s *AxisService) apply(targets []models.AxisTarget, pool []models.Recommendation) error {
+	slugs := make([]string, len(targets))
+	for i, t := range targets {
+		slugs[i] = t.Axis
+	}
+	ids := make([]string, len(pool))
+	for i, r := range pool {
+		ids[i] = r.Media.ID
+	}
+	positions, err := s.db.GetAxisPositions(slugs, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range pool {
+		pos := positions[pool[i].Media.ID]
+		if len(pos) == 0 {
+			continue
+		}
+		pool[i].Axes = pos
+		for _, t := range targets {
+			p, ok := pos[t.Axis]
+			if !ok || t.Mode != models.AxisModeSoft {
+				continue
+			}
+			pool[i].Score -= axisSoftWeight * math.Max(0, math.Abs(p-t.Target)-t.Tolerance)
+		}
+	}
+	return nil
+}
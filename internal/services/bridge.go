@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"w2w/internal/embeddings"
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+const (
+	// MaxBridgeAnchors caps how many titles one bridge can sit between
+	MaxBridgeAnchors = 5
+	// bridgeCandidateFactor widens the pool around the midpoint so ranking
+	// by the weakest anchor has picks to choose from
+	bridgeCandidateFactor = 10
+)
+
+var (
+	// ErrUnknownAnchor is returned when a bridge anchor names no indexed title
+	ErrUnknownAnchor = errors.New("unknown bridge anchor")
+	// ErrAnchorCount is returned when a bridge has too few or too many
+	// distinct anchors
+	ErrAnchorCount = fmt.Errorf("a bridge needs 2-%d different titles", MaxBridgeAnchors)
+)
+
+// BridgeConfig holds the options for a bridge request
+type BridgeConfig struct {
+	UserIDs  []string // Every requesting session; their seen and dismissed titles are excluded
+	MediaIDs []string
+	Titles   []string // Resolved to media IDs by exact (case-insensitive) title
+	Limit    int
+}
+
+// slerp interpolates between two vectors along the unit sphere, t of the
+// way from a to b. Nearly parallel vectors fall back to a normalized lerp.
+// Opposite vectors have no single great circle between them, so the path
+// turns through a fixed direction perpendicular to a.
+func slerp(a, b []float32, t float64) []float32 {
+	a, b = normalize(a), normalize(b)
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	if dot < -1+1e-6 {
+		p := perpendicular(a)
+		wa, wp := math.Cos(t*math.Pi), math.Sin(t*math.Pi)
+		out := make([]float32, len(a))
+		for i := range a {
+			out[i] = float32(wa*float64(a[i]) + wp*float64(p[i]))
+		}
+		return normalize(out)
+	}
+	omega := math.Acos(math.Max(-1, math.Min(1, dot)))
+	wa, wb := 1-t, t
+	if sin := math.Sin(omega); sin > 1e-6 {
+		wa = math.Sin((1-t)*omega) / sin
+		wb = math.Sin(t*omega) / sin
+	}
+	out := make([]float32, len(a))
+	for i := range a {
+		out[i] = float32(wa*float64(a[i]) + wb*float64(b[i]))
+	}
+	return normalize(out)
+}
+
+// perpendicular returns a unit vector orthogonal to the unit vector a, by
+// projecting a out of the axis a is least aligned with
+func perpendicular(a []float32) []float32 {
+	axis := 0
+	for i := range a {
+		if math.Abs(float64(a[i])) < math.Abs(float64(a[axis])) {
+			axis = i
+		}
+	}
+	p := make([]float32, len(a))
+	for i := range a {
+		p[i] = -a[axis] * a[i]
+	}
+	p[axis]++
+	return normalize(p)
+}
+
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// sphericalMidpoint folds the vectors together with slerp, giving the k-th
+// vector a 1/k share of the angle so later anchors don't dominate. With two
+// anchors it is the slerp midpoint; with more it approximates the spherical
+// mean.
+func sphericalMidpoint(vecs [][]float32) []float32 {
+	mid := normalize(vecs[0])
+	for k := 1; k < len(vecs); k++ {
+		mid = slerp(mid, vecs[k], 1/float64(k+1))
+	}
+	return mid
+}
+
+// Bridge finds titles that sit between two or more anchors. Candidates come
+// from around the spherical midpoint of the anchors' embeddings and are
+// ranked by their weakest similarity to any anchor, so a pick close to one
+// anchor but far from the other loses to one near both.
+func (s *VibeSearchService) Bridge(config BridgeConfig) (*SearchResult, error) {
+	if config.Limit <= 0 {
+		config.Limit = 5
+	}
+
+	anchors, err := s.resolveAnchors(config.MediaIDs, config.Titles)
+	if err != nil {
+		return nil, err
+	}
+	if len(anchors) < 2 || len(anchors) > MaxBridgeAnchors {
+		return nil, ErrAnchorCount
+	}
+	vecs := make([][]float32, len(anchors))
+	for i, a := range anchors {
+		vec, ok := s.vectorStore.Get(a.ID)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no embedding", ErrUnknownAnchor, a.Title)
+		}
+		vecs[i] = vec
+	}
+
+	// Everything any requester has seen or dismissed, and the anchors
+	// themselves, are off the table
+	excludedIDs := make(map[string]bool)
+	for _, userID := range config.UserIDs {
+		ids, err := s.db.GetExcludedMediaIDs(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get excluded media: %w", err)
+		}
+		for id := range ids {
+			excludedIDs[id] = true
+		}
+	}
+	filteredCount := len(excludedIDs)
+	for _, a := range anchors {
+		excludedIDs[a.ID] = true
+	}
+
+	candidates := s.vectorStore.Search(sphericalMidpoint(vecs), config.Limit*bridgeCandidateFactor, excludedIDs)
+	var pool []models.Recommendation
+	for _, c := range candidates {
+		vec, ok := s.vectorStore.Get(c.MediaID)
+		if !ok {
+			continue
+		}
+		media, err := s.db.GetMedia(c.MediaID)
+		if err != nil || media == nil {
+			continue
+		}
+		rec := models.Recommendation{Media: *media, VibeScore: c.Similarity, Score: math.Inf(1)}
+		for i, a := range anchors {
+			sim := embeddings.CosineSimilarity(vec, vecs[i])
+			rec.Bridge = append(rec.Bridge, models.AnchorFit{MediaID: a.ID, Title: a.Title, Similarity: sim})
+			rec.Score = math.Min(rec.Score, sim)
+		}
+		pool = append(pool, rec)
+	}
+	sortByScore(pool)
+	ranked := rankedItems(pool)
+
+	recommendations := []models.Recommendation{}
+	for i := range pool {
+		if len(recommendations) >= config.Limit {
+			break
+		}
+		pool[i].Rank = len(recommendations) + 1
+		recommendations = append(recommendations, pool[i])
+	}
+	s.explainBridgePicks(anchors, recommendations)
+	if err := s.facets.AttachChips(recommendations); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+
+	titles := make([]string, len(anchors))
+	for i, a := range anchors {
+		titles[i] = a.Title
+	}
+	return &SearchResult{
+		Recommendations: recommendations,
+		Query:           strings.Join(titles, " + "),
+		TotalCandidates: len(candidates),
+		FilteredCount:   filteredCount,
+		Candidates:      ranked,
+	}, nil
+}
+
+// resolveAnchors loads the anchors named by ID or title, dropping repeats
+func (s *VibeSearchService) resolveAnchors(mediaIDs, titles []string) ([]models.Media, error) {
+	var anchors []models.Media
+	picked := make(map[string]bool)
+	add := func(m *models.Media) {
+		if !picked[m.ID] {
+			picked[m.ID] = true
+			anchors = append(anchors, *m)
+		}
+	}
+
+	for _, id := range mediaIDs {
+		m, err := s.db.GetMedia(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get media: %w", err)
+		}
+		if m == nil {
+			return nil, fmt.Errorf("%w: no media with ID %s", ErrUnknownAnchor, id)
+		}
+		add(m)
+	}
+	for _, title := range titles {
+		m, err := s.db.GetMediaByTitle(title)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get media: %w", err)
+		}
+		if m == nil {
+			return nil, fmt.Errorf("%w: no media titled %q", ErrUnknownAnchor, title)
+		}
+		add(m)
+	}
+	return anchors, nil
+}
+
+// explainBridgePicks asks the LLM what each pick borrows from each anchor,
+// falling back to the similarities
+func (s *VibeSearchService) explainBridgePicks(anchors []models.Media, recs []models.Recommendation) {
+	var explanations map[string]string
+	if s.llmClient != nil && len(recs) > 0 {
+		between := make([]llm.BridgeAnchor, len(anchors))
+		for i, a := range anchors {
+			between[i] = llm.BridgeAnchor{Title: a.Title, VibeProfile: a.VibeProfile}
+		}
+		picks := make([]llm.BridgePick, len(recs))
+		for i, r := range recs {
+			picks[i] = llm.BridgePick{
+				MediaID:      r.Media.ID,
+				Title:        r.Media.Title,
+				VibeProfile:  r.Media.VibeProfile,
+				Similarities: make(map[string]float64, len(r.Bridge)),
+			}
+			for _, f := range r.Bridge {
+				picks[i].Similarities[f.Title] = f.Similarity
+			}
+		}
+
+		var err error
+		explanations, err = s.llmClient.ExplainBridgePicks(between, picks)
+		if err != nil {
+			log.Printf("Failed to explain bridge picks: %v", err)
+		}
+	}
+
+	for i := range recs {
+		if e := explanations[recs[i].Media.ID]; e != "" {
+			recs[i].Explanation = e
+			continue
+		}
+		recs[i].Explanation = bridgeExplanation(recs[i].Bridge)
+	}
+}
+
+// bridgeExplanation summarizes how close a pick sits to each anchor
+func bridgeExplanation(fits []models.AnchorFit) string {
+	parts := make([]string, len(fits))
+	for i, f := range fits {
+		parts[i] = fmt.Sprintf("%s (%.0f%%)", f.Title, f.Similarity*100)
+	}
+	last := len(parts) - 1
+	return "Sits between " + strings.Join(parts[:last], ", ") + " and " + parts[last]
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"w2w/internal/embeddings"
+)
+
+func assertVec(t *testing.T, name string, got []float32, want ...float64) {
+	t.Helper()
+	for i := range want {
+		if math.Abs(float64(got[i])-want[i]) > 1e-6 {
+			t.Errorf("%s = %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func TestSlerp(t *testing.T) {
+	r := 1 / math.Sqrt2
+	x, y := []float32{1, 0, 0}, []float32{0, 1, 0}
+
+	assertVec(t, "slerp(x, y, 0)", slerp(x, y, 0), 1, 0, 0)
+	assertVec(t, "slerp(x, y, 1)", slerp(x, y, 1), 0, 1, 0)
+	assertVec(t, "slerp(x, y, 0.5)", slerp(x, y, 0.5), r, r, 0)
+	// A third of the way along a right angle is 30 degrees
+	assertVec(t, "slerp(x, y, 1/3)", slerp(x, y, 1.0/3), math.Sqrt(3)/2, 0.5, 0)
+	// Lengths don't matter, only directions
+	assertVec(t, "slerp(3x, 2y, 0.5)", slerp([]float32{3, 0, 0}, []float32{0, 2, 0}, 0.5), r, r, 0)
+
+	// Identical vectors stay put instead of dividing by sin(0)
+	assertVec(t, "slerp(x, x, 0.5)", slerp(x, x, 0.5), 1, 0, 0)
+
+	// Opposite vectors turn through a perpendicular, ending at b
+	minusX := []float32{-1, 0, 0}
+	mid := slerp(x, minusX, 0.5)
+	if n := embeddings.CosineSimilarity(mid, mid); math.Abs(n-1) > 1e-6 {
+		t.Fatalf("antipodal midpoint %v is not a unit vector", mid)
+	}
+	if dot := embeddings.CosineSimilarity(mid, x); math.Abs(dot) > 1e-6 {
+		t.Errorf("antipodal midpoint %v is not perpendicular to both ends", mid)
+	}
+	assertVec(t, "slerp(x, -x, 1)", slerp(x, minusX, 1), -1, 0, 0)
+	assertVec(t, "slerp(x, -x, 0.5) again", slerp(x, minusX, 0.5), float64(mid[0]), float64(mid[1]), float64(mid[2]))
+}
+
+func TestSphericalMidpoint(t *testing.T) {
+	r := 1 / math.Sqrt2
+	x, y, z := []float32{1, 0, 0}, []float32{0, 1, 0}, []float32{0, 0, 1}
+
+	assertVec(t, "midpoint(x, y)", sphericalMidpoint([][]float32{x, y}), r, r, 0)
+	assertVec(t, "midpoint(x, x)", sphericalMidpoint([][]float32{x, x}), 1, 0, 0)
+
+	// z turns the x-y midpoint a third of the way over, 30 degrees toward it
+	c := math.Sqrt(6) / 4
+	assertVec(t, "midpoint(x, y, z)", sphericalMidpoint([][]float32{x, y, z}), c, c, 0.5)
+
+	mid := sphericalMidpoint([][]float32{x, {-1, 0, 0}})
+	if math.Abs(embeddings.CosineSimilarity(mid, x)) > 1e-6 || math.Abs(embeddings.CosineSimilarity(mid, mid)-1) > 1e-6 {
+		t.Errorf("midpoint(x, -x) = %v, want a unit vector perpendicular to x", mid)
+	}
+}
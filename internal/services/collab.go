@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+const (
+	// collabNeighbors is how many neighbors are stored per item
+	collabNeighbors = 20
+	// collabMinCoWatch drops pairs seen together by fewer users than this
+	collabMinCoWatch = 2
+	// collabShrinkage damps similarities built from few co-watchers:
+	// score = cosine * co / (co + shrinkage)
+	collabShrinkage = 5.0
+	// collabFullRebuildEvery forces a from-scratch rebuild every N runs so
+	// truncation drift from incremental updates never accumulates
+	collabFullRebuildEvery = 24
+)
+
+// CollabFilter periodically computes item-item similarity from seen_media
+// co-occurrence ("people who watched this also watched")
+type CollabFilter struct {
+	db      database.CollabRepository
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	runs    int
+}
+
+// NewCollabFilter creates a new collaborative filtering job
+func NewCollabFilter(db database.CollabRepository) *CollabFilter {
+	return &CollabFilter{db: db}
+}
+
+// Start begins periodic recomputation
+func (f *CollabFilter) Start(ctx context.Context, interval time.Duration) {
+	f.mu.Lock()
+	if f.running {
+		f.mu.Unlock()
+		return
+	}
+	f.running = true
+	f.stopCh = make(chan struct{})
+	f.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Run immediately on start
+		f.runLogged()
+
+		for {
+			select {
+			case <-ticker.C:
+				f.runLogged()
+			case <-f.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the recomputation loop
+func (f *CollabFilter) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.running {
+		close(f.stopCh)
+		f.running = false
+	}
+}
+
+func (f *CollabFilter) runLogged() {
+	updated, err := f.Recompute()
+	if err != nil {
+		log.Printf("Collaborative filtering failed: %v", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("Collaborative filtering: refreshed neighbors for %d items", updated)
+	}
+}
+
+// Recompute refreshes neighbor lists. Only items touched by seen_media
+// changes since the last run are recomputed, along with every item seen by a
+// user whose history changed (their rating mean moved). The first run, and
+// every collabFullRebuildEvery-th run, rebuilds everything. Returns the
+// number of items whose neighbor list was rewritten.
+func (f *CollabFilter) Recompute() (int, error) {
+	f.mu.Lock()
+	f.runs++
+	full := f.runs%collabFullRebuildEvery == 1
+	f.mu.Unlock()
+
+	upTo, changedUsers, changedMedia, err := f.db.GetPendingSeenChanges()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read seen changes: %w", err)
+	}
+	if !full {
+		stored, err := f.db.CountItemNeighbors()
+		if err != nil {
+			return 0, fmt.Errorf("failed to count neighbors: %w", err)
+		}
+		full = stored == 0
+	}
+	if !full && upTo == 0 {
+		return 0, nil
+	}
+
+	seen, err := f.db.GetAllSeenRatings()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load seen ratings: %w", err)
+	}
+	m := buildCoWatchMatrix(seen)
+
+	// Decide which items need a fresh neighbor list
+	affected := make(map[string]bool)
+	if full {
+		for item := range m.itemUsers {
+			affected[item] = true
+		}
+	} else {
+		for _, item := range changedMedia {
+			affected[item] = true
+		}
+		for _, user := range changedUsers {
+			for item := range m.userItems[user] {
+				affected[item] = true
+			}
+		}
+	}
+
+	updates := make(map[string][]models.ItemNeighbor, len(affected))
+	for item := range affected {
+		updates[item] = m.neighbors(item)
+	}
+
+	// Similarity is symmetric, so unaffected items may gain or lose an
+	// affected neighbor. Merge the fresh scores into their stored lists.
+	if !full {
+		reverse := make(map[string][]models.ItemNeighbor)
+		for item, list := range updates {
+			for _, n := range list {
+				if affected[n.NeighborID] {
+					continue
+				}
+				reverse[n.NeighborID] = append(reverse[n.NeighborID], models.ItemNeighbor{
+					MediaID:    n.NeighborID,
+					NeighborID: item,
+					Score:      n.Score,
+					CoCount:    n.CoCount,
+				})
+			}
+		}
+		for item := range m.itemUsers {
+			if affected[item] {
+				continue
+			}
+			stored, err := f.db.GetItemNeighbors(item, collabNeighbors)
+			if err != nil {
+				return 0, fmt.Errorf("failed to load neighbors of %s: %w", item, err)
+			}
+			touched := len(reverse[item]) > 0
+			merged := reverse[item]
+			for _, n := range stored {
+				if affected[n.NeighborID] {
+					touched = true
+					continue
+				}
+				merged = append(merged, n)
+			}
+			if touched {
+				updates[item] = topNeighbors(merged)
+			}
+		}
+	}
+
+	if err := f.db.ReplaceItemNeighbors(updates, full); err != nil {
+		return 0, fmt.Errorf("failed to store neighbors: %w", err)
+	}
+	if upTo > 0 {
+		if err := f.db.ClearSeenChanges(upTo); err != nil {
+			return 0, fmt.Errorf("failed to clear seen changes: %w", err)
+		}
+	}
+	return len(updates), nil
+}
+
+// coWatchMatrix is the sparse user-item matrix with rating-adjusted values
+type coWatchMatrix struct {
+	userItems map[string]map[string]float64
+	itemUsers map[string]map[string]float64
+	itemNorm  map[string]float64
+}
+
+// buildCoWatchMatrix weights each watch by how the rating compares with the
+// user's own mean: an unrated watch counts 1, a rating above the user's mean
+// counts up to 1.5, one below down to 0.5. Watching is the main signal;
+// ratings sharpen it the way adjusted cosine would. A dropped title is left
+// out: giving up on something is not a reason to recommend its neighbours.
+func buildCoWatchMatrix(seen []models.SeenMedia) *coWatchMatrix {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, s := range seen {
+		if s.Rating != nil {
+			sums[s.UserID] += *s.Rating
+			counts[s.UserID]++
+		}
+	}
+
+	m := &coWatchMatrix{
+		userItems: make(map[string]map[string]float64),
+		itemUsers: make(map[string]map[string]float64),
+		itemNorm:  make(map[string]float64),
+	}
+	for _, s := range seen {
+		if s.Status == models.WatchDropped {
+			continue
+		}
+		v := 1.0
+		if s.Rating != nil {
+			mean := sums[s.UserID] / float64(counts[s.UserID])
+			v += 0.5 * (*s.Rating - mean) / 4.5
+		}
+		if m.userItems[s.UserID] == nil {
+			m.userItems[s.UserID] = make(map[string]float64)
+		}
+		if m.itemUsers[s.MediaID] == nil {
+			m.itemUsers[s.MediaID] = make(map[string]float64)
+		}
+		m.userItems[s.UserID][s.MediaID] = v
+		m.itemUsers[s.MediaID][s.UserID] = v
+		m.itemNorm[s.MediaID] += v * v
+	}
+	for item, sq := range m.itemNorm {
+		m.itemNorm[item] = math.Sqrt(sq)
+	}
+	return m
+}
+
+// neighbors computes the top neighbors of one item by walking its watchers'
+// histories
+func (m *coWatchMatrix) neighbors(item string) []models.ItemNeighbor {
+	dots := make(map[string]float64)
+	co := make(map[string]int)
+	for user, va := range m.itemUsers[item] {
+		for other, vb := range m.userItems[user] {
+			if other == item {
+				continue
+			}
+			dots[other] += va * vb
+			co[other]++
+		}
+	}
+
+	var list []models.ItemNeighbor
+	for other, dot := range dots {
+		if co[other] < collabMinCoWatch {
+			continue
+		}
+		denom := m.itemNorm[item] * m.itemNorm[other]
+		if denom == 0 {
+			continue
+		}
+		n := float64(co[other])
+		score := dot / denom * n / (n + collabShrinkage)
+		if score <= 0 {
+			continue
+		}
+		list = append(list, models.ItemNeighbor{
+			MediaID:    item,
+			NeighborID: other,
+			Score:      score,
+			CoCount:    co[other],
+		})
+	}
+	return topNeighbors(list)
+}
+
+// topNeighbors sorts by score and keeps the first collabNeighbors
+func topNeighbors(list []models.ItemNeighbor) []models.ItemNeighbor {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].NeighborID < list[j].NeighborID
+	})
+	if len(list) > collabNeighbors {
+		list = list[:collabNeighbors]
+	}
+	return list
+}
+
+// AlsoWatched returns the co-watch neighbors of a media entry that the user
+// has not seen or dismissed, strongest first
+func (s *VibeSearchService) AlsoWatched(userID, mediaID string, limit int) ([]models.Recommendation, error) {
+	excludedIDs, err := s.db.GetExcludedMediaIDs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
+	}
+
+	// Over-fetch so excluded titles don't starve the list
+	neighbors, err := s.db.GetItemNeighbors(mediaID, limit+len(excludedIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get co-watch neighbors: %w", err)
+	}
+
+	var recs []models.Recommendation
+	for _, n := range neighbors {
+		if excludedIDs[n.NeighborID] {
+			continue
+		}
+		media, err := s.db.GetMedia(n.NeighborID)
+		if err != nil || media == nil {
+			continue
+		}
+		recs = append(recs, models.Recommendation{
+			Media:       *media,
+			Score:       n.Score,
+			CollabScore: n.Score,
+			Explanation: fmt.Sprintf("Watched by %d people who also watched this", n.CoCount),
+			Rank:        len(recs) + 1,
+		})
+		if len(recs) == limit {
+			break
+		}
+	}
+
+	if err := s.facets.AttachChips(recs); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+	return recs, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+func TestBuildCoWatchMatrix(t *testing.T) {
+	rate := func(r float64) *float64 { return &r }
+	m := buildCoWatchMatrix([]models.SeenMedia{
+		{UserID: "u1", MediaID: "a", Rating: rate(10)},
+		{UserID: "u1", MediaID: "b", Rating: rate(6)},
+		{UserID: "u1", MediaID: "c"},
+		{UserID: "u1", MediaID: "d", Status: models.WatchDropped},
+		{UserID: "u2", MediaID: "a", Rating: rate(3)},
+	})
+
+	// u1 rates around a mean of 8; u2's only rating is its own mean
+	tests := []struct {
+		user, item string
+		want       float64
+	}{
+		{"u1", "a", 1 + 0.5*2/4.5},
+		{"u1", "b", 1 - 0.5*2/4.5},
+		{"u1", "c", 1},
+		{"u2", "a", 1},
+	}
+	for _, tt := range tests {
+		if got := m.userItems[tt.user][tt.item]; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s/%s = %v, want %v", tt.user, tt.item, got, tt.want)
+		}
+		if m.itemUsers[tt.item][tt.user] != m.userItems[tt.user][tt.item] {
+			t.Errorf("%s/%s differs between the two indexes", tt.user, tt.item)
+		}
+	}
+	if _, ok := m.itemUsers["d"]; ok {
+		t.Error("dropped title entered the matrix")
+	}
+	if want := math.Sqrt(1 + math.Pow(1+0.5*2/4.5, 2)); math.Abs(m.itemNorm["a"]-want) > 1e-9 {
+		t.Errorf("norm of a = %v, want %v", m.itemNorm["a"], want)
+	}
+}
+
+// coWatchFixture has u1-u3 watch a and b, u1 and u2 also watch c, u3 also
+// watches d, and u4 and u5 watch a and x
+func coWatchFixture(t *testing.T) *database.DB {
+	t.Helper()
+	db := newTestDB(t)
+	for _, id := range []string{"a", "b", "c", "d", "x", "y"} {
+		if err := db.CreateMedia(&models.Media{ID: id, Title: id, MediaType: "movie", VibeProfile: "x"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := map[string][]string{
+		"u1": {"a", "b", "c"},
+		"u2": {"a", "b", "c"},
+		"u3": {"a", "b", "d"},
+		"u4": {"a", "x"},
+		"u5": {"a", "x"},
+		"u6": {"y"},
+	}
+	for user, items := range history {
+		if err := db.CreateUser(&models.User{ID: user, Username: user}); err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			if err := db.MarkAsSeen(&models.SeenMedia{UserID: user, MediaID: item}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return db
+}
+
+// neighborScores maps each stored neighbor of an item to its score
+func neighborScores(t *testing.T, db *database.DB, item string) map[string]float64 {
+	t.Helper()
+	list, err := db.GetItemNeighbors(item, collabNeighbors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[string]float64, len(list))
+	for _, n := range list {
+		scores[n.NeighborID] = n.Score
+	}
+	return scores
+}
+
+func TestCollabRecompute(t *testing.T) {
+	db := coWatchFixture(t)
+	f := NewCollabFilter(db)
+
+	// The first run rebuilds everything
+	if _, err := f.Recompute(); err != nil {
+		t.Fatalf("Recompute: %v", err)
+	}
+	got := neighborScores(t, db, "a")
+	// cosine * co / (co + shrinkage) over unrated watches
+	want := map[string]float64{
+		"b": 3 / math.Sqrt(5*3) * 3 / 8,
+		"c": 2 / math.Sqrt(5*2) * 2 / 7,
+		"x": 2 / math.Sqrt(5*2) * 2 / 7,
+	}
+	// d shares only u3 with a, below the co-watch minimum
+	if len(got) != len(want) {
+		t.Errorf("neighbors of a = %v, want b, c and x", got)
+	}
+	for id, w := range want {
+		if math.Abs(got[id]-w) > 1e-9 {
+			t.Errorf("a~%s = %v, want %v", id, got[id], w)
+		}
+	}
+	list, _ := db.GetItemNeighbors("a", 1)
+	if len(list) != 1 || list[0].NeighborID != "b" || list[0].CoCount != 3 {
+		t.Errorf("strongest neighbor of a = %+v, want b watched together 3 times", list)
+	}
+	if n := neighborScores(t, db, "y"); len(n) != 0 {
+		t.Errorf("y, watched by one person alone, has neighbors %v", n)
+	}
+
+	// Nothing changed, nothing to do
+	if updated, err := f.Recompute(); err != nil || updated != 0 {
+		t.Errorf("idle run updated %d (%v), want 0", updated, err)
+	}
+
+	// u6 watching a touches a and y directly; x is untouched, but its stored
+	// score with a must follow a's new norm
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u6", MediaID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Recompute(); err != nil {
+		t.Fatalf("incremental Recompute: %v", err)
+	}
+	fromA, fromX := neighborScores(t, db, "a")["x"], neighborScores(t, db, "x")["a"]
+	if want := 2 / math.Sqrt(6*2) * 2 / 7; math.Abs(fromA-want) > 1e-9 {
+		t.Errorf("a~x after u6 = %v, want %v", fromA, want)
+	}
+	if fromX != fromA {
+		t.Errorf("x~a = %v, a~x = %v; the untouched side was not merged", fromX, fromA)
+	}
+
+	// Dropping a title takes it out of the matrix
+	if err := db.SaveProgress(&models.SeenMedia{UserID: "u2", MediaID: "c", Status: models.WatchDropped}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Recompute(); err != nil {
+		t.Fatalf("Recompute after a drop: %v", err)
+	}
+	if _, ok := neighborScores(t, db, "a")["c"]; ok {
+		t.Error("a kept c as a neighbor after one of their two co-watchers dropped it")
+	}
+}
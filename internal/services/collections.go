@@ -0,0 +1,308 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/embeddings"
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+// fallbackSummaryItems caps how many item profiles the offline summary uses
+const fallbackSummaryItems = 3
+
+// CollectionService manages user-curated collections and keeps each one's
+// vibe summary and embedding in step with its contents
+type CollectionService struct {
+	db        database.CollectionRepository
+	embedder  embeddings.Provider
+	llmClient *llm.Client
+	store     *embeddings.VectorStore // Collection embeddings, keyed by collection ID
+}
+
+// NewCollectionService creates a collection service and loads the
+// collection index
+func NewCollectionService(db database.CollectionRepository, embedder embeddings.Provider, llmClient *llm.Client) (*CollectionService, error) {
+	svc := &CollectionService{
+		db:        db,
+		embedder:  embedder,
+		llmClient: llmClient,
+		store:     embeddings.NewVectorStore(),
+	}
+
+	vecs, err := db.GetAllCollectionEmbeddings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load collection embeddings: %w", err)
+	}
+	svc.store.LoadFromMap(vecs)
+
+	return svc, nil
+}
+
+// Create starts a new, empty collection owned by the user. Every collection
+// gets its unguessable slug up front; Public decides whether it works for
+// anyone but the owner.
+func (s *CollectionService) Create(userID, title, description string, public bool) (*models.Collection, error) {
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	slug, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &models.Collection{
+		ID:          id,
+		UserID:      userID,
+		Title:       title,
+		Description: description,
+		Public:      public,
+		Slug:        slug,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.db.CreateCollection(c); err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return c, nil
+}
+
+// Owned returns the collection if it exists and belongs to the user, nil
+// otherwise (other people's collections look missing, not forbidden)
+func (s *CollectionService) Owned(userID, id string) (*models.Collection, error) {
+	c, err := s.db.GetCollection(id)
+	if err != nil || c == nil || c.UserID != userID {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Shared returns the collection behind a slug if the user may see it:
+// public collections for everyone, private ones for their owner
+func (s *CollectionService) Shared(userID, slug string) (*models.Collection, error) {
+	c, err := s.db.GetCollectionBySlug(slug)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	if !c.Public && c.UserID != userID {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// WithItems loads a collection's items into it
+func (s *CollectionService) WithItems(c *models.Collection) error {
+	items, err := s.db.GetCollectionItems(c.ID)
+	if err != nil {
+		return err
+	}
+	if items == nil {
+		items = []models.CollectionItem{}
+	}
+	c.Items = items
+	return nil
+}
+
+// Update saves edited fields and refreshes the vibe if the text changed
+func (s *CollectionService) Update(c *models.Collection, textChanged bool) error {
+	if err := s.db.UpdateCollection(c); err != nil {
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+	if textChanged {
+		return s.RefreshVibe(c)
+	}
+	return nil
+}
+
+// Delete removes a collection from the database and the index
+func (s *CollectionService) Delete(id string) error {
+	if err := s.db.DeleteCollection(id); err != nil {
+		return err
+	}
+	s.store.Remove(id)
+	return nil
+}
+
+// AddItem adds (or re-notes) a title and refreshes the collection vibe
+func (s *CollectionService) AddItem(c *models.Collection, mediaID, note string) error {
+	if err := s.db.AddCollectionItem(c.ID, mediaID, note); err != nil {
+		return fmt.Errorf("failed to add item: %w", err)
+	}
+	return s.RefreshVibe(c)
+}
+
+// UpdateItemNote changes a curator note and refreshes the collection vibe.
+// Returns false if the title is not in the collection.
+func (s *CollectionService) UpdateItemNote(c *models.Collection, mediaID, note string) (bool, error) {
+	ok, err := s.db.UpdateCollectionItemNote(c.ID, mediaID, note)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, s.RefreshVibe(c)
+}
+
+// RemoveItem drops a title and refreshes the collection vibe. Returns false
+// if the title was not in the collection.
+func (s *CollectionService) RemoveItem(c *models.Collection, mediaID string) (bool, error) {
+	ok, err := s.db.RemoveCollectionItem(c.ID, mediaID)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return true, s.RefreshVibe(c)
+}
+
+// RefreshVibe regenerates the collection-level vibe summary and embedding.
+// The LLM writes the summary when available; otherwise it is stitched from
+// the description and the first few item profiles. Empty collections have
+// neither and drop out of the index.
+func (s *CollectionService) RefreshVibe(c *models.Collection) error {
+	items, err := s.db.GetCollectionItems(c.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load items: %w", err)
+	}
+	if len(items) == 0 {
+		s.store.Remove(c.ID)
+		c.VibeSummary = ""
+		return s.db.SetCollectionVibe(c.ID, "", nil, "")
+	}
+
+	var summary string
+	if s.llmClient != nil {
+		entries := make([]llm.CollectionEntry, len(items))
+		for i, item := range items {
+			entries[i] = llm.CollectionEntry{
+				Title:       item.Media.Title,
+				VibeProfile: item.Media.VibeProfile,
+				Note:        item.Note,
+			}
+		}
+		summary, err = s.llmClient.SummarizeCollection(c.Title, c.Description, entries)
+		if err != nil {
+			summary = ""
+		}
+	}
+	if summary == "" {
+		summary = fallbackCollectionSummary(c, items)
+	}
+
+	vec, err := s.embedder.Embed(summary)
+	if err != nil {
+		return fmt.Errorf("failed to embed collection vibe: %w", err)
+	}
+	if err := s.db.SetCollectionVibe(c.ID, summary, vec, s.embedder.ModelName()); err != nil {
+		return fmt.Errorf("failed to store collection vibe: %w", err)
+	}
+	s.store.Add(c.ID, vec)
+	c.VibeSummary = summary
+	return nil
+}
+
+// Search finds collections whose vibe matches a free-text query. Only public
+// collections and the user's own are returned.
+func (s *CollectionService) Search(userID, query string, limit int) ([]models.CollectionMatch, error) {
+	vec, err := s.embedder.Embed(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return s.nearest(userID, [][]float32{vec}, limit, true)
+}
+
+// nearest ranks visible collections by their best similarity to any of the
+// given vectors
+func (s *CollectionService) nearest(userID string, vecs [][]float32, limit int, includeOwn bool) ([]models.CollectionMatch, error) {
+	best := make(map[string]float64)
+	var order []string
+	// Over-fetch: private collections of other users are filtered below
+	for _, vec := range vecs {
+		for _, r := range s.store.Search(vec, limit*4, nil) {
+			if prev, ok := best[r.MediaID]; !ok || r.Similarity > prev {
+				if !ok {
+					order = append(order, r.MediaID)
+				}
+				best[r.MediaID] = r.Similarity
+			}
+		}
+	}
+
+	collections, err := s.db.GetCollectionsByIDs(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load collections: %w", err)
+	}
+
+	matches := []models.CollectionMatch{}
+	for _, id := range order {
+		c := collections[id]
+		if c == nil {
+			continue
+		}
+		own := c.UserID == userID
+		if (!c.Public && !own) || (own && !includeOwn) {
+			continue
+		}
+		matches = append(matches, models.CollectionMatch{Collection: *c, Similarity: best[id]})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Similarity > matches[j].Similarity
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// ImportToWatchlist appends a collection's unseen titles to the user's
+// watchlist
+func (s *CollectionService) ImportToWatchlist(userID string, c *models.Collection) (int, error) {
+	return s.db.ImportCollectionToWatchlist(userID, c.ID)
+}
+
+// RecommendCollections suggests other people's public collections near the
+// user's taste profile
+func (s *VibeSearchService) RecommendCollections(userID string, limit int) ([]models.CollectionMatch, error) {
+	centroids, err := s.tasteProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load taste profile: %w", err)
+	}
+	if len(centroids) == 0 {
+		return []models.CollectionMatch{}, nil
+	}
+
+	vecs := make([][]float32, len(centroids))
+	for i, c := range centroids {
+		vecs[i] = c.Vector
+	}
+	return s.collections.nearest(userID, vecs, limit, false)
+}
+
+// fallbackCollectionSummary builds a summary without the LLM
+func fallbackCollectionSummary(c *models.Collection, items []models.CollectionItem) string {
+	parts := []string{c.Title}
+	if c.Description != "" {
+		parts = append(parts, c.Description)
+	}
+	for i, item := range items {
+		if i >= fallbackSummaryItems {
+			break
+		}
+		parts = append(parts, item.Media.VibeProfile)
+	}
+	return strings.Join(parts, " ")
+}
+
+// randomToken returns n random bytes, hex-encoded
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
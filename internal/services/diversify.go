@@ -0,0 +1,58 @@
+package services
+
+import (
+	"w2w/internal/embeddings"
+	"w2w/internal/models"
+)
+
+// diversify reorders a score-sorted pool with maximal marginal relevance:
+// each pick maximises lambda*score - (1-lambda)*similarity to the titles
+// already picked, so near-duplicates sink below distinct alternatives
+func (s *VibeSearchService) diversify(pool []models.Recommendation, lambda float64) {
+	if lambda >= 1 || len(pool) < 3 {
+		return
+	}
+
+	vecs := make([][]float32, len(pool))
+	for i, r := range pool {
+		vecs[i], _ = s.vectorStore.Get(r.Media.ID)
+	}
+
+	// maxSim[i] tracks candidate i's highest similarity to any pick so far
+	maxSim := make([]float64, len(pool))
+	picked := make([]bool, len(pool))
+	order := make([]int, 0, len(pool))
+
+	for len(order) < len(pool) {
+		best, bestScore := -1, 0.0
+		for i := range pool {
+			if picked[i] {
+				continue
+			}
+			mmr := lambda*pool[i].Score - (1-lambda)*maxSim[i]
+			if best == -1 || mmr > bestScore {
+				best, bestScore = i, mmr
+			}
+		}
+		picked[best] = true
+		order = append(order, best)
+
+		if vecs[best] == nil {
+			continue
+		}
+		for i := range pool {
+			if picked[i] || vecs[i] == nil {
+				continue
+			}
+			if sim := embeddings.CosineSimilarity(vecs[i], vecs[best]); sim > maxSim[i] {
+				maxSim[i] = sim
+			}
+		}
+	}
+
+	reordered := make([]models.Recommendation, len(pool))
+	for i, idx := range order {
+		reordered[i] = pool[idx]
+	}
+	copy(pool, reordered)
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// ExplorePolicy decides whether and how a ranked list trades a few slots
+// for titles the user would not otherwise see
+type ExplorePolicy string
+
+const (
+	// ExploreNone is the zero value: no exploration and no novelty penalty.
+	// Internal callers (eval, rooms) rely on it for reproducible rankings.
+	ExploreNone ExplorePolicy = ""
+	// ExploreOff is the client-facing way to ask for ExploreNone, so it
+	// turns off the novelty penalty along with the slot swaps
+	ExploreOff ExplorePolicy = "off"
+	// ExploreEpsilon swaps each slot for a random deeper candidate with
+	// probability Tuning.ExploreEpsilon
+	ExploreEpsilon ExplorePolicy = "epsilon"
+	// ExploreThompson samples each title's engagement rate from its logged
+	// feedback and lets deeper candidates win slots when their draw is better
+	ExploreThompson ExplorePolicy = "thompson"
+)
+
+const (
+	// exploreFeedbackWindow is how far back logged feedback informs sampling
+	exploreFeedbackWindow = 30 * 24 * time.Hour
+	// exploreFeedbackTTL is how long tallied feedback is reused between searches
+	exploreFeedbackTTL = 10 * time.Minute
+	// exploreSlotShare caps the share of a list exploration may replace;
+	// the top pick is never replaced
+	exploreSlotShare = 0.25
+	// noveltyMaxRepeats caps how many recent showings compound the penalty
+	noveltyMaxRepeats = 3
+)
+
+// ParseExplorePolicy validates a client-supplied explore setting. An empty
+// value, "on" and "true" resolve against the server default.
+func ParseExplorePolicy(value string, fallback ExplorePolicy) (ExplorePolicy, bool) {
+	switch value {
+	case "":
+		return fallback, true
+	case "on", "true", "1":
+		if fallback == ExploreNone || fallback == ExploreOff {
+			return ExploreThompson, true
+		}
+		return fallback, true
+	case "off", "false", "0":
+		return ExploreOff, true
+	case string(ExploreEpsilon), string(ExploreThompson):
+		return ExplorePolicy(value), true
+	}
+	return ExploreNone, false
+}
+
+// ExplorePolicy resolves a client-supplied explore setting against the
+// server default
+func (s *VibeSearchService) ExplorePolicy(value string) (ExplorePolicy, bool) {
+	return ParseExplorePolicy(value, s.tuning.ExplorePolicy)
+}
+
+func (p ExplorePolicy) enabled() bool {
+	return p == ExploreEpsilon || p == ExploreThompson
+}
+
+// exploreFeedback caches per-title feedback tallied from the impression log
+type exploreFeedback struct {
+	mu    sync.Mutex
+	at    time.Time
+	items map[string]models.ItemFeedback
+}
+
+// loadFeedback returns recent per-title feedback, re-tallying it at most
+// every exploreFeedbackTTL. The tally runs without the lock, so searches
+// keep using the cached feedback meanwhile. Failures fall back to the last
+// tally, or no feedback (uniform priors).
+func (s *VibeSearchService) loadFeedback() map[string]models.ItemFeedback {
+	s.feedback.mu.Lock()
+	items, fresh := s.feedback.items, time.Since(s.feedback.at) < exploreFeedbackTTL
+	s.feedback.mu.Unlock()
+	if items != nil && fresh {
+		return items
+	}
+
+	tallied, err := s.db.GetImpressionFeedback(time.Now().Add(-exploreFeedbackWindow))
+	if err != nil {
+		log.Printf("Failed to load exploration feedback: %v", err)
+		return items
+	}
+	s.feedback.mu.Lock()
+	s.feedback.items = tallied
+	s.feedback.at = time.Now()
+	s.feedback.mu.Unlock()
+	return tallied
+}
+
+// applyNovelty demotes titles already shown to the user within the novelty
+// window, compounding per showing: score *= (1 - penalty)^shown
+func (s *VibeSearchService) applyNovelty(userID string, pool []models.Recommendation) {
+	penalty := s.tuning.NoveltyPenalty
+	if penalty <= 0 || s.tuning.NoveltyWindow <= 0 || len(pool) == 0 {
+		return
+	}
+	shown, err := s.db.GetShownCounts(userID, time.Now().Add(-s.tuning.NoveltyWindow))
+	if err != nil {
+		log.Printf("Failed to load recent impressions for %s: %v", userID, err)
+		return
+	}
+	for i := range pool {
+		n := shown[pool[i].Media.ID]
+		if n == 0 {
+			continue
+		}
+		if n > noveltyMaxRepeats {
+			n = noveltyMaxRepeats
+		}
+		pool[i].Score *= math.Pow(1-penalty, float64(n))
+	}
+}
+
+// explore lets the policy replace some of recs (the final list) with titles
+// from rest (the deeper candidates). Replacements take over the slot's rank
+// and are flagged Explored.
+func (s *VibeSearchService) explore(policy ExplorePolicy, recs, rest []models.Recommendation) []models.Recommendation {
+	if !policy.enabled() || len(recs) < 2 || len(rest) == 0 {
+		return recs
+	}
+	slots := int(math.Ceil(float64(len(recs)) * exploreSlotShare))
+
+	switch policy {
+	case ExploreEpsilon:
+		rand.Shuffle(len(rest), func(i, j int) { rest[i], rest[j] = rest[j], rest[i] })
+		next := 0
+		for i := 1; i < len(recs) && slots > 0 && next < len(rest); i++ {
+			if rand.Float64() < s.tuning.ExploreEpsilon {
+				recs[i] = exploredPick(rest[next], recs[i].Rank)
+				next++
+				slots--
+			}
+		}
+
+	case ExploreThompson:
+		// Expected value of a slot = sampled engagement rate x relevance.
+		// Titles that keep being shown without engagement sample low; titles
+		// with little history sample widely and get their chance.
+		feedback := s.loadFeedback()
+		draw := func(r models.Recommendation) float64 {
+			f := feedback[r.Media.ID]
+			return sampleBeta(globalRand{}, float64(1+f.Engaged), float64(1+f.Shown-f.Engaged)) * math.Max(r.Score, 0)
+		}
+
+		type drawn struct {
+			idx   int
+			value float64
+		}
+		head := make([]drawn, 0, len(recs)-1)
+		for i := 1; i < len(recs); i++ {
+			head = append(head, drawn{i, draw(recs[i])})
+		}
+		tail := make([]drawn, len(rest))
+		for i, r := range rest {
+			tail[i] = drawn{i, draw(r)}
+		}
+		sort.Slice(head, func(i, j int) bool { return head[i].value < head[j].value })
+		sort.Slice(tail, func(i, j int) bool { return tail[i].value > tail[j].value })
+
+		for k := 0; k < slots && k < len(head) && k < len(tail); k++ {
+			if tail[k].value <= head[k].value {
+				break
+			}
+			i := head[k].idx
+			recs[i] = exploredPick(rest[tail[k].idx], recs[i].Rank)
+		}
+	}
+	return recs
+}
+
+// exploredPick prepares a deeper candidate to fill a slot
+func exploredPick(r models.Recommendation, rank int) models.Recommendation {
+	r.Rank = rank
+	r.Explored = true
+	r.Explanation = fmt.Sprintf("Off the beaten path: %s", r.Media.VibeProfile)
+	return r
+}
+
+// remainingPool returns the pool entries that did not make the final list
+func remainingPool(pool, recs []models.Recommendation) []models.Recommendation {
+	used := make(map[string]bool, len(recs))
+	for _, r := range recs {
+		used[r.Media.ID] = true
+	}
+	var rest []models.Recommendation
+	for _, r := range pool {
+		if !used[r.Media.ID] {
+			rest = append(rest, r)
+		}
+	}
+	return rest
+}
+
+// sampler is the randomness Thompson sampling draws on: math/rand's
+// global source in production, a seeded *rand.Rand in tests
+type sampler interface {
+	Float64() float64
+	NormFloat64() float64
+}
+
+// globalRand draws from math/rand's goroutine-safe global source
+type globalRand struct{}
+
+func (globalRand) Float64() float64     { return rand.Float64() }
+func (globalRand) NormFloat64() float64 { return rand.NormFloat64() }
+
+// sampleBeta draws from Beta(a, b) via two gamma draws
+func sampleBeta(rng sampler, a, b float64) float64 {
+	x := sampleGamma(rng, a)
+	y := sampleGamma(rng, b)
+	if x+y == 0 {
+		return 0.5
+	}
+	return x / (x + y)
+}
+
+// sampleGamma draws from Gamma(shape, 1) with Marsaglia and Tsang's method
+// (shape >= 1, which the Beta(1+engaged, 1+ignored) priors guarantee)
+func sampleGamma(rng sampler, shape float64) float64 {
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
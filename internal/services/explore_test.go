@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+func TestSampleBetaMatchesDistribution(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	const draws = 20000
+
+	for _, tt := range []struct{ a, b float64 }{
+		{1, 1}, // Uniform: no feedback yet
+		{2, 5},
+		{10, 3},
+		{50, 50},
+	} {
+		t.Run(fmt.Sprintf("Beta(%g,%g)", tt.a, tt.b), func(t *testing.T) {
+			var sum, sumSq float64
+			for i := 0; i < draws; i++ {
+				x := sampleBeta(rng, tt.a, tt.b)
+				if x < 0 || x > 1 || math.IsNaN(x) {
+					t.Fatalf("draw %v outside [0, 1]", x)
+				}
+				sum += x
+				sumSq += x * x
+			}
+			mean := sum / draws
+			variance := sumSq/draws - mean*mean
+
+			n := tt.a + tt.b
+			wantMean := tt.a / n
+			wantVar := tt.a * tt.b / (n * n * (n + 1))
+			if math.Abs(mean-wantMean) > 0.01 {
+				t.Errorf("mean = %.4f, want %.4f", mean, wantMean)
+			}
+			if math.Abs(variance-wantVar) > 0.1*wantVar {
+				t.Errorf("variance = %.5f, want %.5f", variance, wantVar)
+			}
+		})
+	}
+}
+
+func TestExploreOffKeepsRanking(t *testing.T) {
+	svc := rankingFixture(t)
+	svc.tuning.ExploreEpsilon = 0
+
+	// near has been shown three times, which the novelty penalty would
+	// compound to 0.8^3 = 0.512
+	for i := 0; i < 3; i++ {
+		if err := svc.db.CreateImpression(&models.Impression{
+			RequestID: fmt.Sprintf("r%d", i),
+			UserID:    "u1",
+			Surface:   "search",
+			Results:   []models.ImpressionItem{{MediaID: "near", Rank: 1}},
+			CreatedAt: time.Now(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		result, err := svc.Search(SearchConfig{UserID: "u1", Query: "anything", Explore: ExploreOff})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		got := fmt.Sprint(resultIDs(result))
+		if got != "[near twin far]" {
+			t.Fatalf("explore=off order = %s, want the plain vector order [near twin far]", got)
+		}
+		for _, r := range result.Recommendations {
+			if r.Explored {
+				t.Errorf("%s flagged as explored with explore=off", r.Media.ID)
+			}
+		}
+	}
+
+	// Exploring with no swaps still applies the novelty penalty
+	result, err := svc.Search(SearchConfig{UserID: "u1", Query: "anything", Explore: ExploreEpsilon})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if got := fmt.Sprint(resultIDs(result)); got != "[twin far near]" {
+		t.Errorf("exploring order = %s, want near demoted to [twin far near]", got)
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"w2w/internal/database"
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+// minFacetConfidence is the threshold for a facet to count when filtering,
+// listing counts, or rendering chips
+const minFacetConfidence = 0.5
+
+// facetTerm is one entry of the controlled vibe vocabulary. Keywords drive
+// the offline fallback extractor when no LLM is configured.
+type facetTerm struct {
+	Category string
+	Slug     string
+	Label    string
+	Keywords []string
+}
+
+// facetVocabulary mirrors the categories the vibe profile prompt asks for
+var facetVocabulary = []facetTerm{
+	// Visual style
+	{"visual_style", "neon-noir", "Neon-noir", []string{"neon-noir", "neon noir", "neon", "noir"}},
+	{"visual_style", "pastel-dreamscape", "Pastel dreamscape", []string{"pastel", "dreamscape", "soft colors"}},
+	{"visual_style", "gritty-realism", "Gritty realism", []string{"gritty", "grimy", "realism", "handheld"}},
+	{"visual_style", "hyperkinetic-animation", "Hyperkinetic animation", []string{"hyperkinetic", "kinetic", "explosive animation"}},
+	{"visual_style", "stark-monochrome", "Stark monochrome", []string{"black and white", "black-and-white", "monochrome"}},
+	{"visual_style", "painterly", "Painterly", []string{"painterly", "painting", "watercolor", "every frame a"}},
+	{"visual_style", "retro-futurism", "Retro-futurism", []string{"retrofutur", "retro-futur", "retro"}},
+	{"visual_style", "surreal-kaleidoscope", "Surreal kaleidoscope", []string{"kaleidoscop", "surreal", "psychedelic", "prismatic"}},
+	{"visual_style", "sterile-minimalism", "Sterile minimalism", []string{"sterile", "minimalis", "brutalist"}},
+
+	// Pacing
+	{"pacing", "meditative-slowburn", "Meditative slowburn", []string{"slowburn", "slow burn", "slow-burn", "meditative", "meditation", "glacial", "hypnotic"}},
+	{"pacing", "frenetic-energy", "Frenetic energy", []string{"frenetic", "manic", "breakneck", "relentless", "restless"}},
+	{"pacing", "deliberate-tension", "Deliberate tension", []string{"deliberate", "tension", "tense", "simmering"}},
+	{"pacing", "breezy-episodic", "Breezy and episodic", []string{"breezy", "episodic", "easygoing"}},
+	{"pacing", "escalating-spiral", "Escalating spiral", []string{"escalat", "spiral", "descent", "unravel"}},
+
+	// Emotional texture
+	{"emotional_texture", "existential-dread", "Existential dread", []string{"existential", "dread"}},
+	{"emotional_texture", "cozy-melancholy", "Cozy melancholy", []string{"cozy", "melancholy", "melancholic", "bittersweet"}},
+	{"emotional_texture", "manic-joy", "Manic joy", []string{"joyful", "joy", "exuberant", "euphoric"}},
+	{"emotional_texture", "contemplative-silence", "Contemplative silence", []string{"contemplative", "quiet", "silence", "mournful"}},
+	{"emotional_texture", "aching-loneliness", "Aching loneliness", []string{"loneliness", "lonely", "isolation", "aching"}},
+	{"emotional_texture", "paranoid-unease", "Paranoid unease", []string{"paranoid", "paranoia", "unsettling", "unease", "uneasy"}},
+	{"emotional_texture", "warm-hope", "Warm hope", []string{"hopeful", "heartfelt", "found family", "beating heart", "warm"}},
+	{"emotional_texture", "cool-detachment", "Cool detachment", []string{"detached", "ennui", "deadpan", "effortlessly stylish"}},
+
+	// Atmosphere
+	{"atmosphere", "rain-soaked-streets", "Rain-soaked streets", []string{"rain-soaked", "rain", "wet streets"}},
+	{"atmosphere", "sun-drenched-nostalgia", "Sun-drenched nostalgia", []string{"sun-drenched", "sunlit", "nostalgi", "summer"}},
+	{"atmosphere", "clinical-coldness", "Clinical coldness", []string{"clinical", "coldness", "cold ", "fluorescent"}},
+	{"atmosphere", "cyberpunk-sprawl", "Cyberpunk sprawl", []string{"cyberpunk", "chrome", "megacity", "holographic"}},
+	{"atmosphere", "liminal-spaces", "Liminal spaces", []string{"liminal", "empty", "abandoned", "power lines"}},
+	{"atmosphere", "claustrophobic", "Claustrophobic", []string{"claustrophobic", "suffocating", "confined"}},
+	{"atmosphere", "dreamlike", "Dreamlike", []string{"dreamlike", "dream logic", "dream-logic", "fever dream", "dream"}},
+	{"atmosphere", "cosmic-vastness", "Cosmic vastness", []string{"cosmic", "vast", "infinite", "space"}},
+}
+
+// FacetService extracts structured vibe facets from free-prose vibe profiles
+// and answers facet queries
+type FacetService struct {
+	db        FacetStore
+	llmClient *llm.Client
+	terms     map[string]facetTerm
+}
+
+// FacetStore is what FacetService reads and writes
+type FacetStore interface {
+	database.MediaRepository
+	database.FacetRepository
+}
+
+// NewFacetService creates a facet service and makes sure the vocabulary is
+// present in the database
+func NewFacetService(db FacetStore, llmClient *llm.Client) (*FacetService, error) {
+	return newFacetService(db, llmClient, true)
+}
+
+// newFacetService creates a facet service, seeding the vocabulary only when
+// seed is set
+func newFacetService(db FacetStore, llmClient *llm.Client, seed bool) (*FacetService, error) {
+	svc := &FacetService{
+		db:        db,
+		llmClient: llmClient,
+		terms:     make(map[string]facetTerm, len(facetVocabulary)),
+	}
+
+	for _, t := range facetVocabulary {
+		svc.terms[t.Slug] = t
+		if !seed {
+			continue
+		}
+		if err := db.UpsertFacet(&models.Facet{Slug: t.Slug, Category: t.Category, Label: t.Label}); err != nil {
+			return nil, fmt.Errorf("failed to seed facet %s: %w", t.Slug, err)
+		}
+	}
+
+	return svc, nil
+}
+
+// Vocabulary returns the allowed slugs grouped by category
+func (s *FacetService) Vocabulary() map[string][]string {
+	vocab := make(map[string][]string)
+	for _, t := range facetVocabulary {
+		vocab[t.Category] = append(vocab[t.Category], t.Slug)
+	}
+	return vocab
+}
+
+// Extract maps a media's vibe profile onto the vocabulary. The LLM is used
+// when available; keyword matching is the fallback.
+func (s *FacetService) Extract(media *models.Media) []models.MediaFacet {
+	if s.llmClient != nil {
+		scores, err := s.llmClient.ExtractFacets(media.VibeProfile, s.Vocabulary())
+		if err == nil {
+			return llmFacets(media.ID, scores)
+		}
+	}
+	return extractFacetsByKeywords(media.ID, media.VibeProfile)
+}
+
+// llmFacets turns LLM facet scores into media facets. The LLM can repeat a
+// slug; its most confident score is kept, since a duplicate would fail the
+// whole write.
+func llmFacets(mediaID string, scores []llm.FacetScore) []models.MediaFacet {
+	facets := make([]models.MediaFacet, 0, len(scores))
+	index := make(map[string]int)
+	for _, sc := range scores {
+		if i, ok := index[sc.Slug]; ok {
+			if sc.Confidence > facets[i].Confidence {
+				facets[i].Confidence = sc.Confidence
+			}
+			continue
+		}
+		index[sc.Slug] = len(facets)
+		facets = append(facets, models.MediaFacet{
+			MediaID:    mediaID,
+			FacetSlug:  sc.Slug,
+			Confidence: sc.Confidence,
+			Source:     "llm",
+		})
+	}
+	return facets
+}
+
+// TagMedia (re)extracts and stores the facets of a single media entry
+func (s *FacetService) TagMedia(mediaID string) error {
+	media, err := s.db.GetMedia(mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to get media: %w", err)
+	}
+	if media == nil {
+		return fmt.Errorf("media not found: %s", mediaID)
+	}
+	return s.db.ReplaceMediaFacets(mediaID, s.Extract(media))
+}
+
+// Backfill tags every media entry that has no facets yet (or all of them
+// when force is set). progress is called after each entry.
+func (s *FacetService) Backfill(force bool, progress func(done, total int, mediaID string, err error)) (int, error) {
+	ids, err := s.db.GetMediaIDsMissingFacets(force)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list media: %w", err)
+	}
+
+	tagged := 0
+	for i, id := range ids {
+		err := s.TagMedia(id)
+		if err == nil {
+			tagged++
+		}
+		if progress != nil {
+			progress(i+1, len(ids), id, err)
+		}
+	}
+	return tagged, nil
+}
+
+// ListFacets returns the vocabulary with media counts
+func (s *FacetService) ListFacets(category string) ([]models.Facet, error) {
+	return s.db.ListFacets(category, minFacetConfidence)
+}
+
+// ValidateSlugs rejects slugs that are not part of the vocabulary
+func (s *FacetService) ValidateSlugs(slugs []string) error {
+	for _, slug := range slugs {
+		if _, ok := s.terms[slug]; !ok {
+			return fmt.Errorf("unknown facet: %s", slug)
+		}
+	}
+	return nil
+}
+
+// MatchingMediaIDs returns media carrying every one of the given facets
+func (s *FacetService) MatchingMediaIDs(slugs []string) (map[string]bool, error) {
+	return s.db.GetMediaIDsWithFacets(slugs, minFacetConfidence)
+}
+
+// AttachChips fills in the Facets field of each recommendation
+func (s *FacetService) AttachChips(recs []models.Recommendation) error {
+	ids := make([]string, len(recs))
+	for i, r := range recs {
+		ids[i] = r.Media.ID
+	}
+
+	chips, err := s.db.GetFacetChips(ids, minFacetConfidence)
+	if err != nil {
+		return err
+	}
+	for i := range recs {
+		recs[i].Facets = chips[recs[i].Media.ID]
+	}
+	return nil
+}
+
+// CommonLabels returns the labels of the facets carried by the most of the
+// given titles, at most n, most shared first
+func (s *FacetService) CommonLabels(mediaIDs []string, n int) ([]string, error) {
+	chips, err := s.db.GetFacetChips(mediaIDs, minFacetConfidence)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, cs := range chips {
+		for _, c := range cs {
+			counts[c.Label]++
+		}
+	}
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if counts[labels[i]] != counts[labels[j]] {
+			return counts[labels[i]] > counts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	if len(labels) > n {
+		labels = labels[:n]
+	}
+	return labels, nil
+}
+
+// extractFacetsByKeywords is the offline extractor: each vocabulary term
+// scores by how many of its keywords appear in the profile
+func extractFacetsByKeywords(mediaID, vibeProfile string) []models.MediaFacet {
+	text := strings.ToLower(vibeProfile)
+
+	var facets []models.MediaFacet
+	for _, t := range facetVocabulary {
+		hits := 0
+		for _, kw := range t.Keywords {
+			if strings.Contains(text, kw) {
+				hits++
+			}
+		}
+		if hits == 0 {
+			continue
+		}
+
+		// One keyword is a weak-but-usable signal, just at the threshold;
+		// more push toward certainty
+		confidence := minFacetConfidence + 0.2*float64(hits-1)
+		if strings.Contains(text, strings.ToLower(t.Label)) {
+			confidence += 0.25
+		}
+		if confidence > 0.95 {
+			confidence = 0.95
+		}
+
+		facets = append(facets, models.MediaFacet{
+			MediaID:    mediaID,
+			FacetSlug:  t.Slug,
+			Confidence: confidence,
+			Source:     "keyword",
+		})
+	}
+
+	// Keep at most two facets per category, mirroring the LLM instructions
+	sort.SliceStable(facets, func(i, j int) bool {
+		return facets[i].Confidence > facets[j].Confidence
+	})
+	perCategory := make(map[string]int)
+	kept := facets[:0]
+	for _, f := range facets {
+		category := facetCategory(f.FacetSlug)
+		if perCategory[category] >= 2 {
+			continue
+		}
+		perCategory[category]++
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// facetCategory looks up the category of a vocabulary slug
+func facetCategory(slug string) string {
+	for _, t := range facetVocabulary {
+		if t.Slug == slug {
+			return t.Category
+		}
+	}
+	return ""
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+// facetSummary renders facets as slug:confidence, in order
+func facetSummary(facets []models.MediaFacet) string {
+	out := make([]string, len(facets))
+	for i, f := range facets {
+		out[i] = fmt.Sprintf("%s:%.2f", f.FacetSlug, f.Confidence)
+	}
+	return fmt.Sprint(out)
+}
+
+func TestExtractFacetsByKeywords(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    string
+	}{
+		{"more keywords, more confidence", "Neon noir city in the rain", "[neon-noir:0.90 rain-soaked-streets:0.50]"},
+		{"the label itself counts extra, up to a cap", "A cozy melancholy, bittersweet and melancholic", "[cozy-melancholy:0.95]"},
+		// Painterly names its label; pastel and gritty lose the tie to neon
+		{"two per category", "neon, pastel, gritty and painterly", "[painterly:0.75 neon-noir:0.50]"},
+		{"nothing matches", "a film", "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facets := extractFacetsByKeywords("m1", tt.profile)
+			if got := facetSummary(facets); got != tt.want {
+				t.Errorf("facets = %s, want %s", got, tt.want)
+			}
+			for _, f := range facets {
+				if f.MediaID != "m1" || f.Source != "keyword" {
+					t.Errorf("facet %+v not tagged as a keyword match for m1", f)
+				}
+			}
+		})
+	}
+}
+
+func TestLLMFacetsDedupe(t *testing.T) {
+	facets := llmFacets("m1", []llm.FacetScore{
+		{Slug: "dreamlike", Confidence: 0.4},
+		{Slug: "neon-noir", Confidence: 0.7},
+		{Slug: "dreamlike", Confidence: 0.9},
+		{Slug: "neon-noir", Confidence: 0.2},
+	})
+	if got := facetSummary(facets); got != "[dreamlike:0.90 neon-noir:0.70]" {
+		t.Errorf("facets = %s, want each slug once at its best score", got)
+	}
+	for _, f := range facets {
+		if f.MediaID != "m1" || f.Source != "llm" {
+			t.Errorf("facet %+v not tagged as an LLM match for m1", f)
+		}
+	}
+}
+
+func TestFacetTagging(t *testing.T) {
+	db := newTestDB(t)
+	for _, m := range []models.Media{
+		{ID: "m1", Title: "Blade Runner", MediaType: "movie", VibeProfile: "neon noir city in the rain"},
+		{ID: "m2", Title: "Drive", MediaType: "movie", VibeProfile: "neon glow"},
+		{ID: "m3", Title: "Untagged", MediaType: "movie", VibeProfile: "a film"},
+	} {
+		if err := db.CreateMedia(&m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc, err := NewFacetService(db, nil)
+	if err != nil {
+		t.Fatalf("NewFacetService: %v", err)
+	}
+
+	tagged, err := svc.Backfill(false, nil)
+	if err != nil || tagged != 3 {
+		t.Fatalf("Backfill = %d, %v; want all three", tagged, err)
+	}
+	// m3 matched nothing, so it is still missing facets; the rest are done
+	if tagged, _ := svc.Backfill(false, nil); tagged != 1 {
+		t.Errorf("second backfill tagged %d, want only the facet-less m3", tagged)
+	}
+
+	ids, err := svc.MatchingMediaIDs([]string{"neon-noir"})
+	if err != nil || len(ids) != 2 || !ids["m1"] || !ids["m2"] {
+		t.Errorf("neon-noir titles = %v (%v), want m1 and m2", ids, err)
+	}
+	if ids, _ := svc.MatchingMediaIDs([]string{"neon-noir", "rain-soaked-streets"}); len(ids) != 1 || !ids["m1"] {
+		t.Errorf("titles with both facets = %v, want m1", ids)
+	}
+	facets, err := svc.ListFacets("visual_style")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range facets {
+		if f.Category != "visual_style" {
+			t.Errorf("%s listed under visual_style", f.Slug)
+		}
+		if f.Slug == "neon-noir" && f.MediaCount != 2 {
+			t.Errorf("neon-noir counts %d titles, want 2", f.MediaCount)
+		}
+	}
+	if labels, _ := svc.CommonLabels([]string{"m1", "m2"}, 5); fmt.Sprint(labels) != "[Neon-noir Rain-soaked streets]" {
+		t.Errorf("common labels = %v", labels)
+	}
+
+	// Re-tagging replaces the old set instead of adding to it
+	if err := db.UpdateVibeProfile("m1", "cozy melancholy"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.TagMedia("m1"); err != nil {
+		t.Fatalf("TagMedia: %v", err)
+	}
+	if err := svc.TagMedia("m1"); err != nil {
+		t.Fatalf("TagMedia again: %v", err)
+	}
+	recs := []models.Recommendation{{Media: models.Media{ID: "m1"}}}
+	if err := svc.AttachChips(recs); err != nil {
+		t.Fatal(err)
+	}
+	if chips := recs[0].Facets; len(chips) != 1 || chips[0].Slug != "cozy-melancholy" {
+		t.Errorf("m1 chips after re-tagging = %+v, want only cozy-melancholy", chips)
+	}
+	if err := svc.TagMedia("nope"); err == nil {
+		t.Error("tagged an unknown title")
+	}
+
+	if err := svc.ValidateSlugs([]string{"neon-noir", "dreamlike"}); err != nil {
+		t.Errorf("ValidateSlugs: %v", err)
+	}
+	if err := svc.ValidateSlugs([]string{"neon-noir", "vaporwave"}); err == nil {
+		t.Error("vaporwave accepted as a facet")
+	}
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+const (
+	// Picks added per refresh from each source
+	feedForYouPicks   = 6
+	feedTrendingPicks = 3
+	feedNewPicks      = 3
+	// feedNewWindow is how recently a title must have been added to count as
+	// new in the catalog
+	feedNewWindow = 14 * 24 * time.Hour
+	// feedKeepItems caps how many published picks a feed retains
+	feedKeepItems = 50
+)
+
+// FeedService publishes per-session feeds of fresh picks. Picks are
+// regenerated at most once per refresh interval; in between, feed readers
+// are served the stored items.
+type FeedService struct {
+	db         database.FeedRepository
+	vibeSearch *VibeSearchService
+	refresh    time.Duration
+	baseURL    string
+	mu         sync.Mutex // Serializes refreshes so concurrent polls regenerate once
+}
+
+// NewFeedService creates a feed service that refreshes picks every refresh.
+// baseURL is the site's public address, used in feed and entry links.
+func NewFeedService(db database.FeedRepository, vibeSearch *VibeSearchService, refresh time.Duration, baseURL string) *FeedService {
+	return &FeedService{db: db, vibeSearch: vibeSearch, refresh: refresh, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// BaseURL is the site's public address, without a trailing slash
+func (f *FeedService) BaseURL() string {
+	return f.baseURL
+}
+
+// RefreshInterval is how long a feed's picks are served before regenerating
+func (f *FeedService) RefreshInterval() time.Duration {
+	return f.refresh
+}
+
+// Items returns the feed's items, newest first, refreshing its picks first
+// if they are older than the refresh interval
+func (f *FeedService) Items(feed *models.Feed) ([]models.FeedItem, error) {
+	if f.stale(feed) {
+		if err := f.refreshFeed(feed); err != nil {
+			return nil, err
+		}
+	}
+	items, err := f.db.GetFeedItems(feed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feed items: %w", err)
+	}
+	return items, nil
+}
+
+func (f *FeedService) stale(feed *models.Feed) bool {
+	return feed.RefreshedAt == nil || time.Since(*feed.RefreshedAt) >= f.refresh
+}
+
+func (f *FeedService) refreshFeed(feed *models.Feed) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Another request may have refreshed while this one waited
+	current, err := f.db.GetFeed(feed.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load feed: %w", err)
+	}
+	if current == nil {
+		return fmt.Errorf("feed %s no longer exists", feed.ID)
+	}
+	if !f.stale(current) {
+		feed.RefreshedAt = current.RefreshedAt
+		return nil
+	}
+
+	if err := f.db.RefreshFeed(feed, f.picks(feed.UserID), feedKeepItems); err != nil {
+		return fmt.Errorf("failed to refresh feed: %w", err)
+	}
+	return nil
+}
+
+// picks gathers For-You, trending and new-in-catalog titles the user has not
+// seen, in that order of precedence. A failing source is logged and skipped
+// so the others still publish.
+func (f *FeedService) picks(userID string) []models.FeedItem {
+	var items []models.FeedItem
+	picked := make(map[string]bool)
+	add := func(mediaID, source, explanation string) {
+		if picked[mediaID] {
+			return
+		}
+		picked[mediaID] = true
+		items = append(items, models.FeedItem{MediaID: mediaID, Source: source, Explanation: explanation})
+	}
+
+	if result, err := f.vibeSearch.ForYou(userID, feedForYouPicks, ExploreNone); err != nil {
+		log.Printf("Feed: For-You picks failed for %s: %v", userID, err)
+	} else {
+		for _, rec := range result.Recommendations {
+			add(rec.Media.ID, models.FeedSourceForYou, rec.Explanation)
+		}
+	}
+
+	if result, err := f.vibeSearch.GetTrending(TrendingConfig{UserID: userID, Limit: feedTrendingPicks}); err != nil {
+		log.Printf("Feed: trending picks failed for %s: %v", userID, err)
+	} else {
+		for _, rec := range result.Recommendations {
+			add(rec.Media.ID, models.FeedSourceTrending, rec.Explanation)
+		}
+	}
+
+	if media, err := f.db.GetNewMedia(userID, feedNewPicks); err != nil {
+		log.Printf("Feed: new titles failed for %s: %v", userID, err)
+	} else {
+		for _, m := range media {
+			if time.Since(m.CreatedAt) > feedNewWindow {
+				break
+			}
+			add(m.ID, models.FeedSourceNew, "New in the catalog since "+m.CreatedAt.Format("Jan 2"))
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+// ErrFranchiseEntry is returned when a franchise definition names a title
+// twice or a title that does not exist
+var ErrFranchiseEntry = errors.New("invalid franchise entry")
+
+var franchiseSlugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// FranchiseService groups sequels, separately released seasons and
+// spin-offs, works out where a user should start each franchise, and folds
+// franchises down to that entry point in search results
+type FranchiseService struct {
+	db FranchiseStore
+}
+
+// FranchiseStore is what FranchiseService reads and writes
+type FranchiseStore interface {
+	database.MediaRepository
+	database.SeenRepository
+	database.FranchiseRepository
+}
+
+// NewFranchiseService creates a franchise service
+func NewFranchiseService(db FranchiseStore) *FranchiseService {
+	return &FranchiseService{db: db}
+}
+
+// entryPoint returns the index of the first main entry the user has not
+// seen, or -1 once every main entry is seen. Entries are main first, each
+// in watch order.
+func entryPoint(f *models.Franchise, seenIDs map[string]bool) int {
+	for i, e := range f.Entries {
+		if e.Role == models.FranchiseRoleMain && !seenIDs[e.MediaID] {
+			return i
+		}
+	}
+	return -1
+}
+
+// collapse keeps the best-ranked result of each franchise in the pool and
+// drops the rest. A result that is a later entry than the user's entry
+// point is replaced by the entry point, keeping its scores, so nobody is
+// offered part three before part one. A franchise whose entry point is
+// itself filtered out (dismissed, or excluded by the watchlist mode) is
+// dropped. An entry point outside allowIDs (the search's facet, duration,
+// axis and type restrictions; nil allows everything) does not replace the
+// result that matched them.
+func (s *FranchiseService) collapse(userID string, pool []models.Recommendation, allowIDs, excludedIDs map[string]bool) ([]models.Recommendation, error) {
+	ids := make([]string, len(pool))
+	for i, rec := range pool {
+		ids[i] = rec.Media.ID
+	}
+	franchises, err := s.db.GetFranchisesOf(ids)
+	if err != nil || len(franchises) == 0 {
+		return pool, err
+	}
+	seenIDs, err := s.db.GetSeenMediaIDs(userID)
+	if err != nil {
+		return pool, err
+	}
+
+	type membership struct {
+		franchise *models.Franchise
+		index     int
+	}
+	memberOf := make(map[string]membership)
+	for _, f := range franchises {
+		for i, e := range f.Entries {
+			memberOf[e.MediaID] = membership{f, i}
+		}
+	}
+
+	out := make([]models.Recommendation, 0, len(pool))
+	shown := make(map[string]int) // Franchise ID -> index in out, or -1 if dropped
+	for _, rec := range pool {
+		m, ok := memberOf[rec.Media.ID]
+		if !ok {
+			out = append(out, rec)
+			continue
+		}
+		f := m.franchise
+		if i, ok := shown[f.ID]; ok {
+			if i >= 0 && out[i].Media.ID != rec.Media.ID {
+				out[i].Franchise.Collapsed = append(out[i].Franchise.Collapsed, rec.Media.ID)
+			}
+			continue
+		}
+
+		ref := &models.FranchiseRef{ID: f.ID, Name: f.Name, Total: len(f.Entries)}
+		for _, e := range f.Entries {
+			if seenIDs[e.MediaID] {
+				ref.Seen++
+			}
+		}
+		position := m.index
+		if ep := entryPoint(f, seenIDs); ep >= 0 && ep < m.index {
+			epID := f.Entries[ep].MediaID
+			if excludedIDs[epID] {
+				shown[f.ID] = -1
+				continue
+			}
+			var media *models.Media
+			if allowIDs == nil || allowIDs[epID] {
+				if media, err = s.db.GetMedia(epID); err != nil {
+					return pool, fmt.Errorf("failed to get entry point: %w", err)
+				}
+			}
+			if media != nil {
+				ref.MatchedID = rec.Media.ID
+				rec = models.Recommendation{
+					Media:     *media,
+					VibeScore: rec.VibeScore,
+					Score:     rec.Score,
+				}
+				position = ep
+			}
+		}
+		ref.Position = position + 1
+		rec.Franchise = ref
+		shown[f.ID] = len(out)
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+// WatchOrder returns a franchise with its titles in watch order, each
+// marked seen or not and the user's entry point flagged, or nil
+func (s *FranchiseService) WatchOrder(userID, id string) (*models.Franchise, error) {
+	f, err := s.db.GetFranchise(id)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	if f.Entries, err = s.db.GetFranchiseEntries(id); err != nil {
+		return nil, err
+	}
+	seenIDs, err := s.db.GetSeenMediaIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range f.Entries {
+		f.Entries[i].Seen = seenIDs[f.Entries[i].MediaID]
+	}
+	if ep := entryPoint(f, seenIDs); ep >= 0 {
+		f.Entries[ep].EntryPoint = true
+	}
+	return f, nil
+}
+
+// OfMedia returns the ID of the franchise a title belongs to, or ""
+func (s *FranchiseService) OfMedia(mediaID string) (string, error) {
+	return s.db.GetMediaFranchiseID(mediaID)
+}
+
+// List returns every franchise without entries
+func (s *FranchiseService) List() ([]models.Franchise, error) {
+	return s.db.ListFranchises()
+}
+
+// Define links titles into a hand-made franchise, for series whose seasons
+// are separate titles and for spin-offs TMDB has no collection for. The
+// franchise ID comes from the name, so defining it again replaces it.
+// Titles already in another franchise move to this one.
+func (s *FranchiseService) Define(req models.FranchiseRequest) (*models.Franchise, error) {
+	name := strings.TrimSpace(req.Name)
+	slug := strings.Trim(franchiseSlugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		return nil, fmt.Errorf("%w: name must contain letters or digits", ErrFranchiseEntry)
+	}
+	if len(req.MediaIDs) == 0 {
+		return nil, fmt.Errorf("%w: media_ids must list at least one title", ErrFranchiseEntry)
+	}
+
+	f := &models.Franchise{
+		ID:     "manual-" + slug,
+		Name:   name,
+		Source: models.FranchiseSourceManual,
+	}
+	picked := make(map[string]bool)
+	add := func(id, role string) error {
+		if picked[id] {
+			return fmt.Errorf("%w: %s is listed twice", ErrFranchiseEntry, id)
+		}
+		media, err := s.db.GetMedia(id)
+		if err != nil {
+			return fmt.Errorf("failed to get media: %w", err)
+		}
+		if media == nil {
+			return fmt.Errorf("%w: no media with ID %s", ErrFranchiseEntry, id)
+		}
+		picked[id] = true
+		f.Entries = append(f.Entries, models.FranchiseEntry{MediaID: id, WatchOrder: len(f.Entries) + 1, Role: role})
+		return nil
+	}
+	for _, id := range req.MediaIDs {
+		if err := add(id, models.FranchiseRoleMain); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range req.SpinoffIDs {
+		if err := add(id, models.FranchiseRoleSpinoff); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.ReplaceFranchise(f); err != nil {
+		return nil, fmt.Errorf("failed to save franchise: %w", err)
+	}
+	saved, err := s.db.GetFranchise(f.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload franchise: %w", err)
+	}
+	saved.Entries = f.Entries
+	return saved, nil
+}
+
+// Delete removes a franchise, leaving its titles standalone. Returns false
+// if there was no such franchise.
+func (s *FranchiseService) Delete(id string) (bool, error) {
+	return s.db.DeleteFranchise(id)
+}
+
+// collapseFranchises folds the pool's franchises down to one entry point
+// each, keeping the pool as it is when that fails
+func (s *VibeSearchService) collapseFranchises(userID string, pool []models.Recommendation, allowIDs, excludedIDs map[string]bool) []models.Recommendation {
+	collapsed, err := s.franchises.collapse(userID, pool, allowIDs, excludedIDs)
+	if err != nil {
+		log.Printf("Failed to collapse franchises: %v", err)
+		return pool
+	}
+	return collapsed
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+// franchiseFixture is a MemoryStore holding a three-part series with a
+// spin-off, one standalone title, and a user who has seen part one
+func franchiseFixture(t *testing.T) (*database.MemoryStore, *FranchiseService) {
+	t.Helper()
+	store := database.NewMemoryStore()
+	if err := store.CreateUser(&models.User{ID: "u1", Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []models.Media{
+		{ID: "p1", Title: "Part One", MediaType: "movie", VibeProfile: "a"},
+		{ID: "p2", Title: "Part Two", MediaType: "movie", VibeProfile: "b"},
+		{ID: "p3", Title: "Part Three", MediaType: "movie", VibeProfile: "c"},
+		{ID: "sp", Title: "Spin-off", MediaType: "tv", VibeProfile: "d"},
+		{ID: "solo", Title: "Standalone", MediaType: "movie", VibeProfile: "e"},
+	} {
+		if err := store.CreateMedia(&m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewFranchiseService(store)
+	if _, err := svc.Define(models.FranchiseRequest{
+		Name:       "The Saga",
+		MediaIDs:   []string{"p1", "p2", "p3"},
+		SpinoffIDs: []string{"sp"},
+	}); err != nil {
+		t.Fatalf("Define: %v", err)
+	}
+	return store, svc
+}
+
+func recs(ids ...string) []models.Recommendation {
+	pool := make([]models.Recommendation, len(ids))
+	for i, id := range ids {
+		pool[i] = models.Recommendation{Media: models.Media{ID: id}, Score: float64(len(ids) - i)}
+	}
+	return pool
+}
+
+func TestFranchiseDefine(t *testing.T) {
+	_, svc := franchiseFixture(t)
+
+	f, err := svc.WatchOrder("u1", "manual-the-saga")
+	if err != nil || f == nil {
+		t.Fatalf("WatchOrder = %v, %v", f, err)
+	}
+	if f.Name != "The Saga" || f.EntryCount != 4 {
+		t.Errorf("franchise = %q with %d entries, want The Saga with 4", f.Name, f.EntryCount)
+	}
+	want := []string{"p1", "p2", "p3", "sp"}
+	for i, e := range f.Entries {
+		if e.MediaID != want[i] {
+			t.Fatalf("entry %d = %s, want %s", i, e.MediaID, want[i])
+		}
+	}
+	if !f.Entries[0].Seen || f.Entries[1].Seen {
+		t.Error("only part one should be marked seen")
+	}
+	if !f.Entries[1].EntryPoint {
+		t.Error("part two should be the entry point after seeing part one")
+	}
+
+	for _, req := range []models.FranchiseRequest{
+		{Name: "!!!", MediaIDs: []string{"p1"}},
+		{Name: "Twice", MediaIDs: []string{"p1", "p1"}},
+		{Name: "Missing", MediaIDs: []string{"nope"}},
+	} {
+		if _, err := svc.Define(req); !errors.Is(err, ErrFranchiseEntry) {
+			t.Errorf("Define(%q) error = %v, want ErrFranchiseEntry", req.Name, err)
+		}
+	}
+
+	// Redefining moves titles and drops the ones left out
+	if _, err := svc.Define(models.FranchiseRequest{Name: "The Saga", MediaIDs: []string{"p2", "p3"}}); err != nil {
+		t.Fatalf("redefine: %v", err)
+	}
+	if id, _ := svc.OfMedia("p1"); id != "" {
+		t.Errorf("p1 still in franchise %q after being left out", id)
+	}
+}
+
+func TestFranchiseCollapse(t *testing.T) {
+	_, svc := franchiseFixture(t)
+
+	// Part three matched best; part two is where the user should start
+	out, err := svc.collapse("u1", recs("p3", "solo", "p1", "sp"), nil, nil)
+	if err != nil {
+		t.Fatalf("collapse: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("collapse kept %d results, want 2", len(out))
+	}
+	first := out[0]
+	if first.Media.ID != "p2" || first.Franchise == nil {
+		t.Fatalf("first result = %s, want entry point p2 standing in for the franchise", first.Media.ID)
+	}
+	ref := first.Franchise
+	if ref.MatchedID != "p3" || ref.Position != 2 || ref.Total != 4 || ref.Seen != 1 {
+		t.Errorf("franchise ref = %+v, want matched p3 at position 2 of 4 with 1 seen", *ref)
+	}
+	if len(ref.Collapsed) != 2 || ref.Collapsed[0] != "p1" || ref.Collapsed[1] != "sp" {
+		t.Errorf("collapsed = %v, want [p1 sp]", ref.Collapsed)
+	}
+	if first.Score != 4 {
+		t.Errorf("entry point score = %v, want the matched title's 4", first.Score)
+	}
+	if out[1].Media.ID != "solo" || out[1].Franchise != nil {
+		t.Errorf("second result = %s, want the standalone title untouched", out[1].Media.ID)
+	}
+}
+
+func TestFranchiseCollapseKeepsMatchOutsideFilters(t *testing.T) {
+	_, svc := franchiseFixture(t)
+
+	allow := map[string]bool{"p3": true, "solo": true}
+	out, err := svc.collapse("u1", recs("p3", "solo"), allow, nil)
+	if err != nil {
+		t.Fatalf("collapse: %v", err)
+	}
+	if out[0].Media.ID != "p3" {
+		t.Fatalf("first result = %s, want p3 kept because p2 fails the filters", out[0].Media.ID)
+	}
+	if ref := out[0].Franchise; ref == nil || ref.MatchedID != "" || ref.Position != 3 {
+		t.Errorf("franchise ref = %+v, want p3 at position 3 with no replacement", ref)
+	}
+}
+
+func TestFranchiseCollapseDropsExcludedEntryPoint(t *testing.T) {
+	_, svc := franchiseFixture(t)
+
+	out, err := svc.collapse("u1", recs("p3", "solo"), nil, map[string]bool{"p2": true})
+	if err != nil {
+		t.Fatalf("collapse: %v", err)
+	}
+	if len(out) != 1 || out[0].Media.ID != "solo" {
+		t.Errorf("collapse = %v, want only the standalone title", out)
+	}
+}
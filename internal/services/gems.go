@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+const (
+	// gemMinPriorVotes floors the Bayesian prior weight, so a catalog of
+	// barely-rated titles still shrinks toward its mean
+	gemMinPriorVotes = 50
+	// gemMentionWeight is the share of the gem score given to Reddit
+	// hidden_gem threads naming the title
+	gemMentionWeight = 0.2
+	// gemPopularityCutoff keeps the more popular part of each media type
+	// out of hidden gems
+	gemPopularityCutoff = 0.5
+	// gemQueryWeight blends gem score into vibe similarity when /hidden-gems
+	// has a query
+	gemQueryWeight = 0.3
+)
+
+// GemScorer periodically recomputes hidden-gem scores. A gem is well
+// regarded relative to the catalog and little known relative to its media
+// type; both are expressed as percentiles so neither scale dominates.
+type GemScorer struct {
+	db      database.GemRepository
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+}
+
+// NewGemScorer creates a new gem scoring job
+func NewGemScorer(db database.GemRepository) *GemScorer {
+	return &GemScorer{db: db}
+}
+
+// Start begins periodic recomputation
+func (g *GemScorer) Start(ctx context.Context, interval time.Duration) {
+	g.mu.Lock()
+	if g.running {
+		g.mu.Unlock()
+		return
+	}
+	g.running = true
+	g.stopCh = make(chan struct{})
+	g.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Run immediately on start
+		g.runLogged()
+
+		for {
+			select {
+			case <-ticker.C:
+				g.runLogged()
+			case <-g.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the recomputation loop
+func (g *GemScorer) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.running {
+		close(g.stopCh)
+		g.running = false
+	}
+}
+
+func (g *GemScorer) runLogged() {
+	n, err := g.Recompute()
+	if err != nil {
+		log.Printf("Gem scoring failed: %v", err)
+		return
+	}
+	log.Printf("Gem scoring: scored %d titles", n)
+}
+
+// Recompute scores every title and replaces the stored scores. Returns the
+// number of titles scored.
+func (g *GemScorer) Recompute() (int, error) {
+	inputs, err := g.db.GetGemInputs()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load gem inputs: %w", err)
+	}
+	scores := scoreGems(inputs)
+	if err := g.db.ReplaceGemScores(scores); err != nil {
+		return 0, fmt.Errorf("failed to store gem scores: %w", err)
+	}
+	return len(scores), nil
+}
+
+// scoreGems computes gem scores:
+//
+//	bayes = (v*R + m*C) / (v + m)        C = mean rating, m = median vote count
+//	score = (1-w) * sqrt(ratingPct * (1-popularityPct)) + w * mentions/maxMentions (log-scaled)
+//
+// ratingPct ranks bayes across the catalog; popularityPct ranks popularity
+// (ties broken by vote count) within the title's media type. Titles without
+// votes get the catalog mean.
+func scoreGems(inputs []models.GemInput) []models.GemScore {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	var ratingSum float64
+	var counts []int
+	for _, in := range inputs {
+		if in.VoteCount > 0 {
+			ratingSum += in.VoteAverage
+			counts = append(counts, in.VoteCount)
+		}
+	}
+	mean := 0.0
+	prior := float64(gemMinPriorVotes)
+	if len(counts) > 0 {
+		mean = ratingSum / float64(len(counts))
+		sort.Ints(counts)
+		prior = math.Max(prior, float64(counts[len(counts)/2]))
+	}
+
+	maxMentions := 0
+	bayes := make([]float64, len(inputs))
+	for i, in := range inputs {
+		v := float64(in.VoteCount)
+		bayes[i] = (v*in.VoteAverage + prior*mean) / (v + prior)
+		if in.Mentions > maxMentions {
+			maxMentions = in.Mentions
+		}
+	}
+	ratingPct := percentiles(len(inputs), func(i, j int) int {
+		return compareFloat(bayes[i], bayes[j])
+	})
+
+	// Popularity percentiles are per media type: a mid-popular anime is far
+	// less known than a mid-popular film
+	popularityPct := make([]float64, len(inputs))
+	byType := make(map[string][]int)
+	for i, in := range inputs {
+		byType[in.MediaType] = append(byType[in.MediaType], i)
+	}
+	for _, idx := range byType {
+		pct := percentiles(len(idx), func(a, b int) int {
+			x, y := inputs[idx[a]], inputs[idx[b]]
+			if c := compareFloat(x.Popularity, y.Popularity); c != 0 {
+				return c
+			}
+			return x.VoteCount - y.VoteCount
+		})
+		for k, i := range idx {
+			popularityPct[i] = pct[k]
+		}
+	}
+
+	scores := make([]models.GemScore, len(inputs))
+	for i, in := range inputs {
+		mention := 0.0
+		if maxMentions > 0 {
+			mention = math.Log1p(float64(in.Mentions)) / math.Log1p(float64(maxMentions))
+		}
+		core := math.Sqrt(ratingPct[i] * (1 - popularityPct[i]))
+		scores[i] = models.GemScore{
+			MediaID:       in.MediaID,
+			Score:         (1-gemMentionWeight)*core + gemMentionWeight*mention,
+			BayesRating:   bayes[i],
+			RatingPct:     ratingPct[i],
+			PopularityPct: popularityPct[i],
+			Mentions:      in.Mentions,
+		}
+	}
+	return scores
+}
+
+// percentiles ranks n items with cmp and returns each one's percentile in
+// [0, 1]. Ties share their average rank; a single item sits at 0.5.
+func percentiles(n int, cmp func(i, j int) int) []float64 {
+	pct := make([]float64, n)
+	if n == 1 {
+		pct[0] = 0.5
+		return pct
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return cmp(order[a], order[b]) < 0 })
+
+	for start := 0; start < n; {
+		end := start + 1
+		for end < n && cmp(order[start], order[end]) == 0 {
+			end++
+		}
+		rank := float64(start+end-1) / 2
+		for k := start; k < end; k++ {
+			pct[order[k]] = rank / float64(n-1)
+		}
+		start = end
+	}
+	return pct
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// GemConfig holds the options for a hidden-gems request
+type GemConfig struct {
+	UserID    string
+	Query     string // Optional vibe; without one gems are ranked by gem score alone
+	Limit     int
+	MediaType string   // "movie", "tv", "anime" or "documentary"; empty for all
+	Facets    []string // Facet slugs every result must carry (AND)
+	Duration  models.DurationFilter
+}
+
+// GetHiddenGems finds well-regarded but little-known media the user has not
+// seen. With a query, gems are vibe-searched and their gem score blended in.
+func (s *VibeSearchService) GetHiddenGems(config GemConfig) (*SearchResult, error) {
+	if config.Limit <= 0 {
+		config.Limit = 10
+	}
+
+	if config.Query != "" {
+		gems, err := s.db.GetGemScores(config.MediaType, gemPopularityCutoff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gem scores: %w", err)
+		}
+		return s.Search(SearchConfig{
+			UserID:       config.UserID,
+			Query:        config.Query,
+			TopK:         config.Limit * 3,
+			FinalResults: config.Limit,
+			Facets:       config.Facets,
+			Duration:     config.Duration,
+			Gems:         gems,
+		})
+	}
+
+	candidates, err := s.db.GetHiddenGemCandidates(config.UserID, config.MediaType, gemPopularityCutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hidden gems: %w", err)
+	}
+
+	// Facet and duration filters apply on top of the gem ordering
+	var allowIDs map[string]bool
+	if len(config.Facets) > 0 {
+		allowIDs, err = s.facets.MatchingMediaIDs(config.Facets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by facets: %w", err)
+		}
+	}
+	if config.Duration.Active() {
+		fitting, err := s.db.GetMediaIDsWithinDuration(config.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by duration: %w", err)
+		}
+		allowIDs = intersectIDs(allowIDs, fitting)
+	}
+
+	// Over-fetch so rating signals have room to reorder
+	var recommendations []models.Recommendation
+	for _, g := range candidates {
+		if allowIDs != nil && !allowIDs[g.MediaID] {
+			continue
+		}
+		media := *g.Media
+		gem := g
+		gem.Media = nil
+		recommendations = append(recommendations, models.Recommendation{
+			Media:       media,
+			Score:       g.Score,
+			Explanation: gemExplanation(gem),
+			Gem:         &gem,
+		})
+		if len(recommendations) >= config.Limit*3 {
+			break
+		}
+	}
+
+	s.applyUserRatingSignals(config.UserID, s.tuning.RatingSignalStrength, recommendations)
+	sortByScore(recommendations)
+	result := &SearchResult{
+		TotalCandidates: len(recommendations),
+		Candidates:      rankedItems(recommendations),
+	}
+	if len(recommendations) > config.Limit {
+		recommendations = recommendations[:config.Limit]
+	}
+	for i := range recommendations {
+		recommendations[i].Rank = i + 1
+	}
+	if recommendations == nil {
+		recommendations = []models.Recommendation{}
+	}
+	if err := s.facets.AttachChips(recommendations); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+
+	result.Recommendations = recommendations
+	return result, nil
+}
+
+// applyGemScores blends each candidate's gem score into its similarity and
+// attaches the breakdown
+func applyGemScores(gems map[string]models.GemScore, pool []models.Recommendation) {
+	for i := range pool {
+		g, ok := gems[pool[i].Media.ID]
+		if !ok {
+			continue
+		}
+		pool[i].Score = (1-gemQueryWeight)*pool[i].Score + gemQueryWeight*g.Score
+		pool[i].Gem = &g
+	}
+}
+
+// gemExplanation says why a title counts as a hidden gem
+func gemExplanation(g models.GemScore) string {
+	text := fmt.Sprintf("Rated above %.0f%% of the catalog, yet less known than %.0f%% of its peers",
+		g.RatingPct*100, (1-g.PopularityPct)*100)
+	if g.Mentions > 0 {
+		text += fmt.Sprintf("; called a hidden gem in %d Reddit thread", g.Mentions)
+		if g.Mentions > 1 {
+			text += "s"
+		}
+	}
+	return text
+}
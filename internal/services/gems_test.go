@@ -0,0 +1,113 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestPercentiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   []float64
+	}{
+		{"single item", []float64{7}, []float64{0.5}},
+		{"distinct", []float64{3, 1, 2}, []float64{1, 0, 0.5}},
+		// The tied pair shares ranks 1 and 2
+		{"tie in the middle", []float64{1, 2, 2, 3}, []float64{0, 0.5, 0.5, 1}},
+		{"all tied", []float64{5, 5, 5}, []float64{0.5, 0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		got := percentiles(len(tt.values), func(i, j int) int {
+			return compareFloat(tt.values[i], tt.values[j])
+		})
+		for i := range tt.want {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: percentiles = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestScoreGems(t *testing.T) {
+	// Rated titles average (9+7+6)/3 = 7.333 with a median of 100 votes,
+	// which outweighs the 50-vote floor as the prior
+	inputs := []models.GemInput{
+		{MediaID: "a", MediaType: "movie", VoteAverage: 9, VoteCount: 10, Popularity: 1, Mentions: 3},
+		{MediaID: "b", MediaType: "movie", VoteAverage: 7, VoteCount: 1000, Popularity: 100},
+		{MediaID: "c", MediaType: "anime", VoteAverage: 6, VoteCount: 100, Popularity: 5},
+		{MediaID: "d", MediaType: "anime", Popularity: 1, Mentions: 1},
+		{MediaID: "e", MediaType: "tv", Popularity: 1000},
+	}
+	byID := make(map[string]models.GemScore)
+	for _, s := range scoreGems(inputs) {
+		byID[s.MediaID] = s
+	}
+
+	mean := 22.0 / 3
+	tests := []struct {
+		id                       string
+		bayes, ratingPct, popPct float64
+		score                    float64
+	}{
+		// (10*9 + 100*7.333) / 110: ten votes of 9 barely lift it off the mean,
+		// but it still rates highest
+		{"a", (90 + 100*mean) / 110, 1, 0, 0.8*1 + 0.2*1},
+		{"b", (7000 + 100*mean) / 1100, 0.25, 1, 0},
+		// The most popular anime, though far less popular than b
+		{"c", (600 + 100*mean) / 200, 0, 1, 0},
+		// Unrated titles get the mean, so d and e tie on ranks 2 and 3
+		{"d", mean, 0.625, 0, 0.8*math.Sqrt(0.625) + 0.2*math.Log(2)/math.Log(4)},
+		// Alone in its media type
+		{"e", mean, 0.625, 0.5, 0.8 * math.Sqrt(0.625*0.5)},
+	}
+	for _, tt := range tests {
+		got, ok := byID[tt.id]
+		if !ok {
+			t.Fatalf("%s not scored", tt.id)
+		}
+		for _, c := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"bayes rating", got.BayesRating, tt.bayes},
+			{"rating percentile", got.RatingPct, tt.ratingPct},
+			{"popularity percentile", got.PopularityPct, tt.popPct},
+			{"score", got.Score, tt.score},
+		} {
+			if math.Abs(c.got-c.want) > 1e-9 {
+				t.Errorf("%s %s = %.6f, want %.6f", tt.id, c.name, c.got, c.want)
+			}
+		}
+	}
+}
+
+func TestHiddenGemsWithoutQueryAttachChips(t *testing.T) {
+	svc := rankingFixture(t)
+	if err := svc.db.ReplaceGemScores([]models.GemScore{
+		{MediaID: "far", Score: 0.9, RatingPct: 1, PopularityPct: 0},
+		{MediaID: "twin", Score: 0.4, RatingPct: 0.5, PopularityPct: 0.5},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.db.ReplaceMediaFacets("far", []models.MediaFacet{
+		{MediaID: "far", FacetSlug: "dreamlike", Confidence: 0.9, Source: "keyword"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := svc.GetHiddenGems(GemConfig{UserID: "u1"})
+	if err != nil {
+		t.Fatalf("GetHiddenGems: %v", err)
+	}
+	if len(result.Recommendations) != 2 || result.Recommendations[0].Media.ID != "far" {
+		t.Fatalf("gems = %v, want far then twin", resultIDs(result))
+	}
+	chips := result.Recommendations[0].Facets
+	if len(chips) != 1 || chips[0].Slug != "dreamlike" {
+		t.Errorf("far facet chips = %+v, want dreamlike", chips)
+	}
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"w2w/internal/embeddings"
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+const (
+	// maxGroupMembers caps a watch party; beyond this nobody is happy anyway
+	maxGroupMembers = 12
+	// inviteCodeLength is short enough to read out loud
+	inviteCodeLength = 6
+	// inviteAlphabet leaves out look-alikes (0/O, 1/I/L)
+	inviteAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	// groupQueryWeight is the share of member fit that comes from the query
+	// when both a query and the member's taste are available
+	groupQueryWeight = 0.5
+	// maxMemberFavorites is how many liked titles describe a member to the LLM
+	maxMemberFavorites = 3
+)
+
+// ErrGroupFull is returned when joining a group at maxGroupMembers
+var ErrGroupFull = errors.New("group is full")
+
+// ValidGroupStrategy reports whether s names an aggregation strategy
+func ValidGroupStrategy(s string) bool {
+	switch s {
+	case models.GroupStrategyAverage, models.GroupStrategyLeastMisery, models.GroupStrategyMostPleasure:
+		return true
+	}
+	return false
+}
+
+// CreateGroup starts a watch party owned by the user, with a fresh invite
+// code
+func (s *VibeSearchService) CreateGroup(ownerID, name, nickname string) (*models.Group, error) {
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &models.Group{
+		ID:        id,
+		Name:      name,
+		OwnerID:   ownerID,
+		CreatedAt: time.Now(),
+	}
+	if nickname == "" {
+		nickname = "Member 1"
+	}
+
+	// Codes are short, so retry the rare collision instead of lengthening them
+	for attempt := 0; ; attempt++ {
+		g.InviteCode, err = inviteCode()
+		if err != nil {
+			return nil, err
+		}
+		err = s.db.CreateGroup(g, nickname)
+		if err == nil || attempt == 2 || !strings.Contains(err.Error(), "UNIQUE") {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	return g, nil
+}
+
+// JoinGroup adds the user to the group behind an invite code. Joining again
+// just updates the nickname. Returns nil if the code is unknown.
+func (s *VibeSearchService) JoinGroup(userID, code, nickname string) (*models.Group, error) {
+	g, err := s.db.GetGroupByInviteCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil || g == nil {
+		return nil, err
+	}
+
+	members, err := s.db.GetGroupMembers(g.ID)
+	if err != nil {
+		return nil, err
+	}
+	already := false
+	for _, m := range members {
+		if m.UserID == userID {
+			already = true
+			if nickname == "" {
+				nickname = m.Nickname
+			}
+		}
+	}
+	if !already && len(members) >= maxGroupMembers {
+		return nil, ErrGroupFull
+	}
+	if nickname == "" {
+		nickname = defaultNickname(members)
+	}
+
+	if err := s.db.AddGroupMember(g.ID, userID, nickname); err != nil {
+		return nil, fmt.Errorf("failed to join group: %w", err)
+	}
+	return g, nil
+}
+
+// defaultNickname returns the first "Member N" no current member goes by,
+// so a leaver's number is reused instead of duplicating someone else's
+func defaultNickname(members []models.GroupMember) string {
+	taken := make(map[string]bool, len(members))
+	for _, m := range members {
+		taken[m.Nickname] = true
+	}
+	for n := 1; ; n++ {
+		nickname := fmt.Sprintf("Member %d", n)
+		if !taken[nickname] {
+			return nickname
+		}
+	}
+}
+
+// MemberGroup returns the group with its members if the user belongs to it,
+// nil otherwise (other people's groups look missing, not forbidden)
+func (s *VibeSearchService) MemberGroup(userID, groupID string) (*models.Group, error) {
+	g, err := s.db.GetGroup(groupID)
+	if err != nil || g == nil {
+		return nil, err
+	}
+	members, err := s.db.GetGroupMembers(g.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := false
+	for i := range members {
+		if members[i].UserID == userID {
+			members[i].IsYou = true
+			isMember = true
+		}
+	}
+	if !isMember {
+		return nil, nil
+	}
+	g.Members = members
+	return g, nil
+}
+
+// groupMember is a member's taste as used for scoring
+type groupMember struct {
+	userID    string
+	nickname  string
+	centroids []models.TasteCentroid
+}
+
+// GroupRecommend picks titles for the whole group. Each candidate gets a
+// fit per member (query match blended with that member's taste), and the
+// strategy folds those fits into one score. Anything any member has seen or
+// dismissed is excluded.
+func (s *VibeSearchService) GroupRecommend(g *models.Group, query, strategy string, limit int) (*SearchResult, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+
+	// Step 1: Union of every member's exclusions, and their taste profiles
+	excludedIDs := make(map[string]bool)
+	members := make([]groupMember, len(g.Members))
+	for i, m := range g.Members {
+		ids, err := s.db.GetExcludedMediaIDs(m.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get excluded media: %w", err)
+		}
+		for id := range ids {
+			excludedIDs[id] = true
+		}
+
+		centroids, err := s.tasteProfile(m.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load taste profile: %w", err)
+		}
+		members[i] = groupMember{userID: m.UserID, nickname: m.Nickname, centroids: centroids}
+	}
+
+	result := &SearchResult{
+		Recommendations: []models.Recommendation{},
+		Query:           query,
+		FilteredCount:   len(excludedIDs),
+	}
+
+	// Step 2: Candidates near the query, or near anyone's taste without one
+	querySim := make(map[string]float64)
+	var candidates []embeddings.SearchResult
+	if query != "" {
+		queryEmbedding, err := s.embedder.Embed(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		candidates = s.vectorStore.Search(queryEmbedding, limit*6, excludedIDs)
+		for _, c := range candidates {
+			querySim[c.MediaID] = c.Similarity
+		}
+	} else {
+		seen := make(map[string]bool)
+		for _, m := range members {
+			for _, c := range m.centroids {
+				for _, cand := range s.vectorStore.Search(c.Vector, limit*2, excludedIDs) {
+					if !seen[cand.MediaID] {
+						seen[cand.MediaID] = true
+						candidates = append(candidates, cand)
+					}
+				}
+			}
+		}
+	}
+	result.TotalCandidates = len(candidates)
+
+	// Step 3: Per-member fit, folded by the strategy
+	var pool []models.Recommendation
+	for _, c := range candidates {
+		vec, ok := s.vectorStore.Get(c.MediaID)
+		if !ok {
+			continue
+		}
+
+		fits := make([]models.MemberFit, len(members))
+		var known []float64
+		for i, m := range members {
+			fits[i].UserID = m.userID
+			fits[i].Nickname = m.nickname
+			fit, ok := memberFit(vec, m.centroids, querySim, c.MediaID, query != "")
+			if !ok {
+				continue
+			}
+			fits[i].Fit = &fit
+			known = append(known, fit)
+		}
+		if len(known) == 0 {
+			continue
+		}
+
+		media, err := s.db.GetMedia(c.MediaID)
+		if err != nil || media == nil {
+			continue
+		}
+		pool = append(pool, models.Recommendation{
+			Media:      *media,
+			VibeScore:  querySim[c.MediaID],
+			Score:      aggregateFits(known, strategy),
+			MemberFits: fits,
+		})
+	}
+
+	sortByScore(pool)
+	result.Candidates = rankedItems(pool)
+	if len(pool) > limit {
+		pool = pool[:limit]
+	}
+	for i := range pool {
+		pool[i].Rank = i + 1
+	}
+
+	// Step 4: Explain each pick for this group
+	s.explainGroupPicks(g, pool, strategy)
+
+	if err := s.facets.AttachChips(pool); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+
+	result.Recommendations = pool
+	return result, nil
+}
+
+// memberFit scores a candidate for one member: max cosine to their taste
+// centroids, blended with the query match when there is a query. Members
+// without history fall back to the query match alone, and have no fit at all
+// without a query.
+func memberFit(vec []float32, centroids []models.TasteCentroid, querySim map[string]float64, mediaID string, hasQuery bool) (float64, bool) {
+	if len(centroids) == 0 {
+		if !hasQuery {
+			return 0, false
+		}
+		return querySim[mediaID], true
+	}
+
+	taste := -1.0
+	for _, c := range centroids {
+		if sim := embeddings.CosineSimilarity(vec, c.Vector); sim > taste {
+			taste = sim
+		}
+	}
+	if !hasQuery {
+		return taste, true
+	}
+	return groupQueryWeight*querySim[mediaID] + (1-groupQueryWeight)*taste, true
+}
+
+// aggregateFits folds member fits into a group score
+func aggregateFits(fits []float64, strategy string) float64 {
+	switch strategy {
+	case models.GroupStrategyLeastMisery:
+		lowest := fits[0]
+		for _, f := range fits[1:] {
+			if f < lowest {
+				lowest = f
+			}
+		}
+		return lowest
+	case models.GroupStrategyMostPleasure:
+		highest := fits[0]
+		for _, f := range fits[1:] {
+			if f > highest {
+				highest = f
+			}
+		}
+		return highest
+	default:
+		var sum float64
+		for _, f := range fits {
+			sum += f
+		}
+		return sum / float64(len(fits))
+	}
+}
+
+// explainGroupPicks asks the LLM why each pick suits the group, falling
+// back to a summary of the member fits
+func (s *VibeSearchService) explainGroupPicks(g *models.Group, pool []models.Recommendation, strategy string) {
+	var explanations map[string]string
+	if s.llmClient != nil && len(pool) > 0 {
+		tastes := make([]llm.GroupMemberTaste, len(g.Members))
+		for i, m := range g.Members {
+			tastes[i] = llm.GroupMemberTaste{UserID: m.UserID, Nickname: m.Nickname, Favorites: s.memberFavorites(m.UserID)}
+		}
+		picks := make([]llm.GroupPick, len(pool))
+		for i, r := range pool {
+			picks[i] = llm.GroupPick{
+				MediaID:     r.Media.ID,
+				Title:       r.Media.Title,
+				VibeProfile: r.Media.VibeProfile,
+				Fits:        make(map[string]float64),
+			}
+			for _, f := range r.MemberFits {
+				if f.Fit != nil {
+					picks[i].Fits[f.UserID] = *f.Fit
+				}
+			}
+		}
+
+		var err error
+		explanations, err = s.llmClient.ExplainGroupPicks(tastes, picks)
+		if err != nil {
+			log.Printf("Failed to explain group picks: %v", err)
+		}
+	}
+
+	for i := range pool {
+		if e := explanations[pool[i].Media.ID]; e != "" {
+			pool[i].Explanation = e
+			continue
+		}
+		pool[i].Explanation = fitExplanation(pool[i].MemberFits, strategy)
+	}
+}
+
+// memberFavorites lists a member's highest-rated titles (unrated titles
+// rank as a middling 6)
+func (s *VibeSearchService) memberFavorites(userID string) []string {
+	seen, err := s.db.GetSeenMedia(userID)
+	if err != nil {
+		return nil
+	}
+
+	rating := func(sm models.SeenMedia) float64 {
+		if sm.Rating == nil {
+			return 6
+		}
+		return *sm.Rating
+	}
+	sort.SliceStable(seen, func(i, j int) bool {
+		return rating(seen[i]) > rating(seen[j])
+	})
+
+	var titles []string
+	for _, sm := range seen {
+		if len(titles) >= maxMemberFavorites || rating(sm) <= 5 {
+			break
+		}
+		if media, err := s.db.GetMedia(sm.MediaID); err == nil && media != nil {
+			titles = append(titles, media.Title)
+		}
+	}
+	return titles
+}
+
+// fitExplanation describes a pick by its best and worst member fit
+func fitExplanation(fits []models.MemberFit, strategy string) string {
+	var best, worst *models.MemberFit
+	for i := range fits {
+		f := &fits[i]
+		if f.Fit == nil {
+			continue
+		}
+		if best == nil || *f.Fit > *best.Fit {
+			best = f
+		}
+		if worst == nil || *f.Fit < *worst.Fit {
+			worst = f
+		}
+	}
+	if best == nil {
+		return "A match for the group's vibe"
+	}
+	if best == worst {
+		return fmt.Sprintf("Right up %s's alley", best.Nickname)
+	}
+
+	switch strategy {
+	case models.GroupStrategyLeastMisery:
+		return fmt.Sprintf("Safe for everyone: even %s's fit is %.2f", worst.Nickname, *worst.Fit)
+	case models.GroupStrategyMostPleasure:
+		return fmt.Sprintf("%s will love this (fit %.2f)", best.Nickname, *best.Fit)
+	default:
+		return fmt.Sprintf("Best for %s (%.2f), still works for %s (%.2f)",
+			best.Nickname, *best.Fit, worst.Nickname, *worst.Fit)
+	}
+}
+
+// inviteCode returns a random, easy-to-read group invite code
+func inviteCode() (string, error) {
+	b := make([]byte, inviteCodeLength)
+	size := big.NewInt(int64(len(inviteAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate invite code: %w", err)
+		}
+		b[i] = inviteAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestAggregateFits(t *testing.T) {
+	fits := []float64{0.9, 0.3, 0.6}
+	tests := []struct {
+		strategy string
+		want     float64
+	}{
+		{models.GroupStrategyAverage, 0.6},
+		{models.GroupStrategyLeastMisery, 0.3},
+		{models.GroupStrategyMostPleasure, 0.9},
+		// Anything else averages, as the handler only lets valid ones through
+		{"", 0.6},
+	}
+	for _, tt := range tests {
+		if got := aggregateFits(fits, tt.strategy); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("aggregateFits(%v, %q) = %v, want %v", fits, tt.strategy, got, tt.want)
+		}
+	}
+	if got := aggregateFits([]float64{0.4}, models.GroupStrategyLeastMisery); got != 0.4 {
+		t.Errorf("one member's least misery = %v, want their own fit", got)
+	}
+}
+
+func TestMemberFit(t *testing.T) {
+	vec := []float32{1, 0, 0}
+	querySim := map[string]float64{"m1": 0.8}
+	taste := []models.TasteCentroid{
+		{Vector: []float32{0, 1, 0}},
+		{Vector: []float32{0.6, 0.8, 0}},
+	}
+
+	if _, ok := memberFit(vec, nil, querySim, "m1", false); ok {
+		t.Error("a member without history or a query got a fit")
+	}
+	if fit, ok := memberFit(vec, nil, querySim, "m1", true); !ok || fit != 0.8 {
+		t.Errorf("without history fit = %v, %v; want the query match 0.8", fit, ok)
+	}
+	// The closest taste mode counts, not the average of them
+	if fit, ok := memberFit(vec, taste, querySim, "m1", false); !ok || math.Abs(fit-0.6) > 1e-6 {
+		t.Errorf("taste-only fit = %v, %v; want 0.6", fit, ok)
+	}
+	want := groupQueryWeight*0.8 + (1-groupQueryWeight)*0.6
+	if fit, ok := memberFit(vec, taste, querySim, "m1", true); !ok || math.Abs(fit-want) > 1e-6 {
+		t.Errorf("blended fit = %v, %v; want %v", fit, ok, want)
+	}
+}
+
+func TestFitExplanation(t *testing.T) {
+	high, low := 0.9, 0.2
+	fits := []models.MemberFit{
+		{Nickname: "Ana", Fit: &high},
+		{Nickname: "Sam", Fit: &low},
+		{Nickname: "Lee"},
+	}
+	tests := []struct {
+		strategy string
+		want     string
+	}{
+		{models.GroupStrategyAverage, "Best for Ana (0.90), still works for Sam (0.20)"},
+		{models.GroupStrategyLeastMisery, "Safe for everyone: even Sam's fit is 0.20"},
+		{models.GroupStrategyMostPleasure, "Ana will love this (fit 0.90)"},
+	}
+	for _, tt := range tests {
+		if got := fitExplanation(fits, tt.strategy); got != tt.want {
+			t.Errorf("fitExplanation(%s) = %q, want %q", tt.strategy, got, tt.want)
+		}
+	}
+	if got := fitExplanation(fits[:1], models.GroupStrategyAverage); got != "Right up Ana's alley" {
+		t.Errorf("one known fit: %q", got)
+	}
+	if got := fitExplanation(fits[2:], models.GroupStrategyAverage); got != "A match for the group's vibe" {
+		t.Errorf("no known fits: %q", got)
+	}
+}
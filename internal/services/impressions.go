@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+// ImpressionLogger records recommendation responses and the interactions
+// that follow them, and prunes both once they outlive the retention window
+type ImpressionLogger struct {
+	db        database.ImpressionRepository
+	retention time.Duration // 0 disables logging
+	mu        sync.Mutex
+	running   bool
+	stopCh    chan struct{}
+}
+
+// NewImpressionLogger creates an impression logger. A zero retention turns
+// logging off entirely.
+func NewImpressionLogger(db database.ImpressionRepository, retention time.Duration) *ImpressionLogger {
+	return &ImpressionLogger{db: db, retention: retention}
+}
+
+// Enabled reports whether impressions are being recorded
+func (l *ImpressionLogger) Enabled() bool {
+	return l.retention > 0
+}
+
+// Log stores one recommendation response under its request ID
+func (l *ImpressionLogger) Log(requestID, userID, surface, query string, options map[string]interface{}, result *SearchResult) error {
+	if !l.Enabled() || requestID == "" || result == nil {
+		return nil
+	}
+
+	imp := &models.Impression{
+		RequestID:  requestID,
+		UserID:     userID,
+		Surface:    surface,
+		Query:      query,
+		Options:    options,
+		Candidates: result.Candidates,
+		Results:    make([]models.ImpressionItem, len(result.Recommendations)),
+		CreatedAt:  time.Now(),
+	}
+	if imp.Options == nil {
+		imp.Options = map[string]interface{}{}
+	}
+	imp.Options["reranked"] = result.Reranked
+	if result.Explore != ExploreNone {
+		imp.Options["explore"] = result.Explore
+	}
+	if imp.Candidates == nil {
+		imp.Candidates = []models.ImpressionItem{}
+	}
+	for i, r := range result.Recommendations {
+		imp.Results[i] = models.ImpressionItem{
+			MediaID:   r.Media.ID,
+			Rank:      r.Rank,
+			VibeScore: r.VibeScore,
+			Score:     r.Score,
+			Explored:  r.Explored,
+		}
+	}
+
+	if err := l.db.CreateImpression(imp); err != nil {
+		return fmt.Errorf("failed to log impression: %w", err)
+	}
+	return nil
+}
+
+// ErrUnknownImpression is returned when an interaction names a request ID
+// that was never logged for this user (or has been pruned)
+var ErrUnknownImpression = errors.New("unknown request_id")
+
+// RecordInteraction logs a follow-up action against the user's own impression
+func (l *ImpressionLogger) RecordInteraction(userID, requestID, mediaID, action string) (*models.Interaction, error) {
+	owner, err := l.db.GetImpressionUserID(requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up impression: %w", err)
+	}
+	if owner == "" || owner != userID {
+		return nil, ErrUnknownImpression
+	}
+
+	in := &models.Interaction{
+		RequestID: requestID,
+		UserID:    userID,
+		MediaID:   mediaID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	}
+	if err := l.db.CreateInteraction(in); err != nil {
+		return nil, fmt.Errorf("failed to log interaction: %w", err)
+	}
+	return in, nil
+}
+
+// Export streams impressions logged in [since, until) to fn
+func (l *ImpressionLogger) Export(since, until time.Time, fn func(*models.Impression) error) error {
+	return l.db.ExportImpressions(since, until, fn)
+}
+
+// Start begins periodic pruning of impressions past the retention window
+func (l *ImpressionLogger) Start(ctx context.Context, interval time.Duration) {
+	l.mu.Lock()
+	if l.running || !l.Enabled() {
+		l.mu.Unlock()
+		return
+	}
+	l.running = true
+	l.stopCh = make(chan struct{})
+	l.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Run immediately on start
+		l.pruneLogged()
+
+		for {
+			select {
+			case <-ticker.C:
+				l.pruneLogged()
+			case <-l.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the pruning loop
+func (l *ImpressionLogger) Stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running {
+		close(l.stopCh)
+		l.running = false
+	}
+}
+
+func (l *ImpressionLogger) pruneLogged() {
+	removed, err := l.db.PruneImpressions(time.Now().Add(-l.retention))
+	if err != nil {
+		log.Printf("Impression pruning failed: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("Pruned %d impressions older than %v", removed, l.retention)
+	}
+}
+
+// rankedItems snapshots a scored pool in its current order
+func rankedItems(pool []models.Recommendation) []models.ImpressionItem {
+	items := make([]models.ImpressionItem, len(pool))
+	for i, r := range pool {
+		items[i] = models.ImpressionItem{
+			MediaID:   r.Media.ID,
+			Rank:      i + 1,
+			VibeScore: r.VibeScore,
+			Score:     r.Score,
+		}
+	}
+	return items
+}
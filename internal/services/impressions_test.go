@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// exportAll collects every impression logged in the last day
+func exportAll(t *testing.T, l *ImpressionLogger) []*models.Impression {
+	t.Helper()
+	var out []*models.Impression
+	err := l.Export(time.Now().Add(-24*time.Hour), time.Now().Add(time.Minute), func(imp *models.Impression) error {
+		out = append(out, imp)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	return out
+}
+
+func TestImpressionAttribution(t *testing.T) {
+	db := newTestDB(t)
+	l := NewImpressionLogger(db, time.Hour)
+	result := &SearchResult{
+		Recommendations: []models.Recommendation{
+			{Media: models.Media{ID: "a"}, Rank: 1, Score: 0.9, VibeScore: 0.8},
+			{Media: models.Media{ID: "b"}, Rank: 2, Score: 0.7, VibeScore: 0.75, Explored: true},
+		},
+		Reranked: true,
+		Explore:  ExploreThompson,
+	}
+	if err := l.Log("r1", "u1", models.SurfaceSearch, "cosy", map[string]interface{}{"endpoint": "vibe"}, result); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	// Nothing to attribute to without a request ID
+	if err := l.Log("", "u1", models.SurfaceSearch, "cosy", nil, result); err != nil {
+		t.Fatalf("Log without a request ID: %v", err)
+	}
+
+	in, err := l.RecordInteraction("u1", "r1", "b", models.ActionClicked)
+	if err != nil || in.ID == 0 {
+		t.Fatalf("RecordInteraction = %+v, %v", in, err)
+	}
+	if _, err := l.RecordInteraction("u2", "r1", "a", models.ActionClicked); !errors.Is(err, ErrUnknownImpression) {
+		t.Errorf("interaction on someone else's impression: error %v, want ErrUnknownImpression", err)
+	}
+	if _, err := l.RecordInteraction("u1", "r9", "a", models.ActionClicked); !errors.Is(err, ErrUnknownImpression) {
+		t.Errorf("interaction on an unlogged request: error %v, want ErrUnknownImpression", err)
+	}
+
+	logged := exportAll(t, l)
+	if len(logged) != 1 {
+		t.Fatalf("%d impressions exported, want 1", len(logged))
+	}
+	imp := logged[0]
+	if imp.UserID != "u1" || imp.Query != "cosy" || imp.Options["endpoint"] != "vibe" || imp.Options["reranked"] != true || imp.Options["explore"] != string(ExploreThompson) {
+		t.Errorf("impression = %+v", imp)
+	}
+	if len(imp.Results) != 2 || imp.Results[1].MediaID != "b" || !imp.Results[1].Explored || imp.Candidates == nil {
+		t.Errorf("results = %+v, candidates = %v", imp.Results, imp.Candidates)
+	}
+	if len(imp.Interactions) != 1 || imp.Interactions[0].MediaID != "b" || imp.Interactions[0].Action != models.ActionClicked {
+		t.Errorf("interactions = %+v, want the click on b", imp.Interactions)
+	}
+
+	// Once the impression outlives the retention window it goes, and with it
+	// anything left to attribute to
+	short := NewImpressionLogger(db, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	short.pruneLogged()
+	if logged := exportAll(t, l); len(logged) != 0 {
+		t.Errorf("%d impressions left after pruning", len(logged))
+	}
+	if _, err := l.RecordInteraction("u1", "r1", "a", models.ActionSeen); !errors.Is(err, ErrUnknownImpression) {
+		t.Errorf("interaction on a pruned impression: error %v, want ErrUnknownImpression", err)
+	}
+}
+
+func TestImpressionLoggingDisabled(t *testing.T) {
+	db := newTestDB(t)
+	l := NewImpressionLogger(db, 0)
+	if l.Enabled() {
+		t.Fatal("zero retention left logging on")
+	}
+	if err := l.Log("r1", "u1", models.SurfaceSearch, "cosy", nil, &SearchResult{}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if logged := exportAll(t, l); len(logged) != 0 {
+		t.Errorf("%d impressions logged with logging off", len(logged))
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"w2w/internal/models"
+)
+
+// miniseriesEpisodes is what "a short series" or "a miniseries" means
+const miniseriesEpisodes = 10
+
+// QueryIntent is what a free-text query says besides its vibe
+type QueryIntent struct {
+	// Query is the text left to embed once intent phrases are removed
+	Query    string
+	Duration models.DurationFilter
+}
+
+var (
+	// "I have 90 minutes", "under 2 hours", "less than an hour and a half"
+	intentTimeBudget = regexp.MustCompile(`(?i)\b(?:i(?:'ve)? (?:only )?(?:have|got)|i've got|only have|got|under|less than|at most|no more than|no longer than|shorter than|up to|within|max(?:imum)?)\s+` +
+		`(an?|half an|\d+(?:\.\d+)?)\s*(hours?|hrs?|h|minutes?|mins?|m)\b(?:\s+and\s+(a half|\d+)\s*(?:minutes?|mins?)?)?`)
+	// "under 10 episodes", "no more than 8 episodes"
+	intentEpisodeCap = regexp.MustCompile(`(?i)\b(under|less than|fewer than|at most|no more than|up to|max(?:imum)?)\s+(\d+)\s+episodes?\b`)
+	// "a miniseries", "short series", "limited series"
+	intentMiniseries = regexp.MustCompile(`(?i)\b(?:a\s+)?(?:mini-?series|short series|limited series)\b`)
+	// "finish this weekend", "finishable", "binge over the weekend"
+	intentFinishable = regexp.MustCompile(`(?i)\b(?:(?:that\s+)?(?:i|we)\s+can\s+)?(?:finish(?:able)?|binge|get through)(?:\s+(?:it|in|over|during|this|a|the|one))*\s+weekend\b|\bfinishable\b`)
+)
+
+// ParseQueryIntent pulls duration constraints out of a natural-language
+// query. Matched phrases are removed so the rest embeds as a pure vibe; if
+// nothing is left, the original query is kept.
+func ParseQueryIntent(query string) QueryIntent {
+	intent := QueryIntent{Query: query}
+	rest := query
+
+	if m := intentTimeBudget.FindStringSubmatchIndex(rest); m != nil {
+		if minutes := budgetMinutes(rest, m); minutes > 0 {
+			intent.Duration.MaxMinutes = minutes
+			rest = rest[:m[0]] + " " + rest[m[1]:]
+		}
+	}
+	if m := intentEpisodeCap.FindStringSubmatch(rest); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		switch strings.ToLower(m[1]) {
+		case "under", "less than", "fewer than":
+			n--
+		}
+		if n > 0 {
+			intent.Duration.MaxEpisodes = n
+			rest = strings.Replace(rest, m[0], " ", 1)
+		}
+	}
+	if intent.Duration.MaxEpisodes == 0 {
+		if m := intentMiniseries.FindString(rest); m != "" {
+			intent.Duration.MaxEpisodes = miniseriesEpisodes
+			rest = strings.Replace(rest, m, " ", 1)
+		}
+	}
+	if m := intentFinishable.FindString(rest); m != "" {
+		intent.Duration.Finishable = true
+		rest = strings.Replace(rest, m, " ", 1)
+	}
+
+	if cleaned := cleanIntentQuery(rest); cleaned != "" && intent.Duration.Active() {
+		intent.Query = cleaned
+	}
+	return intent
+}
+
+// budgetMinutes converts a matched time budget to minutes
+func budgetMinutes(s string, m []int) int {
+	group := func(i int) string {
+		if m[2*i] < 0 {
+			return ""
+		}
+		return strings.ToLower(s[m[2*i]:m[2*i+1]])
+	}
+
+	var amount float64
+	switch group(1) {
+	case "a", "an":
+		amount = 1
+	case "half an":
+		amount = 0.5
+	default:
+		amount, _ = strconv.ParseFloat(group(1), 64)
+	}
+
+	minutes := amount
+	if strings.HasPrefix(group(2), "h") {
+		minutes = amount * 60
+	}
+	switch extra := group(3); extra {
+	case "":
+	case "a half":
+		minutes += 30
+	default:
+		n, _ := strconv.Atoi(extra)
+		minutes += float64(n)
+	}
+	return int(minutes)
+}
+
+// danglingWords are connectors left hanging once a phrase is cut from
+// either end of a query ("something dreamy with", "that feels lonely")
+var danglingWords = map[string]bool{"with": true, "and": true, "that": true, "in": true, "for": true, "but": true}
+
+// cleanIntentQuery tidies what is left after removing intent phrases
+func cleanIntentQuery(s string) string {
+	words := strings.Fields(s)
+	dangling := func(w string) bool {
+		return danglingWords[strings.ToLower(strings.Trim(w, " ,.;:-!?"))]
+	}
+	for len(words) > 0 && dangling(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+	for len(words) > 0 && dangling(words[0]) {
+		words = words[1:]
+	}
+	return strings.Trim(strings.Join(words, " "), " ,.;:-!?")
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestParseQueryIntent(t *testing.T) {
+	tests := []struct {
+		query    string
+		want     string
+		duration models.DurationFilter
+	}{
+		{"something cozy under 2 hours", "something cozy", models.DurationFilter{MaxMinutes: 120}},
+		{"I've got 90 min for something tense", "something tense", models.DurationFilter{MaxMinutes: 90}},
+		{"less than an hour and a half, dreamy", "dreamy", models.DurationFilter{MaxMinutes: 90}},
+		{"a short series about grief", "about grief", models.DurationFilter{MaxEpisodes: miniseriesEpisodes}},
+		// "under" excludes the number itself
+		{"space opera under 10 episodes", "space opera", models.DurationFilter{MaxEpisodes: 9}},
+		{"funny anime I can binge this weekend", "funny anime", models.DurationFilter{Finishable: true}},
+		{"something eerie, a miniseries I can finish this weekend", "something eerie", models.DurationFilter{MaxEpisodes: miniseriesEpisodes, Finishable: true}},
+		// Nothing left to embed, so the query is kept whole
+		{"under 2 hours", "under 2 hours", models.DurationFilter{MaxMinutes: 120}},
+		{"melancholy neon noir", "melancholy neon noir", models.DurationFilter{}},
+		// "hours" alone is not a budget
+		{"hours of quiet awe", "hours of quiet awe", models.DurationFilter{}},
+	}
+	for _, tt := range tests {
+		got := ParseQueryIntent(tt.query)
+		if got.Query != tt.want || got.Duration != tt.duration {
+			t.Errorf("ParseQueryIntent(%q) = %q %+v, want %q %+v", tt.query, got.Query, got.Duration, tt.want, tt.duration)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/embeddings"
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+const (
+	// DefaultJourneyLength is how many titles a journey has when the request
+	// does not say; MinJourneyLength and MaxJourneyLength bound it
+	DefaultJourneyLength = 6
+	MinJourneyLength     = 3
+	MaxJourneyLength     = 12
+	// journeyNeighbours is how many nearest neighbours each title keeps in
+	// the graph a journey walks
+	journeyNeighbours = 12
+	// journeyGraphTTL is how long the neighbour graph is reused before it is
+	// rebuilt to pick up refreshed embeddings
+	journeyGraphTTL = time.Hour
+	// journeyMinStep is the smallest step (in radians) a journey may take
+	// between titles, however close its ends are
+	journeyMinStep = 0.35
+	// journeyFallbackPool is how many titles near a waypoint are considered
+	// when the graph has no usable neighbour
+	journeyFallbackPool = 20
+)
+
+// ErrUnknownJourneyEnd is returned when a journey starts or ends at a media
+// ID that is not indexed
+var ErrUnknownJourneyEnd = errors.New("unknown journey end")
+
+// neighbourGraph is a k-nearest-neighbour graph over the vector store, with
+// each title's neighbours sorted by similarity
+type neighbourGraph struct {
+	store     *embeddings.VectorStore
+	size      int
+	builtAt   time.Time
+	neighbors map[string][]embeddings.SearchResult
+}
+
+func buildNeighbourGraph(store *embeddings.VectorStore, k int) *neighbourGraph {
+	ids := make([]string, 0, store.Size())
+	vecs := make([][]float32, 0, store.Size())
+	store.Each(func(id string, vec []float32) {
+		ids = append(ids, id)
+		vecs = append(vecs, vec)
+	})
+
+	g := &neighbourGraph{
+		store:     store,
+		size:      len(ids),
+		builtAt:   time.Now(),
+		neighbors: make(map[string][]embeddings.SearchResult, len(ids)),
+	}
+	for i, id := range ids {
+		near := make([]embeddings.SearchResult, 0, len(ids)-1)
+		for j, other := range ids {
+			if i != j {
+				near = append(near, embeddings.SearchResult{MediaID: other, Similarity: embeddings.CosineSimilarity(vecs[i], vecs[j])})
+			}
+		}
+		sort.Slice(near, func(a, b int) bool { return near[a].Similarity > near[b].Similarity })
+		if len(near) > k {
+			near = near[:k]
+		}
+		g.neighbors[id] = near
+	}
+	return g
+}
+
+// JourneyService plans watch sequences that drift from one vibe to another
+// by walking a nearest-neighbour graph, and stores them for sharing
+type JourneyService struct {
+	db         JourneyStore
+	embedder   embeddings.Provider
+	llmClient  *llm.Client
+	mu         sync.Mutex // Guards graph and rebuilding
+	graph      *neighbourGraph
+	rebuilding bool // A background rebuild is running
+}
+
+// JourneyStore is what JourneyService reads and writes
+type JourneyStore interface {
+	database.MediaRepository
+	database.DismissalRepository
+	database.JourneyRepository
+}
+
+// NewJourneyService creates a journey service
+func NewJourneyService(db JourneyStore, embedder embeddings.Provider, llmClient *llm.Client) *JourneyService {
+	return &JourneyService{db: db, embedder: embedder, llmClient: llmClient}
+}
+
+// neighbourGraph returns the graph for the store. Only the first call builds
+// it inline; after that a graph whose store was swapped or resized, or that
+// is older than its TTL, is rebuilt in the background while the previous
+// one keeps serving (titles it lacks fall back to a nearest search).
+func (s *JourneyService) neighbourGraph(store *embeddings.VectorStore) *neighbourGraph {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.graph
+	if g == nil {
+		g = buildNeighbourGraph(store, journeyNeighbours)
+		s.graph = g
+		return g
+	}
+	stale := g.store != store || g.size != store.Size() || time.Since(g.builtAt) > journeyGraphTTL
+	if stale && !s.rebuilding {
+		s.rebuilding = true
+		go s.rebuildGraph(store)
+	}
+	return g
+}
+
+// rebuildGraph builds a fresh graph off the request path and swaps it in
+func (s *JourneyService) rebuildGraph(store *embeddings.VectorStore) {
+	start := time.Now()
+	g := buildNeighbourGraph(store, journeyNeighbours)
+
+	s.mu.Lock()
+	s.graph = g
+	s.rebuilding = false
+	s.mu.Unlock()
+	log.Printf("Journey graph rebuilt over %d titles in %s", g.size, time.Since(start).Round(time.Millisecond))
+}
+
+// journeyEnd is a resolved start or end of a journey
+type journeyEnd struct {
+	label   string
+	vec     []float32
+	mediaID string // Empty for a query
+}
+
+func (s *JourneyService) resolveEnd(query, mediaID string, store *embeddings.VectorStore) (journeyEnd, error) {
+	if mediaID != "" {
+		media, err := s.db.GetMedia(mediaID)
+		if err != nil {
+			return journeyEnd{}, fmt.Errorf("failed to get media: %w", err)
+		}
+		vec, ok := store.Get(mediaID)
+		if media == nil || !ok {
+			return journeyEnd{}, fmt.Errorf("%w: %s", ErrUnknownJourneyEnd, mediaID)
+		}
+		return journeyEnd{label: media.Title, vec: vec, mediaID: mediaID}, nil
+	}
+	vec, err := s.embedder.Embed(query)
+	if err != nil {
+		return journeyEnd{}, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return journeyEnd{label: query, vec: vec}, nil
+}
+
+// angle is the angle in radians between two vectors
+func angle(a, b []float32) float64 {
+	return math.Acos(math.Max(-1, math.Min(1, embeddings.CosineSimilarity(a, b))))
+}
+
+// Plan walks from the starting vibe to the ending one and saves the result.
+// Waypoints are spaced evenly along the great circle between the two ends;
+// each step moves to the graph neighbour of the previous title that best
+// matches the next waypoint without jumping further than twice the even
+// spacing. Seen, dismissed and already-used titles are skipped, and when
+// the graph offers nothing usable the titles nearest the waypoint are tried
+// instead.
+func (s *JourneyService) Plan(userID string, req models.JourneyRequest, store *embeddings.VectorStore) (*models.Journey, error) {
+	length := req.Length
+	if length == 0 {
+		length = DefaultJourneyLength
+	}
+
+	from, err := s.resolveEnd(req.FromQuery, req.FromMediaID, store)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.resolveEnd(req.ToQuery, req.ToMediaID, store)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seen and dismissed titles, the ends and every title already on the
+	// journey are skipped
+	skipIDs, err := s.db.GetExcludedMediaIDs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
+	}
+	for _, end := range []journeyEnd{from, to} {
+		if end.mediaID != "" {
+			skipIDs[end.mediaID] = true
+		}
+	}
+
+	graph := s.neighbourGraph(store)
+	total := angle(from.vec, to.vec)
+	maxStep := math.Max(2*total/float64(length-1), journeyMinStep)
+
+	var steps []models.JourneyStep
+	var stops []llm.JourneyStop
+	current := from.vec
+	currentID := ""
+	for len(steps) < length {
+		waypoint := slerp(from.vec, to.vec, float64(len(steps))/float64(length-1))
+
+		var options []embeddings.SearchResult
+		if currentID != "" {
+			options = graph.neighbors[currentID]
+		}
+		nextID := bestJourneyStep(options, store, current, waypoint, maxStep, skipIDs)
+		if nextID == "" {
+			nearby := store.SearchWithin(waypoint, journeyFallbackPool, nil, skipIDs)
+			nextID = bestJourneyStep(nearby, store, current, waypoint, maxStep, skipIDs)
+			if nextID == "" {
+				nextID = nearestJourneyStep(nearby, store, current, skipIDs)
+			}
+		}
+		if nextID == "" {
+			break // Catalog exhausted
+		}
+
+		vec, _ := store.Get(nextID)
+		media, err := s.db.GetMedia(nextID)
+		skipIDs[nextID] = true
+		if err != nil || media == nil {
+			continue
+		}
+		fromStart, toEnd := angle(from.vec, vec), angle(vec, to.vec)
+		progress := 0.0
+		if fromStart+toEnd > 0 {
+			progress = fromStart / (fromStart + toEnd)
+		}
+		steps = append(steps, models.JourneyStep{
+			Position:       len(steps) + 1,
+			MediaID:        nextID,
+			StepSimilarity: embeddings.CosineSimilarity(current, vec),
+			Progress:       progress,
+			Media:          media,
+		})
+		stops = append(stops, llm.JourneyStop{MediaID: nextID, Title: media.Title, VibeProfile: media.VibeProfile})
+		current, currentID = vec, nextID
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no unseen titles to build a journey from")
+	}
+	s.writeNotes(from, to, steps, stops)
+
+	id, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	slug, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	title := req.Title
+	if title == "" {
+		title = from.label + " → " + to.label
+	}
+	j := &models.Journey{
+		ID:        id,
+		UserID:    userID,
+		Slug:      slug,
+		Title:     title,
+		FromLabel: from.label,
+		ToLabel:   to.label,
+		StepCount: len(steps),
+		Steps:     steps,
+		CreatedAt: time.Now(),
+	}
+	if err := s.db.CreateJourney(j); err != nil {
+		return nil, fmt.Errorf("failed to save journey: %w", err)
+	}
+	return j, nil
+}
+
+// bestJourneyStep picks the option closest to the waypoint that is within
+// maxStep of the current position
+func bestJourneyStep(options []embeddings.SearchResult, store *embeddings.VectorStore, current, waypoint []float32, maxStep float64, skipIDs map[string]bool) string {
+	best, bestSim := "", math.Inf(-1)
+	for _, o := range options {
+		if skipIDs[o.MediaID] {
+			continue
+		}
+		vec, ok := store.Get(o.MediaID)
+		if !ok || angle(current, vec) > maxStep {
+			continue
+		}
+		if sim := embeddings.CosineSimilarity(vec, waypoint); sim > bestSim {
+			best, bestSim = o.MediaID, sim
+		}
+	}
+	return best
+}
+
+// nearestJourneyStep picks the option closest to the current position, for
+// when no option is within the step limit
+func nearestJourneyStep(options []embeddings.SearchResult, store *embeddings.VectorStore, current []float32, skipIDs map[string]bool) string {
+	best, bestSim := "", math.Inf(-1)
+	for _, o := range options {
+		if skipIDs[o.MediaID] {
+			continue
+		}
+		vec, ok := store.Get(o.MediaID)
+		if !ok {
+			continue
+		}
+		if sim := embeddings.CosineSimilarity(current, vec); sim > bestSim {
+			best, bestSim = o.MediaID, sim
+		}
+	}
+	return best
+}
+
+// writeNotes asks the LLM for each step's transition note, falling back to
+// the step similarity and progress
+func (s *JourneyService) writeNotes(from, to journeyEnd, steps []models.JourneyStep, stops []llm.JourneyStop) {
+	var notes map[string]string
+	if s.llmClient != nil {
+		var err error
+		notes, err = s.llmClient.WriteJourneyNotes(from.label, to.label, stops)
+		if err != nil {
+			log.Printf("Failed to write journey notes: %v", err)
+		}
+	}
+
+	for i := range steps {
+		if n := notes[steps[i].MediaID]; n != "" {
+			steps[i].Note = n
+			continue
+		}
+		if i == 0 {
+			steps[i].Note = fmt.Sprintf("Starts close to %s (%.0f%% match)", from.label, steps[i].StepSimilarity*100)
+			continue
+		}
+		steps[i].Note = fmt.Sprintf("Keeps %.0f%% of %s's vibe, %.0f%% of the way to %s",
+			steps[i].StepSimilarity*100, steps[i-1].Media.Title, steps[i].Progress*100, to.label)
+	}
+}
+
+// Owned returns the journey if it exists and belongs to the user, nil
+// otherwise
+func (s *JourneyService) Owned(userID, id string) (*models.Journey, error) {
+	j, err := s.db.GetJourney(id)
+	if err != nil || j == nil || j.UserID != userID {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Shared returns the journey with the given share slug, or nil
+func (s *JourneyService) Shared(slug string) (*models.Journey, error) {
+	return s.db.GetJourneyBySlug(slug)
+}
+
+// List returns the user's journeys, newest first, without steps
+func (s *JourneyService) List(userID string) ([]models.Journey, error) {
+	return s.db.ListJourneys(userID)
+}
+
+// Delete removes a journey
+func (s *JourneyService) Delete(id string) error {
+	return s.db.DeleteJourney(id)
+}
+
+// WithSteps loads a journey's steps into it
+func (s *JourneyService) WithSteps(j *models.Journey) error {
+	steps, err := s.db.GetJourneySteps(j.ID)
+	if err != nil {
+		return err
+	}
+	j.Steps = steps
+	return nil
+}
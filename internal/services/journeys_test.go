@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/embeddings"
+	"w2w/internal/models"
+)
+
+// arcFixture indexes seven titles every 15 degrees along the quarter circle
+// from x (a0) to y (a6)
+func arcFixture(t *testing.T) (*JourneyService, *database.DB, *embeddings.VectorStore) {
+	t.Helper()
+	db := newTestDB(t)
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	store := embeddings.NewVectorStore()
+	for i := 0; i <= 6; i++ {
+		id := fmt.Sprintf("a%d", i)
+		theta := float64(i) * math.Pi / 12
+		if err := db.CreateMedia(&models.Media{ID: id, Title: id, MediaType: "movie", VibeProfile: "arc"}); err != nil {
+			t.Fatal(err)
+		}
+		store.Add(id, []float32{float32(math.Cos(theta)), float32(math.Sin(theta)), 0})
+	}
+	return NewJourneyService(db, fixedEmbedder{1, 0, 0}, nil), db, store
+}
+
+// waitForGraph waits for a background rebuild to swap out old
+func waitForGraph(t *testing.T, s *JourneyService, old *neighbourGraph) *neighbourGraph {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		g, rebuilding := s.graph, s.rebuilding
+		s.mu.Unlock()
+		if g != old && !rebuilding {
+			return g
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("graph was not rebuilt")
+	return nil
+}
+
+func TestBuildNeighbourGraph(t *testing.T) {
+	_, _, store := arcFixture(t)
+	g := buildNeighbourGraph(store, 3)
+	if g.size != 7 || len(g.neighbors) != 7 {
+		t.Fatalf("graph over %d titles with %d lists, want 7", g.size, len(g.neighbors))
+	}
+	near := g.neighbors["a3"]
+	if len(near) != 3 {
+		t.Fatalf("a3 has %d neighbours, want k=3", len(near))
+	}
+	if near[0].MediaID != "a2" && near[0].MediaID != "a4" || near[2].MediaID != "a1" && near[2].MediaID != "a5" {
+		t.Errorf("a3 neighbours = %+v, want the titles either side first", near)
+	}
+	for i, n := range near {
+		if n.MediaID == "a3" {
+			t.Error("a3 is its own neighbour")
+		}
+		if i > 0 && n.Similarity > near[i-1].Similarity {
+			t.Errorf("neighbours not sorted by similarity: %+v", near)
+		}
+	}
+}
+
+func TestJourneyGraphRebuild(t *testing.T) {
+	s, _, store := arcFixture(t)
+
+	// The first call builds inline, and an unchanged store reuses it
+	first := s.neighbourGraph(store)
+	if first == nil || first.size != 7 {
+		t.Fatalf("first graph = %+v", first)
+	}
+	if g := s.neighbourGraph(store); g != first || s.rebuilding {
+		t.Fatal("unchanged store rebuilt the graph")
+	}
+
+	t.Run("store grows", func(t *testing.T) {
+		store.Add("b", []float32{0, 0, 1})
+		// The old graph keeps serving while the new one builds
+		if g := s.neighbourGraph(store); g != first {
+			t.Fatal("a stale graph was rebuilt on the request path")
+		}
+		g := waitForGraph(t, s, first)
+		if g.size != 8 || len(g.neighbors["b"]) == 0 {
+			t.Errorf("rebuilt graph over %d titles, b has %d neighbours", g.size, len(g.neighbors["b"]))
+		}
+		first = g
+	})
+
+	t.Run("graph expires", func(t *testing.T) {
+		s.mu.Lock()
+		s.graph.builtAt = time.Now().Add(-2 * journeyGraphTTL)
+		s.mu.Unlock()
+		s.neighbourGraph(store)
+		first = waitForGraph(t, s, first)
+		if time.Since(first.builtAt) > time.Minute {
+			t.Error("expired graph not replaced")
+		}
+	})
+
+	t.Run("store swapped", func(t *testing.T) {
+		swapped := embeddings.NewVectorStore()
+		store.Each(func(id string, vec []float32) { swapped.Add(id, vec) })
+		s.neighbourGraph(swapped)
+		if g := waitForGraph(t, s, first); g.store != swapped {
+			t.Error("graph still built over the old store")
+		}
+	})
+
+	t.Run("one rebuild at a time", func(t *testing.T) {
+		s.mu.Lock()
+		current := s.graph
+		s.rebuilding = true
+		s.mu.Unlock()
+		store.Add("c", []float32{0, 0.5, 0.5})
+		s.neighbourGraph(store)
+		time.Sleep(20 * time.Millisecond)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.graph != current {
+			t.Error("a second rebuild started while one was running")
+		}
+	})
+}
+
+func TestPlanJourney(t *testing.T) {
+	s, db, store := arcFixture(t)
+	ids := func(j *models.Journey) string {
+		var out []string
+		for _, step := range j.Steps {
+			out = append(out, step.MediaID)
+		}
+		return fmt.Sprint(out)
+	}
+
+	// Waypoints at 0, 30, 60 and 90 degrees; the ends themselves are skipped
+	j, err := s.Plan("u1", models.JourneyRequest{FromMediaID: "a0", ToMediaID: "a6", Length: 4}, store)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if got := ids(j); got != "[a1 a2 a4 a5]" {
+		t.Errorf("steps = %s, want [a1 a2 a4 a5]", got)
+	}
+	if j.Title != "a0 → a6" || j.Slug == "" {
+		t.Errorf("title %q, slug %q", j.Title, j.Slug)
+	}
+	for i, step := range j.Steps {
+		if step.Position != i+1 {
+			t.Errorf("step %d has position %d", i, step.Position)
+		}
+		if i > 0 && step.Progress <= j.Steps[i-1].Progress {
+			t.Errorf("progress went backwards at step %d: %v", i+1, step.Progress)
+		}
+	}
+	if want := math.Cos(math.Pi / 12); math.Abs(j.Steps[0].StepSimilarity-want) > 1e-6 {
+		t.Errorf("first step similarity = %v, want %v", j.Steps[0].StepSimilarity, want)
+	}
+
+	// Seen titles are stepped around
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "a2"}); err != nil {
+		t.Fatal(err)
+	}
+	j, err = s.Plan("u1", models.JourneyRequest{FromMediaID: "a0", ToMediaID: "a6", Length: 4}, store)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if got := ids(j); got != "[a1 a3 a4 a5]" {
+		t.Errorf("steps with a2 seen = %s, want [a1 a3 a4 a5]", got)
+	}
+
+	if _, err := s.Plan("u1", models.JourneyRequest{FromMediaID: "nope", ToMediaID: "a6"}, store); !errors.Is(err, ErrUnknownJourneyEnd) {
+		t.Errorf("journey from an unknown title: error %v, want ErrUnknownJourneyEnd", err)
+	}
+}
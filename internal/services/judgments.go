@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+const (
+	// SimilarK is the cutoff used when scoring the similar-titles surface
+	SimilarK = 10
+	// minJudgmentsPerReference skips references with too little evidence
+	// to say anything about ranking quality
+	minJudgmentsPerReference = 2
+	// judgmentUserID is a user with no history, so nothing is filtered as
+	// seen while scoring
+	judgmentUserID = "eval"
+)
+
+// JudgmentJob mines Reddit similar_to threads into (reference, recommended,
+// weight) judgments and scores GetSimilarToMedia against them, keeping a
+// history so similar-title quality can be tracked over time
+type JudgmentJob struct {
+	db      database.JudgmentRepository
+	svc     *VibeSearchService
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+	runMu   sync.Mutex // Serialises scheduled and manual runs
+}
+
+// NewJudgmentJob creates the judgment mining and scoring job
+func NewJudgmentJob(db database.JudgmentRepository, svc *VibeSearchService) *JudgmentJob {
+	return &JudgmentJob{db: db, svc: svc}
+}
+
+// Start begins periodic mining and scoring
+func (j *JudgmentJob) Start(ctx context.Context, interval time.Duration) {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return
+	}
+	j.running = true
+	j.stopCh = make(chan struct{})
+	j.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// Run immediately on start
+		j.runLogged()
+
+		for {
+			select {
+			case <-ticker.C:
+				j.runLogged()
+			case <-j.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background job
+func (j *JudgmentJob) Stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.running {
+		close(j.stopCh)
+		j.running = false
+	}
+}
+
+func (j *JudgmentJob) runLogged() {
+	run, err := j.Run()
+	if err != nil {
+		log.Printf("Similar judgment run failed: %v", err)
+		return
+	}
+	if run != nil {
+		log.Printf("Similar quality: ndcg@%d=%.3f recall=%.3f mrr=%.3f over %d references",
+			run.K, run.NDCG, run.Recall, run.MRR, run.ReferenceCount)
+	}
+}
+
+// Run re-mines the judgments, scores GetSimilarToMedia against them and
+// records the result. Returns nil (and records nothing) when no reference
+// has enough judgments yet.
+func (j *JudgmentJob) Run() (*models.SimilarQualityRun, error) {
+	j.runMu.Lock()
+	defer j.runMu.Unlock()
+
+	if _, err := j.Mine(); err != nil {
+		return nil, err
+	}
+
+	run, err := j.Score(SimilarK)
+	if err != nil || run == nil {
+		return nil, err
+	}
+	if err := j.db.CreateSimilarQualityRun(run); err != nil {
+		return nil, fmt.Errorf("failed to record quality run: %w", err)
+	}
+	return run, nil
+}
+
+// Mine rebuilds the judgment table from similar_to threads. Each
+// thread recommending a title adds 1 + ln(1 + score) to its weight, so
+// upvoted threads count more without a single viral one dominating.
+func (j *JudgmentJob) Mine() (int, error) {
+	mentions, err := j.db.GetSimilarMentions()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load similar_to mentions: %w", err)
+	}
+
+	type pair struct{ ref, media string }
+	byPair := make(map[pair]*models.SimilarJudgment)
+	for _, m := range mentions {
+		key := pair{m.ReferenceID, m.MediaID}
+		jd := byPair[key]
+		if jd == nil {
+			jd = &models.SimilarJudgment{ReferenceID: m.ReferenceID, MediaID: m.MediaID}
+			byPair[key] = jd
+		}
+		jd.Weight += 1 + math.Log1p(math.Max(float64(m.Score), 0))
+		jd.ThreadCount++
+	}
+
+	judgments := make([]models.SimilarJudgment, 0, len(byPair))
+	for _, jd := range byPair {
+		judgments = append(judgments, *jd)
+	}
+	if err := j.db.ReplaceSimilarJudgments(judgments); err != nil {
+		return 0, fmt.Errorf("failed to store judgments: %w", err)
+	}
+	return len(judgments), nil
+}
+
+// Score runs GetSimilarToMedia for every reference with enough judgments
+// and averages graded nDCG@k, recall@k and MRR, using judgment weights as
+// gains. It reads the judgments as last mined and records nothing. Returns
+// nil when there is nothing to score.
+func (j *JudgmentJob) Score(k int) (*models.SimilarQualityRun, error) {
+	all, err := j.db.GetSimilarJudgments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load judgments: %w", err)
+	}
+
+	refs := make([]string, 0, len(all))
+	for ref, js := range all {
+		if len(js) >= minJudgmentsPerReference {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	sort.Strings(refs)
+
+	run := &models.SimilarQualityRun{
+		K:              k,
+		EmbeddingModel: j.svc.EmbeddingModel(),
+		CreatedAt:      time.Now(),
+	}
+	for _, ref := range refs {
+		result, err := j.svc.GetSimilarToMedia(judgmentUserID, ref, k)
+		if err != nil {
+			// References without an embedding can't be scored
+			continue
+		}
+
+		gains := make(map[string]float64, len(all[ref]))
+		for _, jd := range all[ref] {
+			gains[jd.MediaID] = jd.Weight
+		}
+		ids := make([]string, len(result.Recommendations))
+		for i, rec := range result.Recommendations {
+			ids[i] = rec.Media.ID
+		}
+
+		ndcg, recall, mrr := judgedMetrics(ids, gains, k)
+		run.NDCG += ndcg
+		run.Recall += recall
+		run.MRR += mrr
+		run.ReferenceCount++
+		run.JudgmentCount += len(all[ref])
+	}
+	if run.ReferenceCount == 0 {
+		return nil, nil
+	}
+
+	n := float64(run.ReferenceCount)
+	run.NDCG /= n
+	run.Recall /= n
+	run.MRR /= n
+	return run, nil
+}
+
+// judgedMetrics scores one reference's top k results against its judgments:
+// graded nDCG using the weights as gains, and recall and reciprocal rank
+// counting every judged title as relevant
+func judgedMetrics(results []string, gains map[string]float64, k int) (ndcg, recall, mrr float64) {
+	if len(results) > k {
+		results = results[:k]
+	}
+
+	var dcg float64
+	hits := 0
+	for i, id := range results {
+		gain, ok := gains[id]
+		if !ok {
+			continue
+		}
+		dcg += gain / math.Log2(float64(i+2))
+		hits++
+		if mrr == 0 {
+			mrr = 1 / float64(i+1)
+		}
+	}
+
+	ideal := make([]float64, 0, len(gains))
+	for _, g := range gains {
+		ideal = append(ideal, g)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+	var idcg float64
+	for i, g := range ideal {
+		if i >= k {
+			break
+		}
+		idcg += g / math.Log2(float64(i+2))
+	}
+	if idcg > 0 {
+		ndcg = dcg / idcg
+	}
+	if len(gains) > 0 {
+		recall = float64(hits) / float64(len(gains))
+	}
+	return ndcg, recall, mrr
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"w2w/internal/models"
+)
+
+// lexicalStopwords are query words too common to count as a match
+var lexicalStopwords = map[string]bool{
+	"the": true, "and": true, "but": true, "for": true, "with": true,
+	"like": true, "that": true, "this": true, "something": true, "about": true,
+	"more": true, "less": true, "very": true, "from": true, "into": true,
+}
+
+// lexicalTerms splits text into lowercase words of three or more letters,
+// dropping stopwords
+func lexicalTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := words[:0]
+	for _, w := range words {
+		if len(w) >= 3 && !lexicalStopwords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// lexicalScore is the fraction of query terms found in the media's title,
+// vibe profile or plot. Terms match on prefix so "dreams" finds "dreamy".
+func lexicalScore(queryTerms []string, media models.Media) float64 {
+	if len(queryTerms) == 0 {
+		return 0
+	}
+	doc := lexicalTerms(media.Title + " " + media.VibeProfile + " " + media.PlotSummary)
+
+	hits := 0
+	for _, q := range queryTerms {
+		stem := q
+		if len(stem) > 5 {
+			stem = stem[:5]
+		}
+		for _, w := range doc {
+			if strings.HasPrefix(w, stem) {
+				hits++
+				break
+			}
+		}
+	}
+	return float64(hits) / float64(len(queryTerms))
+}
+
+// applyLexicalMatch blends keyword overlap into each candidate's score so
+// exact names and distinctive words can lift a title the embedding ranks
+// lower (hybrid retrieval over the vector pool)
+func applyLexicalMatch(query string, weight float64, pool []models.Recommendation) {
+	terms := lexicalTerms(query)
+	if len(terms) == 0 {
+		return
+	}
+	for i := range pool {
+		pool[i].Score = (1-weight)*pool[i].Score + weight*lexicalScore(terms, pool[i].Media)
+	}
+}
@@ -0,0 +1,458 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"w2w/internal/embeddings"
+	"w2w/internal/llm"
+	"w2w/internal/models"
+)
+
+const (
+	// OnboardingTarget is how many non-skip answers finish the quiz
+	OnboardingTarget = 8
+	// onboardingMaxQuestions ends the quiz for users who skip a lot
+	onboardingMaxQuestions = 16
+	// onboardingPoolSize is how many of the best-known titles the quiz
+	// draws from
+	onboardingPoolSize = 60
+	// onboardingClusters is how many vibe clusters the pool is split into;
+	// each is asked about once before any is asked about twice
+	onboardingClusters = 8
+	// onboardingDeckTTL is how long the clustered pool is reused before it
+	// is rebuilt to pick up new and refreshed titles
+	onboardingDeckTTL = time.Hour
+	// maxModeTitles is how many titles describe each taste mode
+	maxModeTitles = 3
+)
+
+// Ratings written to seen_media for each onboarding answer
+var onboardingRatings = map[string]float64{
+	models.AnswerLoved: 9,
+	models.AnswerSeen:  7,
+	models.AnswerHated: 2,
+}
+
+// ErrOnboardingAnswer is returned for an answer that is not seen, loved,
+// hated or skip, or that names an unknown title or one already in the
+// user's seen list
+var ErrOnboardingAnswer = errors.New("invalid onboarding answer")
+
+// onboardingCard is one quiz candidate with its vibe cluster and how well
+// known it is (1 for the most popular title in the pool, falling to 0)
+type onboardingCard struct {
+	media   models.Media
+	vec     []float32
+	cluster int
+	fame    float64
+}
+
+// onboardingDeck caches the clustered quiz pool
+type onboardingDeck struct {
+	mu    sync.Mutex
+	at    time.Time
+	store *embeddings.VectorStore
+	size  int
+	cards []onboardingCard
+}
+
+// onboardingCards returns the clustered quiz pool, rebuilding it when the
+// index was swapped or resized, or the pool is older than its TTL
+func (s *VibeSearchService) onboardingCards() ([]onboardingCard, error) {
+	s.deck.mu.Lock()
+	defer s.deck.mu.Unlock()
+
+	d := &s.deck
+	if d.cards != nil && d.store == s.vectorStore && d.size == s.vectorStore.Size() && time.Since(d.at) < onboardingDeckTTL {
+		return d.cards, nil
+	}
+
+	media, err := s.db.GetWellKnownMedia(onboardingPoolSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarding titles: %w", err)
+	}
+	var cards []onboardingCard
+	var points []tastePoint
+	for i, m := range media {
+		vec, ok := s.vectorStore.Get(m.ID)
+		if !ok {
+			continue
+		}
+		fame := 1 - float64(i)/float64(len(media))
+		cards = append(cards, onboardingCard{media: m, vec: vec, fame: fame})
+		points = append(points, tastePoint{id: m.ID, vec: vec, weight: fame})
+	}
+	if len(points) > 0 {
+		k := onboardingClusters
+		if k > len(points) {
+			k = len(points)
+		}
+		_, assign := sphericalKMeans(points, k)
+		clusterOf := make(map[string]int, len(points))
+		for i, p := range points {
+			clusterOf[p.id] = assign[i]
+		}
+		for i := range cards {
+			cards[i].cluster = clusterOf[cards[i].media.ID]
+		}
+	}
+
+	d.cards, d.at, d.store, d.size = cards, time.Now(), s.vectorStore, s.vectorStore.Size()
+	return cards, nil
+}
+
+// answerValue maps a rating to how much the user liked a title, from -1 to
+// 1. An unrated title counts as a mild like, as it does for the taste
+// profile.
+func answerValue(rating *float64) float64 {
+	if rating == nil {
+		return 0.3
+	}
+	return math.Max(-1, math.Min(1, (*rating-5.5)/4.5))
+}
+
+// knownTaste is a rated title the quiz adapts to
+type knownTaste struct {
+	vec   []float32
+	value float64
+}
+
+// Onboarding returns where the user stands in the onboarding quiz: the
+// next question, or the taste summary once enough answers are in, the
+// question limit is hit or the pool runs out
+func (s *VibeSearchService) Onboarding(userID string) (*models.OnboardingState, error) {
+	answers, err := s.db.GetOnboardingAnswers(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarding answers: %w", err)
+	}
+
+	state := &models.OnboardingState{Answered: len(answers), Target: OnboardingTarget}
+	for _, a := range answers {
+		if a.Answer != models.AnswerSkip {
+			state.Rated++
+		}
+	}
+	if state.Rated < OnboardingTarget && len(answers) < onboardingMaxQuestions {
+		if state.Question, err = s.nextOnboardingQuestion(userID, answers); err != nil {
+			return nil, err
+		}
+	}
+	if state.Question != nil {
+		return state, nil
+	}
+
+	state.Done = true
+	if state.Summary, err = s.TasteSummary(userID); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// AnswerOnboarding records an answer and returns the new quiz state. Seen,
+// loved and hated titles are marked seen with a rating and folded into the
+// taste profile; skipped ones are only remembered so they are not asked
+// again.
+func (s *VibeSearchService) AnswerOnboarding(userID string, req models.OnboardingAnswerRequest) (*models.OnboardingState, error) {
+	rating, rated := onboardingRatings[req.Answer]
+	if !rated && req.Answer != models.AnswerSkip {
+		return nil, fmt.Errorf("%w: answer must be seen, loved, hated or skip", ErrOnboardingAnswer)
+	}
+	media, err := s.db.GetMedia(req.MediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	if media == nil {
+		return nil, fmt.Errorf("%w: no media with ID %s", ErrOnboardingAnswer, req.MediaID)
+	}
+
+	// The quiz only writes seen_media rows it owns: a title the user tracked
+	// or rated outside the quiz keeps their own rating
+	owned, err := s.ownsOnboardingSeen(userID, req.MediaID)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, fmt.Errorf("%w: %s is already in your seen list", ErrOnboardingAnswer, req.MediaID)
+	}
+
+	if err := s.db.SaveOnboardingAnswer(userID, req.MediaID, req.Answer); err != nil {
+		return nil, fmt.Errorf("failed to save answer: %w", err)
+	}
+	if rated {
+		seen := &models.SeenMedia{UserID: userID, MediaID: req.MediaID, Rating: &rating, WatchedAt: time.Now()}
+		if err := s.db.MarkAsSeen(seen); err != nil {
+			return nil, fmt.Errorf("failed to mark as seen: %w", err)
+		}
+		if err := s.UpdateTasteOnSeen(userID, req.MediaID, &rating, models.WatchCompleted); err != nil {
+			log.Printf("Failed to update taste profile for %s: %v", userID, err)
+		}
+	} else {
+		// Changing an answer to skip takes back what the earlier answer wrote
+		removed, err := s.db.RemoveSeen(userID, req.MediaID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove seen entry: %w", err)
+		}
+		if removed {
+			if err := s.UpdateTasteOnUnseen(userID, req.MediaID); err != nil {
+				log.Printf("Failed to update taste profile for %s: %v", userID, err)
+			}
+		}
+	}
+	return s.Onboarding(userID)
+}
+
+// ownsOnboardingSeen reports whether the quiz may write the user's
+// seen_media row for a title: there is none, or it is still the one an
+// earlier onboarding answer wrote
+func (s *VibeSearchService) ownsOnboardingSeen(userID, mediaID string) (bool, error) {
+	seen, err := s.db.GetProgress(userID, mediaID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get seen entry: %w", err)
+	}
+	if seen == nil {
+		return true, nil
+	}
+	previous, err := s.db.GetOnboardingAnswer(userID, mediaID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get earlier answer: %w", err)
+	}
+	rating, rated := onboardingRatings[previous]
+	return rated && seen.Status == models.WatchCompleted && seen.Rating != nil && *seen.Rating == rating, nil
+}
+
+// ResetOnboarding starts the quiz over. Titles already marked seen stay
+// seen, so they are not asked again.
+func (s *VibeSearchService) ResetOnboarding(userID string) error {
+	return s.db.ResetOnboarding(userID)
+}
+
+// nextOnboardingQuestion picks the most informative title still to ask
+// about. Clusters asked about least go first, so the opening questions
+// cover the catalog's range of vibes. Within them a title scores by how far
+// it sits from everything the user has rated (coverage) plus how much
+// their ratings of similar titles disagree (ambiguity), weighted towards
+// well-known titles. Returns nil when nothing is left to ask.
+func (s *VibeSearchService) nextOnboardingQuestion(userID string, answers []models.OnboardingAnswer) (*models.OnboardingQuestion, error) {
+	cards, err := s.onboardingCards()
+	if err != nil {
+		return nil, err
+	}
+
+	asked, err := s.db.GetExcludedMediaIDs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
+	}
+	for _, a := range answers {
+		asked[a.MediaID] = true
+	}
+	seen, err := s.db.GetSeenMedia(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seen media: %w", err)
+	}
+	var known []knownTaste
+	for _, sm := range seen {
+		vec, ok := s.vectorStore.Get(sm.MediaID)
+		if !ok {
+			continue
+		}
+		value := answerValue(sm.Rating)
+		if sm.Status == models.WatchDropped {
+			value = -1
+		}
+		known = append(known, knownTaste{vec: vec, value: value})
+	}
+
+	clusterAsked := make(map[int]int)
+	for _, c := range cards {
+		if asked[c.media.ID] {
+			clusterAsked[c.cluster]++
+		}
+	}
+	least := math.MaxInt
+	for _, c := range cards {
+		if !asked[c.media.ID] && clusterAsked[c.cluster] < least {
+			least = clusterAsked[c.cluster]
+		}
+	}
+
+	var best *onboardingCard
+	var bestScore, bestCoverage, bestAmbiguity float64
+	for i := range cards {
+		c := &cards[i]
+		if asked[c.media.ID] || clusterAsked[c.cluster] != least {
+			continue
+		}
+		coverage, ambiguity := informativeness(c.vec, known)
+		score := (coverage + ambiguity) * (0.5 + 0.5*c.fame)
+		if best == nil || score > bestScore {
+			best, bestScore, bestCoverage, bestAmbiguity = c, score, coverage, ambiguity
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	reason := "Far from anything you've rated so far"
+	switch {
+	case least == 0:
+		reason = "A corner of the catalog you haven't rated yet"
+	case bestAmbiguity > bestCoverage:
+		reason = "Your answers pull both ways on titles like this"
+	}
+	return &models.OnboardingQuestion{Media: best.media, Reason: reason}, nil
+}
+
+// informativeness scores what an answer about a title would teach:
+// coverage is one minus its similarity to the nearest rated title, and
+// ambiguity is the similarity-weighted variance of the ratings of titles
+// near it
+func informativeness(vec []float32, known []knownTaste) (float64, float64) {
+	if len(known) == 0 {
+		return 1, 0
+	}
+
+	nearest := -1.0
+	var wSum, mean float64
+	sims := make([]float64, len(known))
+	for i, k := range known {
+		sims[i] = embeddings.CosineSimilarity(vec, k.vec)
+		nearest = math.Max(nearest, sims[i])
+		if sims[i] > 0 {
+			wSum += sims[i]
+			mean += sims[i] * k.value
+		}
+	}
+	if wSum == 0 {
+		return 1 - nearest, 0
+	}
+	mean /= wSum
+	var variance float64
+	for i, k := range known {
+		if sims[i] > 0 {
+			variance += sims[i] * (k.value - mean) * (k.value - mean)
+		}
+	}
+	return 1 - nearest, variance / wSum
+}
+
+// TasteSummary reads the user's taste from their seen history: the titles
+// they loved, liked and hated, each taste mode with its titles and shared
+// facets, and a short description from the LLM (or from the modes, without
+// one)
+func (s *VibeSearchService) TasteSummary(userID string) (*models.TasteSummary, error) {
+	seen, err := s.db.GetSeenMedia(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get seen media: %w", err)
+	}
+
+	summary := &models.TasteSummary{Loved: []string{}, Liked: []string{}, Hated: []string{}, Modes: []models.TasteMode{}}
+	var loved, liked, hated []llm.TasteTitle
+	for _, sm := range seen {
+		media, err := s.db.GetMedia(sm.MediaID)
+		if err != nil || media == nil {
+			continue
+		}
+		t := llm.TasteTitle{Title: media.Title, VibeProfile: media.VibeProfile}
+		switch value := answerValue(sm.Rating); {
+		case sm.Status == models.WatchDropped || value <= -0.5:
+			summary.Hated = append(summary.Hated, media.Title)
+			hated = append(hated, t)
+		case value >= 0.5:
+			summary.Loved = append(summary.Loved, media.Title)
+			loved = append(loved, t)
+		default:
+			summary.Liked = append(summary.Liked, media.Title)
+			liked = append(liked, t)
+		}
+	}
+
+	centroids, err := s.tasteProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load taste profile: %w", err)
+	}
+	var total float64
+	for _, c := range centroids {
+		total += c.WeightSum
+	}
+	for _, c := range centroids {
+		ids := make([]string, 0, len(c.Members))
+		for id := range c.Members {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if c.Members[ids[i]] != c.Members[ids[j]] {
+				return c.Members[ids[i]] > c.Members[ids[j]]
+			}
+			return ids[i] < ids[j]
+		})
+
+		mode := models.TasteMode{Share: c.WeightSum / total}
+		for _, id := range ids {
+			if len(mode.Titles) == maxModeTitles {
+				break
+			}
+			if media, err := s.db.GetMedia(id); err == nil && media != nil {
+				mode.Titles = append(mode.Titles, media.Title)
+			}
+		}
+		if mode.Facets, err = s.facets.CommonLabels(ids, 3); err != nil {
+			log.Printf("Failed to label taste mode: %v", err)
+		}
+		summary.Modes = append(summary.Modes, mode)
+	}
+
+	if s.llmClient != nil && len(loved)+len(liked)+len(hated) > 0 {
+		if summary.Text, err = s.llmClient.SummarizeTaste(loved, liked, hated); err != nil {
+			log.Printf("Failed to summarize taste: %v", err)
+		}
+	}
+	if summary.Text == "" {
+		summary.Text = tasteSummaryText(summary)
+	}
+	return summary, nil
+}
+
+// tasteSummaryText describes the taste modes and hated titles without an LLM
+func tasteSummaryText(summary *models.TasteSummary) string {
+	if len(summary.Modes) == 0 {
+		return "Not enough liked titles yet to read your taste; rate a few more to get started."
+	}
+
+	var parts []string
+	for _, m := range summary.Modes {
+		part := "titles like " + joinTitles(m.Titles)
+		if len(m.Facets) > 0 {
+			part = strings.ToLower(strings.Join(m.Facets, ", ")) + " " + part
+		}
+		parts = append(parts, part)
+	}
+	text := "You lean towards " + parts[0]
+	if last := len(parts) - 1; last > 0 {
+		text = "You lean towards " + strings.Join(parts[:last], "; ") + "; and " + parts[last]
+	}
+	text += "."
+	if len(summary.Hated) > 0 {
+		n := len(summary.Hated)
+		if n > maxModeTitles {
+			n = maxModeTitles
+		}
+		text += " You steer clear of the likes of " + joinTitles(summary.Hated[:n]) + "."
+	}
+	return text
+}
+
+// joinTitles lists titles as "A", "A and B" or "A, B and C"
+func joinTitles(titles []string) string {
+	if len(titles) < 2 {
+		return strings.Join(titles, "")
+	}
+	last := len(titles) - 1
+	return strings.Join(titles[:last], ", ") + " and " + titles[last]
+}
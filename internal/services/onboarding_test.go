@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+// onboardingFixture indexes four titles in two vibe pairs for user u1
+func onboardingFixture(t *testing.T) (*VibeSearchService, *database.DB) {
+	t.Helper()
+	db := newTestDB(t)
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range []struct {
+		id  string
+		vec []float32
+	}{
+		{"cosy1", []float32{1, 0, 0}},
+		{"cosy2", []float32{0.95, 0.31, 0}},
+		{"grim1", []float32{0, 0, 1}},
+		{"grim2", []float32{0, 0.31, 0.95}},
+	} {
+		if err := db.CreateMedia(&models.Media{ID: m.id, Title: m.id, MediaType: "movie", VibeProfile: "x"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.StoreEmbedding(m.id, m.vec, "fixed"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc, err := NewVibeSearchService(db, fixedEmbedder{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+	return svc, db
+}
+
+// seenRating returns the user's seen entry for a title as its rating, -1
+// for unrated and 0 for not seen
+func seenRating(t *testing.T, db *database.DB, mediaID string) float64 {
+	t.Helper()
+	p, err := db.GetProgress("u1", mediaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	switch {
+	case p == nil:
+		return 0
+	case p.Rating == nil:
+		return -1
+	}
+	return *p.Rating
+}
+
+func TestAnswerOnboarding(t *testing.T) {
+	svc, db := onboardingFixture(t)
+	answer := func(mediaID, answer string) (*models.OnboardingState, error) {
+		return svc.AnswerOnboarding("u1", models.OnboardingAnswerRequest{MediaID: mediaID, Answer: answer})
+	}
+
+	t.Run("invalid answers", func(t *testing.T) {
+		if _, err := answer("cosy1", "meh"); !errors.Is(err, ErrOnboardingAnswer) {
+			t.Errorf("unknown answer: error %v", err)
+		}
+		if _, err := answer("nope", models.AnswerLoved); !errors.Is(err, ErrOnboardingAnswer) {
+			t.Errorf("unknown title: error %v", err)
+		}
+	})
+
+	state, err := answer("cosy1", models.AnswerLoved)
+	if err != nil {
+		t.Fatalf("AnswerOnboarding: %v", err)
+	}
+	if seenRating(t, db, "cosy1") != onboardingRatings[models.AnswerLoved] {
+		t.Errorf("loved cosy1 rated %v", seenRating(t, db, "cosy1"))
+	}
+	if state.Answered != 1 || state.Rated != 1 || state.Question == nil {
+		t.Fatalf("state = %+v, want one rated answer and a next question", state)
+	}
+	// The other vibe pair is a corner not yet covered
+	if id := state.Question.Media.ID; id != "grim1" && id != "grim2" {
+		t.Errorf("next question %s, want a grim title", id)
+	}
+
+	t.Run("changing an answer", func(t *testing.T) {
+		if _, err := answer("cosy1", models.AnswerHated); err != nil {
+			t.Fatalf("re-answering: %v", err)
+		}
+		if seenRating(t, db, "cosy1") != onboardingRatings[models.AnswerHated] {
+			t.Errorf("hated cosy1 rated %v", seenRating(t, db, "cosy1"))
+		}
+
+		// Skipping takes back the rating the earlier answer wrote
+		state, err := answer("cosy1", models.AnswerSkip)
+		if err != nil {
+			t.Fatalf("skipping: %v", err)
+		}
+		if seenRating(t, db, "cosy1") != 0 {
+			t.Error("skip left cosy1 in the seen list")
+		}
+		if state.Answered != 1 || state.Rated != 0 {
+			t.Errorf("state after skipping = %+v, want one unrated answer", state)
+		}
+		if state.Question == nil || state.Question.Media.ID == "cosy1" {
+			t.Errorf("skipped title asked again: %+v", state.Question)
+		}
+	})
+
+	t.Run("titles already seen are refused", func(t *testing.T) {
+		six := 6.0
+		if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "grim1", Rating: &six}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := answer("grim1", models.AnswerLoved); !errors.Is(err, ErrOnboardingAnswer) {
+			t.Errorf("answering a title rated outside the quiz: error %v", err)
+		}
+		if _, err := answer("grim1", models.AnswerSkip); !errors.Is(err, ErrOnboardingAnswer) {
+			t.Errorf("skipping a title rated outside the quiz: error %v", err)
+		}
+		if seenRating(t, db, "grim1") != 6 {
+			t.Errorf("grim1 rating = %v, want the user's own 6 kept", seenRating(t, db, "grim1"))
+		}
+
+		// A quiz answer the user has since re-rated is theirs too
+		if _, err := answer("cosy2", models.AnswerSeen); err != nil {
+			t.Fatal(err)
+		}
+		ten := 10.0
+		if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "cosy2", Rating: &ten}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := answer("cosy2", models.AnswerSkip); !errors.Is(err, ErrOnboardingAnswer) {
+			t.Errorf("skipping a re-rated answer: error %v", err)
+		}
+		if seenRating(t, db, "cosy2") != 10 {
+			t.Errorf("cosy2 rating = %v, want the user's 10 kept", seenRating(t, db, "cosy2"))
+		}
+	})
+
+	// With every title answered or seen the pool is out, so the quiz ends
+	state, err = answer("grim2", models.AnswerLoved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Done || state.Question != nil || state.Summary == nil {
+		t.Fatalf("state = %+v, want done with a summary", state)
+	}
+
+	// Starting over keeps the seen list, so nothing is asked twice
+	if err := svc.ResetOnboarding("u1"); err != nil {
+		t.Fatal(err)
+	}
+	state, err = svc.Onboarding("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Answered != 0 || state.Question == nil || state.Question.Media.ID != "cosy1" {
+		t.Errorf("state after reset = %+v, want only the skipped cosy1 asked again", state)
+	}
+}
+
+func TestInformativeness(t *testing.T) {
+	if coverage, ambiguity := informativeness([]float32{1, 0}, nil); coverage != 1 || ambiguity != 0 {
+		t.Errorf("with nothing rated = %v, %v; want full coverage", coverage, ambiguity)
+	}
+
+	// Two opposite ratings on the same spot are as ambiguous as it gets
+	split := []knownTaste{{vec: []float32{1, 0}, value: 1}, {vec: []float32{1, 0}, value: -1}}
+	coverage, ambiguity := informativeness([]float32{1, 0}, split)
+	if math.Abs(coverage) > 1e-9 || math.Abs(ambiguity-1) > 1e-9 {
+		t.Errorf("split ratings = %v, %v; want no coverage, ambiguity 1", coverage, ambiguity)
+	}
+	agreed := []knownTaste{{vec: []float32{1, 0}, value: 1}, {vec: []float32{1, 0}, value: 1}}
+	if _, ambiguity := informativeness([]float32{1, 0}, agreed); ambiguity != 0 {
+		t.Errorf("agreeing ratings ambiguity = %v, want 0", ambiguity)
+	}
+	// Orthogonal titles teach nothing about each other
+	if coverage, ambiguity := informativeness([]float32{0, 1}, split); math.Abs(coverage-1) > 1e-9 || ambiguity != 0 {
+		t.Errorf("unrelated title = %v, %v; want full coverage", coverage, ambiguity)
+	}
+}
@@ -0,0 +1,746 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"w2w/internal/models"
+)
+
+const (
+	// Shortlist size and countdown bounds for a voting round
+	defaultRoomShortlist = 5
+	minRoomShortlist     = 3
+	maxRoomShortlist     = 10
+	// maxRoomCandidates caps the shortlist once suggestions are added
+	maxRoomCandidates    = 15
+	defaultRoomCountdown = 90 * time.Second
+	minRoomCountdown     = 15 * time.Second
+	maxRoomCountdown     = 10 * time.Minute
+	// roomIdleTimeout is how long an empty room keeps its state so members
+	// can reconnect
+	roomIdleTimeout = 30 * time.Minute
+	// roomSendBuffer is how many events a connection may fall behind before
+	// it is dropped; every state event is a full snapshot, so a client that
+	// reconnects loses nothing
+	roomSendBuffer = 16
+	// Starting a round embeds the query and may call the LLM, so starts are
+	// throttled: each room waits roomStartCooldown between rounds, and each
+	// user may start roomUserStarts rounds per roomUserStartWindow across
+	// all rooms
+	roomStartCooldown   = 15 * time.Second
+	roomUserStarts      = 6
+	roomUserStartWindow = 10 * time.Minute
+)
+
+// Errors reported to the sender of a room message
+var (
+	ErrRoomBusy        = errors.New("a round is already running")
+	ErrRoomNotVoting   = errors.New("no round is running")
+	ErrRoomNotOwner    = errors.New("only the group owner can do that")
+	ErrRoomUnknownPick = errors.New("that title is not on the shortlist")
+	ErrRoomVetoUsed    = errors.New("you have already used your veto")
+	ErrRoomLastPick    = errors.New("cannot veto the last remaining title")
+	ErrRoomFull        = errors.New("the shortlist is full")
+	ErrRoomSeen        = errors.New("someone in the group has already seen or dismissed that")
+	ErrRoomCooldown    = errors.New("a round was just started, wait a moment")
+	ErrRoomStartLimit  = errors.New("you have started too many rounds, try again later")
+)
+
+// RoomHub holds the live voting room of every watch-party group. Rooms live
+// in memory only: they are created on first connect and dropped once they
+// have been empty for roomIdleTimeout.
+type RoomHub struct {
+	svc     *VibeSearchService
+	rooms   map[string]*Room
+	mu      sync.Mutex
+	running bool
+	stopCh  chan struct{}
+
+	startMu sync.Mutex
+	starts  map[string][]time.Time // User ID -> recent round starts
+}
+
+// NewRoomHub creates an empty room hub
+func NewRoomHub(svc *VibeSearchService) *RoomHub {
+	return &RoomHub{
+		svc:    svc,
+		rooms:  make(map[string]*Room),
+		starts: make(map[string][]time.Time),
+	}
+}
+
+// Room is one group's voting state. Votes and vetoes are keyed by user, so
+// a member who drops and reconnects picks up where they left off.
+type Room struct {
+	hub     *RoomHub
+	groupID string
+	ownerID string
+
+	mu        sync.Mutex
+	members   []models.GroupMember
+	conns     map[*RoomConn]bool
+	phase     string
+	round     int
+	query     string
+	shortlist []roomCandidate
+	votes     map[string]map[string]int // media ID -> user ID -> +1/-1
+	vetoes    map[string]string         // user ID -> media ID
+	present   map[string]bool           // users connected at some point this round
+	deadline  time.Time
+	timer     *time.Timer
+	winner    string
+	markSeen  bool
+	idleSince time.Time
+	startedAt time.Time
+}
+
+type roomCandidate struct {
+	media       models.Media
+	suggestedBy string
+}
+
+// RoomConn is one member's connection to a room. The transport drains
+// Events and calls Leave when the client goes away.
+type RoomConn struct {
+	Events chan models.RoomEvent
+	userID string
+	room   *Room
+	closed bool // guarded by room.mu
+}
+
+// Join connects a member to their group's room, creating the room if this
+// is the first connection. The member list is refreshed on every join so
+// newcomers show up. The hub lock is held until the connection is in, so
+// the idle sweep cannot drop the room in between.
+func (h *RoomHub) Join(g *models.Group, userID string) *RoomConn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	room, ok := h.rooms[g.ID]
+	if !ok {
+		room = &Room{
+			hub:     h,
+			groupID: g.ID,
+			ownerID: g.OwnerID,
+			conns:   make(map[*RoomConn]bool),
+			phase:   models.RoomLobby,
+			votes:   make(map[string]map[string]int),
+			vetoes:  make(map[string]string),
+			present: make(map[string]bool),
+		}
+		h.rooms[g.ID] = room
+	}
+
+	conn := &RoomConn{
+		Events: make(chan models.RoomEvent, roomSendBuffer),
+		userID: userID,
+		room:   room,
+	}
+
+	room.mu.Lock()
+	room.members = g.Members
+	room.conns[conn] = true
+	if room.phase == models.RoomVoting || room.phase == models.RoomSearching {
+		room.present[userID] = true
+	}
+	room.broadcastLocked()
+	room.mu.Unlock()
+
+	return conn
+}
+
+// RemoveMember drops a user who left the group from its room: their open
+// connections are closed and their votes and veto in the running round are
+// discarded
+func (h *RoomHub) RemoveMember(groupID, userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[groupID]
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for conn := range r.conns {
+		if conn.userID == userID {
+			r.dropLocked(conn)
+		}
+	}
+	members := r.members[:0:0]
+	for _, m := range r.members {
+		if m.UserID != userID {
+			members = append(members, m)
+		}
+	}
+	r.members = members
+	for _, votes := range r.votes {
+		delete(votes, userID)
+	}
+	delete(r.vetoes, userID)
+	delete(r.present, userID)
+	r.broadcastLocked()
+}
+
+// allowStart records a round start by the user, or reports that they have
+// used up their starts for the window
+func (h *RoomHub) allowStart(userID string) bool {
+	h.startMu.Lock()
+	defer h.startMu.Unlock()
+
+	cutoff := time.Now().Add(-roomUserStartWindow)
+	recent := h.starts[userID][:0]
+	for _, t := range h.starts[userID] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= roomUserStarts {
+		h.starts[userID] = recent
+		return false
+	}
+	h.starts[userID] = append(recent, time.Now())
+	return true
+}
+
+// Leave disconnects a member. The room keeps its state for reconnects.
+func (h *RoomHub) Leave(conn *RoomConn) {
+	r := conn.room
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropLocked(conn)
+	r.broadcastLocked()
+}
+
+// Handle applies one client message. Errors go back to the sender only;
+// every successful change is broadcast to the whole room.
+func (h *RoomHub) Handle(conn *RoomConn, msg models.RoomMessage) {
+	r := conn.room
+	// A member removed from the group may still have a message in flight
+	r.mu.Lock()
+	closed := conn.closed
+	r.mu.Unlock()
+	if closed {
+		return
+	}
+
+	var err error
+	switch msg.Type {
+	case "start":
+		err = r.start(conn.userID, msg)
+	case "vote":
+		err = r.vote(conn.userID, msg.MediaID, msg.Value)
+	case "veto":
+		err = r.veto(conn.userID, msg.MediaID)
+	case "suggest":
+		err = r.suggest(conn.userID, msg.MediaID)
+	case "finish":
+		err = r.finish(conn.userID)
+	case "state":
+		r.mu.Lock()
+		r.sendLocked(conn, models.RoomEvent{Type: "state", State: r.stateLocked(conn.userID)})
+		r.mu.Unlock()
+	default:
+		err = fmt.Errorf("unknown message type %q", msg.Type)
+	}
+	if err != nil {
+		r.mu.Lock()
+		r.sendLocked(conn, models.RoomEvent{Type: "error", Error: err.Error()})
+		r.mu.Unlock()
+	}
+}
+
+// Start begins periodic cleanup of idle rooms
+func (h *RoomHub) Start(ctx context.Context, interval time.Duration) {
+	h.mu.Lock()
+	if h.running {
+		h.mu.Unlock()
+		return
+	}
+	h.running = true
+	h.stopCh = make(chan struct{})
+	h.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				h.sweep()
+			case <-h.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the cleanup loop and every running round's countdown, so no
+// round is decided (and no winner marked seen) after shutdown begins
+func (h *RoomHub) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.running {
+		close(h.stopCh)
+		h.running = false
+	}
+	for _, r := range h.rooms {
+		r.mu.Lock()
+		if r.timer != nil {
+			r.timer.Stop()
+			r.timer = nil
+		}
+		r.mu.Unlock()
+	}
+}
+
+// sweep drops rooms that have been empty for roomIdleTimeout, and start
+// history that has aged out
+func (h *RoomHub) sweep() {
+	h.startMu.Lock()
+	cutoff := time.Now().Add(-roomUserStartWindow)
+	for userID, starts := range h.starts {
+		if len(starts) == 0 || !starts[len(starts)-1].After(cutoff) {
+			delete(h.starts, userID)
+		}
+	}
+	h.startMu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, r := range h.rooms {
+		r.mu.Lock()
+		idle := len(r.conns) == 0 && time.Since(r.idleSince) > roomIdleTimeout
+		if idle && r.timer != nil {
+			r.timer.Stop()
+		}
+		r.mu.Unlock()
+		if idle {
+			delete(h.rooms, id)
+		}
+	}
+}
+
+// start builds a fresh shortlist and opens voting. The search runs without
+// the room lock; the "searching" phase keeps a second start out meanwhile.
+func (r *Room) start(userID string, msg models.RoomMessage) error {
+	size := msg.Size
+	if size == 0 {
+		size = defaultRoomShortlist
+	}
+	if size < minRoomShortlist || size > maxRoomShortlist {
+		return fmt.Errorf("size must be between %d and %d", minRoomShortlist, maxRoomShortlist)
+	}
+	countdown := time.Duration(msg.Countdown) * time.Second
+	if countdown == 0 {
+		countdown = defaultRoomCountdown
+	}
+	if countdown < minRoomCountdown || countdown > maxRoomCountdown {
+		return fmt.Errorf("countdown must be between %d and %d seconds",
+			int(minRoomCountdown.Seconds()), int(maxRoomCountdown.Seconds()))
+	}
+	if msg.Query == "" {
+		return errors.New("query is required")
+	}
+
+	r.mu.Lock()
+	if r.phase == models.RoomSearching || r.phase == models.RoomVoting {
+		r.mu.Unlock()
+		return ErrRoomBusy
+	}
+	if time.Since(r.startedAt) < roomStartCooldown {
+		r.mu.Unlock()
+		return ErrRoomCooldown
+	}
+	if !r.hub.allowStart(userID) {
+		r.mu.Unlock()
+		return ErrRoomStartLimit
+	}
+	r.startedAt = time.Now()
+	previous := r.phase
+	r.phase = models.RoomSearching
+	r.round++
+	round := r.round
+	others := r.otherMembersLocked(userID)
+	r.broadcastLocked()
+	r.mu.Unlock()
+
+	intent := ParseQueryIntent(msg.Query)
+	result, err := r.hub.svc.Search(SearchConfig{
+		UserID:        userID,
+		Query:         intent.Query,
+		TopK:          size * 3,
+		FinalResults:  size,
+		ExcludeSeenBy: others,
+		Duration:      intent.Duration,
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.round != round {
+		return nil
+	}
+	if err == nil && len(result.Recommendations) == 0 {
+		err = errors.New("nothing unseen matches that vibe")
+	}
+	if err != nil {
+		r.phase = previous
+		r.broadcastLocked()
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	r.phase = models.RoomVoting
+	r.query = msg.Query
+	r.markSeen = msg.MarkSeen
+	r.winner = ""
+	r.shortlist = make([]roomCandidate, len(result.Recommendations))
+	for i, rec := range result.Recommendations {
+		r.shortlist[i] = roomCandidate{media: rec.Media}
+	}
+	r.votes = make(map[string]map[string]int)
+	r.vetoes = make(map[string]string)
+	r.present = make(map[string]bool)
+	for conn := range r.conns {
+		r.present[conn.userID] = true
+	}
+	r.deadline = time.Now().Add(countdown)
+	r.timer = time.AfterFunc(countdown, func() { r.decide(round) })
+	r.broadcastLocked()
+	return nil
+}
+
+// vote records an up (1) or down (-1) vote, or clears it (0)
+func (r *Room) vote(userID, mediaID string, value int) error {
+	if value < -1 || value > 1 {
+		return errors.New("value must be 1, -1 or 0")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.phase != models.RoomVoting {
+		return ErrRoomNotVoting
+	}
+	if !r.activeLocked(mediaID) {
+		return ErrRoomUnknownPick
+	}
+
+	if value == 0 {
+		delete(r.votes[mediaID], userID)
+	} else {
+		if r.votes[mediaID] == nil {
+			r.votes[mediaID] = make(map[string]int)
+		}
+		r.votes[mediaID][userID] = value
+	}
+	r.broadcastLocked()
+	return nil
+}
+
+// veto knocks a title out of the round. Everyone gets one veto per round,
+// and the last title standing cannot be vetoed.
+func (r *Room) veto(userID, mediaID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.phase != models.RoomVoting {
+		return ErrRoomNotVoting
+	}
+	if _, used := r.vetoes[userID]; used {
+		return ErrRoomVetoUsed
+	}
+	if !r.activeLocked(mediaID) {
+		return ErrRoomUnknownPick
+	}
+	remaining := 0
+	for _, c := range r.shortlist {
+		if r.activeLocked(c.media.ID) {
+			remaining++
+		}
+	}
+	if remaining <= 1 {
+		return ErrRoomLastPick
+	}
+
+	r.vetoes[userID] = mediaID
+	r.broadcastLocked()
+	return nil
+}
+
+// suggest adds a title to the running round, as long as nobody in the
+// group has seen or dismissed it
+func (r *Room) suggest(userID, mediaID string) error {
+	r.mu.Lock()
+	if r.phase != models.RoomVoting {
+		r.mu.Unlock()
+		return ErrRoomNotVoting
+	}
+	members := make([]string, len(r.members))
+	for i, m := range r.members {
+		members[i] = m.UserID
+	}
+	r.mu.Unlock()
+
+	db := r.hub.svc.db
+	media, err := db.GetMedia(mediaID)
+	if err != nil {
+		return fmt.Errorf("failed to look up title: %w", err)
+	}
+	if media == nil {
+		return errors.New("unknown media_id")
+	}
+	for _, m := range members {
+		excluded, err := db.GetExcludedMediaIDs(m)
+		if err != nil {
+			return fmt.Errorf("failed to check history: %w", err)
+		}
+		if excluded[mediaID] {
+			return ErrRoomSeen
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.phase != models.RoomVoting {
+		return ErrRoomNotVoting
+	}
+	for _, c := range r.shortlist {
+		if c.media.ID == mediaID {
+			return errors.New("already on the shortlist")
+		}
+	}
+	if len(r.shortlist) >= maxRoomCandidates {
+		return ErrRoomFull
+	}
+
+	r.shortlist = append(r.shortlist, roomCandidate{media: *media, suggestedBy: userID})
+	// Suggesting counts as an up vote from the suggester
+	r.votes[mediaID] = map[string]int{userID: 1}
+	r.broadcastLocked()
+	return nil
+}
+
+// finish ends the countdown early (owner only)
+func (r *Room) finish(userID string) error {
+	r.mu.Lock()
+	if r.ownerID != userID {
+		r.mu.Unlock()
+		return ErrRoomNotOwner
+	}
+	if r.phase != models.RoomVoting {
+		r.mu.Unlock()
+		return ErrRoomNotVoting
+	}
+	round := r.round
+	r.mu.Unlock()
+
+	r.decide(round)
+	return nil
+}
+
+// decide closes the round and picks the winner: best net score among the
+// titles still standing, then most up votes, then shortlist order
+func (r *Room) decide(round int) {
+	r.mu.Lock()
+	if r.phase != models.RoomVoting || r.round != round {
+		r.mu.Unlock()
+		return
+	}
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
+	bestScore, bestUp := 0, 0
+	for _, c := range r.shortlist {
+		if !r.activeLocked(c.media.ID) {
+			continue
+		}
+		up, down := r.tallyLocked(c.media.ID)
+		if r.winner == "" || up-down > bestScore || (up-down == bestScore && up > bestUp) {
+			r.winner = c.media.ID
+			bestScore, bestUp = up-down, up
+		}
+	}
+	r.phase = models.RoomDecided
+	r.deadline = time.Time{}
+
+	var participants []string
+	if r.markSeen {
+		for userID := range r.present {
+			participants = append(participants, userID)
+		}
+	}
+	winner := r.winner
+	r.broadcastLocked()
+	r.mu.Unlock()
+
+	for _, userID := range participants {
+		if err := r.hub.svc.markRoomPickSeen(userID, winner); err != nil {
+			log.Printf("Room %s: failed to mark %s seen for %s: %v", r.groupID, winner, userID, err)
+		}
+	}
+}
+
+// markRoomPickSeen records a room's winner as seen, leaving existing
+// entries (and their ratings) alone
+func (s *VibeSearchService) markRoomPickSeen(userID, mediaID string) error {
+	seen, err := s.db.IsMediaSeen(userID, mediaID)
+	if err != nil || seen {
+		return err
+	}
+	if err := s.db.MarkAsSeen(&models.SeenMedia{UserID: userID, MediaID: mediaID, WatchedAt: time.Now()}); err != nil {
+		return err
+	}
+	return s.UpdateTasteOnSeen(userID, mediaID, nil, models.WatchCompleted)
+}
+
+// activeLocked reports whether a title is on the shortlist and not vetoed
+func (r *Room) activeLocked(mediaID string) bool {
+	for _, vetoed := range r.vetoes {
+		if vetoed == mediaID {
+			return false
+		}
+	}
+	for _, c := range r.shortlist {
+		if c.media.ID == mediaID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Room) tallyLocked(mediaID string) (up, down int) {
+	for _, v := range r.votes[mediaID] {
+		if v > 0 {
+			up++
+		} else {
+			down++
+		}
+	}
+	return up, down
+}
+
+func (r *Room) otherMembersLocked(userID string) []string {
+	var others []string
+	for _, m := range r.members {
+		if m.UserID != userID {
+			others = append(others, m.UserID)
+		}
+	}
+	return others
+}
+
+func (r *Room) nicknameLocked(userID string) string {
+	for _, m := range r.members {
+		if m.UserID == userID {
+			return m.Nickname
+		}
+	}
+	return ""
+}
+
+// stateLocked renders the room for one member
+func (r *Room) stateLocked(userID string) *models.RoomState {
+	state := &models.RoomState{
+		GroupID:      r.groupID,
+		Phase:        r.phase,
+		Round:        r.round,
+		Query:        r.query,
+		Shortlist:    make([]models.RoomCandidate, 0, len(r.shortlist)),
+		Participants: make([]models.RoomParticipant, 0, len(r.members)),
+		MarkSeen:     r.markSeen,
+		YourVeto:     r.vetoes[userID],
+	}
+	if r.phase == models.RoomVoting {
+		deadline := r.deadline
+		state.Deadline = &deadline
+		state.SecondsLeft = int(time.Until(deadline).Round(time.Second).Seconds())
+	}
+
+	vetoedBy := make(map[string]string, len(r.vetoes))
+	for voter, mediaID := range r.vetoes {
+		vetoedBy[mediaID] = r.nicknameLocked(voter)
+	}
+	for _, c := range r.shortlist {
+		up, down := r.tallyLocked(c.media.ID)
+		cand := models.RoomCandidate{
+			MediaID:     c.media.ID,
+			Title:       c.media.Title,
+			MediaType:   c.media.MediaType,
+			Year:        c.media.Year,
+			Up:          up,
+			Down:        down,
+			Score:       up - down,
+			SuggestedBy: r.nicknameLocked(c.suggestedBy),
+			YourVote:    r.votes[c.media.ID][userID],
+		}
+		if by, ok := vetoedBy[c.media.ID]; ok {
+			cand.Vetoed = true
+			cand.VetoedBy = by
+		}
+		state.Shortlist = append(state.Shortlist, cand)
+		if c.media.ID == r.winner {
+			winner := cand
+			state.Winner = &winner
+		}
+	}
+
+	online := make(map[string]bool)
+	for conn := range r.conns {
+		online[conn.userID] = true
+	}
+	for _, m := range r.members {
+		voted := false
+		for _, votes := range r.votes {
+			if _, ok := votes[m.UserID]; ok {
+				voted = true
+				break
+			}
+		}
+		state.Participants = append(state.Participants, models.RoomParticipant{
+			Nickname: m.Nickname,
+			Online:   online[m.UserID],
+			Voted:    voted,
+			IsYou:    m.UserID == userID,
+		})
+	}
+	return state
+}
+
+// broadcastLocked sends every connection its own view of the room
+func (r *Room) broadcastLocked() {
+	for conn := range r.conns {
+		r.sendLocked(conn, models.RoomEvent{Type: "state", State: r.stateLocked(conn.userID)})
+	}
+}
+
+// sendLocked queues an event without blocking; a connection that has
+// fallen too far behind is dropped and can reconnect
+func (r *Room) sendLocked(conn *RoomConn, event models.RoomEvent) {
+	if conn.closed {
+		return
+	}
+	select {
+	case conn.Events <- event:
+	default:
+		r.dropLocked(conn)
+	}
+}
+
+func (r *Room) dropLocked(conn *RoomConn) {
+	if conn.closed {
+		return
+	}
+	conn.closed = true
+	close(conn.Events)
+	delete(r.conns, conn)
+	if len(r.conns) == 0 {
+		r.idleSince = time.Now()
+	}
+}
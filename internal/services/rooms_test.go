@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+func TestRoomHubStopHaltsCountdowns(t *testing.T) {
+	hub := NewRoomHub(rankingFixture(t))
+	group := &models.Group{ID: "g1", OwnerID: "u1", Members: []models.GroupMember{{UserID: "u1", Nickname: "Ana"}}}
+	conn := hub.Join(group, "u1")
+	go func() {
+		for range conn.Events {
+		}
+	}()
+
+	hub.Handle(conn, models.RoomMessage{Type: "start", Query: "anything", Size: 3, Countdown: 15})
+	room := conn.room
+	room.mu.Lock()
+	if room.phase != models.RoomVoting || room.timer == nil {
+		room.mu.Unlock()
+		t.Fatalf("phase = %s after start, want a running countdown", room.phase)
+	}
+	// Bring the deadline forward so a surviving timer would fire right away
+	room.timer.Reset(10 * time.Millisecond)
+	room.mu.Unlock()
+
+	hub.Stop()
+	time.Sleep(50 * time.Millisecond)
+
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	if room.phase != models.RoomVoting {
+		t.Errorf("phase = %s after Stop, want the round left undecided", room.phase)
+	}
+}
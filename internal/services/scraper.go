@@ -18,7 +18,7 @@ import (
 
 // RedditScraper handles scraping recommendation subreddits
 type RedditScraper struct {
-	db         *database.DB
+	db         database.Store
 	llmClient  *llm.Client
 	httpClient *http.Client
 	subreddits []string
@@ -27,8 +27,9 @@ type RedditScraper struct {
 	stopCh     chan struct{}
 }
 
-// NewRedditScraper creates a new Reddit scraper
-func NewRedditScraper(db *database.DB, llmClient *llm.Client) *RedditScraper {
+// NewRedditScraper creates a new Reddit scraper over any store, so it can
+// run against a MemoryStore
+func NewRedditScraper(db database.Store, llmClient *llm.Client) *RedditScraper {
 	return &RedditScraper{
 		db:        db,
 		llmClient: llmClient,
@@ -180,6 +181,9 @@ func (s *RedditScraper) scrapeSubreddit(subreddit string) error {
 			NumComments: post.NumComments,
 			ScrapedAt:   time.Now(),
 		}
+		if post.Created > 0 {
+			thread.PostedAt = time.Unix(int64(post.Created), 0).UTC()
+		}
 
 		// Classify the thread type
 		if s.llmClient != nil {
@@ -430,29 +434,9 @@ func (s *RedditScraper) ScrapeNow() error {
 
 // GetScrapingStats returns statistics about scraped data
 func (s *RedditScraper) GetScrapingStats() map[string]interface{} {
-	var threadCount, mentionCount int
-	s.db.QueryRow(`SELECT COUNT(*) FROM reddit_threads`).Scan(&threadCount)
-	s.db.QueryRow(`SELECT COUNT(*) FROM reddit_mentions`).Scan(&mentionCount)
-
-	var bySubreddit []struct {
-		Subreddit string `json:"subreddit"`
-		Count     int    `json:"count"`
-	}
-
-	rows, _ := s.db.Query(`SELECT subreddit, COUNT(*) as cnt FROM reddit_threads GROUP BY subreddit`)
-	if rows != nil {
-		defer rows.Close()
-		for rows.Next() {
-			var sub string
-			var cnt int
-			if rows.Scan(&sub, &cnt) == nil {
-				bySubreddit = append(bySubreddit, struct {
-					Subreddit string `json:"subreddit"`
-					Count     int    `json:"count"`
-				}{sub, cnt})
-			}
-		}
-	}
+	threadCount, _ := s.db.CountRedditThreads()
+	mentionCount, _ := s.db.CountRedditMentions()
+	bySubreddit, _ := s.db.GetThreadCountsBySubreddit()
 
 	return map[string]interface{}{
 		"total_threads":  threadCount,
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+// roundTripFunc serves HTTP requests from a function
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+const hotListing = `{"data": {"children": [
+	{"data": {"id": "t1", "subreddit": "animesuggest", "title": "anything similar to bebop",
+		"selftext": "Cowboy Bebop. Ghost In The Shell is a masterpiece.", "score": 40, "num_comments": 12,
+		"created_utc": 1700000000}},
+	{"data": {"id": "t2", "subreddit": "animesuggest", "title": "low effort",
+		"selftext": "Cowboy Bebop.", "score": 2}}
+]}}`
+
+func TestScraperStoresThreadsAndMentions(t *testing.T) {
+	store := database.NewMemoryStore()
+	for _, m := range []models.Media{
+		{ID: "bebop", Title: "Cowboy Bebop", MediaType: "anime", VibeProfile: "jazz noir"},
+		{ID: "gits", Title: "Ghost in the Shell", MediaType: "anime", VibeProfile: "cyberpunk melancholy"},
+		{ID: "lain", Title: "Serial Experiments Lain", MediaType: "anime", VibeProfile: "wired dread"},
+	} {
+		if err := store.CreateMedia(&m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scraper := NewRedditScraper(store, nil)
+	var requested string
+	scraper.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = req.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(hotListing)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	if err := scraper.scrapeSubreddit("animesuggest"); err != nil {
+		t.Fatalf("scrapeSubreddit: %v", err)
+	}
+	if !strings.Contains(requested, "/r/animesuggest/hot.json") {
+		t.Errorf("requested %s, want the subreddit's hot listing", requested)
+	}
+
+	if n, _ := store.CountRedditThreads(); n != 1 {
+		t.Errorf("stored %d threads, want 1 (the low-score post is skipped)", n)
+	}
+	counts, _ := store.GetThreadCountsBySubreddit()
+	if len(counts) != 1 || counts[0] != (models.SubredditCount{Subreddit: "animesuggest", Count: 1}) {
+		t.Errorf("thread counts = %v", counts)
+	}
+	for id, want := range map[string]int{"bebop": 1, "gits": 1, "lain": 0} {
+		if n, _ := store.GetMentionCountForMedia(id); n != want {
+			t.Errorf("%s has %d mentions, want %d", id, n, want)
+		}
+	}
+
+	// "masterpiece" is worth 0.5, and 40 upvotes is below any multiplier
+	for id, want := range map[string]float64{"bebop": 0.5, "gits": 0.5, "lain": 0} {
+		m, _ := store.GetMedia(id)
+		if m.QualityScore != want {
+			t.Errorf("%s quality score = %v, want %v", id, m.QualityScore, want)
+		}
+	}
+}
+
+func TestScraperReportsBadStatus(t *testing.T) {
+	scraper := NewRedditScraper(database.NewMemoryStore(), nil)
+	scraper.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	if err := scraper.scrapeSubreddit("animesuggest"); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("scrapeSubreddit error = %v, want the 429 status", err)
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"math"
+
+	"w2w/internal/embeddings"
+	"w2w/internal/models"
+)
+
+const (
+	// negativeRatingMax is the highest rating treated as a negative anchor
+	negativeRatingMax = 4.0
+	// positiveRatingMin is the lowest rating treated as a positive prior
+	positiveRatingMin = 8.0
+	// minSignalDelta hides annotations for adjustments too small to matter
+	minSignalDelta = 0.005
+	// notInterestedWeight is the anchor weight of a "not interested"
+	// dismissal: softer than a 1/10 rating, about a 2-3
+	notInterestedWeight = 0.6
+	// droppedWeight is the anchor weight of an unrated dropped title: the
+	// user chose it and gave up, a little softer than "not interested"
+	droppedWeight = 0.5
+)
+
+// ratingAnchor is a rated seen title that pulls similar candidates up or
+// pushes them down
+type ratingAnchor struct {
+	mediaID string
+	title   string
+	rating  float64
+	weight  float64 // 0-1, stronger for more extreme ratings
+	vec     []float32
+
+	dismissed bool // From a "not interested" dismissal rather than a rating
+	dropped   bool // From an unrated dropped title rather than a rating
+}
+
+// ratingSignals holds a user's anchors, split by direction
+type ratingSignals struct {
+	positive []ratingAnchor
+	negative []ratingAnchor
+}
+
+func (rs *ratingSignals) empty() bool {
+	return rs == nil || (len(rs.positive) == 0 && len(rs.negative) == 0)
+}
+
+// loadRatingSignals turns the user's extreme ratings into anchors: 1-4 are
+// negative (1 strongest), 8-10 are positive (10 strongest). Unrated dropped
+// titles and "not interested" dismissals join the negatives as soft anchors.
+func (s *VibeSearchService) loadRatingSignals(userID string) (*ratingSignals, error) {
+	seen, err := s.db.GetSeenMedia(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	signals := &ratingSignals{}
+	for _, sm := range seen {
+		if sm.Rating == nil && sm.Status == models.WatchDropped {
+			if vec, ok := s.vectorStore.Get(sm.MediaID); ok {
+				anchor := ratingAnchor{mediaID: sm.MediaID, weight: droppedWeight, vec: vec, dropped: true}
+				if media, err := s.db.GetMedia(sm.MediaID); err == nil && media != nil {
+					anchor.title = media.Title
+				}
+				signals.negative = append(signals.negative, anchor)
+			}
+			continue
+		}
+		if sm.Rating == nil {
+			continue
+		}
+		r := *sm.Rating
+		if r > negativeRatingMax && r < positiveRatingMin {
+			continue
+		}
+		vec, ok := s.vectorStore.Get(sm.MediaID)
+		if !ok {
+			continue
+		}
+
+		anchor := ratingAnchor{mediaID: sm.MediaID, rating: r, vec: vec}
+		if media, err := s.db.GetMedia(sm.MediaID); err == nil && media != nil {
+			anchor.title = media.Title
+		}
+
+		if r <= negativeRatingMax {
+			anchor.weight = (negativeRatingMax + 1 - r) / negativeRatingMax
+			signals.negative = append(signals.negative, anchor)
+		} else {
+			anchor.weight = (r - positiveRatingMin + 1) / (10 - positiveRatingMin + 1)
+			signals.positive = append(signals.positive, anchor)
+		}
+	}
+
+	notInterested, err := s.db.GetNotInterestedIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range notInterested {
+		vec, ok := s.vectorStore.Get(id)
+		if !ok {
+			continue
+		}
+		anchor := ratingAnchor{mediaID: id, weight: notInterestedWeight, vec: vec, dismissed: true}
+		if media, err := s.db.GetMedia(id); err == nil && media != nil {
+			anchor.title = media.Title
+		}
+		signals.negative = append(signals.negative, anchor)
+	}
+	return signals, nil
+}
+
+// applyRatingSignals adjusts each candidate's score by
+// strength * (strongest positive pull - strongest negative pull), counting
+// only anchors at least threshold-similar, and annotates the decisive anchor.
+// Callers re-sort afterwards.
+func (s *VibeSearchService) applyRatingSignals(pool []models.Recommendation, signals *ratingSignals, strength, threshold float64) {
+	if strength <= 0 || signals.empty() {
+		return
+	}
+
+	for i := range pool {
+		vec, ok := s.vectorStore.Get(pool[i].Media.ID)
+		if !ok {
+			continue
+		}
+
+		pos, posAnchor, posSim := strongestPull(vec, signals.positive, threshold)
+		neg, negAnchor, negSim := strongestPull(vec, signals.negative, threshold)
+
+		delta := strength * (pos - neg)
+		if math.Abs(delta) < minSignalDelta {
+			continue
+		}
+		pool[i].Score += delta
+
+		signal := &models.RatingSignal{Delta: delta}
+		if delta > 0 {
+			signal.Effect = "boosted"
+			signal.AnchorID, signal.AnchorTitle, signal.AnchorRating = posAnchor.mediaID, posAnchor.title, posAnchor.rating
+			signal.Similarity = posSim
+		} else {
+			signal.Effect = "demoted"
+			signal.AnchorID, signal.AnchorTitle, signal.AnchorRating = negAnchor.mediaID, negAnchor.title, negAnchor.rating
+			signal.Similarity = negSim
+			signal.Dismissed = negAnchor.dismissed
+			signal.Dropped = negAnchor.dropped
+		}
+		pool[i].RatingSignal = signal
+	}
+}
+
+// strongestPull returns the largest weight*similarity over anchors that
+// clear the threshold, with the anchor responsible
+func strongestPull(vec []float32, anchors []ratingAnchor, threshold float64) (float64, ratingAnchor, float64) {
+	var best float64
+	var bestAnchor ratingAnchor
+	var bestSim float64
+	for _, a := range anchors {
+		sim := embeddings.CosineSimilarity(vec, a.vec)
+		if sim < threshold {
+			continue
+		}
+		if pull := a.weight * sim; pull > best {
+			best, bestAnchor, bestSim = pull, a, sim
+		}
+	}
+	return best, bestAnchor, bestSim
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+func TestLoadRatingSignals(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	rate := func(r float64) *float64 { return &r }
+	for i, id := range []string{"loved", "liked", "meh", "hated", "disliked", "dropped", "dismissed", "unindexed"} {
+		if err := db.CreateMedia(&models.Media{ID: id, Title: id, MediaType: "movie", VibeProfile: "x"}); err != nil {
+			t.Fatal(err)
+		}
+		if id != "unindexed" {
+			vec := make([]float32, 8)
+			vec[i] = 1
+			if err := db.StoreEmbedding(id, vec, "fixed"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for id, r := range map[string]*float64{
+		"loved": rate(10), "liked": rate(8), "meh": rate(6), "hated": rate(1), "disliked": rate(4), "unindexed": rate(10),
+	} {
+		if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: id, Rating: r}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.SaveProgress(&models.SeenMedia{UserID: "u1", MediaID: "dropped", Status: models.WatchDropped}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Dismiss(&models.Dismissal{UserID: "u1", MediaID: "dismissed", Reason: models.DismissNotInterested}); err != nil {
+		t.Fatal(err)
+	}
+	svc, err := NewVibeSearchService(db, fixedEmbedder{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+
+	signals, err := svc.loadRatingSignals("u1")
+	if err != nil {
+		t.Fatalf("loadRatingSignals: %v", err)
+	}
+	weights := make(map[string]float64)
+	for _, a := range signals.positive {
+		weights["+"+a.mediaID] = a.weight
+	}
+	for _, a := range signals.negative {
+		weights["-"+a.mediaID] = a.weight
+		if a.dropped != (a.mediaID == "dropped") || a.dismissed != (a.mediaID == "dismissed") {
+			t.Errorf("anchor %s marked dropped=%v dismissed=%v", a.mediaID, a.dropped, a.dismissed)
+		}
+	}
+	// Middling ratings and titles without embeddings carry no signal
+	want := map[string]float64{
+		"+loved": 1, "+liked": 1.0 / 3,
+		"-hated": 1, "-disliked": 0.25, "-dropped": droppedWeight, "-dismissed": notInterestedWeight,
+	}
+	if len(weights) != len(want) {
+		t.Errorf("anchors = %v, want %v", weights, want)
+	}
+	for id, w := range want {
+		if got, ok := weights[id]; !ok || math.Abs(got-w) > 1e-9 {
+			t.Errorf("anchor %s weight = %v (present %v), want %v", id, got, ok, w)
+		}
+	}
+}
+
+func TestApplyRatingSignals(t *testing.T) {
+	db := newTestDB(t)
+	candidates := map[string][]float32{
+		"near-loved":  {0.96, 0.28, 0},
+		"near-hated":  {0.28, 0.96, 0},
+		"in-between":  {0.6, 0.8, 0},
+		"unrelated":   {0, 0, 1},
+		"not-indexed": nil,
+	}
+	var pool []models.Recommendation
+	for id, vec := range candidates {
+		if err := db.CreateMedia(&models.Media{ID: id, Title: id, MediaType: "movie", VibeProfile: "x"}); err != nil {
+			t.Fatal(err)
+		}
+		if vec != nil {
+			if err := db.StoreEmbedding(id, vec, "fixed"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		pool = append(pool, models.Recommendation{Media: models.Media{ID: id}, Score: 1})
+	}
+	svc, err := NewVibeSearchService(db, fixedEmbedder{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+	signals := &ratingSignals{
+		positive: []ratingAnchor{{mediaID: "loved", title: "Loved", rating: 10, weight: 1, vec: []float32{1, 0, 0}}},
+		negative: []ratingAnchor{{mediaID: "meh", title: "Meh", weight: notInterestedWeight, vec: []float32{0, 1, 0}, dismissed: true}},
+	}
+
+	svc.applyRatingSignals(pool, signals, 0, 0.5)
+	for _, r := range pool {
+		if r.Score != 1 || r.RatingSignal != nil {
+			t.Fatalf("strength 0 changed %s: %+v", r.Media.ID, r)
+		}
+	}
+
+	svc.applyRatingSignals(pool, signals, 0.2, 0.5)
+	byID := make(map[string]models.Recommendation)
+	for _, r := range pool {
+		byID[r.Media.ID] = r
+	}
+	tests := []struct {
+		id     string
+		delta  float64
+		effect string
+		anchor string
+	}{
+		// Only the loved anchor clears the threshold: 0.2 * 0.96
+		{"near-loved", 0.192, "boosted", "loved"},
+		// Only the dismissal clears it: -0.2 * 0.6 * 0.96
+		{"near-hated", -0.1152, "demoted", "meh"},
+		// Both pull: 0.2 * (0.6 - 0.6*0.8)
+		{"in-between", 0.024, "boosted", "loved"},
+		{"unrelated", 0, "", ""},
+		{"not-indexed", 0, "", ""},
+	}
+	for _, tt := range tests {
+		r := byID[tt.id]
+		if math.Abs(r.Score-1-tt.delta) > 1e-6 {
+			t.Errorf("%s score = %v, want %v", tt.id, r.Score, 1+tt.delta)
+		}
+		if tt.effect == "" {
+			if r.RatingSignal != nil {
+				t.Errorf("%s annotated with %+v", tt.id, r.RatingSignal)
+			}
+			continue
+		}
+		sig := r.RatingSignal
+		if sig == nil || sig.Effect != tt.effect || sig.AnchorID != tt.anchor || math.Abs(sig.Delta-tt.delta) > 1e-6 {
+			t.Errorf("%s signal = %+v, want %s by %s", tt.id, sig, tt.effect, tt.anchor)
+			continue
+		}
+		if sig.Dismissed != (tt.anchor == "meh") {
+			t.Errorf("%s signal dismissed = %v", tt.id, sig.Dismissed)
+		}
+	}
+}
+
+func TestSearchAppliesRatingSignals(t *testing.T) {
+	svc := rankingFixture(t)
+	db := svc.db.(interface {
+		CreateUser(*models.User) error
+		MarkAsSeen(*models.SeenMedia) error
+	})
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	one := 1.0
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "near", Rating: &one}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hating near demotes twin (cosine 0.96) more than far (0.6)
+	result, err := svc.Search(SearchConfig{UserID: "u1", Query: "awe"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(result.Recommendations) != 2 {
+		t.Fatalf("got %v, want twin and far (near is seen)", resultIDs(result))
+	}
+	strength := DefaultTuning().RatingSignalStrength
+	for _, r := range result.Recommendations {
+		sig := r.RatingSignal
+		if sig == nil || sig.Effect != "demoted" || sig.AnchorID != "near" || sig.AnchorRating != 1 {
+			t.Fatalf("%s signal = %+v, want demoted by near", r.Media.ID, sig)
+		}
+		if want := -strength * sig.Similarity; math.Abs(sig.Delta-want) > 1e-6 {
+			t.Errorf("%s delta = %v, want %v", r.Media.ID, sig.Delta, want)
+		}
+	}
+
+	// The request can turn the signal off
+	off := 0.0
+	result, _ = svc.Search(SearchConfig{UserID: "u1", Query: "awe", RatingSignalStrength: &off})
+	for _, r := range result.Recommendations {
+		if r.RatingSignal != nil {
+			t.Errorf("%s annotated with the signal off", r.Media.ID)
+		}
+	}
+}
@@ -0,0 +1,535 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"w2w/internal/embeddings"
+	"w2w/internal/models"
+)
+
+const (
+	// maxTasteCentroids caps how many taste modes a user can have
+	maxTasteCentroids = 4
+	// newCentroidThreshold is the cosine below which an incoming title starts
+	// a new taste mode instead of joining the nearest one
+	newCentroidThreshold = 0.35
+	// tasteRebuildEvery triggers a full re-clustering after this many titles,
+	// so incremental assignment can't drift too far from the optimum
+	tasteRebuildEvery = 10
+	// maxTasteDrivers is how many past titles are cited per pick
+	maxTasteDrivers = 2
+)
+
+// tasteWeight converts a 1-10 rating into a centroid weight. Unrated titles
+// count as a mild positive (the user chose to watch them); ratings of 5 and
+// below, and dropped titles, contribute nothing to the positive profile.
+func tasteWeight(rating *float64, status string) float64 {
+	if status == models.WatchDropped {
+		return 0
+	}
+	if rating == nil {
+		return 0.6
+	}
+	if *rating <= 5 {
+		return 0
+	}
+	return (*rating - 5) / 5
+}
+
+// tasteLocks serializes each user's taste profile updates. Every update
+// reads the stored centroids, changes them and writes them back, so two
+// concurrent updates for one user would otherwise lose one of the titles.
+type tasteLocks struct {
+	mu    sync.Mutex
+	users map[string]*tasteLock
+}
+
+type tasteLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lock takes the user's lock and returns the function that releases it.
+// Locks are dropped once nobody holds or waits for them.
+func (l *tasteLocks) lock(userID string) func() {
+	l.mu.Lock()
+	if l.users == nil {
+		l.users = make(map[string]*tasteLock)
+	}
+	ul, ok := l.users[userID]
+	if !ok {
+		ul = &tasteLock{}
+		l.users[userID] = ul
+	}
+	ul.waiters++
+	l.mu.Unlock()
+
+	ul.Lock()
+	return func() {
+		ul.Unlock()
+		l.mu.Lock()
+		if ul.waiters--; ul.waiters == 0 {
+			delete(l.users, userID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// UpdateTasteOnSeen folds a newly seen (or re-rated, or re-statused) title
+// into the user's taste profile without recomputing it from scratch
+func (s *VibeSearchService) UpdateTasteOnSeen(userID, mediaID string, rating *float64, status string) error {
+	defer s.tasteLocks.lock(userID)()
+
+	centroids, err := s.db.GetTasteCentroids(userID)
+	if err != nil {
+		return fmt.Errorf("failed to load taste profile: %w", err)
+	}
+
+	// A user with history but no stored profile predates taste tracking
+	if len(centroids) == 0 {
+		return s.rebuildTaste(userID)
+	}
+
+	centroids = s.removeFromCentroids(centroids, mediaID)
+
+	weight := tasteWeight(rating, status)
+	if vec, ok := s.vectorStore.Get(mediaID); ok && weight > 0 {
+		centroids = assignToCentroid(centroids, userID, mediaID, vec, weight)
+	}
+
+	if memberCount(centroids)%tasteRebuildEvery == 0 {
+		return s.rebuildTaste(userID)
+	}
+	return s.db.ReplaceTasteCentroids(userID, centroids)
+}
+
+// UpdateTasteOnUnseen removes a title from the user's taste profile
+func (s *VibeSearchService) UpdateTasteOnUnseen(userID, mediaID string) error {
+	defer s.tasteLocks.lock(userID)()
+
+	centroids, err := s.db.GetTasteCentroids(userID)
+	if err != nil {
+		return fmt.Errorf("failed to load taste profile: %w", err)
+	}
+	if len(centroids) == 0 {
+		return nil
+	}
+	return s.db.ReplaceTasteCentroids(userID, s.removeFromCentroids(centroids, mediaID))
+}
+
+// RebuildTaste recomputes the user's taste profile from their full seen list
+// with spherical k-means over rating-weighted embeddings
+func (s *VibeSearchService) RebuildTaste(userID string) error {
+	defer s.tasteLocks.lock(userID)()
+	return s.rebuildTaste(userID)
+}
+
+// rebuildTaste is RebuildTaste for callers holding the user's taste lock
+func (s *VibeSearchService) rebuildTaste(userID string) error {
+	seen, err := s.db.GetSeenMedia(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get seen media: %w", err)
+	}
+
+	var points []tastePoint
+	for _, sm := range seen {
+		weight := tasteWeight(sm.Rating, sm.Status)
+		if weight <= 0 {
+			continue
+		}
+		vec, ok := s.vectorStore.Get(sm.MediaID)
+		if !ok {
+			continue
+		}
+		points = append(points, tastePoint{id: sm.MediaID, vec: vec, weight: weight})
+	}
+
+	return s.db.ReplaceTasteCentroids(userID, clusterTaste(userID, points))
+}
+
+// tasteProfile loads the user's centroids, building them on first use
+func (s *VibeSearchService) tasteProfile(userID string) ([]models.TasteCentroid, error) {
+	centroids, err := s.db.GetTasteCentroids(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(centroids) != 0 {
+		return centroids, nil
+	}
+
+	// Another request may have built it while we waited for the lock
+	defer s.tasteLocks.lock(userID)()
+	if centroids, err = s.db.GetTasteCentroids(userID); err != nil || len(centroids) != 0 {
+		return centroids, err
+	}
+	if err := s.rebuildTaste(userID); err != nil {
+		return nil, err
+	}
+	return s.db.GetTasteCentroids(userID)
+}
+
+// ForYou recommends unseen titles near the user's taste centroids, with no
+// query. Each mode gets a share of the results proportional to its weight.
+// An exploration policy widens each mode's share so it has deeper
+// candidates to swap in.
+func (s *VibeSearchService) ForYou(userID string, limit int, explore ExplorePolicy) (*SearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	centroids, err := s.tasteProfile(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load taste profile: %w", err)
+	}
+
+	excludedIDs, err := s.db.GetExcludedMediaIDs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
+	}
+
+	result := &SearchResult{
+		Recommendations: []models.Recommendation{},
+		FilteredCount:   len(excludedIDs),
+	}
+	if len(centroids) == 0 {
+		return result, nil
+	}
+
+	var totalWeight float64
+	for _, c := range centroids {
+		totalWeight += c.WeightSum
+	}
+
+	picked := make(map[string]bool)
+	var pool []models.Recommendation
+	for _, c := range centroids {
+		quota := int(math.Round(float64(limit) * c.WeightSum / totalWeight))
+		if quota < 1 {
+			quota = 1
+		}
+		if explore.enabled() {
+			quota *= 2
+		}
+
+		exclude := make(map[string]bool, len(excludedIDs)+len(picked))
+		for id := range excludedIDs {
+			exclude[id] = true
+		}
+		for id := range picked {
+			exclude[id] = true
+		}
+
+		candidates := s.vectorStore.Search(c.Vector, quota, exclude)
+		result.TotalCandidates += len(candidates)
+		for _, cand := range candidates {
+			media, err := s.db.GetMedia(cand.MediaID)
+			if err != nil || media == nil {
+				continue
+			}
+			picked[media.ID] = true
+			rec := models.Recommendation{
+				Media:     *media,
+				VibeScore: cand.Similarity,
+				Score:     cand.Similarity,
+				BecauseOf: s.tasteDrivers(cand.MediaID, c),
+			}
+			rec.Explanation = becauseExplanation(rec.BecauseOf)
+			pool = append(pool, rec)
+		}
+	}
+
+	if explore.enabled() {
+		s.applyNovelty(userID, pool)
+	}
+	sortByScore(pool)
+	result.Candidates = rankedItems(pool)
+	recs := pool
+	if len(recs) > limit {
+		recs = pool[:limit:limit]
+	}
+	for i := range recs {
+		recs[i].Rank = i + 1
+	}
+	if explore.enabled() {
+		recs = s.explore(explore, recs, pool[len(recs):])
+	}
+	result.Explore = explore
+
+	if err := s.facets.AttachChips(recs); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+
+	result.Recommendations = recs
+	return result, nil
+}
+
+// applyPersonalization blends taste similarity into each candidate's score:
+// score = (1-w) * vibe + w * max cosine to any taste centroid
+func (s *VibeSearchService) applyPersonalization(userID string, weight float64, pool []models.Recommendation) {
+	if weight <= 0 || len(pool) == 0 {
+		return
+	}
+
+	centroids, err := s.tasteProfile(userID)
+	if err != nil {
+		log.Printf("Failed to load taste profile for %s: %v", userID, err)
+		return
+	}
+	if len(centroids) == 0 {
+		return
+	}
+
+	for i := range pool {
+		vec, ok := s.vectorStore.Get(pool[i].Media.ID)
+		if !ok {
+			continue
+		}
+		best, bestSim := 0, -1.0
+		for ci, c := range centroids {
+			if sim := embeddings.CosineSimilarity(vec, c.Vector); sim > bestSim {
+				best, bestSim = ci, sim
+			}
+		}
+		pool[i].Score = (1-weight)*pool[i].Score + weight*bestSim
+		pool[i].BecauseOf = s.tasteDrivers(pool[i].Media.ID, centroids[best])
+	}
+}
+
+// tasteDrivers finds the centroid members most similar to a pick
+func (s *VibeSearchService) tasteDrivers(mediaID string, centroid models.TasteCentroid) []models.TasteDriver {
+	vec, ok := s.vectorStore.Get(mediaID)
+	if !ok {
+		return nil
+	}
+
+	var drivers []models.TasteDriver
+	for memberID := range centroid.Members {
+		memberVec, ok := s.vectorStore.Get(memberID)
+		if !ok {
+			continue
+		}
+		drivers = append(drivers, models.TasteDriver{
+			MediaID:    memberID,
+			Similarity: embeddings.CosineSimilarity(vec, memberVec),
+		})
+	}
+	sort.Slice(drivers, func(i, j int) bool {
+		return drivers[i].Similarity > drivers[j].Similarity
+	})
+	if len(drivers) > maxTasteDrivers {
+		drivers = drivers[:maxTasteDrivers]
+	}
+
+	for i := range drivers {
+		if media, err := s.db.GetMedia(drivers[i].MediaID); err == nil && media != nil {
+			drivers[i].Title = media.Title
+		}
+	}
+	return drivers
+}
+
+// becauseExplanation renders drivers as a human-readable reason
+func becauseExplanation(drivers []models.TasteDriver) string {
+	if len(drivers) == 0 {
+		return "Matches your overall taste"
+	}
+	titles := make([]string, len(drivers))
+	for i, d := range drivers {
+		titles[i] = d.Title
+	}
+	return "Because you liked " + strings.Join(titles, " and ")
+}
+
+// removeFromCentroids subtracts a title's contribution from whichever
+// centroid holds it, dropping centroids that become empty
+func (s *VibeSearchService) removeFromCentroids(centroids []models.TasteCentroid, mediaID string) []models.TasteCentroid {
+	out := centroids[:0]
+	for _, c := range centroids {
+		if weight, ok := c.Members[mediaID]; ok {
+			if vec, ok := s.vectorStore.Get(mediaID); ok {
+				addScaled(c.Vector, vec, -weight)
+			}
+			c.WeightSum -= weight
+			delete(c.Members, mediaID)
+		}
+		if len(c.Members) > 0 {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// assignToCentroid adds a title to its nearest centroid, or opens a new one
+// when it doesn't resemble any existing taste mode
+func assignToCentroid(centroids []models.TasteCentroid, userID, mediaID string, vec []float32, weight float64) []models.TasteCentroid {
+	best, bestSim := -1, -1.0
+	for i, c := range centroids {
+		if sim := embeddings.CosineSimilarity(vec, c.Vector); sim > bestSim {
+			best, bestSim = i, sim
+		}
+	}
+
+	if best == -1 || (bestSim < newCentroidThreshold && len(centroids) < maxTasteCentroids) {
+		c := models.TasteCentroid{
+			UserID:  userID,
+			Index:   len(centroids),
+			Vector:  make([]float32, len(vec)),
+			Members: make(map[string]float64),
+		}
+		centroids = append(centroids, c)
+		best = len(centroids) - 1
+	}
+
+	c := &centroids[best]
+	addScaled(c.Vector, vec, weight)
+	c.WeightSum += weight
+	c.Members[mediaID] = weight
+	return centroids
+}
+
+// tastePoint is a weighted embedding fed into clustering
+type tastePoint struct {
+	id     string
+	vec    []float32
+	weight float64
+}
+
+// clusterTaste runs a small deterministic spherical k-means. k grows with
+// the number of titles (about one mode per five) up to maxTasteCentroids,
+// and modes that end up nearly identical are merged.
+func clusterTaste(userID string, points []tastePoint) []models.TasteCentroid {
+	if len(points) == 0 {
+		return nil
+	}
+
+	k := len(points) / 5
+	if k < 1 {
+		k = 1
+	}
+	if k > maxTasteCentroids {
+		k = maxTasteCentroids
+	}
+	seeds, assign := sphericalKMeans(points, k)
+
+	var centroids []models.TasteCentroid
+	for ci, sd := range seeds {
+		c := models.TasteCentroid{UserID: userID, Vector: sd, Members: make(map[string]float64)}
+		for i, p := range points {
+			if assign[i] == ci {
+				c.WeightSum += p.weight
+				c.Members[p.id] = p.weight
+			}
+		}
+		if len(c.Members) == 0 {
+			continue
+		}
+
+		// Merge into an existing mode if the two are practically the same
+		merged := false
+		for j := range centroids {
+			if embeddings.CosineSimilarity(centroids[j].Vector, c.Vector) > 0.9 {
+				addScaled(centroids[j].Vector, c.Vector, 1)
+				centroids[j].WeightSum += c.WeightSum
+				for id, w := range c.Members {
+					centroids[j].Members[id] = w
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			c.Index = len(centroids)
+			centroids = append(centroids, c)
+		}
+	}
+
+	// Heaviest mode first
+	sort.SliceStable(centroids, func(i, j int) bool {
+		return centroids[i].WeightSum > centroids[j].WeightSum
+	})
+	return centroids
+}
+
+// sphericalKMeans clusters weighted points into k groups (k at most
+// len(points)), returning the unnormalized, weighted cluster vectors and
+// each point's cluster. Points are sorted heaviest first, in place.
+func sphericalKMeans(points []tastePoint, k int) ([][]float32, []int) {
+	// Farthest-first seeding from the heaviest point keeps results stable
+	sort.SliceStable(points, func(i, j int) bool {
+		if points[i].weight != points[j].weight {
+			return points[i].weight > points[j].weight
+		}
+		return points[i].id < points[j].id
+	})
+	seeds := [][]float32{points[0].vec}
+	for len(seeds) < k {
+		farthest, farthestSim := -1, 2.0
+		for i, p := range points {
+			nearest := -1.0
+			for _, sd := range seeds {
+				if sim := embeddings.CosineSimilarity(p.vec, sd); sim > nearest {
+					nearest = sim
+				}
+			}
+			if nearest < farthestSim {
+				farthest, farthestSim = i, nearest
+			}
+		}
+		seeds = append(seeds, points[farthest].vec)
+	}
+
+	assign := make([]int, len(points))
+	for iter := 0; iter < 10; iter++ {
+		changed := iter == 0
+		for i, p := range points {
+			best, bestSim := 0, -2.0
+			for ci, sd := range seeds {
+				if sim := embeddings.CosineSimilarity(p.vec, sd); sim > bestSim {
+					best, bestSim = ci, sim
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+
+		for ci := range seeds {
+			sum := make([]float32, len(points[0].vec))
+			for i, p := range points {
+				if assign[i] == ci {
+					addScaled(sum, p.vec, p.weight)
+				}
+			}
+			seeds[ci] = sum
+		}
+		if !changed {
+			break
+		}
+	}
+	return seeds, assign
+}
+
+// memberCount totals the titles across all centroids
+func memberCount(centroids []models.TasteCentroid) int {
+	n := 0
+	for _, c := range centroids {
+		n += len(c.Members)
+	}
+	return n
+}
+
+// addScaled accumulates w*v into sum in place
+func addScaled(sum, v []float32, w float64) {
+	if len(sum) != len(v) {
+		return
+	}
+	for i := range v {
+		sum[i] += float32(w * float64(v[i]))
+	}
+}
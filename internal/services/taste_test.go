@@ -0,0 +1,164 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+
+	"w2w/internal/models"
+)
+
+// modePoints returns n unit vectors fanned slightly around axis, so each
+// call yields one tight taste mode
+func modePoints(prefix string, axis, n int, weight float64) []tastePoint {
+	points := make([]tastePoint, n)
+	for i := range points {
+		vec := make([]float32, 3)
+		vec[axis] = 1
+		vec[(axis+1)%3] = 0.05 * float32(i)
+		points[i] = tastePoint{id: fmt.Sprintf("%s%d", prefix, i), vec: vec, weight: weight}
+	}
+	return points
+}
+
+func memberIDs(c models.TasteCentroid) []string {
+	ids := make([]string, 0, len(c.Members))
+	for id := range c.Members {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestSphericalKMeansSeparatesModes(t *testing.T) {
+	// The single heaviest point seeds the first cluster whatever the order
+	points := append(modePoints("a", 0, 3, 1), modePoints("b", 1, 3, 1)...)
+	points[4].weight = 2
+
+	seeds, assign := sphericalKMeans(points, 2)
+	if points[0].id != "b1" {
+		t.Fatalf("first point = %s, want the heaviest (b1)", points[0].id)
+	}
+	if len(seeds) != 2 {
+		t.Fatalf("got %d clusters, want 2", len(seeds))
+	}
+	for i, p := range points {
+		want := 1
+		if p.id[0] == 'b' {
+			want = 0
+		}
+		if assign[i] != want {
+			t.Errorf("%s in cluster %d, want %d", p.id, assign[i], want)
+		}
+	}
+
+	// Cluster vectors are weighted sums: b1 counts twice
+	if got := seeds[0][1]; math.Abs(float64(got)-4) > 1e-6 {
+		t.Errorf("cluster 0 y = %v, want 4", got)
+	}
+}
+
+func TestClusterTaste(t *testing.T) {
+	t.Run("few titles make one mode", func(t *testing.T) {
+		points := append(modePoints("a", 0, 2, 1), modePoints("b", 1, 2, 1)...)
+		centroids := clusterTaste("u1", points)
+		if len(centroids) != 1 || len(centroids[0].Members) != 4 {
+			t.Fatalf("got %d centroids, want all four titles in one", len(centroids))
+		}
+	})
+
+	t.Run("distinct modes, heaviest first", func(t *testing.T) {
+		points := append(modePoints("a", 0, 5, 1), modePoints("b", 1, 5, 1.5)...)
+		centroids := clusterTaste("u1", points)
+		if len(centroids) != 2 {
+			t.Fatalf("got %d centroids, want 2", len(centroids))
+		}
+		if got := memberIDs(centroids[0]); fmt.Sprint(got) != "[b0 b1 b2 b3 b4]" {
+			t.Errorf("heaviest mode = %v", got)
+		}
+		if got := memberIDs(centroids[1]); fmt.Sprint(got) != "[a0 a1 a2 a3 a4]" {
+			t.Errorf("second mode = %v", got)
+		}
+		if centroids[0].WeightSum != 7.5 || centroids[1].WeightSum != 5 {
+			t.Errorf("weight sums = %v, %v", centroids[0].WeightSum, centroids[1].WeightSum)
+		}
+	})
+
+	t.Run("near-identical modes merge", func(t *testing.T) {
+		// Ten titles ask for two modes, but they all point the same way
+		centroids := clusterTaste("u1", modePoints("a", 0, 10, 1))
+		if len(centroids) != 1 || len(centroids[0].Members) != 10 {
+			t.Fatalf("got %d centroids, want one holding all ten titles", len(centroids))
+		}
+		if centroids[0].WeightSum != 10 {
+			t.Errorf("weight sum = %v, want 10", centroids[0].WeightSum)
+		}
+	})
+
+	t.Run("k is capped", func(t *testing.T) {
+		var points []tastePoint
+		for i := 0; i < 30; i++ {
+			points = append(points, modePoints(fmt.Sprintf("m%d-", i), i%3, 1, 1)...)
+		}
+		if centroids := clusterTaste("u1", points); len(centroids) > maxTasteCentroids {
+			t.Errorf("got %d centroids, more than %d", len(centroids), maxTasteCentroids)
+		}
+	})
+}
+
+func TestUpdateTasteOnSeenConcurrently(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.CreateUser(&models.User{ID: "u1", Username: "ana"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		id := fmt.Sprintf("m%d", i)
+		if err := db.CreateMedia(&models.Media{ID: id, Title: id, MediaType: "movie", VibeProfile: "quiet awe"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.StoreEmbedding(id, []float32{1, 0.01 * float32(i), 0}, "fixed"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc, err := NewVibeSearchService(db, fixedEmbedder{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+
+	// The first title builds the profile; the rest arrive all at once and
+	// each must land in it
+	if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: "m0"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.UpdateTasteOnSeen("u1", "m0", nil, models.WatchCompleted); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 1; i < 9; i++ {
+		id := fmt.Sprintf("m%d", i)
+		if err := db.MarkAsSeen(&models.SeenMedia{UserID: "u1", MediaID: id}); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := svc.UpdateTasteOnSeen("u1", id, nil, models.WatchCompleted); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	centroids, err := db.GetTasteCentroids("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := memberCount(centroids); n != 9 {
+		t.Errorf("profile holds %d titles after concurrent updates, want 9", n)
+	}
+	if len(svc.tasteLocks.users) != 0 {
+		t.Errorf("%d taste locks left behind", len(svc.tasteLocks.users))
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"w2w/internal/models"
+)
+
+// trendingWindow is a span /trending can rank by. Mentions inside it decay
+// with the given half-life, so a burst of threads today outweighs the same
+// number spread over the window.
+type trendingWindow struct {
+	name     string
+	label    string
+	span     time.Duration
+	halfLife time.Duration
+}
+
+var trendingWindows = []trendingWindow{
+	{"24h", "24 hours", 24 * time.Hour, 6 * time.Hour},
+	{"7d", "7 days", 7 * 24 * time.Hour, 2 * 24 * time.Hour},
+	{"30d", "30 days", 30 * 24 * time.Hour, 7 * 24 * time.Hour},
+}
+
+const (
+	// DefaultTrendingWindow is used when /trending is not given one
+	DefaultTrendingWindow = "7d"
+	// trendingCommentWeight scales comment count against thread score in a
+	// mention's weight
+	trendingCommentWeight = 0.5
+	// trendingMaxThreads caps the driving threads shown per title
+	trendingMaxThreads = 3
+	// trendingQueryWeight blends normalized velocity into vibe similarity
+	// when /trending has a query
+	trendingQueryWeight = 0.5
+)
+
+// IsTrendingWindow reports whether name is a window /trending understands
+func IsTrendingWindow(name string) bool {
+	_, ok := findTrendingWindow(name)
+	return ok
+}
+
+func findTrendingWindow(name string) (trendingWindow, bool) {
+	for _, w := range trendingWindows {
+		if w.name == name {
+			return w, true
+		}
+	}
+	return trendingWindow{}, false
+}
+
+// mentionWeight is how much one thread mention counts before decay:
+//
+//	1 + ln(1 + score) + 0.5 * ln(1 + comments)
+//
+// so a busy thread counts for more without a viral one drowning the rest
+func mentionWeight(a models.MentionActivity) float64 {
+	return 1 + math.Log1p(math.Max(float64(a.Score), 0)) +
+		trendingCommentWeight*math.Log1p(math.Max(float64(a.NumComments), 0))
+}
+
+// decayedMention is a mention's weight after decay, or 0 outside the window
+func decayedMention(a models.MentionActivity, now time.Time, w trendingWindow) float64 {
+	age := now.Sub(a.PostedAt)
+	if age < 0 {
+		age = 0
+	}
+	if age > w.span {
+		return 0
+	}
+	return mentionWeight(a) * math.Exp2(-float64(age)/float64(w.halfLife))
+}
+
+// scoreTrending turns mention activity into per-title velocities (decayed
+// mentions per day) for every window, keeping titles that have any velocity
+// in the chosen one. An empty mediaType keeps every type.
+func scoreTrending(activity []models.MentionActivity, now time.Time, window trendingWindow, mediaType string) map[string]models.TrendingScore {
+	byMedia := make(map[string][]models.MentionActivity)
+	for _, a := range activity {
+		if mediaType != "" && a.MediaType != mediaType {
+			continue
+		}
+		byMedia[a.MediaID] = append(byMedia[a.MediaID], a)
+	}
+
+	scores := make(map[string]models.TrendingScore)
+	for mediaID, mentions := range byMedia {
+		velocities := make(map[string]float64, len(trendingWindows))
+		for _, w := range trendingWindows {
+			var total float64
+			for _, a := range mentions {
+				total += decayedMention(a, now, w)
+			}
+			velocities[w.name] = total / w.span.Hours() * 24
+		}
+		if velocities[window.name] == 0 {
+			continue
+		}
+
+		var total float64
+		var threads []models.TrendingThread
+		for _, a := range mentions {
+			c := decayedMention(a, now, window)
+			if c == 0 {
+				continue
+			}
+			total += c
+			threads = append(threads, models.TrendingThread{
+				ID:           a.ThreadID,
+				Subreddit:    a.Subreddit,
+				Title:        a.Title,
+				URL:          fmt.Sprintf("https://www.reddit.com/r/%s/comments/%s", a.Subreddit, a.ThreadID),
+				ThreadType:   a.ThreadType,
+				Score:        a.Score,
+				NumComments:  a.NumComments,
+				PostedAt:     a.PostedAt,
+				Contribution: c,
+			})
+		}
+		sort.Slice(threads, func(i, j int) bool { return threads[i].Contribution > threads[j].Contribution })
+		mentionCount := len(threads)
+		if len(threads) > trendingMaxThreads {
+			threads = threads[:trendingMaxThreads]
+		}
+		for i := range threads {
+			threads[i].Contribution /= total
+		}
+
+		scores[mediaID] = models.TrendingScore{
+			MediaID:    mediaID,
+			Window:     window.name,
+			Velocity:   velocities[window.name],
+			Velocities: velocities,
+			Mentions:   mentionCount,
+			Threads:    threads,
+		}
+	}
+	return scores
+}
+
+// TrendingConfig holds the options for a trending request
+type TrendingConfig struct {
+	UserID    string
+	Query     string // Optional vibe; without one titles are ranked by velocity alone
+	Window    string // "24h", "7d" or "30d"; DefaultTrendingWindow when empty
+	Limit     int
+	MediaType string   // "movie", "tv", "anime" or "documentary"; empty for all
+	Facets    []string // Facet slugs every result must carry (AND)
+}
+
+// GetTrending finds the titles Reddit is talking about most right now that
+// the user has not seen or dismissed. With a query, trending titles are
+// vibe-searched and their velocity blended in.
+func (s *VibeSearchService) GetTrending(config TrendingConfig) (*SearchResult, error) {
+	if config.Limit <= 0 {
+		config.Limit = 10
+	}
+	if config.Window == "" {
+		config.Window = DefaultTrendingWindow
+	}
+	window, ok := findTrendingWindow(config.Window)
+	if !ok {
+		return nil, fmt.Errorf("unknown trending window: %s", config.Window)
+	}
+
+	// Every window is reported, so read back as far as the longest
+	now := time.Now()
+	longest := trendingWindows[len(trendingWindows)-1].span
+	activity, err := s.db.GetMentionActivity(now.Add(-longest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mention activity: %w", err)
+	}
+	scores := scoreTrending(activity, now, window, config.MediaType)
+
+	if config.Query != "" {
+		return s.Search(SearchConfig{
+			UserID:       config.UserID,
+			Query:        config.Query,
+			TopK:         config.Limit * 3,
+			FinalResults: config.Limit,
+			Facets:       config.Facets,
+			Trending:     scores,
+		})
+	}
+
+	excludedIDs, err := s.db.GetExcludedMediaIDs(config.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
+	}
+	var allowIDs map[string]bool
+	if len(config.Facets) > 0 {
+		allowIDs, err = s.facets.MatchingMediaIDs(config.Facets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by facets: %w", err)
+		}
+	}
+
+	ranked := make([]models.TrendingScore, 0, len(scores))
+	for id, t := range scores {
+		if excludedIDs[id] || (allowIDs != nil && !allowIDs[id]) {
+			continue
+		}
+		ranked = append(ranked, t)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Velocity != ranked[j].Velocity {
+			return ranked[i].Velocity > ranked[j].Velocity
+		}
+		return ranked[i].MediaID < ranked[j].MediaID
+	})
+
+	maxVelocity := maxTrendingVelocity(scores)
+	recommendations := []models.Recommendation{}
+	for i := range ranked {
+		if len(recommendations) >= config.Limit {
+			break
+		}
+		t := ranked[i]
+		media, err := s.db.GetMedia(t.MediaID)
+		if err != nil || media == nil {
+			continue
+		}
+		recommendations = append(recommendations, models.Recommendation{
+			Media:       *media,
+			Score:       t.Velocity / maxVelocity,
+			Explanation: trendingExplanation(t, window),
+			Rank:        len(recommendations) + 1,
+			Trending:    &t,
+		})
+	}
+
+	return &SearchResult{
+		Recommendations: recommendations,
+		TotalCandidates: len(ranked),
+		FilteredCount:   len(scores) - len(ranked),
+		Candidates:      rankedItems(recommendations),
+	}, nil
+}
+
+func maxTrendingVelocity(scores map[string]models.TrendingScore) float64 {
+	highest := 0.0
+	for _, t := range scores {
+		highest = math.Max(highest, t.Velocity)
+	}
+	return highest
+}
+
+// applyTrendingScores blends each candidate's velocity, normalized to the
+// fastest trending title, into its similarity and attaches the breakdown
+func applyTrendingScores(scores map[string]models.TrendingScore, pool []models.Recommendation) {
+	maxVelocity := maxTrendingVelocity(scores)
+	if maxVelocity == 0 {
+		return
+	}
+	for i := range pool {
+		t, ok := scores[pool[i].Media.ID]
+		if !ok {
+			continue
+		}
+		pool[i].Score = (1-trendingQueryWeight)*pool[i].Score + trendingQueryWeight*t.Velocity/maxVelocity
+		pool[i].Trending = &t
+	}
+}
+
+// trendingExplanation says how much buzz a title has and where it comes from
+func trendingExplanation(t models.TrendingScore, w trendingWindow) string {
+	text := fmt.Sprintf("Mentioned in %d Reddit thread", t.Mentions)
+	if t.Mentions != 1 {
+		text += "s"
+	}
+	text += " in the last " + w.label
+	if len(t.Threads) > 0 {
+		top := t.Threads[0]
+		text += fmt.Sprintf(`, led by "%s" on r/%s`, top.Title, top.Subreddit)
+	}
+	return text
+}
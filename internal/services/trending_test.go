@@ -0,0 +1,110 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"w2w/internal/models"
+)
+
+func TestScoreTrending(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	mention := func(mediaID, mediaType, threadID string, age time.Duration, score int) models.MentionActivity {
+		return models.MentionActivity{
+			MediaID: mediaID, MediaType: mediaType, ThreadID: threadID,
+			Subreddit: "movies", Title: "thread " + threadID, ThreadType: "recommendation",
+			Score: score, PostedAt: now.Add(-age),
+		}
+	}
+	activity := []models.MentionActivity{
+		// One busy thread just now: 1 + ln(100)
+		mention("viral", "movie", "v1", 0, 99),
+		// Two quiet threads just now
+		mention("burst", "movie", "b1", 0, 0),
+		mention("burst", "movie", "b2", 0, 0),
+		// Halving every 6 hours; the 24h-old thread is on the window's edge
+		// and the 30h-old one is outside it
+		mention("steady", "movie", "s12", 12*time.Hour, 0),
+		mention("steady", "movie", "s6", 6*time.Hour, 0),
+		mention("steady", "movie", "s24", 24*time.Hour, 0),
+		mention("steady", "movie", "s18", 18*time.Hour, 0),
+		mention("steady", "movie", "s30", 30*time.Hour, 0),
+		mention("anime", "anime", "a1", 0, 0),
+		mention("stale", "movie", "x1", 3*24*time.Hour, 0),
+	}
+	window, _ := findTrendingWindow("24h")
+
+	scores := scoreTrending(activity, now, window, "")
+	if _, ok := scores["stale"]; ok {
+		t.Error("a title with no mentions in the last 24 hours is trending")
+	}
+
+	ranked := make([]models.TrendingScore, 0, len(scores))
+	for _, s := range scores {
+		ranked = append(ranked, s)
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Velocity > ranked[j].Velocity })
+	want := []struct {
+		id       string
+		velocity float64
+		mentions int
+	}{
+		{"viral", 1 + math.Log(100), 1},
+		{"burst", 2, 2},
+		{"anime", 1, 1},
+		{"steady", 0.5 + 0.25 + 0.125 + 0.0625, 4},
+	}
+	if len(ranked) != len(want) {
+		t.Fatalf("trending %d titles, want %d", len(ranked), len(want))
+	}
+	for i, w := range want {
+		got := ranked[i]
+		if got.MediaID != w.id {
+			t.Fatalf("rank %d = %s, want %s", i+1, got.MediaID, w.id)
+		}
+		if math.Abs(got.Velocity-w.velocity) > 1e-9 || got.Mentions != w.mentions {
+			t.Errorf("%s velocity %.4f over %d mentions, want %.4f over %d", w.id, got.Velocity, got.Mentions, w.velocity, w.mentions)
+		}
+	}
+
+	// Over 7 days the same mentions halve every 2 days and are spread over
+	// seven, and the 30h-old thread counts too
+	steady := scores["steady"]
+	var week float64
+	for _, h := range []float64{6, 12, 18, 24, 30} {
+		week += math.Exp2(-h / 48)
+	}
+	if math.Abs(steady.Velocities["7d"]-week/7) > 1e-9 {
+		t.Errorf("steady 7d velocity = %.4f, want %.4f", steady.Velocities["7d"], week/7)
+	}
+
+	// The three freshest threads drive it, as shares of the in-window total
+	wantThreads := []struct {
+		id    string
+		share float64
+	}{
+		{"s6", 0.5 / 0.9375},
+		{"s12", 0.25 / 0.9375},
+		{"s18", 0.125 / 0.9375},
+	}
+	if len(steady.Threads) != len(wantThreads) {
+		t.Fatalf("steady has %d driving threads, want %d", len(steady.Threads), len(wantThreads))
+	}
+	for i, w := range wantThreads {
+		th := steady.Threads[i]
+		if th.ID != w.id || math.Abs(th.Contribution-w.share) > 1e-9 {
+			t.Errorf("thread %d = %s with share %.4f, want %s with %.4f", i, th.ID, th.Contribution, w.id, w.share)
+		}
+	}
+	if url := steady.Threads[0].URL; url != "https://www.reddit.com/r/movies/comments/s6" {
+		t.Errorf("thread URL = %s", url)
+	}
+
+	// A media type filter drops the other types
+	movies := scoreTrending(activity, now, window, "movie")
+	if _, ok := movies["anime"]; ok || len(movies) != 3 {
+		t.Errorf("movie-only trending = %d titles, want viral, burst and steady", len(movies))
+	}
+}
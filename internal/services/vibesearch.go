@@ -2,7 +2,10 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"math"
 	"sort"
+	"time"
 
 	"w2w/internal/database"
 	"w2w/internal/embeddings"
@@ -12,19 +15,129 @@ import (
 
 // VibeSearchService handles the core recommendation logic
 type VibeSearchService struct {
-	db          *database.DB
+	db          SearchStore
 	embedder    embeddings.Provider
 	llmClient   *llm.Client
+	reranker    Reranker
 	vectorStore *embeddings.VectorStore
+	facets      *FacetService
+	collections *CollectionService
+	axes        *AxisService
+	journeys    *JourneyService
+	franchises  *FranchiseService
+	tuning      Tuning
+	feedback    exploreFeedback
+	deck        onboardingDeck
+	tasteLocks  tasteLocks
+}
+
+// Tuning holds server-wide ranking knobs
+type Tuning struct {
+	// RatingSignalStrength scales how far a user's extreme ratings boost or
+	// demote similar candidates (0 disables)
+	RatingSignalStrength float64
+	// RatingSignalThreshold is the minimum cosine for an anchor to apply
+	RatingSignalThreshold float64
+	// SimilarVibeWeight and SimilarCollabWeight blend embedding similarity
+	// with co-watch similarity in GetSimilarToMedia
+	SimilarVibeWeight   float64
+	SimilarCollabWeight float64
+	// ExplorePolicy is used when a request does not choose one
+	ExplorePolicy ExplorePolicy
+	// ExploreEpsilon is the per-slot swap probability for ExploreEpsilon
+	ExploreEpsilon float64
+	// NoveltyPenalty demotes titles shown to the user within NoveltyWindow,
+	// compounding per showing (0 disables). It applies only while exploring,
+	// so explore=off also turns it off for a reproducible ranking.
+	NoveltyPenalty float64
+	NoveltyWindow  time.Duration
+}
+
+// Reranker reorders a candidate pool for a query. *llm.Client is the
+// production implementation; the eval harness swaps in an offline fake.
+type Reranker interface {
+	RerankByVibe(query string, candidates []llm.RerankCandidate) ([]llm.RerankResult, error)
+}
+
+// DefaultTuning returns the ranking knobs used when nothing is configured
+func DefaultTuning() Tuning {
+	return Tuning{
+		RatingSignalStrength:  0.15,
+		RatingSignalThreshold: 0.5,
+		SimilarVibeWeight:     0.7,
+		SimilarCollabWeight:   0.3,
+		ExplorePolicy:         ExploreThompson,
+		ExploreEpsilon:        0.1,
+		NoveltyPenalty:        0.2,
+		NoveltyWindow:         72 * time.Hour,
+	}
+}
+
+// SearchStore is everything VibeSearchService and the facet, collection,
+// axis, journey and franchise services it owns read and write
+type SearchStore interface {
+	database.Store
+	database.DismissalRepository
+	database.WatchlistRepository
+	database.ProgressRepository
+	database.TasteRepository
+	database.OnboardingRepository
+	database.ImpressionRepository
+	database.CollabRepository
+	database.RuntimeRepository
+	database.GemRepository
+	database.TrendingRepository
+	database.GroupRepository
+	FacetStore
+	database.CollectionRepository
+	database.AxisRepository
+	JourneyStore
+	FranchiseStore
 }
 
 // NewVibeSearchService creates a new vibe search service
-func NewVibeSearchService(db *database.DB, embedder embeddings.Provider, llmClient *llm.Client) (*VibeSearchService, error) {
+func NewVibeSearchService(db SearchStore, embedder embeddings.Provider, llmClient *llm.Client) (*VibeSearchService, error) {
+	return newVibeSearchService(db, embedder, llmClient, false)
+}
+
+// NewReadOnlyVibeSearchService creates a vibe search service that leaves
+// the database as it found it: the facet vocabulary and default axes are
+// not seeded, and axis anchors and positions are not synced to the
+// embedder. The eval harness uses it to search a live database with an
+// alternate embedder. Searching works as usual; ingesting and defining
+// still write.
+func NewReadOnlyVibeSearchService(db SearchStore, embedder embeddings.Provider, llmClient *llm.Client) (*VibeSearchService, error) {
+	return newVibeSearchService(db, embedder, llmClient, true)
+}
+
+func newVibeSearchService(db SearchStore, embedder embeddings.Provider, llmClient *llm.Client, readOnly bool) (*VibeSearchService, error) {
+	facets, err := newFacetService(db, llmClient, !readOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init facets: %w", err)
+	}
+	collections, err := NewCollectionService(db, embedder, llmClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init collections: %w", err)
+	}
+	axes, err := newAxisService(db, embedder, !readOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init axes: %w", err)
+	}
+
 	svc := &VibeSearchService{
 		db:          db,
 		embedder:    embedder,
 		llmClient:   llmClient,
 		vectorStore: embeddings.NewVectorStore(),
+		facets:      facets,
+		collections: collections,
+		axes:        axes,
+		journeys:    NewJourneyService(db, embedder, llmClient),
+		franchises:  NewFranchiseService(db),
+		tuning:      DefaultTuning(),
+	}
+	if llmClient != nil {
+		svc.reranker = llmClient
 	}
 
 	// Load existing embeddings into memory
@@ -32,6 +145,18 @@ func NewVibeSearchService(db *database.DB, embedder embeddings.Provider, llmClie
 		return nil, fmt.Errorf("failed to load embeddings: %w", err)
 	}
 
+	if readOnly {
+		return svc, nil
+	}
+
+	// Place titles embedded since the last run on the vibe axes; a failure
+	// only leaves their sliders stale
+	if n, err := axes.Sync(svc.vectorStore); err != nil {
+		log.Printf("Failed to sync vibe axes: %v", err)
+	} else if n > 0 {
+		log.Printf("Projected %d title positions onto vibe axes", n)
+	}
+
 	return svc, nil
 }
 
@@ -70,6 +195,11 @@ func (s *VibeSearchService) IngestMedia(req models.VibeProfileRequest) (*models.
 		Year:        req.Year,
 		PlotSummary: req.Synopsis,
 		VibeProfile: vibeProfile,
+
+		RuntimeMinutes: req.RuntimeMinutes,
+		EpisodeRuntime: req.EpisodeRuntime,
+		EpisodeCount:   req.EpisodeCount,
+		SeasonCount:    req.SeasonCount,
 	}
 
 	if err := s.db.CreateMedia(media); err != nil {
@@ -88,17 +218,146 @@ func (s *VibeSearchService) IngestMedia(req models.VibeProfileRequest) (*models.
 
 	// Add to in-memory vector store
 	s.vectorStore.Add(media.ID, embedding)
+	if err := s.axes.ProjectMedia(media.ID, embedding); err != nil {
+		log.Printf("Failed to project %s onto vibe axes: %v", media.ID, err)
+	}
+
+	// Tag structured facets; a failure here shouldn't fail the ingest
+	if err := s.facets.TagMedia(media.ID); err != nil {
+		log.Printf("Failed to tag facets for %s: %v", media.ID, err)
+	}
 
 	return media, nil
 }
 
+// SetTuning replaces the ranking knobs
+func (s *VibeSearchService) SetTuning(t Tuning) {
+	s.tuning = t
+}
+
+// SetReranker replaces the reranker used when SearchConfig.UseReranking is
+// set (nil disables reranking)
+func (s *VibeSearchService) SetReranker(r Reranker) {
+	s.reranker = r
+}
+
+// Reindex re-embeds every vibe profile with the current embedder and
+// reloads the in-memory index. Nothing is written back to the database, so
+// an offline embedder can be evaluated against a production snapshot.
+func (s *VibeSearchService) Reindex() (int, error) {
+	profiles, err := s.db.GetAllVibeProfiles()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load vibe profiles: %w", err)
+	}
+
+	vecs := make(map[string][]float32, len(profiles))
+	for id, profile := range profiles {
+		vec, err := s.embedder.Embed(profile)
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed %s: %w", id, err)
+		}
+		vecs[id] = vec
+	}
+
+	s.vectorStore = embeddings.NewVectorStore()
+	s.vectorStore.LoadFromMap(vecs)
+	return len(vecs), nil
+}
+
+// Embedding returns the indexed vector for a media entry
+func (s *VibeSearchService) Embedding(mediaID string) ([]float32, bool) {
+	return s.vectorStore.Get(mediaID)
+}
+
+// EmbeddingModel names the embedder behind the index
+func (s *VibeSearchService) EmbeddingModel() string {
+	return s.embedder.ModelName()
+}
+
+// IndexSize returns how many media entries are searchable
+func (s *VibeSearchService) IndexSize() int {
+	return s.vectorStore.Size()
+}
+
+// Facets exposes the facet service for listing and validation
+func (s *VibeSearchService) Facets() *FacetService {
+	return s.facets
+}
+
+// Collections exposes the collection service
+func (s *VibeSearchService) Collections() *CollectionService {
+	return s.collections
+}
+
+// Axes exposes the vibe axis service for listing and validation
+func (s *VibeSearchService) Axes() *AxisService {
+	return s.axes
+}
+
+// DefineAxis creates or redefines a vibe axis and places every indexed
+// title on it
+func (s *VibeSearchService) DefineAxis(req models.AxisRequest) (*models.VibeAxis, error) {
+	return s.axes.Define(req, s.vectorStore)
+}
+
+// Journeys exposes the journey service
+func (s *VibeSearchService) Journeys() *JourneyService {
+	return s.journeys
+}
+
+// Franchises exposes the franchise service
+func (s *VibeSearchService) Franchises() *FranchiseService {
+	return s.franchises
+}
+
+// PlanJourney plans and saves a journey for the user across the indexed
+// titles
+func (s *VibeSearchService) PlanJourney(userID string, req models.JourneyRequest) (*models.Journey, error) {
+	return s.journeys.Plan(userID, req, s.vectorStore)
+}
+
 // SearchConfig holds configuration for a vibe search
 type SearchConfig struct {
 	UserID       string
 	Query        string
-	TopK         int  // Number of candidates to retrieve from vector search
-	FinalResults int  // Number of final results after reranking
-	UseReranking bool // Whether to use LLM reranking
+	TopK         int      // Number of candidates to retrieve from vector search
+	FinalResults int      // Number of final results after reranking
+	UseReranking bool     // Whether to use LLM reranking
+	Facets       []string // Facet slugs every result must carry (AND)
+
+	// PersonalizationWeight blends taste-profile similarity into ranking
+	// (0 = pure query match, 1 = pure taste match)
+	PersonalizationWeight float64
+	// RatingSignalStrength overrides Tuning.RatingSignalStrength when set
+	RatingSignalStrength *float64
+	// Watchlist decides how titles on the user's watchlist are treated
+	Watchlist WatchlistMode
+	// LexicalWeight blends query-term overlap with title and vibe profile
+	// into ranking (0 = pure vector search)
+	LexicalWeight float64
+	// MMRLambda diversifies the ranked pool with maximal marginal relevance
+	// (0 disables; closer to 1 favours relevance over novelty)
+	MMRLambda float64
+	// ExcludeSeenBy lists other users whose seen and dismissed titles are
+	// filtered too (watch-party rooms)
+	ExcludeSeenBy []string
+	// Explore swaps a few slots for deeper candidates and demotes titles the
+	// user was recently shown (ExploreNone keeps the ranking deterministic)
+	Explore ExplorePolicy
+	// Duration keeps only titles that fit the viewer's time budget
+	Duration models.DurationFilter
+	// Gems restricts results to these hidden gems and blends their gem
+	// score into ranking (hidden gems with a vibe query)
+	Gems map[string]models.GemScore
+	// Trending restricts results to these trending titles and blends their
+	// mention velocity into ranking (trending with a vibe query)
+	Trending map[string]models.TrendingScore
+	// Axes are validated vibe slider targets: filter-mode targets restrict
+	// results, soft ones demote titles by their distance from the target
+	Axes []models.AxisTarget
+	// ExpandFranchises lists every matching entry of a franchise instead of
+	// collapsing it to the entry point the user should watch next
+	ExpandFranchises bool
 }
 
 // SearchResult holds the result of a vibe search
@@ -106,14 +365,20 @@ type SearchResult struct {
 	Recommendations []models.Recommendation
 	Query           string
 	TotalCandidates int
-	FilteredCount   int // How many were filtered due to being seen
+	FilteredCount   int                     // How many were filtered due to being seen
+	Candidates      []models.ImpressionItem // Ranked pool before rerank/trim, for impression logging
+	Reranked        bool                    // Whether the LLM reordered the results
+	Explore         ExplorePolicy           // Exploration policy applied, if any
 }
 
 // Search performs the full vibe search pipeline:
 // 1. Convert query to vector
-// 2. Find top candidates via vector similarity
-// 3. Apply anti-join to filter seen media
-// 4. Optionally rerank via LLM
+// 2. Find top candidates via vector similarity (optionally facet/duration-restricted)
+// 3. Apply anti-join to filter seen and dismissed media
+// 4. Optionally blend in lexical matches and the user's taste profile
+// 5. Boost/demote candidates near titles the user rated highly/poorly
+// 6. Collapse franchises to entry points, optionally diversify (MMR) and rerank via LLM
+// 7. Optionally explore: demote recently shown titles and swap a few slots
 func (s *VibeSearchService) Search(config SearchConfig) (*SearchResult, error) {
 	// Set defaults
 	if config.TopK <= 0 {
@@ -129,73 +394,192 @@ func (s *VibeSearchService) Search(config SearchConfig) (*SearchResult, error) {
 		return nil, fmt.Errorf("failed to embed query: %w", err)
 	}
 
-	// Step 2: Get the user's seen media for filtering (anti-join)
-	seenIDs, err := s.db.GetSeenMediaIDs(config.UserID)
+	// Step 2: Get the user's seen and dismissed media for filtering (anti-join)
+	excludedIDs, err := s.db.GetExcludedMediaIDs(config.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get seen media: %w", err)
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
+	}
+	for _, other := range config.ExcludeSeenBy {
+		ids, err := s.db.GetExcludedMediaIDs(other)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get excluded media: %w", err)
+		}
+		for id := range ids {
+			excludedIDs[id] = true
+		}
+	}
+
+	// Step 3: Restrict to media carrying the requested facets, if any
+	var allowIDs map[string]bool
+	if len(config.Facets) > 0 {
+		allowIDs, err = s.facets.MatchingMediaIDs(config.Facets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by facets: %w", err)
+		}
+	}
+
+	// Step 3a: Restrict to titles that fit the time budget, to hidden gems
+	// or trending titles, and to the filtering vibe axis ranges, if asked
+	if config.Duration.Active() {
+		fitting, err := s.db.GetMediaIDsWithinDuration(config.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by duration: %w", err)
+		}
+		allowIDs = intersectIDs(allowIDs, fitting)
+	}
+
+	if config.Gems != nil {
+		gemIDs := make(map[string]bool, len(config.Gems))
+		for id := range config.Gems {
+			gemIDs[id] = true
+		}
+		allowIDs = intersectIDs(allowIDs, gemIDs)
+	}
+	if config.Trending != nil {
+		trendingIDs := make(map[string]bool, len(config.Trending))
+		for id := range config.Trending {
+			trendingIDs[id] = true
+		}
+		allowIDs = intersectIDs(allowIDs, trendingIDs)
+	}
+	if len(config.Axes) > 0 {
+		axisIDs, err := s.axes.MatchingMediaIDs(config.Axes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by vibe axes: %w", err)
+		}
+		if axisIDs != nil {
+			allowIDs = intersectIDs(allowIDs, axisIDs)
+		}
+	}
+
+	// Step 3b: Drop, restrict to, or just note the user's watchlist
+	var watchlistIDs map[string]bool
+	filteredCount := len(excludedIDs)
+	if config.Watchlist != WatchlistInclude {
+		watchlistIDs, err = s.db.GetWatchlistIDs(config.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get watchlist: %w", err)
+		}
+		switch config.Watchlist {
+		case WatchlistExclude:
+			for id := range watchlistIDs {
+				excludedIDs[id] = true
+			}
+		case WatchlistOnly:
+			allowIDs = intersectIDs(allowIDs, watchlistIDs)
+		}
 	}
 
-	// Step 3: Vector search with anti-join (exclude seen media)
-	candidates := s.vectorStore.Search(queryEmbedding, config.TopK, seenIDs)
+	// Step 4: Vector search with anti-join (exclude seen and dismissed
+	// media). Personalized, hybrid, diversified and exploring searches pull
+	// a wider net so later stages can promote deeper candidates.
+	topK := config.TopK
+	if config.PersonalizationWeight > 0 || config.LexicalWeight > 0 || config.MMRLambda > 0 || config.Explore.enabled() {
+		topK *= 2
+	}
+	candidates := s.vectorStore.SearchWithin(queryEmbedding, topK, allowIDs, excludedIDs)
 
 	if len(candidates) == 0 {
 		return &SearchResult{
 			Recommendations: []models.Recommendation{},
 			Query:           config.Query,
 			TotalCandidates: 0,
-			FilteredCount:   len(seenIDs),
+			FilteredCount:   filteredCount,
 		}, nil
 	}
 
-	// Step 4: Fetch full media details for candidates
-	var rerankCandidates []llm.RerankCandidate
+	// Step 5: Fetch full media details for candidates
+	var pool []models.Recommendation
 	for _, c := range candidates {
 		media, err := s.db.GetMedia(c.MediaID)
 		if err != nil || media == nil {
 			continue
 		}
-		rerankCandidates = append(rerankCandidates, llm.RerankCandidate{
-			Media:     *media,
-			VibeScore: c.Similarity,
+		pool = append(pool, models.Recommendation{
+			Media:       *media,
+			VibeScore:   c.Similarity,
+			Score:       c.Similarity,
+			OnWatchlist: watchlistIDs[c.MediaID],
 		})
 	}
 
-	// Step 5: Optionally rerank using LLM
+	// Step 6: Blend in lexical overlap, the user's taste profile, gem or
+	// trending scores and vibe axis distances, if requested
+	if config.Gems != nil {
+		applyGemScores(config.Gems, pool)
+	}
+	if config.Trending != nil {
+		applyTrendingScores(config.Trending, pool)
+	}
+	if len(config.Axes) > 0 {
+		if err := s.axes.apply(config.Axes, pool); err != nil {
+			log.Printf("Failed to apply vibe axes: %v", err)
+		}
+	}
+	if config.LexicalWeight > 0 {
+		applyLexicalMatch(config.Query, config.LexicalWeight, pool)
+	}
+	if config.PersonalizationWeight > 0 {
+		s.applyPersonalization(config.UserID, config.PersonalizationWeight, pool)
+	}
+
+	// Step 7: Let the user's own ratings boost or demote similar titles
+	strength := s.tuning.RatingSignalStrength
+	if config.RatingSignalStrength != nil {
+		strength = *config.RatingSignalStrength
+	}
+	before := scoresByID(pool)
+	s.applyUserRatingSignals(config.UserID, strength, pool)
+	if config.Explore.enabled() {
+		s.applyNovelty(config.UserID, pool)
+	}
+	adjustments := scoreFactors(before, pool)
+	sortByScore(pool)
+	if !config.ExpandFranchises {
+		pool = s.collapseFranchises(config.UserID, pool, allowIDs, excludedIDs)
+	}
+	if config.MMRLambda > 0 {
+		s.diversify(pool, config.MMRLambda)
+	}
+	ranked := rankedItems(pool)
+
+	// Step 8: Optionally rerank using LLM
 	var recommendations []models.Recommendation
+	rerankApplied := false
+
+	if config.UseReranking && s.reranker != nil && len(pool) > 0 {
+		rerankCandidates := make([]llm.RerankCandidate, len(pool))
+		for i, c := range pool {
+			rerankCandidates[i] = llm.RerankCandidate{Media: c.Media, VibeScore: c.VibeScore}
+		}
 
-	if config.UseReranking && s.llmClient != nil && len(rerankCandidates) > 0 {
 		// Use LLM to rerank based on vibe match
-		reranked, err := s.llmClient.RerankByVibe(config.Query, rerankCandidates)
+		reranked, err := s.reranker.RerankByVibe(config.Query, rerankCandidates)
 		if err != nil {
 			// Fall back to vector similarity ranking on error
-			for i, c := range rerankCandidates {
+			for i, c := range pool {
 				if i >= config.FinalResults {
 					break
 				}
-				recommendations = append(recommendations, models.Recommendation{
-					Media:       c.Media,
-					VibeScore:   c.VibeScore,
-					Explanation: fmt.Sprintf("Vibe match based on: %s", c.Media.VibeProfile),
-					Rank:        i + 1,
-				})
+				c.Explanation = fmt.Sprintf("Vibe match based on: %s", c.Media.VibeProfile)
+				c.Rank = i + 1
+				recommendations = append(recommendations, c)
 			}
 		} else {
 			// Build recommendations from reranked results
-			mediaMap := make(map[string]llm.RerankCandidate)
-			for _, c := range rerankCandidates {
-				mediaMap[c.Media.ID] = c
+			poolMap := make(map[string]models.Recommendation)
+			for _, c := range pool {
+				poolMap[c.Media.ID] = c
 			}
 
 			for _, r := range reranked {
-				if candidate, ok := mediaMap[r.MediaID]; ok {
-					recommendations = append(recommendations, models.Recommendation{
-						Media:       candidate.Media,
-						VibeScore:   candidate.VibeScore,
-						Explanation: r.Explanation,
-						Rank:        r.Rank,
-					})
+				if candidate, ok := poolMap[r.MediaID]; ok {
+					candidate.Explanation = r.Explanation
+					candidate.Rank = r.Rank
+					recommendations = append(recommendations, candidate)
 				}
 			}
+			rerankApplied = len(recommendations) > 0
 
 			// Add remaining candidates if we need more results
 			if len(recommendations) < config.FinalResults {
@@ -204,46 +588,58 @@ func (s *VibeSearchService) Search(config SearchConfig) (*SearchResult, error) {
 					rankedIDs[r.Media.ID] = true
 				}
 
-				for _, c := range rerankCandidates {
+				for _, c := range pool {
 					if len(recommendations) >= config.FinalResults {
 						break
 					}
 					if !rankedIDs[c.Media.ID] {
-						recommendations = append(recommendations, models.Recommendation{
-							Media:       c.Media,
-							VibeScore:   c.VibeScore,
-							Explanation: fmt.Sprintf("Similar vibe: %s", c.Media.VibeProfile),
-							Rank:        len(recommendations) + 1,
-						})
+						c.Explanation = fmt.Sprintf("Similar vibe: %s", c.Media.VibeProfile)
+						c.Rank = len(recommendations) + 1
+						recommendations = append(recommendations, c)
 					}
 				}
 			}
+
+			// The LLM only sees the query and profiles, so put the rating
+			// signals and novelty demotion back on top of its order
+			if rerankApplied {
+				reapplyAdjustments(recommendations, adjustments)
+			}
 		}
 	} else {
 		// No reranking - just use vector similarity order
-		for i, c := range rerankCandidates {
+		for i, c := range pool {
 			if i >= config.FinalResults {
 				break
 			}
-			recommendations = append(recommendations, models.Recommendation{
-				Media:       c.Media,
-				VibeScore:   c.VibeScore,
-				Explanation: fmt.Sprintf("Vibe match: %s", c.Media.VibeProfile),
-				Rank:        i + 1,
-			})
+			c.Explanation = fmt.Sprintf("Vibe match: %s", c.Media.VibeProfile)
+			c.Rank = i + 1
+			recommendations = append(recommendations, c)
 		}
 	}
 
+	// Step 9: Let the exploration policy trade a few slots for deeper picks
+	if config.Explore.enabled() {
+		recommendations = s.explore(config.Explore, recommendations, remainingPool(pool, recommendations))
+	}
+
+	if err := s.facets.AttachChips(recommendations); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+
 	return &SearchResult{
 		Recommendations: recommendations,
 		Query:           config.Query,
 		TotalCandidates: len(candidates),
-		FilteredCount:   len(seenIDs),
+		FilteredCount:   filteredCount,
+		Candidates:      ranked,
+		Reranked:        rerankApplied,
+		Explore:         config.Explore,
 	}, nil
 }
 
 // GetSimilarToMedia finds media similar to a specific title
-func (s *VibeSearchService) GetSimilarToMedia(userID, mediaID string, limit int) ([]models.Recommendation, error) {
+func (s *VibeSearchService) GetSimilarToMedia(userID, mediaID string, limit int) (*SearchResult, error) {
 	// Get the source media's embedding
 	sourceEmbedding, err := s.db.GetEmbedding(mediaID)
 	if err != nil {
@@ -253,77 +649,115 @@ func (s *VibeSearchService) GetSimilarToMedia(userID, mediaID string, limit int)
 		return nil, fmt.Errorf("no embedding found for media %s", mediaID)
 	}
 
-	// Get seen media for filtering
-	seenIDs, err := s.db.GetSeenMediaIDs(userID)
+	// Get seen and dismissed media for filtering
+	excludedIDs, err := s.db.GetExcludedMediaIDs(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get seen media: %w", err)
+		return nil, fmt.Errorf("failed to get excluded media: %w", err)
 	}
 
+	filteredCount := len(excludedIDs)
+
 	// Also exclude the source media itself
-	seenIDs[mediaID] = true
+	excludedIDs[mediaID] = true
 
 	// Search for similar
-	candidates := s.vectorStore.Search(sourceEmbedding, limit*2, seenIDs)
+	candidates := s.vectorStore.Search(sourceEmbedding, limit*2, excludedIDs)
 
-	var recommendations []models.Recommendation
-	for i, c := range candidates {
-		if i >= limit {
-			break
-		}
-		media, err := s.db.GetMedia(c.MediaID)
-		if err != nil || media == nil {
+	// Co-watch neighbors join the pool even when their vibe is further off
+	neighbors, err := s.db.GetItemNeighbors(mediaID, limit*2)
+	if err != nil {
+		log.Printf("Failed to load co-watch neighbors: %v", err)
+	}
+	collab := make(map[string]float64, len(neighbors))
+	for _, n := range neighbors {
+		if excludedIDs[n.NeighborID] {
 			continue
 		}
-		recommendations = append(recommendations, models.Recommendation{
-			Media:       *media,
-			VibeScore:   c.Similarity,
-			Explanation: fmt.Sprintf("Similar vibe to source: %s", media.VibeProfile),
-			Rank:        i + 1,
-		})
+		collab[n.NeighborID] = n.Score
 	}
 
-	return recommendations, nil
-}
+	vibe := make(map[string]float64, len(candidates)+len(collab))
+	var order []string
+	for _, c := range candidates {
+		vibe[c.MediaID] = c.Similarity
+		order = append(order, c.MediaID)
+	}
+	for id := range collab {
+		if _, ok := vibe[id]; ok {
+			continue
+		}
+		vec, ok := s.vectorStore.Get(id)
+		if !ok {
+			continue
+		}
+		vibe[id] = embeddings.CosineSimilarity(sourceEmbedding, vec)
+		order = append(order, id)
+	}
 
-// GetHiddenGems finds high-quality but less popular media
-func (s *VibeSearchService) GetHiddenGems(userID string, limit int) ([]models.Media, error) {
-	// Get seen media for filtering
-	seenIDs, err := s.db.GetSeenMediaIDs(userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get seen media: %w", err)
-	}
-
-	// Query for high quality_score but lower popularity_score
-	rows, err := s.db.Query(`
-		SELECT m.id, m.title, m.media_type, m.year, m.plot_summary, m.vibe_profile,
-		       m.quality_score, m.popularity_score, m.source_subreddit, m.external_id,
-		       m.created_at, m.updated_at
-		FROM media m
-		LEFT JOIN seen_media sm ON m.id = sm.media_id AND sm.user_id = ?
-		WHERE sm.media_id IS NULL
-		AND m.quality_score > m.popularity_score * 0.5
-		ORDER BY (m.quality_score - m.popularity_score * 0.3) DESC
-		LIMIT ?
-	`, userID, limit*2)
-	if err != nil {
-		return nil, err
+	var source *models.Media
+	if len(collab) > 0 {
+		source, _ = s.db.GetMedia(mediaID)
 	}
-	defer rows.Close()
 
-	var gems []models.Media
-	for rows.Next() {
-		var m models.Media
-		if err := rows.Scan(&m.ID, &m.Title, &m.MediaType, &m.Year, &m.PlotSummary,
-			&m.VibeProfile, &m.QualityScore, &m.PopularityScore,
-			&m.SourceSubreddit, &m.ExternalID, &m.CreatedAt, &m.UpdatedAt); err != nil {
+	var recommendations []models.Recommendation
+	for _, id := range order {
+		media, err := s.db.GetMedia(id)
+		if err != nil || media == nil {
 			continue
 		}
-		if !seenIDs[m.ID] && len(gems) < limit {
-			gems = append(gems, m)
+		rec := models.Recommendation{
+			Media:       *media,
+			VibeScore:   vibe[id],
+			Score:       vibe[id],
+			Explanation: fmt.Sprintf("Similar vibe to source: %s", media.VibeProfile),
 		}
+		// Blend only when co-watch data exists for the source, so titles
+		// without any keep plain vibe scores
+		if len(collab) > 0 {
+			rec.CollabScore = collab[id]
+			rec.Score = s.tuning.SimilarVibeWeight*vibe[id] + s.tuning.SimilarCollabWeight*collab[id]
+			if rec.CollabScore > 0 && source != nil {
+				rec.Explanation = fmt.Sprintf("People who watched %s also watched this", source.Title)
+			}
+		}
+		recommendations = append(recommendations, rec)
+	}
+
+	s.applyUserRatingSignals(userID, s.tuning.RatingSignalStrength, recommendations)
+	sortByScore(recommendations)
+	result := &SearchResult{
+		Query:           mediaID,
+		TotalCandidates: len(recommendations),
+		FilteredCount:   filteredCount,
+		Candidates:      rankedItems(recommendations),
+	}
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
 	}
+	for i := range recommendations {
+		recommendations[i].Rank = i + 1
+	}
+
+	if err := s.facets.AttachChips(recommendations); err != nil {
+		log.Printf("Failed to attach facet chips: %v", err)
+	}
+
+	result.Recommendations = recommendations
+	return result, nil
+}
 
-	return gems, nil
+// applyUserRatingSignals loads the user's rating anchors and applies them;
+// failures only cost the adjustment, never the request
+func (s *VibeSearchService) applyUserRatingSignals(userID string, strength float64, pool []models.Recommendation) {
+	if strength <= 0 || len(pool) == 0 {
+		return
+	}
+	signals, err := s.loadRatingSignals(userID)
+	if err != nil {
+		log.Printf("Failed to load rating signals for %s: %v", userID, err)
+		return
+	}
+	s.applyRatingSignals(pool, signals, strength, s.tuning.RatingSignalThreshold)
 }
 
 // RefreshEmbedding regenerates the vibe profile and embedding for a media entry
@@ -345,9 +779,7 @@ func (s *VibeSearchService) RefreshEmbedding(mediaID string) error {
 	}
 
 	// Update media
-	_, err = s.db.Exec(`UPDATE media SET vibe_profile = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
-		vibeProfile, mediaID)
-	if err != nil {
+	if err := s.db.UpdateVibeProfile(mediaID, vibeProfile); err != nil {
 		return fmt.Errorf("failed to update media: %w", err)
 	}
 
@@ -363,21 +795,28 @@ func (s *VibeSearchService) RefreshEmbedding(mediaID string) error {
 
 	// Update vector store
 	s.vectorStore.Add(mediaID, embedding)
+	if err := s.axes.ProjectMedia(mediaID, embedding); err != nil {
+		log.Printf("Failed to re-project %s onto vibe axes: %v", mediaID, err)
+	}
+
+	// The profile changed, so its facets are stale
+	if err := s.facets.TagMedia(mediaID); err != nil {
+		log.Printf("Failed to re-tag facets for %s: %v", mediaID, err)
+	}
 
 	return nil
 }
 
 // GetStats returns statistics about the vibe search index
 func (s *VibeSearchService) GetStats() map[string]interface{} {
-	var mediaCount, embeddingCount int
-	s.db.QueryRow(`SELECT COUNT(*) FROM media`).Scan(&mediaCount)
-	s.db.QueryRow(`SELECT COUNT(*) FROM vibe_embeddings`).Scan(&embeddingCount)
+	mediaCount, _ := s.db.CountMedia()
+	embeddingCount, _ := s.db.CountEmbeddings()
 
 	return map[string]interface{}{
-		"media_count":           mediaCount,
-		"embedding_count":       embeddingCount,
-		"vector_store_size":     s.vectorStore.Size(),
-		"embedding_model":       s.embedder.ModelName(),
+		"media_count":       mediaCount,
+		"embedding_count":   embeddingCount,
+		"vector_store_size": s.vectorStore.Size(),
+		"embedding_model":   s.embedder.ModelName(),
 	}
 }
 
@@ -393,6 +832,59 @@ func SortByVibeScore(recs []models.Recommendation) {
 	sort.Sort(ByVibeScore(recs))
 }
 
+// sortByScore orders recommendations by final ranking score, keeping the
+// incoming order for ties
+func sortByScore(recs []models.Recommendation) {
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].Score > recs[j].Score
+	})
+}
+
+// scoresByID snapshots each recommendation's score
+func scoresByID(recs []models.Recommendation) map[string]float64 {
+	scores := make(map[string]float64, len(recs))
+	for _, r := range recs {
+		scores[r.Media.ID] = r.Score
+	}
+	return scores
+}
+
+// scoreFactors returns how much each score changed since the snapshot, as
+// a ratio, leaving out titles whose score did not change
+func scoreFactors(before map[string]float64, recs []models.Recommendation) map[string]float64 {
+	factors := make(map[string]float64)
+	for _, r := range recs {
+		if b := before[r.Media.ID]; b > 0 && r.Score != b {
+			factors[r.Media.ID] = math.Max(r.Score, 0) / b
+		}
+	}
+	return factors
+}
+
+// reapplyAdjustments reorders a reranked list so score adjustments the
+// reranker never saw still count: each title's rerank position becomes a
+// score falling linearly from 1, scaled by its factor, and ranks are
+// renumbered in the new order
+func reapplyAdjustments(recs []models.Recommendation, factors map[string]float64) {
+	if len(factors) == 0 {
+		return
+	}
+	keys := make(map[string]float64, len(recs))
+	for i, r := range recs {
+		key := 1 - float64(i)/float64(len(recs))
+		if f, ok := factors[r.Media.ID]; ok {
+			key *= f
+		}
+		keys[r.Media.ID] = key
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		return keys[recs[i].Media.ID] > keys[recs[j].Media.ID]
+	})
+	for i := range recs {
+		recs[i].Rank = i + 1
+	}
+}
+
 // generateID creates a deterministic ID from title and type
 func generateID(title, mediaType string) string {
 	// Simple hash-like ID generation
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"w2w/internal/database"
+	"w2w/internal/models"
+)
+
+// letterEmbedder embeds text as its normalised letter counts, which is
+// enough to make texts sharing words land close together
+type letterEmbedder struct{ model string }
+
+func (e letterEmbedder) Embed(text string) ([]float32, error) {
+	vec := make([]float32, 26)
+	for _, r := range strings.ToLower(text) {
+		if r >= 'a' && r <= 'z' {
+			vec[r-'a']++
+		}
+	}
+	var sum float64
+	for _, v := range vec {
+		sum += float64(v) * float64(v)
+	}
+	if sum > 0 {
+		norm := float32(1 / math.Sqrt(sum))
+		for i := range vec {
+			vec[i] *= norm
+		}
+	}
+	return vec, nil
+}
+
+func (e letterEmbedder) ModelName() string { return e.model }
+
+// newTestDB opens a migrated SQLite database in a temporary directory
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestReadOnlyVibeSearchLeavesDatabaseAlone(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := NewReadOnlyVibeSearchService(db, letterEmbedder{"letters"}, nil); err != nil {
+		t.Fatalf("NewReadOnlyVibeSearchService: %v", err)
+	}
+	if facets, _ := db.ListFacets("", 0); len(facets) != 0 {
+		t.Errorf("read-only service seeded %d facets", len(facets))
+	}
+	if axes, _ := db.GetAxes(); len(axes) != 0 {
+		t.Errorf("read-only service seeded %d axes", len(axes))
+	}
+
+	// The server seeds and syncs with its own embedder
+	media := models.Media{ID: "m1", Title: "Arrival", MediaType: "movie", VibeProfile: "quiet awe"}
+	if err := db.CreateMedia(&media); err != nil {
+		t.Fatal(err)
+	}
+	vec, _ := letterEmbedder{}.Embed(media.VibeProfile)
+	if err := db.StoreEmbedding("m1", vec, "letters"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewVibeSearchService(db, letterEmbedder{"letters"}, nil); err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+	before, err := db.GetAxes()
+	if err != nil || len(before) == 0 {
+		t.Fatalf("server did not seed axes (%d, %v)", len(before), err)
+	}
+	positionsBefore, _ := db.GetAxisProjectionTimes()
+	if len(positionsBefore) == 0 {
+		t.Fatal("server did not project the title onto the axes")
+	}
+
+	// An offline run with another embedder must not re-embed them
+	svc, err := NewReadOnlyVibeSearchService(db, letterEmbedder{"offline"}, nil)
+	if err != nil {
+		t.Fatalf("NewReadOnlyVibeSearchService: %v", err)
+	}
+	if svc.IndexSize() != 1 {
+		t.Errorf("index size = %d, want the stored embedding loaded", svc.IndexSize())
+	}
+	after, _ := db.GetAxes()
+	for i := range after {
+		if after[i].Model != "letters" {
+			t.Errorf("axis %s model = %q after a read-only run, want letters", after[i].Slug, after[i].Model)
+		}
+	}
+	positionsAfter, _ := db.GetAxisProjectionTimes()
+	for slug, byID := range positionsBefore {
+		for id, at := range byID {
+			if !positionsAfter[slug][id].Equal(at) {
+				t.Errorf("position of %s on %s rewritten by a read-only run", id, slug)
+			}
+		}
+	}
+}
+
+// fixedEmbedder embeds every query as the same vector
+type fixedEmbedder []float32
+
+func (e fixedEmbedder) Embed(string) ([]float32, error) { return e, nil }
+func (e fixedEmbedder) ModelName() string               { return "fixed" }
+
+// rankingFixture indexes three titles around the query vector (1,0,0):
+// near sits at cosine 1, twin at 0.96 right next to it, and far at 0.6
+// off to one side. Only far mentions a heist.
+func rankingFixture(t *testing.T) *VibeSearchService {
+	t.Helper()
+	db := newTestDB(t)
+	for _, m := range []struct {
+		media models.Media
+		vec   []float32
+	}{
+		{models.Media{ID: "near", Title: "Near", MediaType: "movie", VibeProfile: "quiet awe"}, []float32{1, 0, 0}},
+		{models.Media{ID: "twin", Title: "Twin", MediaType: "movie", VibeProfile: "quiet wonder"}, []float32{0.96, 0.28, 0}},
+		{models.Media{ID: "far", Title: "Far", MediaType: "movie", VibeProfile: "slick heist caper"}, []float32{0.6, 0, 0.8}},
+	} {
+		if err := db.CreateMedia(&m.media); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.StoreEmbedding(m.media.ID, m.vec, "fixed"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc, err := NewVibeSearchService(db, fixedEmbedder{1, 0, 0}, nil)
+	if err != nil {
+		t.Fatalf("NewVibeSearchService: %v", err)
+	}
+	return svc
+}
+
+func resultIDs(result *SearchResult) []string {
+	ids := make([]string, len(result.Recommendations))
+	for i, r := range result.Recommendations {
+		ids[i] = r.Media.ID
+	}
+	return ids
+}
+
+func TestSearchHybridAndMMR(t *testing.T) {
+	svc := rankingFixture(t)
+
+	tests := []struct {
+		name   string
+		config SearchConfig
+		want   []string
+		scores []float64
+	}{
+		{
+			name:   "pure vector",
+			config: SearchConfig{Query: "heist"},
+			want:   []string{"near", "twin", "far"},
+			scores: []float64{1, 0.96, 0.6},
+		},
+		{
+			// Half vector, half keyword: far's 0.3 + 0.5 beats near's 0.5
+			name:   "hybrid lexical",
+			config: SearchConfig{Query: "heist", LexicalWeight: 0.5},
+			want:   []string{"far", "near", "twin"},
+			scores: []float64{0.8, 0.5, 0.48},
+		},
+		{
+			// After near, twin scores 0.3*0.96 - 0.7*0.96 = -0.384 and far
+			// 0.3*0.6 - 0.7*0.6 = -0.24, so the distinct title goes second
+			name:   "mmr favouring novelty",
+			config: SearchConfig{Query: "heist", MMRLambda: 0.3},
+			want:   []string{"near", "far", "twin"},
+			scores: []float64{1, 0.6, 0.96},
+		},
+		{
+			// 0.7*0.96 - 0.3*0.96 = 0.384 beats 0.7*0.6 - 0.3*0.6 = 0.24
+			name:   "mmr favouring relevance",
+			config: SearchConfig{Query: "heist", MMRLambda: 0.7},
+			want:   []string{"near", "twin", "far"},
+			scores: []float64{1, 0.96, 0.6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.UserID = "u1"
+			result, err := svc.Search(tt.config)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			got := resultIDs(result)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("order = %v, want %v", got, tt.want)
+			}
+			for i, r := range result.Recommendations {
+				if math.Abs(r.Score-tt.scores[i]) > 1e-6 {
+					t.Errorf("%s score = %.4f, want %.4f", r.Media.ID, r.Score, tt.scores[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package services
+
+// WatchlistMode decides how a search treats titles on the user's watchlist
+type WatchlistMode string
+
+const (
+	// WatchlistInclude ranks watchlist titles like any other
+	WatchlistInclude WatchlistMode = ""
+	// WatchlistExclude drops watchlist titles from results
+	WatchlistExclude WatchlistMode = "exclude"
+	// WatchlistHighlight keeps them and sets Recommendation.OnWatchlist
+	WatchlistHighlight WatchlistMode = "highlight"
+	// WatchlistOnly restricts the search to the watchlist
+	WatchlistOnly WatchlistMode = "only"
+)
+
+// ParseWatchlistMode validates a client-supplied mode. "only" is reserved
+// for the watchlist pick endpoint.
+func ParseWatchlistMode(mode string) (WatchlistMode, bool) {
+	switch WatchlistMode(mode) {
+	case WatchlistInclude, WatchlistExclude, WatchlistHighlight:
+		return WatchlistMode(mode), true
+	}
+	return WatchlistInclude, false
+}
+
+// intersectIDs narrows an allow-list; a nil allow-list means "everything"
+func intersectIDs(allow, ids map[string]bool) map[string]bool {
+	out := make(map[string]bool, len(ids))
+	for id := range ids {
+		if allow == nil || allow[id] {
+			out[id] = true
+		}
+	}
+	return out
+}
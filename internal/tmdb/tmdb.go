@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -168,6 +169,47 @@ type MovieDetails struct {
 	Keywords         *struct {
 		Keywords []Keyword `json:"keywords"`
 	} `json:"keywords,omitempty"`
+	BelongsToCollection *CollectionRef `json:"belongs_to_collection,omitempty"`
+}
+
+// CollectionRef names the collection (franchise) a movie belongs to.
+type CollectionRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CollectionDetails is a movie collection with every part in it.
+type CollectionDetails struct {
+	ID    int              `json:"id"`
+	Name  string           `json:"name"`
+	Parts []CollectionPart `json:"parts"`
+}
+
+// CollectionPart is one movie of a collection.
+type CollectionPart struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"release_date"`
+}
+
+// WatchOrder returns the movie's 1-based position in the collection by
+// release date, with unreleased parts last, or 0 if it is not a part.
+func (cd *CollectionDetails) WatchOrder(movieID int) int {
+	parts := make([]CollectionPart, len(cd.Parts))
+	copy(parts, cd.Parts)
+	sort.SliceStable(parts, func(i, j int) bool {
+		a, b := parts[i].ReleaseDate, parts[j].ReleaseDate
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		return a < b
+	})
+	for i, p := range parts {
+		if p.ID == movieID {
+			return i + 1
+		}
+	}
+	return 0
 }
 
 // TVDetails represents detailed info about a TV series.
@@ -193,6 +235,27 @@ type TVDetails struct {
 		Name string `json:"name"`
 	} `json:"created_by"`
 	EpisodeRunTime []int `json:"episode_run_time"`
+	NumberOfEpisodes int `json:"number_of_episodes"`
+	NumberOfSeasons  int `json:"number_of_seasons"`
+	LastEpisodeToAir *struct {
+		Runtime int `json:"runtime"`
+	} `json:"last_episode_to_air,omitempty"`
+}
+
+// EpisodeMinutes returns a typical episode runtime. TMDB has stopped filling
+// episode_run_time for many series, so the latest episode stands in.
+func (t *TVDetails) EpisodeMinutes() int {
+	if len(t.EpisodeRunTime) > 0 {
+		total := 0
+		for _, m := range t.EpisodeRunTime {
+			total += m
+		}
+		return total / len(t.EpisodeRunTime)
+	}
+	if t.LastEpisodeToAir != nil {
+		return t.LastEpisodeToAir.Runtime
+	}
+	return 0
 }
 
 // Genre represents a TMDB genre.
@@ -237,6 +300,17 @@ func (c *Client) GetMovieDetails(id int) (*MovieDetails, error) {
 	return &result, json.Unmarshal(data, &result)
 }
 
+// GetCollection fetches a movie collection and its parts.
+func (c *Client) GetCollection(id int) (*CollectionDetails, error) {
+	url := fmt.Sprintf("%s/collection/%d?language=en-US", baseURL, id)
+	data, err := c.doGet(url)
+	if err != nil {
+		return nil, err
+	}
+	var result CollectionDetails
+	return &result, json.Unmarshal(data, &result)
+}
+
 // GetTVDetails fetches full details for a TV series.
 func (c *Client) GetTVDetails(id int) (*TVDetails, error) {
 	url := fmt.Sprintf("%s/tv/%d?append_to_response=credits,keywords&language=en-US", baseURL, id)
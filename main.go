@@ -31,10 +31,18 @@ type Config struct {
 	OpenAIAPIKey       string
 	EnableScraper      bool
 	ScrapeInterval     time.Duration
+	CollabInterval     time.Duration
+	ImpressionTTL      time.Duration
+	JudgmentInterval   time.Duration
+	GemInterval        time.Duration
+	FeedRefresh        time.Duration
+	FeedRateLimit      int
+	PublicBaseURL      string
 	SessionSecret      string
 	AdminSecret        string
 	RateLimitPerMinute int
 	CORSAllowedOrigins []string
+	Tuning             services.Tuning
 }
 
 func loadConfig() *Config {
@@ -44,10 +52,31 @@ func loadConfig() *Config {
 		OpenAIAPIKey:       os.Getenv("OPENAI_API_KEY"),
 		EnableScraper:      getEnv("ENABLE_SCRAPER", "false") == "true",
 		ScrapeInterval:     1 * time.Hour,
+		CollabInterval:     1 * time.Hour,
+		ImpressionTTL:      30 * 24 * time.Hour,
+		JudgmentInterval:   24 * time.Hour,
+		GemInterval:        6 * time.Hour,
+		FeedRefresh:        6 * time.Hour,
+		FeedRateLimit:      getEnvInt("FEED_RATE_LIMIT_PER_MINUTE", 6),
+		PublicBaseURL:      os.Getenv("PUBLIC_BASE_URL"),
 		SessionSecret:      os.Getenv("SESSION_SECRET"),
 		AdminSecret:        os.Getenv("ADMIN_SECRET"),
 		RateLimitPerMinute: getEnvInt("RATE_LIMIT_PER_MINUTE", 20),
 		CORSAllowedOrigins: splitAndTrim(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		Tuning:             services.DefaultTuning(),
+	}
+
+	cfg.Tuning.RatingSignalStrength = getEnvFloat("RATING_SIGNAL_STRENGTH", cfg.Tuning.RatingSignalStrength)
+	cfg.Tuning.RatingSignalThreshold = getEnvFloat("RATING_SIGNAL_THRESHOLD", cfg.Tuning.RatingSignalThreshold)
+	cfg.Tuning.SimilarVibeWeight = getEnvFloat("SIMILAR_VIBE_WEIGHT", cfg.Tuning.SimilarVibeWeight)
+	cfg.Tuning.SimilarCollabWeight = getEnvFloat("SIMILAR_COLLAB_WEIGHT", cfg.Tuning.SimilarCollabWeight)
+	cfg.Tuning.ExploreEpsilon = getEnvFloat("EXPLORE_EPSILON", cfg.Tuning.ExploreEpsilon)
+	cfg.Tuning.NoveltyPenalty = getEnvFloat("NOVELTY_PENALTY", cfg.Tuning.NoveltyPenalty)
+	// EXPLORE_POLICY=off makes rankings deterministic unless a request opts in
+	if policy := os.Getenv("EXPLORE_POLICY"); policy != "" {
+		if p, ok := services.ParseExplorePolicy(policy, cfg.Tuning.ExplorePolicy); ok {
+			cfg.Tuning.ExplorePolicy = p
+		}
 	}
 
 	if interval := os.Getenv("SCRAPE_INTERVAL"); interval != "" {
@@ -55,6 +84,37 @@ func loadConfig() *Config {
 			cfg.ScrapeInterval = d
 		}
 	}
+	if interval := os.Getenv("COLLAB_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			cfg.CollabInterval = d
+		}
+	}
+	if window := os.Getenv("NOVELTY_WINDOW"); window != "" {
+		if d, err := time.ParseDuration(window); err == nil {
+			cfg.Tuning.NoveltyWindow = d
+		}
+	}
+	if interval := os.Getenv("JUDGMENT_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			cfg.JudgmentInterval = d
+		}
+	}
+	if interval := os.Getenv("GEM_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			cfg.GemInterval = d
+		}
+	}
+	if interval := os.Getenv("FEED_REFRESH_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			cfg.FeedRefresh = d
+		}
+	}
+	// IMPRESSION_RETENTION=0 turns impression logging off
+	if retention := os.Getenv("IMPRESSION_RETENTION"); retention != "" {
+		if d, err := time.ParseDuration(retention); err == nil {
+			cfg.ImpressionTTL = d
+		}
+	}
 
 	// A session secret is required to sign cookies. If one isn't provided,
 	// mint an ephemeral one so the app still runs — but sessions won't survive
@@ -65,6 +125,13 @@ func loadConfig() *Config {
 		log.Println("Sessions will be invalidated on restart. Set SESSION_SECRET in production.")
 	}
 
+	// Feed URLs are handed to third-party readers, so they are built from a
+	// configured address rather than the request's (client-controlled) Host
+	if cfg.PublicBaseURL == "" {
+		cfg.PublicBaseURL = "http://localhost:" + cfg.Port
+		log.Printf("WARNING: PUBLIC_BASE_URL not set. Feed URLs will point at %s.", cfg.PublicBaseURL)
+	}
+
 	return cfg
 }
 
@@ -85,6 +152,16 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+// getEnvFloat reads a float env var, falling back on missing/invalid values.
+func getEnvFloat(key string, fallback float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
 // splitAndTrim turns a comma-separated env value into a clean slice.
 func splitAndTrim(value string) []string {
 	if value == "" {
@@ -150,6 +227,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize vibe search: %v", err)
 	}
+	vibeSearch.SetTuning(cfg.Tuning)
 
 	// Initialize Reddit scraper
 	scraper := services.NewRedditScraper(db, llmClient)
@@ -163,8 +241,33 @@ func main() {
 		scraper.Start(ctx, cfg.ScrapeInterval)
 	}
 
+	// Co-watch neighbors are cheap to refresh incrementally, so always run
+	collab := services.NewCollabFilter(db)
+	collab.Start(ctx, cfg.CollabInterval)
+
+	// Hidden-gem scores from ratings, popularity and Reddit mentions
+	gems := services.NewGemScorer(db)
+	gems.Start(ctx, cfg.GemInterval)
+
+	// Impression log for offline evaluation, pruned past its retention window
+	impressions := services.NewImpressionLogger(db, cfg.ImpressionTTL)
+	impressions.Start(ctx, 1*time.Hour)
+
+	// Mine Reddit similar_to threads into judgments and track how well
+	// /similar agrees with them
+	judgments := services.NewJudgmentJob(db, vibeSearch)
+	judgments.Start(ctx, cfg.JudgmentInterval)
+
+	// Live voting rooms for watch-party groups, swept once they sit empty
+	rooms := services.NewRoomHub(vibeSearch)
+	rooms.Start(ctx, 5*time.Minute)
+
+	// Per-session Atom/RSS feeds, regenerated at most once per refresh
+	feeds := services.NewFeedService(db, vibeSearch, cfg.FeedRefresh, cfg.PublicBaseURL)
+
 	// Initialize handlers
-	h := handlers.NewHandler(db, vibeSearch, scraper)
+	h := handlers.NewHandler(db, vibeSearch, scraper, impressions, judgments, rooms,
+		feeds, middleware.NewFeedTokens(cfg.SessionSecret))
 
 	// Setup router (release mode disables debug logging / route dumps)
 	gin.SetMode(gin.ReleaseMode)
@@ -172,13 +275,15 @@ func main() {
 	r.TrustedPlatform = gin.PlatformCloudflare
 	r.Use(gin.Logger(), gin.Recovery())
 
-	// Global middleware: security headers, CORS, and anonymous sessions.
+	// Global middleware: request IDs, security headers, CORS, and anonymous sessions.
+	r.Use(middleware.RequestID())
 	r.Use(middleware.SecurityHeaders())
 	if len(cfg.CORSAllowedOrigins) > 0 {
 		r.Use(cors.New(cors.Config{
 			AllowOrigins:     cfg.CORSAllowedOrigins,
-			AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS"},
+			AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 			AllowHeaders:     []string{"Content-Type", "X-Admin-Secret"},
+			ExposeHeaders:    []string{middleware.RequestIDHeader},
 			AllowCredentials: true,
 			MaxAge:           12 * time.Hour,
 		}))
@@ -189,6 +294,10 @@ func main() {
 	rateLimit := middleware.RateLimit(cfg.RateLimitPerMinute)
 	// Admin guard requiring the X-Admin-Secret header.
 	adminAuth := middleware.AdminAuth(cfg.AdminSecret)
+	// Feed readers carry no cookie, so their polls are limited per feed token.
+	feedRateLimit := middleware.RateLimitParam(cfg.FeedRateLimit, "token")
+	// Cross-site WebSocket guard (CORS does not cover the upgrade).
+	wsOrigin := middleware.WebSocketOrigin(cfg.CORSAllowedOrigins)
 
 	// Health check
 	r.GET("/health", h.GetHealth)
@@ -200,21 +309,103 @@ func main() {
 		rg.POST("/seen", h.PostSeen)
 		rg.GET("/seen", h.GetSeen)
 		rg.DELETE("/seen", h.DeleteSeen)
+		rg.PUT("/progress", h.PutProgress)
+		rg.GET("/progress", h.GetProgress)
+		rg.GET("/continue-watching", h.GetContinueWatching)
+
+		// Onboarding quiz (session-scoped; the summary at the end may call the LLM)
+		rg.GET("/onboarding", rateLimit, h.GetOnboarding)
+		rg.POST("/onboarding/answers", rateLimit, h.PostOnboardingAnswer)
+		rg.DELETE("/onboarding", h.DeleteOnboarding)
+
+		// Dismissals (session-scoped)
+		rg.POST("/dismissals", h.PostDismissal)
+		rg.GET("/dismissals", h.GetDismissals)
+		rg.DELETE("/dismissals/:media_id", h.DeleteDismissal)
+
+		// Watchlist (session-scoped); pick embeds the query, so rate-limit it
+		rg.POST("/watchlist", h.PostWatchlist)
+		rg.GET("/watchlist", h.GetWatchlist)
+		rg.PATCH("/watchlist/:media_id", h.PatchWatchlistItem)
+		rg.DELETE("/watchlist/:media_id", h.DeleteWatchlistItem)
+		rg.POST("/watchlist/reorder", h.PostWatchlistReorder)
+		rg.GET("/watchlist/pick", rateLimit, h.GetWatchlistPick)
+
+		// Collections (owner-scoped). Edits regenerate the collection vibe
+		// via the LLM, so they are rate-limited.
+		rg.POST("/collections", rateLimit, h.PostCollection)
+		rg.GET("/collections", h.GetCollections)
+		rg.GET("/collections/search", rateLimit, h.GetCollectionSearch)
+		rg.GET("/collections/recommended", h.GetRecommendedCollections)
+		rg.GET("/collections/:id", h.GetCollection)
+		rg.PATCH("/collections/:id", rateLimit, h.PatchCollection)
+		rg.DELETE("/collections/:id", h.DeleteCollection)
+		rg.POST("/collections/:id/items", rateLimit, h.PostCollectionItem)
+		rg.PATCH("/collections/:id/items/:media_id", rateLimit, h.PatchCollectionItem)
+		rg.DELETE("/collections/:id/items/:media_id", rateLimit, h.DeleteCollectionItem)
+		rg.POST("/collections/:id/reorder", h.PostCollectionReorder)
+		rg.GET("/shared/:slug", h.GetSharedCollection)
+		rg.POST("/shared/:slug/import", h.PostImportCollection)
+
+		// Vibe journeys (LLM transition notes, so rate-limited to create)
+		rg.POST("/journeys", rateLimit, h.PostJourney)
+		rg.GET("/journeys", h.GetJourneys)
+		rg.GET("/journeys/shared/:slug", h.GetSharedJourney)
+		rg.GET("/journeys/:id", h.GetJourney)
+		rg.DELETE("/journeys/:id", h.DeleteJourney)
+
+		// Watch-party groups (members only); recommend calls OpenAI and join is
+		// rate-limited so invite codes cannot be enumerated
+		rg.POST("/groups", h.PostGroup)
+		rg.POST("/groups/join", rateLimit, h.PostJoinGroup)
+		rg.GET("/groups", h.GetGroups)
+		rg.GET("/groups/:id", h.GetGroup)
+		rg.DELETE("/groups/:id/members/me", h.DeleteGroupMembership)
+		rg.GET("/groups/:id/recommend", rateLimit, h.GetGroupRecommend)
+		rg.GET("/groups/:id/room", wsOrigin, h.GetGroupRoom)
 
 		// Recommendation endpoints (The Core) — rate-limited (OpenAI cost)
 		rg.POST("/recommend", rateLimit, h.PostRecommend)
 		rg.GET("/vibe", rateLimit, h.GetRecommendSimple)
 		rg.GET("/similar/:media_id", h.GetSimilar)
-		rg.GET("/hidden-gems", h.GetHiddenGems)
+		rg.POST("/bridge", rateLimit, h.PostBridge)
+		rg.GET("/hidden-gems", rateLimit, h.GetHiddenGems)
+		rg.GET("/trending", rateLimit, h.GetTrending)
+		rg.GET("/for-you", rateLimit, h.GetForYou)
+		rg.GET("/facets", h.GetFacets)
+		rg.GET("/axes", h.GetAxes)
+		rg.GET("/franchises", h.GetFranchises)
+		rg.GET("/franchises/:id", h.GetFranchise)
+
+		// Atom/RSS feed of fresh picks; the feed URLs carry a signed token
+		// instead of the session cookie
+		rg.POST("/feed", rateLimit, h.PostFeed)
+		rg.GET("/feed", h.GetFeedInfo)
+		rg.DELETE("/feed", h.DeleteFeed)
+		rg.GET("/feeds/:token/atom.xml", feedRateLimit, h.GetFeedAtom)
+		rg.GET("/feeds/:token/rss.xml", feedRateLimit, h.GetFeedRSS)
+
+		// Interactions against a logged recommendation response
+		rg.POST("/interactions", h.PostInteraction)
 
 		// Media management endpoints — rate-limited (OpenAI cost)
 		rg.POST("/media", rateLimit, h.PostMedia)
 		rg.GET("/media/:id", h.GetMedia)
+		rg.GET("/media/:id/also-watched", h.GetAlsoWatched)
+		rg.GET("/media/:id/franchise", h.GetMediaFranchise)
 		rg.POST("/media/:id/refresh", rateLimit, h.PostRefreshVibe)
 
 		// Admin endpoints — behind shared-secret auth
 		rg.GET("/stats", adminAuth, h.GetStats)
 		rg.POST("/admin/scrape", adminAuth, h.PostScrapeNow)
+		rg.POST("/admin/axes", adminAuth, h.PostAxis)
+		rg.DELETE("/admin/axes/:slug", adminAuth, h.DeleteAxis)
+		rg.POST("/admin/franchises", adminAuth, h.PostFranchise)
+		rg.DELETE("/admin/franchises/:id", adminAuth, h.DeleteFranchise)
+		rg.GET("/admin/impressions/export", adminAuth, h.GetImpressionExport)
+		rg.GET("/admin/judgments/export", adminAuth, h.GetJudgmentExport)
+		rg.GET("/admin/similar-quality", adminAuth, h.GetSimilarQuality)
+		rg.POST("/admin/similar-quality/run", adminAuth, h.PostSimilarQualityRun)
 	}
 
 	// API routes with /api prefix (for production where frontend is served from same origin)
@@ -239,6 +430,11 @@ func main() {
 		log.Println("Shutting down...")
 		cancel()
 		scraper.Stop()
+		collab.Stop()
+		gems.Stop()
+		impressions.Stop()
+		judgments.Stop()
+		rooms.Stop()
 		os.Exit(0)
 	}()
 
@@ -255,9 +451,30 @@ func main() {
 	fmt.Println("\nEndpoints:")
 	fmt.Println("  POST /seen           - Mark media as watched")
 	fmt.Println("  GET  /seen           - Get your watch history")
+	fmt.Println("  PUT  /progress       - Set watch status and episode progress")
+	fmt.Println("  GET  /continue-watching - Pick up where you left off")
+	fmt.Println("  GET  /onboarding     - Quick quiz to seed your taste profile")
+	fmt.Println("  POST /dismissals     - Hide a recommendation (not interested/snooze)")
+	fmt.Println("  POST /watchlist      - Save a title for later")
+	fmt.Println("  GET  /watchlist/pick?q=... - Pick from your watchlist for a vibe")
+	fmt.Println("  POST /collections    - Curate a shareable list")
+	fmt.Println("  GET  /shared/:slug   - View a shared collection")
+	fmt.Println("  POST /journeys       - Plan a watch sequence from one vibe to another")
+	fmt.Println("  POST /groups         - Start a watch party (share the invite code)")
+	fmt.Println("  GET  /groups/:id/recommend - Picks for the whole group")
+	fmt.Println("  GET  /groups/:id/room - Live voting room (WebSocket)")
 	fmt.Println("  POST /recommend      - Get vibe-based recommendations")
 	fmt.Println("  GET  /vibe?q=...     - Quick vibe search")
-	fmt.Println("  GET  /hidden-gems    - Discover quality hidden gems")
+	fmt.Println("  POST /bridge         - Something between two titles")
+	fmt.Println("  GET  /hidden-gems    - Well-rated, little-known titles (?q=&type=)")
+	fmt.Println("  GET  /trending       - Titles Reddit is buzzing about (?window=24h|7d|30d)")
+	fmt.Println("  GET  /for-you        - Picks from your taste profile")
+	fmt.Println("  GET  /facets         - Browse vibe facets")
+	fmt.Println("  GET  /axes           - Vibe sliders (dark-light, slow-frenetic, ...)")
+	fmt.Println("  GET  /franchises/:id - Watch order and where to start a franchise")
+	fmt.Println("  POST /feed           - Get Atom/RSS feed URLs for your picks")
+	fmt.Println("  POST /interactions   - Report what you did with a recommendation")
+	fmt.Println("  GET  /media/:id/also-watched - People who watched this also watched")
 	fmt.Println("  POST /media          - Add new media to database")
 	fmt.Println("  GET  /stats          - System statistics")
 	fmt.Println("")